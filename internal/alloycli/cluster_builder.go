@@ -1,6 +1,7 @@
 package alloycli
 
 import (
+	"bytes"
 	"fmt"
 	"net"
 	"os"
@@ -25,11 +26,16 @@ type ClusterOptions struct {
 	EnableClustering       bool
 	MinimumClusterSize     int
 	MinimumSizeWaitTimeout time.Duration
+	HandoffTimeout         time.Duration
+	SharedSecretFile       string
 	NodeName               string
+	NodeWeight             string
+	Zone                   string
 	AdvertiseAddress       string
 	ListenAddress          string
 	JoinPeers              []string
 	DiscoverPeers          string
+	KubernetesService      string
 	RejoinInterval         time.Duration
 	AdvertiseInterfaces    []string
 	ClusterMaxJoinPeers    int
@@ -48,7 +54,7 @@ func buildClusterService(opts ClusterOptions) (*cluster.Service, error) {
 // NewClusterService is visible to make it easier to test clustering e2e.
 func NewClusterService(
 	opts ClusterOptions,
-	getDiscoveryFn func(options discovery.Options) (discovery.DiscoverFn, error),
+	getDiscoveryFn func(options discovery.Options) (discovery.DiscoverFn, discovery.WatchFn, error),
 ) (*cluster.Service, error) {
 
 	listenPort := findPort(opts.ListenAddress, 80)
@@ -61,7 +67,9 @@ func NewClusterService(
 		EnableClustering:       opts.EnableClustering,
 		MinimumClusterSize:     opts.MinimumClusterSize,
 		MinimumSizeWaitTimeout: opts.MinimumSizeWaitTimeout,
+		HandoffTimeout:         opts.HandoffTimeout,
 		NodeName:               opts.NodeName,
+		Zone:                   opts.Zone,
 		RejoinInterval:         opts.RejoinInterval,
 		ClusterMaxJoinPeers:    opts.ClusterMaxJoinPeers,
 		ClusterName:            opts.ClusterName,
@@ -81,17 +89,44 @@ func NewClusterService(
 	}
 
 	var err error
+	config.NodeWeight, err = parseNodeWeight(opts.NodeWeight)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.SharedSecretFile != "" {
+		secret, err := os.ReadFile(opts.SharedSecretFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading cluster shared secret: %w", err)
+		}
+		config.SharedSecret = bytes.TrimSpace(secret)
+		if len(config.SharedSecret) == 0 {
+			return nil, fmt.Errorf("cluster shared secret file %s is empty", opts.SharedSecretFile)
+		}
+	}
+	if opts.EnableClustering && opts.HandoffTimeout > 0 && len(config.SharedSecret) == 0 {
+		return nil, fmt.Errorf("--cluster.handoff-timeout requires --cluster.shared-secret-file to authenticate handoffs between nodes")
+	}
+
 	config.AdvertiseAddress, err = getAdvertiseAddress(opts, listenPort)
 	if err != nil {
 		return nil, err
 	}
 
-	config.DiscoverPeers, err = getDiscoveryFn(discovery.Options{
-		JoinPeers:     opts.JoinPeers,
-		DiscoverPeers: opts.DiscoverPeers,
-		DefaultPort:   listenPort,
-		Logger:        opts.Log,
-		Tracer:        opts.Tracer,
+	// Only Kubernetes discovery watches for changes to peers.
+	var peersChanged chan struct{}
+	if opts.KubernetesService != "" {
+		peersChanged = make(chan struct{}, 1)
+		config.PeersChanged = peersChanged
+	}
+	config.DiscoverPeers, config.WatchPeers, err = getDiscoveryFn(discovery.Options{
+		JoinPeers:         opts.JoinPeers,
+		DiscoverPeers:     opts.DiscoverPeers,
+		KubernetesService: opts.KubernetesService,
+		DefaultPort:       listenPort,
+		PeersChanged:      peersChanged,
+		Logger:            opts.Log,
+		Tracer:            opts.Tracer,
 	})
 	if err != nil {
 		return nil, err
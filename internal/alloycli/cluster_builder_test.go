@@ -62,3 +62,22 @@ func TestGetAdvertiseAddress(t *testing.T) {
 		require.Equal(t, "127.0.0.1:80", addr)
 	})
 }
+
+func TestParseNodeWeight(t *testing.T) {
+	weight, err := parseNodeWeight("")
+	require.NoError(t, err)
+	require.Equal(t, 1.0, weight)
+
+	weight, err = parseNodeWeight("2.5")
+	require.NoError(t, err)
+	require.Equal(t, 2.5, weight)
+
+	weight, err = parseNodeWeight("cpu")
+	require.NoError(t, err)
+	require.Positive(t, weight)
+
+	for _, invalid := range []string{"0", "-1", "gpu"} {
+		_, err = parseNodeWeight(invalid)
+		require.ErrorContains(t, err, "invalid node weight")
+	}
+}
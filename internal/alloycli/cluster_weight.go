@@ -0,0 +1,78 @@
+package alloycli
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/KimMachineGun/automemlimit/memlimit"
+)
+
+// Special values of the --cluster.node-weight flag.
+const (
+	nodeWeightCPU    = "cpu"
+	nodeWeightMemory = "memory"
+)
+
+// cgroupCPUMaxPath is the file holding the CPU limit of the cgroup (v2) of the
+// process.
+const cgroupCPUMaxPath = "/sys/fs/cgroup/cpu.max"
+
+// parseNodeWeight parses the value of the --cluster.node-weight flag. The
+// weight is either a positive number, the number of CPUs available to the
+// process for "cpu", or the memory available to the process in GiB for
+// "memory". An empty string returns a weight of 1.
+func parseNodeWeight(s string) (float64, error) {
+	switch s {
+	case "":
+		return 1, nil
+	case nodeWeightCPU:
+		return cpuLimit(), nil
+	case nodeWeightMemory:
+		limit, err := memoryLimit()
+		if err != nil {
+			return 0, fmt.Errorf("determining memory limit for node weight: %w", err)
+		}
+		return float64(limit) / (1 << 30), nil
+	}
+
+	weight, err := strconv.ParseFloat(s, 64)
+	if err != nil || weight <= 0 {
+		return 0, fmt.Errorf("invalid node weight %q: must be a positive number, %q or %q", s, nodeWeightCPU, nodeWeightMemory)
+	}
+	return weight, nil
+}
+
+// cpuLimit returns the CPU quota of the cgroup of the process, or the number
+// of CPUs if there is no quota.
+func cpuLimit() float64 {
+	cpus := float64(runtime.NumCPU())
+
+	data, err := os.ReadFile(cgroupCPUMaxPath)
+	if err != nil {
+		return cpus
+	}
+	// The file holds the quota and the period, where the quota is "max" if
+	// there is no limit.
+	fields := strings.Fields(string(data))
+	if len(fields) != 2 || fields[0] == "max" {
+		return cpus
+	}
+	quota, err1 := strconv.ParseFloat(fields[0], 64)
+	period, err2 := strconv.ParseFloat(fields[1], 64)
+	if err1 != nil || err2 != nil || quota <= 0 || period <= 0 {
+		return cpus
+	}
+	return min(quota/period, cpus)
+}
+
+// memoryLimit returns the memory limit of the cgroup of the process, or the
+// total memory of the system if there is no limit.
+func memoryLimit() (uint64, error) {
+	if limit, err := memlimit.FromCgroup(); err == nil && limit > 0 {
+		return limit, nil
+	}
+	return memlimit.FromSystem()
+}
@@ -66,6 +66,7 @@ func runCommand() *cobra.Command {
 		clusterAdvInterfaces:  advertise.DefaultInterfaces,
 		clusterMaxJoinPeers:   5,
 		clusterRejoinInterval: 60 * time.Second,
+		clusterNodeWeight:     "1",
 		disableSupportBundle:  false,
 		windowsPriority:       windowspriority.PriorityNormal,
 	}
@@ -123,12 +124,18 @@ depending on the nature of the reload error.
 		BoolVar(&r.clusterEnabled, "cluster.enabled", r.clusterEnabled, "Start in clustered mode")
 	cmd.Flags().
 		StringVar(&r.clusterNodeName, "cluster.node-name", r.clusterNodeName, "The name to use for this node")
+	cmd.Flags().
+		StringVar(&r.clusterNodeWeight, "cluster.node-weight", r.clusterNodeWeight, `The capacity weight of this node, either a positive number, "cpu" or "memory"`)
+	cmd.Flags().
+		StringVar(&r.clusterZone, "cluster.zone", r.clusterZone, "The failure zone of this node, used to spread replicas of clustered work across zones")
 	cmd.Flags().
 		StringVar(&r.clusterAdvAddr, "cluster.advertise-address", r.clusterAdvAddr, "Address to advertise to the cluster")
 	cmd.Flags().
 		StringVar(&r.clusterJoinAddr, "cluster.join-addresses", r.clusterJoinAddr, "Comma-separated list of addresses to join the cluster at")
 	cmd.Flags().
 		StringVar(&r.clusterDiscoverPeers, "cluster.discover-peers", r.clusterDiscoverPeers, "List of key-value tuples for discovering peers")
+	cmd.Flags().
+		StringVar(&r.clusterKubernetesService, "cluster.kubernetes-service", r.clusterKubernetesService, "Kubernetes Service, in the form [namespace/]name, whose ready endpoints are the peers to join")
 	cmd.Flags().
 		StringSliceVar(&r.clusterAdvInterfaces, "cluster.advertise-interfaces", r.clusterAdvInterfaces, "List of interfaces used to infer an address to advertise")
 	cmd.Flags().
@@ -151,6 +158,10 @@ depending on the nature of the reload error.
 		IntVar(&r.clusterWaitForSize, "cluster.wait-for-size", r.clusterWaitForSize, "Wait for the cluster to reach the specified number of instances before allowing components that use clustering to begin processing. Zero means disabled")
 	cmd.Flags().
 		DurationVar(&r.clusterWaitTimeout, "cluster.wait-timeout", 0, "Maximum duration to wait for minimum cluster size before proceeding with available nodes. Zero means wait forever, no timeout")
+	cmd.Flags().
+		DurationVar(&r.clusterHandoffTimeout, "cluster.handoff-timeout", 0, "Maximum duration to hand off work to its new owner when it moves between nodes, including on shutdown. Zero means disabled")
+	cmd.Flags().
+		StringVar(&r.clusterSharedSecretFile, "cluster.shared-secret-file", r.clusterSharedSecretFile, "Path to a file holding a secret shared by all nodes, used to authenticate requests between nodes")
 
 	// Config flags
 	cmd.Flags().StringVar(&r.configFormat, "config.format", r.configFormat, fmt.Sprintf("The format of the source file. Supported formats: %s.", supportedFormatsList()))
@@ -181,9 +192,12 @@ type alloyRun struct {
 	disableReporting             bool
 	clusterEnabled               bool
 	clusterNodeName              string
+	clusterNodeWeight            string
+	clusterZone                  string
 	clusterAdvAddr               string
 	clusterJoinAddr              string
 	clusterDiscoverPeers         string
+	clusterKubernetesService     string
 	clusterAdvInterfaces         []string
 	clusterRejoinInterval        time.Duration
 	clusterMaxJoinPeers          int
@@ -195,6 +209,8 @@ type alloyRun struct {
 	clusterTLSServerName         string
 	clusterWaitForSize           int
 	clusterWaitTimeout           time.Duration
+	clusterHandoffTimeout        time.Duration
+	clusterSharedSecretFile      string
 	configFormat                 string
 	configBypassConversionErrors bool
 	configExtraArgs              string
@@ -294,10 +310,13 @@ func (fr *alloyRun) Run(cmd *cobra.Command, configPath string) error {
 
 		EnableClustering:       fr.clusterEnabled,
 		NodeName:               fr.clusterNodeName,
+		NodeWeight:             fr.clusterNodeWeight,
+		Zone:                   fr.clusterZone,
 		AdvertiseAddress:       fr.clusterAdvAddr,
 		ListenAddress:          fr.httpListenAddr,
 		JoinPeers:              splitPeers(fr.clusterJoinAddr, ","),
 		DiscoverPeers:          fr.clusterDiscoverPeers,
+		KubernetesService:      fr.clusterKubernetesService,
 		RejoinInterval:         fr.clusterRejoinInterval,
 		AdvertiseInterfaces:    fr.clusterAdvInterfaces,
 		ClusterMaxJoinPeers:    fr.clusterMaxJoinPeers,
@@ -309,6 +328,8 @@ func (fr *alloyRun) Run(cmd *cobra.Command, configPath string) error {
 		TLSServerName:          fr.clusterTLSServerName,
 		MinimumClusterSize:     fr.clusterWaitForSize,
 		MinimumSizeWaitTimeout: fr.clusterWaitTimeout,
+		HandoffTimeout:         fr.clusterHandoffTimeout,
+		SharedSecretFile:       fr.clusterSharedSecretFile,
 	})
 	if err != nil {
 		return err
@@ -351,6 +372,7 @@ func (fr *alloyRun) Run(cmd *cobra.Command, configPath string) error {
 	if err != nil {
 		return fmt.Errorf("failed to create the remotecfg service: %w", err)
 	}
+	clusterService.SetRemoteConfig(remoteCfgService)
 
 	liveDebuggingService := livedebugging.New()
 
@@ -404,16 +426,20 @@ func (fr *alloyRun) Run(cmd *cobra.Command, configPath string) error {
 		if err := f.LoadSource(alloySource, nil, configPath); err != nil {
 			return sources, fmt.Errorf("error during the initial load: %w", err)
 		}
+		clusterService.SetConfigHash(fmt.Sprintf("%x", hashSourceFiles(sources)))
 
 		return sources, nil
 	}
 
-	// Alloy controller
+	// Alloy controller. The controller keeps running after an interrupt until
+	// the cluster is drained, so that components can hand off their work.
+	runCtx, runCancel := context.WithCancel(context.Background())
+	defer runCancel()
 	{
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			f.Run(ctx)
+			f.Run(runCtx)
 		}()
 	}
 
@@ -470,6 +496,9 @@ func (fr *alloyRun) Run(cmd *cobra.Command, configPath string) error {
 	for {
 		select {
 		case <-ctx.Done():
+			if err := clusterService.Drain(context.Background()); err != nil {
+				level.Error(l).Log("msg", "failed to drain cluster node", "err", err)
+			}
 			return nil
 		case <-reloadSignal:
 			if _, err := reload(); err != nil {
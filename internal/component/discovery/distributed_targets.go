@@ -1,6 +1,8 @@
 package discovery
 
 import (
+	"slices"
+
 	"github.com/grafana/ckit/peer"
 	"github.com/grafana/ckit/shard"
 
@@ -12,8 +14,10 @@ import (
 type DistributedTargets struct {
 	localTargets []Target
 	// localTargetKeys is used to cache the key hash computation. Improves time performance by ~20%.
-	localTargetKeys  []shard.Key
-	remoteTargetKeys map[shard.Key]struct{}
+	localTargetKeys []shard.Key
+	// remoteTargetKeys holds the owner of every remote target, or its first
+	// owner if targets are replicated.
+	remoteTargetKeys map[shard.Key]peer.Peer
 }
 
 // NewDistributedTargets creates the abstraction that allows components to
@@ -26,14 +30,41 @@ func NewDistributedTargets(clusteringEnabled bool, cluster cluster.Cluster, allT
 // dynamically shard targets between components. Passing in labels will limit the sharding to only use those labels for computing the hash key.
 // Passing in nil or empty array means look at all labels.
 func NewDistributedTargetsWithCustomLabels(clusteringEnabled bool, cluster cluster.Cluster, allTargets []Target, labels []string) *DistributedTargets {
-	if !clusteringEnabled || cluster == nil {
-		cluster = disabledCluster{}
+	return newDistributedTargets(clusteringEnabled, cluster, allTargets, labels, "", 1)
+}
+
+// NewCostAwareDistributedTargets creates the abstraction that allows components
+// to dynamically shard targets between components, so that every component
+// gets a share of the total cost of targets proportional to the capacity
+// weight of its node. The cost of targets is shared in the cluster under
+// scope with [cluster.CostAwareCluster.ReportCosts].
+//
+// If the cluster doesn't support distributing targets by cost, targets are
+// sharded like with NewDistributedTargets.
+func NewCostAwareDistributedTargets(clusteringEnabled bool, cluster cluster.Cluster, allTargets []Target, scope string) *DistributedTargets {
+	return newDistributedTargets(clusteringEnabled, cluster, allTargets, nil, scope, 1)
+}
+
+// NewReplicatedDistributedTargets creates the abstraction that allows
+// components to dynamically shard targets between components, so that every
+// target is owned by replicas peers in different failure zones. Targets are
+// local if the local node is one of their owners.
+//
+// If the cluster doesn't support replication, targets are sharded like with
+// NewDistributedTargets.
+func NewReplicatedDistributedTargets(clusteringEnabled bool, cluster cluster.Cluster, allTargets []Target, replicas int) *DistributedTargets {
+	return newDistributedTargets(clusteringEnabled, cluster, allTargets, nil, "", replicas)
+}
+
+func newDistributedTargets(clusteringEnabled bool, c cluster.Cluster, allTargets []Target, labels []string, costScope string, replicas int) *DistributedTargets {
+	if !clusteringEnabled || c == nil {
+		c = disabledCluster{}
 	}
 
 	var localCap int
-	if !cluster.Ready() {
+	if !c.Ready() {
 		localCap = 0 // cluster not ready - won't take any traffic locally
-	} else if peerCount := len(cluster.Peers()); peerCount != 0 {
+	} else if peerCount := len(c.Peers()); peerCount != 0 {
 		localCap = (len(allTargets) + 1) / peerCount // if we have peers - calculate expected capacity
 	} else {
 		localCap = len(allTargets) // cluster ready but no peers? fall back to all traffic locally
@@ -41,10 +72,14 @@ func NewDistributedTargetsWithCustomLabels(clusteringEnabled bool, cluster clust
 
 	localTargets := make([]Target, 0, localCap)
 	localTargetKeys := make([]shard.Key, 0, localCap)
-	remoteTargetKeys := make(map[shard.Key]struct{}, len(allTargets)-localCap)
+	remoteTargetKeys := make(map[shard.Key]peer.Peer, len(allTargets)-localCap)
 
 	// Need to handle duplicate entries.
-	unique := make(map[shard.Key]struct{})
+	var (
+		unique     = make(map[shard.Key]struct{})
+		keys       = make([]shard.Key, 0, len(allTargets))
+		keyTargets = make([]Target, 0, len(allTargets))
+	)
 	for _, tgt := range allTargets {
 		var targetKey shard.Key
 		// If we have no custom labels check all non-meta labels.
@@ -59,19 +94,51 @@ func NewDistributedTargetsWithCustomLabels(clusteringEnabled bool, cluster clust
 			continue
 		}
 		unique[targetKey] = struct{}{}
+		keys = append(keys, targetKey)
+		keyTargets = append(keyTargets, tgt)
+	}
 
-		// Determine if target belongs locally. Make sure it doesn't if cluster not ready.
-		belongsToLocal := false
-		if cluster.Ready() {
-			peers, err := cluster.Lookup(targetKey, 1, shard.OpReadWrite)
-			belongsToLocal = err != nil || len(peers) == 0 || peers[0].Self
+	// Determine which targets belong locally. Make sure none does if cluster not ready.
+	var (
+		belongsToLocal = make([]bool, len(keys))
+		owners         = make([]peer.Peer, len(keys))
+	)
+	costAware, isCostAware := c.(cluster.CostAwareCluster)
+	replicated, isReplicated := c.(cluster.ReplicatedCluster)
+	switch {
+	case !c.Ready():
+	case replicas > 1 && isReplicated:
+		for i, key := range keys {
+			peers, err := replicated.LookupReplicas(key, replicas)
+			belongsToLocal[i] = err != nil || len(peers) == 0 || slices.ContainsFunc(peers, func(p peer.Peer) bool { return p.Self })
+			if err == nil && len(peers) > 0 {
+				owners[i] = peers[0]
+			}
+		}
+	case costScope != "" && isCostAware:
+		peers, err := costAware.LookupByCost(costScope, keys)
+		for i := range keys {
+			belongsToLocal[i] = err != nil || peers[i].Self
+			if err == nil {
+				owners[i] = peers[i]
+			}
 		}
+	default:
+		for i, key := range keys {
+			peers, err := c.Lookup(key, 1, shard.OpReadWrite)
+			belongsToLocal[i] = err != nil || len(peers) == 0 || peers[0].Self
+			if err == nil && len(peers) > 0 {
+				owners[i] = peers[0]
+			}
+		}
+	}
 
-		if belongsToLocal {
-			localTargets = append(localTargets, tgt)
-			localTargetKeys = append(localTargetKeys, targetKey)
+	for i, key := range keys {
+		if belongsToLocal[i] {
+			localTargets = append(localTargets, keyTargets[i])
+			localTargetKeys = append(localTargetKeys, key)
 		} else {
-			remoteTargetKeys[targetKey] = struct{}{}
+			remoteTargetKeys[key] = owners[i]
 		}
 	}
 
@@ -87,6 +154,12 @@ func (dt *DistributedTargets) LocalTargets() []Target {
 	return dt.localTargets
 }
 
+// LocalTargetKeys returns the shard keys of the targets returned by
+// LocalTargets, in the same order.
+func (dt *DistributedTargets) LocalTargetKeys() []shard.Key {
+	return dt.localTargetKeys
+}
+
 func (dt *DistributedTargets) TargetCount() int {
 	return len(dt.localTargetKeys) + len(dt.remoteTargetKeys)
 }
@@ -109,6 +182,29 @@ func (dt *DistributedTargets) MovedToRemoteInstance(prev *DistributedTargets) []
 	return movedAwayTargets
 }
 
+// MovedTarget is a target which moved from the local cluster node to another
+// one.
+type MovedTarget struct {
+	Target Target
+	Key    shard.Key
+	Owner  peer.Peer // The new owner of the target.
+}
+
+// MovedTargets is like MovedToRemoteInstance, but also returns the key and
+// the new owner of every moved target.
+func (dt *DistributedTargets) MovedTargets(prev *DistributedTargets) []MovedTarget {
+	if prev == nil {
+		return nil
+	}
+	var moved []MovedTarget
+	for i, key := range prev.localTargetKeys {
+		if owner, exist := dt.remoteTargetKeys[key]; exist {
+			moved = append(moved, MovedTarget{Target: prev.localTargets[i], Key: key, Owner: owner})
+		}
+	}
+	return moved
+}
+
 func keyFor(tgt Target) shard.Key {
 	return shard.Key(tgt.NonMetaLabelsHash())
 }
@@ -218,6 +218,73 @@ func TestDistributedTargets_MovedToRemoteInstance(t *testing.T) {
 	}
 }
 
+func TestDistributedTargets_CostAware(t *testing.T) {
+	c := &fakeCostAwareCluster{
+		fakeCluster: fakeCluster{
+			peers: allTestPeers,
+			lookupMap: map[shard.Key][]peer.Peer{
+				keyFor(target1): {peer2},
+				keyFor(target2): {peer2},
+				keyFor(target3): {peer2},
+			},
+		},
+		costMap: map[shard.Key]peer.Peer{
+			keyFor(target1): peer1Self,
+			keyFor(target2): peer2,
+			keyFor(target3): peer1Self,
+		},
+	}
+
+	dt := NewCostAwareDistributedTargets(true, c, allTestTargets, "prometheus.scrape.default")
+	require.Equal(t, []Target{target1, target3}, dt.LocalTargets())
+	require.Equal(t, []shard.Key{keyFor(target1), keyFor(target3)}, dt.LocalTargetKeys())
+	require.Equal(t, "prometheus.scrape.default", c.scope)
+
+	// Without a scope, targets are sharded by lookup.
+	dt = NewDistributedTargets(true, c, allTestTargets)
+	require.Empty(t, dt.LocalTargets())
+
+	// Clusters which don't distribute by cost fall back to lookups.
+	dt = NewCostAwareDistributedTargets(true, &c.fakeCluster, allTestTargets, "prometheus.scrape.default")
+	require.Empty(t, dt.LocalTargets())
+}
+
+func TestDistributedTargets_Replicated(t *testing.T) {
+	c := &fakeReplicatedCluster{
+		fakeCluster: fakeCluster{
+			peers: allTestPeers,
+			lookupMap: map[shard.Key][]peer.Peer{
+				keyFor(target1): {peer2},
+				keyFor(target2): {peer2},
+				keyFor(target3): {peer2},
+			},
+		},
+		replicaMap: map[shard.Key][]peer.Peer{
+			keyFor(target1): {peer2, peer1Self},
+			keyFor(target2): {peer2, peer3},
+			keyFor(target3): {peer1Self, peer3},
+		},
+	}
+
+	dt := NewReplicatedDistributedTargets(true, c, allTestTargets, 2)
+	require.Equal(t, []Target{target1, target3}, dt.LocalTargets())
+	require.Equal(t, 2, c.replicas)
+
+	// The first owner of a remote target is its new owner.
+	moved := NewReplicatedDistributedTargets(true, c, allTestTargets, 2)
+	c.replicaMap[keyFor(target3)] = []peer.Peer{peer3, peer2}
+	dt = NewReplicatedDistributedTargets(true, c, allTestTargets, 2)
+	require.Equal(t, []MovedTarget{{Target: target3, Key: keyFor(target3), Owner: peer3}}, dt.MovedTargets(moved))
+
+	// Without replication, targets are sharded by lookup.
+	dt = NewReplicatedDistributedTargets(true, c, allTestTargets, 1)
+	require.Empty(t, dt.LocalTargets())
+
+	// Clusters which don't support replication fall back to lookups.
+	dt = NewReplicatedDistributedTargets(true, &c.fakeCluster, allTestTargets, 2)
+	require.Empty(t, dt.LocalTargets())
+}
+
 /*
 	 Recent run on M2 MacBook Air:
 
@@ -318,3 +385,31 @@ func (f *fakeCluster) Peers() []peer.Peer {
 func (f *fakeCluster) Ready() bool {
 	return true
 }
+
+type fakeCostAwareCluster struct {
+	fakeCluster
+	costMap map[shard.Key]peer.Peer
+	scope   string
+}
+
+func (f *fakeCostAwareCluster) ReportCosts(string, map[shard.Key]float64) {}
+
+func (f *fakeCostAwareCluster) LookupByCost(scope string, keys []shard.Key) ([]peer.Peer, error) {
+	f.scope = scope
+	owners := make([]peer.Peer, len(keys))
+	for i, key := range keys {
+		owners[i] = f.costMap[key]
+	}
+	return owners, nil
+}
+
+type fakeReplicatedCluster struct {
+	fakeCluster
+	replicaMap map[shard.Key][]peer.Peer
+	replicas   int
+}
+
+func (f *fakeReplicatedCluster) LookupReplicas(key shard.Key, replicas int) ([]peer.Peer, error) {
+	f.replicas = replicas
+	return f.replicaMap[key], nil
+}
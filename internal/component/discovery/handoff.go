@@ -0,0 +1,384 @@
+package discovery
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/ckit/peer"
+	"github.com/grafana/ckit/shard"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/internal/service/cluster"
+)
+
+const (
+	// HandoffKeyLabel is an internal target label holding the shard key of
+	// targets when handoffs are enabled, so that scraped targets can be
+	// matched with their handoff.
+	HandoffKeyLabel = model.ReservedLabelPrefix + "cluster_handoff_key__"
+
+	// handoffOfferWait is how long the new owner of a target waits for the
+	// previous owner to offer it, before it starts scraping the target anyway.
+	handoffOfferWait = 5 * time.Second
+)
+
+// TargetHandoff hands off targets between the nodes of a cluster when they
+// move, so that targets are scraped without gaps.
+//
+// The previous owner of a target keeps scraping it and offers it to the new
+// owner, together with the time of its last scrape. The new owner waits for
+// the offer before it starts scraping the target, so that it can continue on
+// the schedule of the previous owner, and confirms the handoff after its first
+// scrape. The previous owner stops scraping the target once the handoff is
+// confirmed, or once the handoff timeout of the cluster passes.
+//
+// Handoffs are only enabled if the cluster implements [cluster.HandoffCluster]
+// with a non-zero handoff timeout.
+type TargetHandoff struct {
+	scope   string
+	log     log.Logger
+	cluster cluster.HandoffCluster // nil if the cluster doesn't support handoffs.
+
+	mut       sync.Mutex
+	releasing map[shard.Key]*releasingTarget // Targets which moved away and are still scraped.
+	pending   map[shard.Key]time.Time        // Targets which moved here and wait for an offer, by deadline.
+	offers    map[shard.Key]receivedOffer    // Offers received from previous owners.
+	acquired  map[shard.Key]acquiredTarget   // Targets to confirm after their first scrape.
+}
+
+type releasingTarget struct {
+	target   Target
+	owner    peer.Peer
+	deadline time.Time
+}
+
+type receivedOffer struct {
+	cluster.ReceivedHandoff
+	expires time.Time
+}
+
+type acquiredTarget struct {
+	from  string
+	since time.Time
+}
+
+// HandoffResult is the result of TargetHandoff.Update.
+type HandoffResult struct {
+	// Targets are the targets to scrape, and Keys their shard keys. Targets
+	// include targets which moved to another node and weren't handed off yet,
+	// and exclude targets which wait to be offered by their previous owner.
+	// When handoffs are enabled, targets have the HandoffKeyLabel label.
+	Targets []Target
+	Keys    []shard.Key
+
+	// Aligned holds the handoffs of targets which start to be scraped, by
+	// shard key. Components should scrape these targets on the schedule of
+	// their previous owner.
+	Aligned map[shard.Key]cluster.Handoff
+
+	// Released are the targets which moved to another node and stop being
+	// scraped. The new owner is responsible for their staleness markers.
+	Released []Target
+
+	// Moved are the targets which moved to another node since the last call,
+	// and must be offered to their new owner with Offer.
+	Moved []MovedTarget
+}
+
+// NewTargetHandoff returns a new TargetHandoff for the targets of scope.
+// Components typically use their ID as the scope.
+func NewTargetHandoff(c cluster.Cluster, scope string, logger log.Logger) *TargetHandoff {
+	hc, _ := c.(cluster.HandoffCluster)
+	return &TargetHandoff{
+		scope:     scope,
+		log:       logger,
+		cluster:   hc,
+		releasing: make(map[shard.Key]*releasingTarget),
+		pending:   make(map[shard.Key]time.Time),
+		offers:    make(map[shard.Key]receivedOffer),
+		acquired:  make(map[shard.Key]acquiredTarget),
+	}
+}
+
+// Enabled returns whether targets are handed off when clustering is enabled.
+func (h *TargetHandoff) Enabled(clusteringEnabled bool) bool {
+	return clusteringEnabled && h.cluster != nil && h.cluster.HandoffTimeout() > 0
+}
+
+// Notify returns a channel which receives a value whenever a peer offers
+// targets or confirms a handoff. Update should be called when it does. The
+// channel is nil if the cluster doesn't support handoffs.
+func (h *TargetHandoff) Notify() <-chan struct{} {
+	if h.cluster == nil {
+		return nil
+	}
+	return h.cluster.HandoffNotify(h.scope)
+}
+
+// Update returns the targets to scrape after the targets were redistributed
+// from prev to dt. prev is nil on the first call.
+func (h *TargetHandoff) Update(prev, dt *DistributedTargets, clusteringEnabled bool) HandoffResult {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+
+	if !h.Enabled(clusteringEnabled) {
+		h.reset()
+		return HandoffResult{
+			Targets:  dt.LocalTargets(),
+			Keys:     dt.LocalTargetKeys(),
+			Released: dt.MovedToRemoteInstance(prev),
+		}
+	}
+
+	var (
+		res     = HandoffResult{Aligned: make(map[shard.Key]cluster.Handoff)}
+		now     = time.Now()
+		timeout = h.cluster.HandoffTimeout()
+	)
+
+	offers, confirmed := h.cluster.TakeHandoffs(h.scope)
+	for _, offer := range offers {
+		h.offers[offer.Key] = receivedOffer{ReceivedHandoff: offer, expires: now.Add(timeout)}
+	}
+	for key, offer := range h.offers {
+		if now.After(offer.expires) {
+			delete(h.offers, key)
+		}
+	}
+	for _, key := range confirmed {
+		if r, ok := h.releasing[key]; ok {
+			res.Released = append(res.Released, r.target)
+			delete(h.releasing, key)
+		}
+	}
+
+	// Targets which were scraped by the local node in the previous call.
+	wasLocal := make(map[shard.Key]struct{})
+	if prev != nil {
+		for _, key := range prev.localTargetKeys {
+			if _, ok := h.pending[key]; !ok {
+				wasLocal[key] = struct{}{}
+			}
+		}
+	}
+
+	// Targets which moved away are scraped until they're handed off.
+	for _, moved := range dt.MovedTargets(prev) {
+		if _, ok := wasLocal[moved.Key]; !ok {
+			continue // The target was never scraped.
+		}
+		moved.Target = withHandoffKey(moved.Target, moved.Key)
+		if _, ok := h.releasing[moved.Key]; !ok {
+			h.releasing[moved.Key] = &releasingTarget{target: moved.Target, owner: moved.Owner, deadline: now.Add(timeout)}
+			res.Moved = append(res.Moved, moved)
+		}
+	}
+	var timedOut int
+	for key, r := range h.releasing {
+		owner, remote := dt.remoteTargetKeys[key]
+		switch {
+		case !remote:
+			// The target either moved back to the local node, where it's
+			// still scraped, or was removed.
+			wasLocal[key] = struct{}{}
+			delete(h.releasing, key)
+		case now.After(r.deadline):
+			res.Released = append(res.Released, r.target)
+			delete(h.releasing, key)
+			timedOut++
+		default:
+			if owner.Name != r.owner.Name {
+				// The target moved again before it was handed off.
+				r.owner = owner
+				res.Moved = append(res.Moved, MovedTarget{Target: r.target, Key: key, Owner: owner})
+			}
+			res.Targets = append(res.Targets, r.target)
+			res.Keys = append(res.Keys, key)
+		}
+	}
+	if timedOut > 0 {
+		level.Warn(h.log).Log("msg", "targets weren't handed off to their new owner before the timeout", "count", timedOut, "timeout", timeout)
+	}
+
+	// Targets which moved here wait for an offer of their previous owner. This
+	// isn't needed if there's no other node which could have scraped them.
+	var (
+		local       = make(map[shard.Key]struct{}, len(dt.localTargetKeys))
+		otherOwners = h.hasOtherParticipants()
+	)
+	for i, key := range dt.localTargetKeys {
+		local[key] = struct{}{}
+
+		_, scraped := wasLocal[key]
+		starting := !scraped
+
+		if offer, ok := h.offers[key]; ok {
+			delete(h.offers, key)
+			delete(h.pending, key)
+			h.acquired[key] = acquiredTarget{from: offer.From, since: now}
+			if starting {
+				res.Aligned[key] = offer.Handoff
+			}
+		} else if starting && otherOwners {
+			deadline, ok := h.pending[key]
+			if !ok {
+				deadline = now.Add(handoffOfferWait)
+				h.pending[key] = deadline
+			}
+			if now.Before(deadline) {
+				continue
+			}
+			delete(h.pending, key)
+		}
+		res.Targets = append(res.Targets, withHandoffKey(dt.localTargets[i], key))
+		res.Keys = append(res.Keys, key)
+	}
+	for key := range h.pending {
+		if _, ok := local[key]; !ok {
+			delete(h.pending, key)
+		}
+	}
+	for key, a := range h.acquired {
+		if _, ok := local[key]; !ok || now.Sub(a.since) > timeout {
+			delete(h.acquired, key)
+		}
+	}
+
+	h.cluster.ReportReleasing(h.scope, len(h.releasing))
+	return res
+}
+
+// Due returns whether Update must be called again because a target stopped
+// waiting for its offer, or because a handoff timed out.
+func (h *TargetHandoff) Due() bool {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+
+	now := time.Now()
+	for _, deadline := range h.pending {
+		if !now.Before(deadline) {
+			return true
+		}
+	}
+	for _, r := range h.releasing {
+		if now.After(r.deadline) {
+			return true
+		}
+	}
+	return false
+}
+
+// Confirming returns whether there are handoffs to confirm after the first
+// scrape of their target.
+func (h *TargetHandoff) Confirming() bool {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+	return len(h.acquired) > 0
+}
+
+// Offer offers targets which moved to their new owner. schedule returns the
+// time of the last scrape and the scrape interval of a target.
+func (h *TargetHandoff) Offer(ctx context.Context, moved []MovedTarget, schedule func(key shard.Key) (lastScrape time.Time, interval time.Duration)) {
+	if h.cluster == nil || len(moved) == 0 {
+		return
+	}
+
+	var (
+		owners   = make(map[string]peer.Peer)
+		byPeer   = make(map[string][]cluster.Handoff)
+		handoffs int
+	)
+	for _, m := range moved {
+		lastScrape, interval := schedule(m.Key)
+		owners[m.Owner.Name] = m.Owner
+		byPeer[m.Owner.Name] = append(byPeer[m.Owner.Name], cluster.Handoff{Key: m.Key, LastRun: lastScrape, Interval: interval})
+		handoffs++
+	}
+	level.Debug(h.log).Log("msg", "offering targets to their new owners", "count", handoffs, "peers", len(byPeer))
+
+	for name, hs := range byPeer {
+		go func() {
+			if err := h.cluster.OfferHandoffs(ctx, h.scope, owners[name], hs); err != nil {
+				level.Warn(h.log).Log("msg", "failed to offer targets to their new owner", "peer", name, "count", len(hs), "err", err)
+			}
+		}()
+	}
+}
+
+// Confirm confirms the handoffs of targets which were scraped since they were
+// acquired. lastScrapes holds the time of the last scrape of targets by shard
+// key.
+func (h *TargetHandoff) Confirm(ctx context.Context, lastScrapes map[shard.Key]time.Time) {
+	if h.cluster == nil {
+		return
+	}
+
+	h.mut.Lock()
+	byPeer := make(map[string][]shard.Key)
+	for key, a := range h.acquired {
+		if lastScrape, ok := lastScrapes[key]; ok && lastScrape.After(a.since) {
+			byPeer[a.from] = append(byPeer[a.from], key)
+			delete(h.acquired, key)
+		}
+	}
+	h.mut.Unlock()
+
+	for name, keys := range byPeer {
+		go func() {
+			if err := h.cluster.ConfirmHandoffs(ctx, h.scope, name, keys); err != nil {
+				level.Warn(h.log).Log("msg", "failed to confirm handoff of targets to their previous owner", "peer", name, "count", len(keys), "err", err)
+			}
+		}()
+	}
+}
+
+// Close forgets all handoffs in progress.
+func (h *TargetHandoff) Close() {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+	h.reset()
+}
+
+// reset forgets all handoffs in progress. h.mut must be held by the caller.
+func (h *TargetHandoff) reset() {
+	clear(h.releasing)
+	clear(h.pending)
+	clear(h.offers)
+	clear(h.acquired)
+	if h.cluster != nil {
+		h.cluster.ReportReleasing(h.scope, 0)
+	}
+}
+
+func (h *TargetHandoff) hasOtherParticipants() bool {
+	for _, p := range h.cluster.Peers() {
+		if !p.Self && p.State == peer.StateParticipant {
+			return true
+		}
+	}
+	return false
+}
+
+// HandoffKey returns the shard key stored in the HandoffKeyLabel label by get,
+// which returns the value of a target label.
+func HandoffKey(get func(name string) string) (shard.Key, bool) {
+	v := get(HandoffKeyLabel)
+	if v == "" {
+		return 0, false
+	}
+	key, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return shard.Key(key), true
+}
+
+func withHandoffKey(t Target, key shard.Key) Target {
+	m := t.AsMap()
+	m[HandoffKeyLabel] = strconv.FormatUint(uint64(key), 10)
+	return NewTargetFromMap(m)
+}
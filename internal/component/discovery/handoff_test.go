@@ -0,0 +1,218 @@
+package discovery
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/ckit/peer"
+	"github.com/grafana/ckit/shard"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/service/cluster"
+)
+
+func TestTargetHandoff_Disabled(t *testing.T) {
+	c := &fakeHandoffCluster{fakeCluster: fakeCluster{peers: allTestPeers}}
+	h := NewTargetHandoff(c, "scope", log.NewNopLogger())
+
+	prev := handoffDistTargets(map[shard.Key][]peer.Peer{
+		keyFor(target1): {peer1Self},
+		keyFor(target2): {peer1Self},
+	})
+	dt := handoffDistTargets(map[shard.Key][]peer.Peer{
+		keyFor(target1): {peer2},
+		keyFor(target2): {peer1Self},
+	})
+
+	res := h.Update(prev, dt, true)
+	require.Equal(t, []Target{target2}, res.Targets)
+	require.Equal(t, []Target{target1}, res.Released)
+	require.Empty(t, res.Moved)
+}
+
+func TestTargetHandoff_PreviousOwner(t *testing.T) {
+	c := &fakeHandoffCluster{fakeCluster: fakeCluster{peers: allTestPeers}, timeout: time.Minute}
+	h := NewTargetHandoff(c, "scope", log.NewNopLogger())
+
+	prev := handoffDistTargets(map[shard.Key][]peer.Peer{
+		keyFor(target1): {peer1Self},
+		keyFor(target2): {peer1Self},
+	})
+	dt := handoffDistTargets(map[shard.Key][]peer.Peer{
+		keyFor(target1): {peer2},
+		keyFor(target2): {peer1Self},
+	})
+	h.Update(nil, prev, true)
+	h.pending = make(map[shard.Key]time.Time) // Skip waiting for offers.
+	h.Update(prev, prev, true)
+
+	// The target which moved keeps being scraped until it's handed off.
+	res := h.Update(prev, dt, true)
+	require.ElementsMatch(t, []Target{withHandoffKey(target1, keyFor(target1)), withHandoffKey(target2, keyFor(target2))}, res.Targets)
+	require.Empty(t, res.Released)
+	require.Equal(t, []MovedTarget{{Target: withHandoffKey(target1, keyFor(target1)), Key: keyFor(target1), Owner: peer2}}, res.Moved)
+	require.Equal(t, 1, c.releasing)
+
+	h.Offer(t.Context(), res.Moved, func(shard.Key) (time.Time, time.Duration) {
+		return time.Unix(100, 0), time.Minute
+	})
+	require.Eventually(t, func() bool {
+		c.mut.Lock()
+		defer c.mut.Unlock()
+		return len(c.offered["peer2"]) == 1
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, cluster.Handoff{Key: keyFor(target1), LastRun: time.Unix(100, 0), Interval: time.Minute}, c.offered["peer2"][0])
+
+	// The target is only offered once.
+	res = h.Update(dt, dt, true)
+	require.Len(t, res.Targets, 2)
+	require.Empty(t, res.Moved)
+
+	// The target stops being scraped once the handoff is confirmed.
+	c.confirmed = []shard.Key{keyFor(target1)}
+	res = h.Update(dt, dt, true)
+	require.Equal(t, []Target{withHandoffKey(target2, keyFor(target2))}, res.Targets)
+	require.Equal(t, []Target{withHandoffKey(target1, keyFor(target1))}, res.Released)
+	require.Equal(t, 0, c.releasing)
+}
+
+func TestTargetHandoff_Timeout(t *testing.T) {
+	c := &fakeHandoffCluster{fakeCluster: fakeCluster{peers: allTestPeers}, timeout: time.Minute}
+	h := NewTargetHandoff(c, "scope", log.NewNopLogger())
+
+	prev := handoffDistTargets(map[shard.Key][]peer.Peer{keyFor(target1): {peer1Self}})
+	dt := handoffDistTargets(map[shard.Key][]peer.Peer{keyFor(target1): {peer2}})
+	h.Update(nil, prev, true)
+	h.pending = make(map[shard.Key]time.Time)
+	h.Update(prev, prev, true)
+
+	res := h.Update(prev, dt, true)
+	require.Len(t, res.Targets, 1)
+	require.False(t, h.Due())
+
+	h.releasing[keyFor(target1)].deadline = time.Now().Add(-time.Second)
+	require.True(t, h.Due())
+	res = h.Update(dt, dt, true)
+	require.Empty(t, res.Targets)
+	require.Equal(t, []Target{withHandoffKey(target1, keyFor(target1))}, res.Released)
+}
+
+func TestTargetHandoff_NewOwner(t *testing.T) {
+	c := &fakeHandoffCluster{fakeCluster: fakeCluster{peers: allTestPeers}, timeout: time.Minute}
+	h := NewTargetHandoff(c, "scope", log.NewNopLogger())
+
+	prev := handoffDistTargets(map[shard.Key][]peer.Peer{
+		keyFor(target1): {peer2},
+		keyFor(target2): {peer3},
+	})
+	dt := handoffDistTargets(map[shard.Key][]peer.Peer{
+		keyFor(target1): {peer1Self},
+		keyFor(target2): {peer1Self},
+	})
+	h.Update(nil, prev, true)
+
+	// Targets which moved here wait for an offer.
+	res := h.Update(prev, dt, true)
+	require.Empty(t, res.Targets)
+	require.False(t, h.Due())
+
+	// An offered target starts with the schedule of its previous owner.
+	offer := cluster.Handoff{Key: keyFor(target1), LastRun: time.Unix(100, 0), Interval: time.Minute}
+	c.offers = []cluster.ReceivedHandoff{{Handoff: offer, From: "peer2"}}
+	res = h.Update(dt, dt, true)
+	require.Equal(t, []Target{withHandoffKey(target1, keyFor(target1))}, res.Targets)
+	require.Equal(t, map[shard.Key]cluster.Handoff{keyFor(target1): offer}, res.Aligned)
+
+	// Targets which aren't offered start after a while.
+	h.pending[keyFor(target2)] = time.Now().Add(-time.Second)
+	require.True(t, h.Due())
+	res = h.Update(dt, dt, true)
+	require.ElementsMatch(t, []Target{withHandoffKey(target1, keyFor(target1)), withHandoffKey(target2, keyFor(target2))}, res.Targets)
+	require.Empty(t, res.Aligned)
+
+	// The handoff is confirmed after the first scrape.
+	require.True(t, h.Confirming())
+	h.Confirm(t.Context(), map[shard.Key]time.Time{keyFor(target1): time.Now().Add(-time.Hour)})
+	require.True(t, h.Confirming())
+	h.Confirm(t.Context(), map[shard.Key]time.Time{keyFor(target1): time.Now().Add(time.Second)})
+	require.False(t, h.Confirming())
+	require.Eventually(t, func() bool {
+		c.mut.Lock()
+		defer c.mut.Unlock()
+		return len(c.confirmedTo["peer2"]) == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestTargetHandoff_SingleNode(t *testing.T) {
+	c := &fakeHandoffCluster{fakeCluster: fakeCluster{peers: []peer.Peer{peer1Self}}, timeout: time.Minute}
+	h := NewTargetHandoff(c, "scope", log.NewNopLogger())
+
+	// There's no previous owner to wait for.
+	dt := handoffDistTargets(map[shard.Key][]peer.Peer{keyFor(target1): {peer1Self}})
+	res := h.Update(nil, dt, true)
+	require.Len(t, res.Targets, 1)
+}
+
+// handoffDistTargets returns DistributedTargets of the targets in lookupMap
+// only.
+func handoffDistTargets(lookupMap map[shard.Key][]peer.Peer) *DistributedTargets {
+	var targets []Target
+	for _, t := range allTestTargets {
+		if _, ok := lookupMap[keyFor(t)]; ok {
+			targets = append(targets, t)
+		}
+	}
+	return NewDistributedTargets(true, &fakeCluster{
+		peers:     allTestPeers,
+		lookupMap: lookupMap,
+	}, targets)
+}
+
+type fakeHandoffCluster struct {
+	fakeCluster
+	timeout   time.Duration
+	offers    []cluster.ReceivedHandoff
+	confirmed []shard.Key
+	releasing int
+
+	mut         sync.Mutex
+	offered     map[string][]cluster.Handoff
+	confirmedTo map[string][]shard.Key
+}
+
+var _ cluster.HandoffCluster = (*fakeHandoffCluster)(nil)
+
+func (f *fakeHandoffCluster) HandoffTimeout() time.Duration { return f.timeout }
+
+func (f *fakeHandoffCluster) OfferHandoffs(_ context.Context, _ string, to peer.Peer, handoffs []cluster.Handoff) error {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	if f.offered == nil {
+		f.offered = make(map[string][]cluster.Handoff)
+	}
+	f.offered[to.Name] = append(f.offered[to.Name], handoffs...)
+	return nil
+}
+
+func (f *fakeHandoffCluster) ConfirmHandoffs(_ context.Context, _ string, to string, keys []shard.Key) error {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+	if f.confirmedTo == nil {
+		f.confirmedTo = make(map[string][]shard.Key)
+	}
+	f.confirmedTo[to] = append(f.confirmedTo[to], keys...)
+	return nil
+}
+
+func (f *fakeHandoffCluster) TakeHandoffs(string) ([]cluster.ReceivedHandoff, []shard.Key) {
+	offers, confirmed := f.offers, f.confirmed
+	f.offers, f.confirmed = nil, nil
+	return offers, confirmed
+}
+
+func (f *fakeHandoffCluster) HandoffNotify(string) <-chan struct{} { return nil }
+
+func (f *fakeHandoffCluster) ReportReleasing(_ string, count int) { f.releasing = count }
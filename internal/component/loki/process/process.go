@@ -15,6 +15,7 @@ import (
 	"github.com/grafana/alloy/internal/component/loki/process/stages"
 	"github.com/grafana/alloy/internal/featuregate"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/internal/service/cluster"
 	"github.com/grafana/alloy/internal/service/livedebugging"
 )
 
@@ -51,6 +52,7 @@ type Exports struct {
 var (
 	_ component.Component     = (*Component)(nil)
 	_ component.LiveDebugging = (*Component)(nil)
+	_ cluster.Component       = (*Component)(nil)
 )
 
 // Component implements the loki.process component.
@@ -62,11 +64,16 @@ type Component struct {
 	processIn    chan<- loki.Entry
 	processOut   chan loki.Entry
 	entryHandler loki.EntryHandler
+	pipeline     *stages.Pipeline
 	stages       []stages.StageConfig
 
 	fanoutMut sync.RWMutex
 	fanout    []loki.LogsReceiver
 
+	// cluster is nil when the cluster service isn't available. Distributed
+	// limit stages then limit each instance independently.
+	cluster cluster.Cluster
+
 	debugDataPublisher livedebugging.DebugDataPublisher
 }
 
@@ -81,6 +88,9 @@ func New(o component.Options, args Arguments) (*Component, error) {
 		opts:               o,
 		debugDataPublisher: debugDataPublisher.(livedebugging.DebugDataPublisher),
 	}
+	if data, err := o.GetServiceData(cluster.ServiceName); err == nil {
+		c.cluster = data.(cluster.Cluster)
+	}
 
 	// Create and immediately export the receiver which remains the same for
 	// the component's lifetime.
@@ -146,8 +156,12 @@ func (c *Component) Update(args component.Arguments) error {
 		if err != nil {
 			return err
 		}
+		if c.cluster != nil {
+			pipeline.SetCluster(c.cluster, c.opts.ID)
+		}
 		entryHandler := loki.NewEntryHandler(c.processOut, func() { pipeline.Cleanup() })
 		c.entryHandler = pipeline.Wrap(entryHandler)
+		c.pipeline = pipeline
 		c.processIn = c.entryHandler.Chan()
 		c.stages = newArgs.Stages
 	}
@@ -155,6 +169,16 @@ func (c *Component) Update(args component.Arguments) error {
 	return nil
 }
 
+// NotifyClusterChange implements cluster.Component.
+func (c *Component) NotifyClusterChange() {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+
+	if c.pipeline != nil {
+		c.pipeline.NotifyClusterChange()
+	}
+}
+
 func (c *Component) handleIn(ctx context.Context, wg *sync.WaitGroup) {
 	defer wg.Done()
 	componentID := livedebugging.ComponentID(c.opts.ID)
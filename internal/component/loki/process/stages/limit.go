@@ -4,12 +4,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/go-kit/log"
-	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/ckit/peer"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
 	"golang.org/x/time/rate"
+
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/internal/service/cluster"
 )
 
 // Configuration errors.
@@ -22,6 +29,21 @@ var (
 // MinReasonableMaxDistinctLabels provides a sensible default.
 const MinReasonableMaxDistinctLabels = 10000 // 80bytes per rate.Limiter ~ 1MiB memory
 
+const (
+	// limitRebalanceInterval is how often the shares of distributed limits are
+	// recomputed from the demand observed across the cluster.
+	limitRebalanceInterval = 5 * time.Second
+
+	// limitDemandTTL is how long the demand of a peer is remembered by the
+	// cluster after it stopped reporting it.
+	limitDemandTTL = 3 * limitRebalanceInterval
+
+	// limitFairShare is the part of a distributed limit which is split evenly
+	// between peers, so that peers whose demand starts growing aren't starved
+	// until the next rebalance.
+	limitFairShare = 0.1
+)
+
 // LimitConfig sets up a Limit stage.
 type LimitConfig struct {
 	Rate              float64 `alloy:"rate,attr"`
@@ -29,6 +51,7 @@ type LimitConfig struct {
 	Drop              bool    `alloy:"drop,attr,optional"`
 	ByLabelName       string  `alloy:"by_label_name,attr,optional"`
 	MaxDistinctLabels int     `alloy:"max_distinct_labels,attr,optional"`
+	Distributed       bool    `alloy:"distributed,attr,optional"`
 }
 
 func newLimitStage(logger log.Logger, cfg LimitConfig, registerer prometheus.Registerer) (Stage, error) {
@@ -51,6 +74,9 @@ func newLimitStage(logger log.Logger, cfg LimitConfig, registerer prometheus.Reg
 		cfg:       cfg,
 		dropCount: getDropCountMetric(registerer),
 	}
+	if cfg.Distributed {
+		r.shared = newSharedLimits(cfg, time.Now)
+	}
 
 	if cfg.ByLabelName != "" {
 		r.dropCountByLabel = getDropCountByLabelMetric(registerer)
@@ -83,8 +109,13 @@ type limitStage struct {
 	rateLimiterByLabel GenerationalMap[model.LabelValue, *rate.Limiter]
 	dropCount          *prometheus.CounterVec
 	dropCountByLabel   *prometheus.CounterVec
+
+	// shared is set when the limit is distributed across the cluster.
+	shared *sharedLimits
 }
 
+var _ clusterStage = (*limitStage)(nil)
+
 func (m *limitStage) Run(in chan Entry) chan Entry {
 	out := make(chan Entry)
 	go func() {
@@ -106,6 +137,7 @@ func (m *limitStage) shouldThrottle(labels model.LabelSet) bool {
 			return false // if no label found, dont ratelimit
 		}
 		rl := m.rateLimiterByLabel.GetOrCreate(labelValue)
+		m.shared.observe(labelValue, rl)
 		if rl.Allow() {
 			return false
 		}
@@ -114,6 +146,7 @@ func (m *limitStage) shouldThrottle(labels model.LabelSet) bool {
 		return true
 	}
 
+	m.shared.observe("", m.rateLimiter)
 	if m.cfg.Drop {
 		if m.rateLimiter.Allow() {
 			return false
@@ -135,6 +168,217 @@ func (*limitStage) Cleanup() {
 	// no-op
 }
 
+// setCluster implements clusterStage.
+func (m *limitStage) setCluster(c cluster.Cluster, scope string) {
+	m.shared.setCluster(c, scope)
+}
+
+// notifyClusterChange implements clusterStage.
+func (m *limitStage) notifyClusterChange() {
+	m.shared.notifyClusterChange()
+}
+
+// sharedLimits splits the rate and burst of a limit between the peers of a
+// cluster. Every peer reports the number of lines it receives to the cluster,
+// and receives a share of the limit proportional to its part of the
+// cluster-wide demand.
+//
+// Shares are only eventually consistent. Every limitRebalanceInterval, each
+// peer sets its share to
+//
+//	0.1/peers + 0.9*local/demand
+//
+// where local is the number of lines the peer received since the last
+// rebalance, and demand is the number of lines the cluster received in that
+// time. demand is read from key-value counters which lag behind the other
+// peers by up to a few gossip intervals, so the shares of all peers may sum to
+// more or less than the whole limit until the demand is stable.
+//
+// Lines are counted with atomics, and s.mut is only held to rebalance or when
+// the cluster changes. The methods of a nil *sharedLimits are no-ops, and
+// limits aren't shared until a cluster which shares key-value state is set.
+type sharedLimits struct {
+	rate  float64
+	burst int
+	now   func() time.Time
+
+	enabled       atomic.Bool  // Whether a cluster with key-value state is set.
+	nextRebalance atomic.Int64 // Unix nanoseconds.
+	demands       sync.Map     // *limitDemand by model.LabelValue.
+
+	mut     sync.Mutex
+	cluster cluster.Cluster
+	kv      cluster.KV
+}
+
+// limitDemand tracks the demand for a single rate limiter.
+type limitDemand struct {
+	limiter atomic.Pointer[rate.Limiter]
+	local   atomic.Int64 // Lines received locally since the last rebalance.
+
+	// Guarded by sharedLimits.mut.
+	share float64
+	total float64 // Lines received by the cluster at the last rebalance.
+}
+
+func newSharedLimits(cfg LimitConfig, now func() time.Time) *sharedLimits {
+	return &sharedLimits{
+		rate:  cfg.Rate,
+		burst: cfg.Burst,
+		now:   now,
+	}
+}
+
+func (s *sharedLimits) setCluster(c cluster.Cluster, scope string) {
+	if s == nil {
+		return
+	}
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.cluster, s.kv = c, nil
+	if kvc, ok := c.(cluster.KVCluster); ok {
+		s.kv = kvc.KV(scope)
+	}
+	s.nextRebalance.Store(s.now().Add(limitRebalanceInterval).UnixNano())
+	s.enabled.Store(s.kv != nil)
+}
+
+func (s *sharedLimits) notifyClusterChange() {
+	if s == nil {
+		return
+	}
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if s.kv == nil {
+		return
+	}
+
+	// The demand of peers which joined isn't known yet, and the share of
+	// peers which left must be redistributed, so the limit is split evenly
+	// until the demand is measured again.
+	share := 1 / float64(s.participants())
+	s.demands.Range(func(_, v any) bool {
+		d := v.(*limitDemand)
+		d.share = share
+		s.apply(d)
+		return true
+	})
+	s.nextRebalance.Store(s.now().Add(limitRebalanceInterval).UnixNano())
+}
+
+// observe records a line limited by limiter, which limits the lines whose
+// label value is key. It adjusts the limits of all limiters when they are
+// due for a rebalance.
+func (s *sharedLimits) observe(key model.LabelValue, limiter *rate.Limiter) {
+	if s == nil || !s.enabled.Load() {
+		return
+	}
+
+	v, ok := s.demands.Load(key)
+	if !ok {
+		v = s.newDemand(key)
+	}
+	d := v.(*limitDemand)
+	if d.limiter.Load() != limiter {
+		// The limiter is new, or was recreated after it wasn't used for a while.
+		s.mut.Lock()
+		d.limiter.Store(limiter)
+		s.apply(d)
+		s.mut.Unlock()
+	}
+	d.local.Add(1)
+
+	// Skip the rebalance if another line is already doing it.
+	if now := s.now(); now.UnixNano() >= s.nextRebalance.Load() && s.mut.TryLock() {
+		if now.UnixNano() >= s.nextRebalance.Load() && s.kv != nil {
+			s.rebalance()
+			s.nextRebalance.Store(now.Add(limitRebalanceInterval).UnixNano())
+		}
+		s.mut.Unlock()
+	}
+}
+
+// newDemand starts tracking the demand for key with an even share of the
+// limit.
+func (s *sharedLimits) newDemand(key model.LabelValue) *limitDemand {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if v, ok := s.demands.Load(key); ok {
+		return v.(*limitDemand)
+	}
+	d := &limitDemand{share: 1 / float64(s.participants())}
+	if s.kv != nil {
+		d.total = s.kv.Count(demandKey(key))
+	}
+	s.demands.Store(key, d)
+	return d
+}
+
+// rebalance reports the local demand since the last rebalance to the cluster,
+// and recomputes the share of every limiter. s.mut must be held.
+func (s *sharedLimits) rebalance() {
+	peers := float64(s.participants())
+
+	s.demands.Range(func(k, v any) bool {
+		var (
+			key   = k.(model.LabelValue)
+			d     = v.(*limitDemand)
+			local = float64(d.local.Swap(0))
+			total = s.kv.Count(demandKey(key))
+			err   error
+		)
+		if local > 0 {
+			total, err = s.kv.Add(demandKey(key), local, limitDemandTTL)
+		}
+
+		d.share = 1 / peers
+		if demand := total - d.total; err == nil && local > 0 && demand > 0 {
+			d.share = limitFairShare/peers + (1-limitFairShare)*min(1, local/demand)
+		}
+		d.total = total
+		s.apply(d)
+
+		if local == 0 {
+			// Stop tracking idle limiters. They keep their last share until
+			// they're used again. A line counted concurrently is lost, which
+			// only underestimates the demand until the next rebalance.
+			s.demands.Delete(key)
+		}
+		return true
+	})
+}
+
+// apply sets the limit of the limiter of d to its share. s.mut must be held.
+func (s *sharedLimits) apply(d *limitDemand) {
+	limiter := d.limiter.Load()
+	if limiter == nil {
+		return
+	}
+	limiter.SetLimit(rate.Limit(s.rate * d.share))
+	limiter.SetBurst(max(1, int(math.Ceil(float64(s.burst)*d.share))))
+}
+
+// participants returns the number of peers which share the limit.
+func (s *sharedLimits) participants() int {
+	var n int
+	for _, p := range s.cluster.Peers() {
+		if p.State == peer.StateParticipant {
+			n++
+		}
+	}
+	return max(n, 1)
+}
+
+func demandKey(key model.LabelValue) string {
+	if key == "" {
+		return "demand"
+	}
+	return "demand/" + string(key)
+}
+
 func getDropCountByLabelMetric(registerer prometheus.Registerer) *prometheus.CounterVec {
 	return registerCounterVec(registerer, "loki_process", "dropped_lines_by_label_total",
 		"A count of all log lines dropped as a result of a pipeline stage",
@@ -1,15 +1,19 @@
 package stages
 
 import (
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/grafana/ckit/peer"
+	"github.com/grafana/ckit/shard"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/internal/service/cluster"
 	util_log "github.com/grafana/loki/v3/pkg/util/log"
 )
 
@@ -145,3 +149,130 @@ func TestLimitByLabelPipeline(t *testing.T) {
 	assert.True(t, hasTotal)
 	assert.True(t, hasByLabel)
 }
+
+func TestLimitDistributed(t *testing.T) {
+	var (
+		now    = time.Unix(1000, 0)
+		counts = map[string]map[string]float64{}
+		peers  = []peer.Peer{
+			{Name: "a", State: peer.StateParticipant},
+			{Name: "b", State: peer.StateParticipant},
+		}
+	)
+	newStage := func(self string) *limitStage {
+		stage, err := newLimitStage(util_log.Logger, LimitConfig{Rate: 100, Burst: 20, Drop: true, Distributed: true}, prometheus.NewRegistry())
+		require.NoError(t, err)
+		ls := stage.(*limitStage)
+		ls.shared.now = func() time.Time { return now }
+		ls.setCluster(&fakeKVCluster{self: self, peers: &peers, counts: counts}, "loki.process.default/0")
+		return ls
+	}
+	a, b := newStage("a"), newStage("b")
+	receive := func(s *limitStage, lines int) {
+		for range lines {
+			s.shouldThrottle(model.LabelSet{})
+		}
+	}
+
+	// The limit is split evenly until demand has been measured.
+	receive(a, 1)
+	receive(b, 1)
+	require.Equal(t, 50.0, float64(a.rateLimiter.Limit()))
+	require.Equal(t, 10, a.rateLimiter.Burst())
+
+	// Shares follow the demand of each peer once every peer reported it.
+	for range 3 {
+		receive(a, 89)
+		receive(b, 9)
+		now = now.Add(limitRebalanceInterval)
+		receive(a, 1)
+		receive(b, 1)
+	}
+	require.InDelta(t, 100*(0.05+0.9*0.9), float64(a.rateLimiter.Limit()), 1)
+	require.InDelta(t, 100*(0.05+0.9*0.1), float64(b.rateLimiter.Limit()), 1)
+	require.InDelta(t, 100.0, float64(a.rateLimiter.Limit()+b.rateLimiter.Limit()), 0.01)
+
+	// The limit is split evenly again when peers change.
+	peers = append(peers, peer.Peer{Name: "c", State: peer.StateParticipant})
+	a.notifyClusterChange()
+	require.InDelta(t, 100.0/3, float64(a.rateLimiter.Limit()), 0.01)
+	require.Equal(t, 7, a.rateLimiter.Burst())
+}
+
+func TestLimitDistributedConcurrent(t *testing.T) {
+	var (
+		now    = time.Unix(1000, 0)
+		counts = map[string]map[string]float64{}
+		peers  = []peer.Peer{{Name: "a", State: peer.StateParticipant}}
+	)
+	stage, err := newLimitStage(util_log.Logger, LimitConfig{Rate: 100, Burst: 20, Drop: true, Distributed: true}, prometheus.NewRegistry())
+	require.NoError(t, err)
+	ls := stage.(*limitStage)
+	ls.shared.now = func() time.Time { return now }
+	ls.setCluster(&fakeKVCluster{self: "a", peers: &peers, counts: counts}, "loki.process.default/0")
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 1000 {
+				ls.shouldThrottle(model.LabelSet{})
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Every line is reported at the next rebalance.
+	now = now.Add(limitRebalanceInterval)
+	ls.shouldThrottle(model.LabelSet{})
+	require.Equal(t, 8001.0, counts["demand"]["a"])
+}
+
+func TestLimitDistributedWithoutCluster(t *testing.T) {
+	stage, err := newLimitStage(util_log.Logger, LimitConfig{Rate: 100, Burst: 20, Drop: true, Distributed: true}, prometheus.NewRegistry())
+	require.NoError(t, err)
+	ls := stage.(*limitStage)
+
+	// Clusters without key-value state limit each instance independently.
+	ls.setCluster(cluster.Mock(), "loki.process.default/0")
+	for range 10 {
+		ls.shouldThrottle(model.LabelSet{})
+	}
+	require.Equal(t, 100.0, float64(ls.rateLimiter.Limit()))
+}
+
+// fakeKVCluster is a cluster whose peers share counters immediately.
+type fakeKVCluster struct {
+	self   string
+	peers  *[]peer.Peer
+	counts map[string]map[string]float64
+}
+
+func (c *fakeKVCluster) Lookup(shard.Key, int, shard.Op) ([]peer.Peer, error) { return nil, nil }
+func (c *fakeKVCluster) Peers() []peer.Peer                                   { return *c.peers }
+func (c *fakeKVCluster) Ready() bool                                          { return true }
+func (c *fakeKVCluster) KV(string) cluster.KV                                 { return fakeKV{c} }
+
+type fakeKV struct{ c *fakeKVCluster }
+
+func (kv fakeKV) Get(string) ([]byte, bool)               { return nil, false }
+func (kv fakeKV) Set(string, []byte, time.Duration) error { return nil }
+func (kv fakeKV) Delete(string)                           {}
+func (kv fakeKV) Keys(string) []string                    { return nil }
+
+func (kv fakeKV) Add(key string, delta float64, _ time.Duration) (float64, error) {
+	if kv.c.counts[key] == nil {
+		kv.c.counts[key] = map[string]float64{}
+	}
+	kv.c.counts[key][kv.c.self] += delta
+	return kv.Count(key), nil
+}
+
+func (kv fakeKV) Count(key string) float64 {
+	var sum float64
+	for _, n := range kv.c.counts[key] {
+		sum += n
+	}
+	return sum
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/go-kit/log"
 	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/internal/service/cluster"
 	"github.com/grafana/loki/v3/clients/pkg/logentry/logql"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/common/model"
@@ -196,6 +197,20 @@ func (m *matcherStage) Name() string {
 	return StageTypeMatch
 }
 
+// setCluster implements clusterStage.
+func (m *matcherStage) setCluster(c cluster.Cluster, scope string) {
+	if cs, ok := m.stage.(clusterStage); ok {
+		cs.setCluster(c, scope)
+	}
+}
+
+// notifyClusterChange implements clusterStage.
+func (m *matcherStage) notifyClusterChange() {
+	if cs, ok := m.stage.(clusterStage); ok {
+		cs.notifyClusterChange()
+	}
+}
+
 // Cleanup implements Stage.
 func (*matcherStage) Cleanup() {
 	// no-op
@@ -11,6 +11,7 @@ import (
 
 	"github.com/grafana/alloy/internal/component/common/loki"
 	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/internal/service/cluster"
 )
 
 // StageConfig defines a single stage in a processing pipeline.
@@ -137,6 +138,49 @@ func (p *Pipeline) Cleanup() {
 	}
 }
 
+// clusterStage is implemented by stages which coordinate with the other peers
+// of a cluster.
+type clusterStage interface {
+	// setCluster sets the cluster of the stage. scope uniquely identifies the
+	// stage in the cluster.
+	setCluster(c cluster.Cluster, scope string)
+
+	// notifyClusterChange notifies the stage that the peers of the cluster
+	// changed.
+	notifyClusterChange()
+}
+
+var _ clusterStage = (*Pipeline)(nil)
+
+// SetCluster sets the cluster used by stages which coordinate with the other
+// peers, such as distributed limit stages. scope identifies the pipeline in
+// the cluster, and is typically the ID of the component running it.
+func (p *Pipeline) SetCluster(c cluster.Cluster, scope string) {
+	p.setCluster(c, scope)
+}
+
+// NotifyClusterChange notifies stages which coordinate with the other peers
+// that the peers of the cluster changed.
+func (p *Pipeline) NotifyClusterChange() {
+	p.notifyClusterChange()
+}
+
+func (p *Pipeline) setCluster(c cluster.Cluster, scope string) {
+	for i, s := range p.stages {
+		if cs, ok := s.(clusterStage); ok {
+			cs.setCluster(c, fmt.Sprintf("%s/%d", scope, i))
+		}
+	}
+}
+
+func (p *Pipeline) notifyClusterChange() {
+	for _, s := range p.stages {
+		if cs, ok := s.(clusterStage); ok {
+			cs.notifyClusterChange()
+		}
+	}
+}
+
 // Wrap implements EntryMiddleware
 func (p *Pipeline) Wrap(next loki.EntryHandler) loki.EntryHandler {
 	handlerIn := make(chan loki.Entry)
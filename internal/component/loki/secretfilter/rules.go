@@ -0,0 +1,227 @@
+package secretfilter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/go-kit/log"
+
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+)
+
+// RulesConfig configures which Gitleaks rules LoadRules compiles.
+type RulesConfig struct {
+	// GitleaksConfig is the path to a custom gitleaks.toml file. If empty, the
+	// embedded one is used.
+	GitleaksConfig string
+	// Types of secret to look for (e.g. "aws", "gcp", ...). If empty, all
+	// types are included.
+	Types []string
+	// RedactWith is the string secrets are replaced with. Rules matching it
+	// are excluded.
+	RedactWith string
+	// IncludeGeneric includes the generic API key rule.
+	IncludeGeneric bool
+	// AllowList is a list of regexes to allowlist on top of what's in the
+	// Gitleaks config.
+	AllowList []string
+}
+
+// LoadRules parses the Gitleaks configuration and compiles its rules, along
+// with the global allowlist.
+func LoadRules(logger log.Logger, cfg RulesConfig) ([]Rule, []AllowRule, error) {
+	// Parse GitLeaks configuration
+	var gitleaksCfg GitLeaksConfig
+	if cfg.GitleaksConfig == "" {
+		// If no config file is explicitly provided, use the embedded one
+		_, err := toml.DecodeFS(embedFs, "gitleaks.toml", &gitleaksCfg)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		// If a config file is provided, use that
+		_, err := toml.DecodeFile(cfg.GitleaksConfig, &gitleaksCfg)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var (
+		rules             []Rule
+		ruleGenericApiKey *Rule
+	)
+
+	// Compile regexes
+	for _, rule := range gitleaksCfg.Rules {
+		// If the rule regex is empty, skip this rule
+		if rule.Regex == "" {
+			continue
+		}
+		// If specific secret types are provided, only include rules that match the types
+		if len(cfg.Types) > 0 {
+			var found bool
+			for _, t := range cfg.Types {
+				if strings.HasPrefix(strings.ToLower(rule.ID), strings.ToLower(t)) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				// Skip that rule if it doesn't match any of the secret types in the config
+				continue
+			}
+		}
+		re, err := regexp.Compile(rule.Regex)
+		if err != nil {
+			level.Error(logger).Log("msg", "error compiling regex", "error", err)
+			return nil, nil, err
+		}
+		// If the rule regex matches the empty string, skip this rule
+		if re.Match([]byte("")) {
+			level.Warn(logger).Log("msg", "excluded rule due to matching the empty string", "rule", rule.ID)
+			continue
+		}
+		// If the rule regex matches the redaction string, skip this rule
+		redactionString := "<REDACTED-SECRET:" + rule.ID + ">"
+		if cfg.RedactWith != "" {
+			redactionString = cfg.RedactWith
+			redactionString = strings.ReplaceAll(redactionString, "$SECRET_NAME", rule.ID)
+		}
+		if re.Match([]byte(redactionString)) {
+			level.Warn(logger).Log("msg", "excluded rule due to matching the redaction string", "rule", rule.ID)
+			continue
+		}
+
+		// Compile rule-specific allowlist regexes
+		var allowlist []AllowRule
+		for _, r := range rule.Allowlist.Regexes {
+			re, err := regexp.Compile(r)
+			if err != nil {
+				level.Error(logger).Log("msg", "error compiling allowlist regex", "error", err)
+				return nil, nil, err
+			}
+			allowlist = append(allowlist, AllowRule{Regex: re, Source: fmt.Sprintf("rule %s", rule.ID)})
+		}
+		for _, currAllowList := range rule.Allowlists {
+			for _, r := range currAllowList.Regexes {
+				re, err := regexp.Compile(r)
+				if err != nil {
+					level.Error(logger).Log("msg", "error compiling allowlist regex", "error", err)
+					return nil, nil, err
+				}
+				allowlist = append(allowlist, AllowRule{Regex: re, Source: fmt.Sprintf("rule %s", rule.ID)})
+			}
+		}
+
+		newRule := Rule{
+			name:        rule.ID,
+			regex:       re,
+			secretGroup: rule.SecretGroup,
+			entropy:     rule.Entropy,
+			allowlist:   allowlist,
+		}
+
+		// We treat the generic API key rule separately as we want to add it in last position
+		// to the list of rules (so that is has the lowest priority)
+		if strings.ToLower(rule.ID) == "generic-api-key" {
+			ruleGenericApiKey = &newRule
+		} else {
+			rules = append(rules, newRule)
+		}
+	}
+
+	// Compiling global allowlist regexes
+	allowList := make([]AllowRule, 0, len(cfg.AllowList)+len(gitleaksCfg.AllowList.Regexes))
+	// From the arguments
+	for _, r := range cfg.AllowList {
+		re, err := regexp.Compile(r)
+		if err != nil {
+			level.Error(logger).Log("msg", "error compiling allowlist regex", "error", err)
+			return nil, nil, err
+		}
+		allowList = append(allowList, AllowRule{Regex: re, Source: "alloy config"})
+	}
+	// From the Gitleaks config
+	for _, r := range gitleaksCfg.AllowList.Regexes {
+		re, err := regexp.Compile(r)
+		if err != nil {
+			level.Error(logger).Log("msg", "error compiling allowlist regex", "error", err)
+			return nil, nil, err
+		}
+		allowList = append(allowList, AllowRule{Regex: re, Source: "gitleaks config"})
+	}
+
+	// Add the generic API key rule last if needed
+	if ruleGenericApiKey != nil && cfg.IncludeGeneric {
+		rules = append(rules, *ruleGenericApiKey)
+	}
+
+	return rules, allowList, nil
+}
+
+// Name returns the Gitleaks ID of the rule.
+func (r *Rule) Name() string { return r.name }
+
+// Regex returns the compiled regular expression of the rule.
+func (r *Rule) Regex() *regexp.Regexp { return r.regex }
+
+// Allowlist returns the rule-specific allowlist.
+func (r *Rule) Allowlist() []AllowRule { return r.allowlist }
+
+// FindSecrets returns the non-empty secrets the rule finds in line.
+func (r *Rule) FindSecrets(line string) []string {
+	// To find the secret within the text captured by the regex (and avoid being too greedy), we can use the 'secretGroup' field in the gitleaks.toml file.
+	// But it's rare for regexes to have this field set, so we can use a simple heuristic in other cases.
+	//
+	// There seems to be two kinds of regexes in the gitleaks.toml file
+	// 1. Regexes that only match the secret (with no submatches). E.g. (?:A3T[A-Z0-9]|AKIA|ASIA|ABIA|ACCA)[A-Z0-9]{16}
+	// 2. Regexes that match the secret and some context (or delimiters) and have one submatch (the secret itself). E.g. (?i)\b(AIza[0-9A-Za-z\\-_]{35})(?:['|\"|\n|\r|\s|\x60|;]|$)
+	//
+	// For the first case, we can replace the entire match with the redaction string.
+	// For the second case, we can replace the first submatch with the redaction string (to avoid redacting something else than the secret such as delimiters).
+	var secrets []string
+	for _, occ := range r.regex.FindAllStringSubmatch(line, -1) {
+		// By default, the secret is the full match group
+		secret := occ[0]
+
+		// If a secretGroup is provided, use that instead
+		if r.secretGroup > 0 && len(occ) > r.secretGroup {
+			secret = occ[r.secretGroup]
+		} else if len(occ) == 2 {
+			// If not and there are two submatches, the first one is the secret
+			secret = occ[1]
+		}
+
+		// If secret is empty string, ignore
+		if secret == "" {
+			continue
+		}
+		secrets = append(secrets, secret)
+	}
+	return secrets
+}
+
+// AllowedBy returns the first rule of the global allowlist, then of the
+// rule-specific allowlist, which matches secret. It returns nil if the secret
+// isn't allowlisted.
+func (r *Rule) AllowedBy(secret string, global []AllowRule) *AllowRule {
+	for _, a := range global {
+		if a.Regex.MatchString(secret) {
+			return &a
+		}
+	}
+	for _, a := range r.allowlist {
+		if a.Regex.MatchString(secret) {
+			return &a
+		}
+	}
+	return nil
+}
+
+// HasEnoughEntropy reports whether secret reaches the minimum entropy of the
+// rule. Rules without a minimum entropy accept every secret.
+func (r *Rule) HasEnoughEntropy(secret string) bool {
+	return r.entropy <= 0 || calculateEntropy(secret) >= r.entropy
+}
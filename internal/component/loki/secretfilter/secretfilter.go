@@ -11,7 +11,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/BurntSushi/toml"
 	"github.com/grafana/alloy/internal/component"
 	"github.com/grafana/alloy/internal/component/common/loki"
 	"github.com/grafana/alloy/internal/featuregate"
@@ -283,53 +282,9 @@ func (c *Component) processEntry(entry loki.Entry) loki.Entry {
 	}()
 
 	for _, r := range c.Rules {
-		// To find the secret within the text captured by the regex (and avoid being too greedy), we can use the 'secretGroup' field in the gitleaks.toml file.
-		// But it's rare for regexes to have this field set, so we can use a simple heuristic in other cases.
-		//
-		// There seems to be two kinds of regexes in the gitleaks.toml file
-		// 1. Regexes that only match the secret (with no submatches). E.g. (?:A3T[A-Z0-9]|AKIA|ASIA|ABIA|ACCA)[A-Z0-9]{16}
-		// 2. Regexes that match the secret and some context (or delimiters) and have one submatch (the secret itself). E.g. (?i)\b(AIza[0-9A-Za-z\\-_]{35})(?:['|\"|\n|\r|\s|\x60|;]|$)
-		//
-		// For the first case, we can replace the entire match with the redaction string.
-		// For the second case, we can replace the first submatch with the redaction string (to avoid redacting something else than the secret such as delimiters).
-		for _, occ := range r.regex.FindAllStringSubmatch(entry.Line, -1) {
-			// By default, the secret is the full match group
-			secret := occ[0]
-
-			// If a secretGroup is provided, use that instead
-			if r.secretGroup > 0 && len(occ) > r.secretGroup {
-				secret = occ[r.secretGroup]
-			} else if len(occ) == 2 {
-				// If not and there are two submatches, the first one is the secret
-				secret = occ[1]
-			}
-
-			// If secret is empty string, ignore
-			if secret == "" {
-				level.Debug(c.opts.Logger).Log("msg", "empty secret found", "rule", r.name)
-				continue
-			}
-
-			// Check if the secret is in the allowlist
-			var allowRule *AllowRule = nil
-			// First check the global allowlist
-			for _, a := range c.AllowList {
-				if a.Regex.MatchString(secret) {
-					allowRule = &a
-					break
-				}
-			}
-			// Then check the rule-specific allowlists
-			if allowRule == nil {
-				for _, a := range r.allowlist {
-					if a.Regex.MatchString(secret) {
-						allowRule = &a
-						break
-					}
-				}
-			}
+		for _, secret := range r.FindSecrets(entry.Line) {
 			// If allowed, skip redaction
-			if allowRule != nil {
+			if allowRule := r.AllowedBy(secret, c.AllowList); allowRule != nil {
 				level.Debug(c.opts.Logger).Log("msg", "secret in allowlist", "rule", r.name, "source", allowRule.Source)
 				// Record metric for secrets that were not redacted due to allowlist
 				c.metrics.secretsAllowlistedTotal.WithLabelValues(allowRule.Source).Inc()
@@ -414,129 +369,18 @@ func (c *Component) Update(args component.Arguments) error {
 
 	c.metrics = newMetrics(c.opts.Registerer, newArgs.OriginLabel)
 
-	// Parse GitLeaks configuration
-	var gitleaksCfg GitLeaksConfig
-	if c.args.GitleaksConfig == "" {
-		// If no config file is explicitly provided, use the embedded one
-		_, err := toml.DecodeFS(embedFs, "gitleaks.toml", &gitleaksCfg)
-		if err != nil {
-			return err
-		}
-	} else {
-		// If a config file is provided, use that
-		_, err := toml.DecodeFile(c.args.GitleaksConfig, &gitleaksCfg)
-		if err != nil {
-			return err
-		}
-	}
-
-	var ruleGenericApiKey *Rule = nil
-
-	// Compile regexes
-	for _, rule := range gitleaksCfg.Rules {
-		// If the rule regex is empty, skip this rule
-		if rule.Regex == "" {
-			continue
-		}
-		// If specific secret types are provided, only include rules that match the types
-		if len(c.args.Types) > 0 {
-			var found bool
-			for _, t := range c.args.Types {
-				if strings.HasPrefix(strings.ToLower(rule.ID), strings.ToLower(t)) {
-					found = true
-					break
-				}
-			}
-			if !found {
-				// Skip that rule if it doesn't match any of the secret types in the config
-				continue
-			}
-		}
-		re, err := regexp.Compile(rule.Regex)
-		if err != nil {
-			level.Error(c.opts.Logger).Log("msg", "error compiling regex", "error", err)
-			return err
-		}
-		// If the rule regex matches the empty string, skip this rule
-		if re.Match([]byte("")) {
-			level.Warn(c.opts.Logger).Log("msg", "excluded rule due to matching the empty string", "rule", rule.ID)
-			continue
-		}
-		// If the rule regex matches the redaction string, skip this rule
-		redactionString := "<REDACTED-SECRET:" + rule.ID + ">"
-		if c.args.RedactWith != "" {
-			redactionString = c.args.RedactWith
-			redactionString = strings.ReplaceAll(redactionString, "$SECRET_NAME", rule.ID)
-		}
-		if re.Match([]byte(redactionString)) {
-			level.Warn(c.opts.Logger).Log("msg", "excluded rule due to matching the redaction string", "rule", rule.ID)
-			continue
-		}
-
-		// Compile rule-specific allowlist regexes
-		var allowlist []AllowRule
-		for _, r := range rule.Allowlist.Regexes {
-			re, err := regexp.Compile(r)
-			if err != nil {
-				level.Error(c.opts.Logger).Log("msg", "error compiling allowlist regex", "error", err)
-				return err
-			}
-			allowlist = append(allowlist, AllowRule{Regex: re, Source: fmt.Sprintf("rule %s", rule.ID)})
-		}
-		for _, currAllowList := range rule.Allowlists {
-			for _, r := range currAllowList.Regexes {
-				re, err := regexp.Compile(r)
-				if err != nil {
-					level.Error(c.opts.Logger).Log("msg", "error compiling allowlist regex", "error", err)
-					return err
-				}
-				allowlist = append(allowlist, AllowRule{Regex: re, Source: fmt.Sprintf("rule %s", rule.ID)})
-			}
-		}
-
-		newRule := Rule{
-			name:        rule.ID,
-			regex:       re,
-			secretGroup: rule.SecretGroup,
-			entropy:     rule.Entropy,
-			allowlist:   allowlist,
-		}
-
-		// We treat the generic API key rule separately as we want to add it in last position
-		// to the list of rules (so that is has the lowest priority)
-		if strings.ToLower(rule.ID) == "generic-api-key" {
-			ruleGenericApiKey = &newRule
-		} else {
-			c.Rules = append(c.Rules, newRule)
-		}
-	}
-
-	// Compiling global allowlist regexes
-	// Reset the allowlist
-	c.AllowList = make([]AllowRule, 0, len(c.args.AllowList)+len(gitleaksCfg.AllowList.Regexes))
-	// From the arguments
-	for _, r := range c.args.AllowList {
-		re, err := regexp.Compile(r)
-		if err != nil {
-			level.Error(c.opts.Logger).Log("msg", "error compiling allowlist regex", "error", err)
-			return err
-		}
-		c.AllowList = append(c.AllowList, AllowRule{Regex: re, Source: "alloy config"})
-	}
-	// From the Gitleaks config
-	for _, r := range gitleaksCfg.AllowList.Regexes {
-		re, err := regexp.Compile(r)
-		if err != nil {
-			level.Error(c.opts.Logger).Log("msg", "error compiling allowlist regex", "error", err)
-			return err
-		}
-		c.AllowList = append(c.AllowList, AllowRule{Regex: re, Source: "gitleaks config"})
-	}
-
-	// Add the generic API key rule last if needed
-	if ruleGenericApiKey != nil && c.args.IncludeGeneric {
-		c.Rules = append(c.Rules, *ruleGenericApiKey)
+	rules, allowList, err := LoadRules(c.opts.Logger, RulesConfig{
+		GitleaksConfig: c.args.GitleaksConfig,
+		Types:          c.args.Types,
+		RedactWith:     c.args.RedactWith,
+		IncludeGeneric: c.args.IncludeGeneric,
+		AllowList:      c.args.AllowList,
+	})
+	if err != nil {
+		return err
 	}
+	c.Rules = rules
+	c.AllowList = allowList
 
 	level.Info(c.opts.Logger).Log("Compiled regexes for secret detection", len(c.Rules))
 
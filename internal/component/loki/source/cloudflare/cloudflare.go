@@ -19,6 +19,7 @@ import (
 	cft "github.com/grafana/alloy/internal/component/loki/source/cloudflare/internal/cloudflaretarget"
 	"github.com/grafana/alloy/internal/featuregate"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/internal/service/cluster"
 	"github.com/grafana/alloy/syntax/alloytypes"
 	"github.com/prometheus/common/model"
 )
@@ -46,6 +47,7 @@ type Arguments struct {
 	FieldsType       string              `alloy:"fields_type,attr,optional"`
 	AdditionalFields []string            `alloy:"additional_fields,attr,optional"`
 	ForwardTo        []loki.LogsReceiver `alloy:"forward_to,attr"`
+	Clustering       cluster.LeaderBlock `alloy:"clustering,block,optional"`
 }
 
 // Convert returns a cloudflaretarget Config struct from the Arguments.
@@ -94,14 +96,21 @@ type Component struct {
 	opts    component.Options
 	metrics *cft.Metrics
 
-	mut    sync.RWMutex
-	fanout []loki.LogsReceiver
-	target *cft.Target
+	mut        sync.RWMutex
+	args       Arguments
+	fanout     []loki.LogsReceiver
+	target     *cft.Target         // nil if another instance leads the component.
+	leadership *cluster.Leadership // Set if the component only runs on the leader.
 
 	posFile positions.Positions
 	handler loki.LogsReceiver
 }
 
+var (
+	_ component.Component     = (*Component)(nil)
+	_ cluster.LeaderComponent = (*Component)(nil)
+)
+
 // New creates a new loki.source.cloudflare component.
 func New(o component.Options, args Arguments) (*Component, error) {
 	err := os.MkdirAll(o.DataPath, 0750)
@@ -139,7 +148,9 @@ func (c *Component) Run(ctx context.Context) error {
 	defer func() {
 		c.mut.RLock()
 		level.Info(c.opts.Logger).Log("msg", "loki.source.cloudflare component shutting down, stopping the target")
-		c.target.Stop()
+		if c.target != nil {
+			c.target.Stop()
+		}
 		c.mut.RUnlock()
 	}()
 
@@ -164,13 +175,39 @@ func (c *Component) Update(args component.Arguments) error {
 
 	newArgs := args.(Arguments)
 	c.fanout = newArgs.ForwardTo
+	c.args = newArgs
 
+	if newArgs.Clustering.LeaderOnly && c.leadership == nil {
+		leadership, err := cluster.NewComponentLeadership(c.opts)
+		if err != nil {
+			return err
+		}
+		if _, err := leadership.Update(); err != nil {
+			level.Error(c.opts.Logger).Log("msg", "checking leadership failed", "err", err)
+		}
+		c.leadership = leadership
+	} else if !newArgs.Clustering.LeaderOnly {
+		c.leadership = nil
+	}
+
+	return c.restartTarget()
+}
+
+// restartTarget stops the running target and starts a new one if this
+// instance leads the component. c.mut must be held by the caller.
+func (c *Component) restartTarget() error {
 	if c.target != nil {
 		c.target.Stop()
+		c.target = nil
 	}
+	if c.leadership != nil && !c.leadership.IsLeader() {
+		level.Info(c.opts.Logger).Log("msg", "not starting the target because another instance is the leader")
+		return nil
+	}
+
 	entryHandler := loki.NewEntryHandler(c.handler.Chan(), func() {})
 
-	t, err := cft.NewTarget(c.metrics, c.opts.Logger, entryHandler, c.posFile, newArgs.Convert())
+	t, err := cft.NewTarget(c.metrics, c.opts.Logger, entryHandler, c.posFile, c.args.Convert())
 	if err != nil {
 		level.Error(c.opts.Logger).Log("msg", "failed to create cloudflare target with provided config", "err", err)
 		return err
@@ -180,14 +217,38 @@ func (c *Component) Update(args component.Arguments) error {
 	return nil
 }
 
+// NotifyClusterChange implements [cluster.Component].
+func (c *Component) NotifyClusterChange() {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if c.leadership == nil {
+		return
+	}
+	changed, err := c.leadership.Update()
+	if err != nil {
+		level.Error(c.opts.Logger).Log("msg", "checking leadership failed", "err", err)
+		return
+	}
+	if changed {
+		_ = c.restartTarget()
+	}
+}
+
+// Leadership implements [cluster.LeaderComponent].
+func (c *Component) Leadership() *cluster.Leadership {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+	return c.leadership
+}
+
 // DebugInfo returns information about the status of targets.
 func (c *Component) DebugInfo() interface{} {
 	c.mut.RLock()
 	defer c.mut.RUnlock()
 
-	lbls := make(map[string]string, len(c.target.Labels()))
-	for k, v := range c.target.Labels() {
-		lbls[string(k)] = string(v)
+	if c.target == nil {
+		return targetDebugInfo{}
 	}
 	return targetDebugInfo{
 		Ready:   c.target.Ready(),
@@ -16,6 +16,7 @@ import (
 	alloy_relabel "github.com/grafana/alloy/internal/component/common/relabel"
 	"github.com/grafana/alloy/internal/component/loki/source/gcplog/gcptypes"
 	gt "github.com/grafana/alloy/internal/component/loki/source/gcplog/internal/gcplogtarget"
+	"github.com/grafana/alloy/internal/service/cluster"
 	"github.com/grafana/alloy/internal/util"
 )
 
@@ -38,6 +39,7 @@ type Arguments struct {
 	PushTarget   *gcptypes.PushConfig `alloy:"push,block,optional"`
 	ForwardTo    []loki.LogsReceiver  `alloy:"forward_to,attr"`
 	RelabelRules alloy_relabel.Rules  `alloy:"relabel_rules,attr,optional"`
+	Clustering   cluster.LeaderBlock  `alloy:"clustering,block,optional"`
 }
 
 // SetToDefault implements syntax.Defaulter.
@@ -50,6 +52,9 @@ func (a *Arguments) Validate() error {
 	if (a.PullTarget != nil) == (a.PushTarget != nil) {
 		return fmt.Errorf("exactly one of 'push' or 'pull' must be provided")
 	}
+	if a.Clustering.LeaderOnly && a.PushTarget != nil {
+		return fmt.Errorf("clustering leader_only is only supported with 'pull'")
+	}
 	return nil
 }
 
@@ -59,13 +64,20 @@ type Component struct {
 	metrics       *gt.Metrics
 	serverMetrics *util.UncheckedCollector
 
-	mut    sync.RWMutex
-	fanout []loki.LogsReceiver
-	target gt.Target
+	mut        sync.RWMutex
+	args       Arguments
+	fanout     []loki.LogsReceiver
+	target     gt.Target           // nil if another instance leads the component.
+	leadership *cluster.Leadership // Set if the component only runs on the leader.
 
 	handler loki.LogsReceiver
 }
 
+var (
+	_ component.Component     = (*Component)(nil)
+	_ cluster.LeaderComponent = (*Component)(nil)
+)
+
 // New creates a new loki.source.gcplog component.
 func New(o component.Options, args Arguments) (*Component, error) {
 	c := &Component{
@@ -91,9 +103,11 @@ func (c *Component) Run(ctx context.Context) error {
 	defer func() {
 		level.Info(c.opts.Logger).Log("msg", "loki.source.gcplog component shutting down, stopping the targets")
 		c.mut.RLock()
-		err := c.target.Stop()
-		if err != nil {
-			level.Error(c.opts.Logger).Log("msg", "error while stopping gcplog target", "err", err)
+		if c.target != nil {
+			err := c.target.Stop()
+			if err != nil {
+				level.Error(c.opts.Logger).Log("msg", "error while stopping gcplog target", "err", err)
+			}
 		}
 		c.mut.RUnlock()
 	}()
@@ -119,10 +133,30 @@ func (c *Component) Update(args component.Arguments) error {
 
 	newArgs := args.(Arguments)
 	c.fanout = newArgs.ForwardTo
+	c.args = newArgs
+
+	if newArgs.Clustering.LeaderOnly && c.leadership == nil {
+		leadership, err := cluster.NewComponentLeadership(c.opts)
+		if err != nil {
+			return err
+		}
+		if _, err := leadership.Update(); err != nil {
+			level.Error(c.opts.Logger).Log("msg", "checking leadership failed", "err", err)
+		}
+		c.leadership = leadership
+	} else if !newArgs.Clustering.LeaderOnly {
+		c.leadership = nil
+	}
+
+	return c.restartTarget()
+}
 
+// restartTarget stops the running target and starts a new one if this
+// instance leads the component. c.mut must be held by the caller.
+func (c *Component) restartTarget() error {
 	var rcs []*relabel.Config
-	if len(newArgs.RelabelRules) > 0 {
-		rcs = alloy_relabel.ComponentToPromRelabelConfigs(newArgs.RelabelRules)
+	if len(c.args.RelabelRules) > 0 {
+		rcs = alloy_relabel.ComponentToPromRelabelConfigs(c.args.RelabelRules)
 	}
 
 	if c.target != nil {
@@ -130,22 +164,28 @@ func (c *Component) Update(args component.Arguments) error {
 		if err != nil {
 			level.Error(c.opts.Logger).Log("msg", "error while stopping gcplog target", "err", err)
 		}
+		c.target = nil
 	}
+	if c.leadership != nil && !c.leadership.IsLeader() {
+		level.Info(c.opts.Logger).Log("msg", "not starting the target because another instance is the leader")
+		return nil
+	}
+
 	entryHandler := loki.NewEntryHandler(c.handler.Chan(), func() {})
 	r := strings.NewReplacer(".", "_", "/", "_")
 	jobName := r.Replace(c.opts.ID)
 
-	if newArgs.PullTarget != nil {
+	if c.args.PullTarget != nil {
 		// TODO(@tpaschalis) Are there any options from "google.golang.org/api/option"
 		// we should expose as configuration and pass here?
-		t, err := gt.NewPullTarget(c.metrics, c.opts.Logger, entryHandler, jobName, newArgs.PullTarget, rcs)
+		t, err := gt.NewPullTarget(c.metrics, c.opts.Logger, entryHandler, jobName, c.args.PullTarget, rcs)
 		if err != nil {
 			level.Error(c.opts.Logger).Log("msg", "failed to create gcplog target with provided config", "err", err)
 			return err
 		}
 		c.target = t
 	}
-	if newArgs.PushTarget != nil {
+	if c.args.PushTarget != nil {
 		// [gt.NewPushTarget] registers new metrics every time it is called. To
 		// avoid issues with re-registering metrics with the same name, we create a
 		// new registry for the target every time we create one, and pass it to an
@@ -153,7 +193,7 @@ func (c *Component) Update(args component.Arguments) error {
 		registry := prometheus.NewRegistry()
 		c.serverMetrics.SetCollector(registry)
 
-		t, err := gt.NewPushTarget(c.metrics, c.opts.Logger, entryHandler, jobName, newArgs.PushTarget, rcs, registry)
+		t, err := gt.NewPushTarget(c.metrics, c.opts.Logger, entryHandler, jobName, c.args.PushTarget, rcs, registry)
 		if err != nil {
 			level.Error(c.opts.Logger).Log("msg", "failed to create gcplog target with provided config", "err", err)
 			return err
@@ -164,10 +204,38 @@ func (c *Component) Update(args component.Arguments) error {
 	return nil
 }
 
+// NotifyClusterChange implements [cluster.Component].
+func (c *Component) NotifyClusterChange() {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if c.leadership == nil {
+		return
+	}
+	changed, err := c.leadership.Update()
+	if err != nil {
+		level.Error(c.opts.Logger).Log("msg", "checking leadership failed", "err", err)
+		return
+	}
+	if changed {
+		_ = c.restartTarget()
+	}
+}
+
+// Leadership implements [cluster.LeaderComponent].
+func (c *Component) Leadership() *cluster.Leadership {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+	return c.leadership
+}
+
 // DebugInfo returns information about the status of targets.
 func (c *Component) DebugInfo() interface{} {
 	c.mut.RLock()
 	defer c.mut.RUnlock()
+	if c.target == nil {
+		return targetDebugInfo{}
+	}
 	return targetDebugInfo{Details: c.target.Details()}
 }
 
@@ -19,6 +19,7 @@ import (
 	fnet "github.com/grafana/alloy/internal/component/common/net"
 	alloy_relabel "github.com/grafana/alloy/internal/component/common/relabel"
 	"github.com/grafana/alloy/internal/component/loki/source/gcplog/gcptypes"
+	"github.com/grafana/alloy/internal/service/cluster"
 	"github.com/grafana/alloy/internal/util"
 )
 
@@ -165,3 +166,17 @@ func mustNewRegexp(s string) alloy_relabel.Regexp {
 	}
 	return alloy_relabel.Regexp{Regexp: re}
 }
+
+func TestLeaderOnlyRequiresPull(t *testing.T) {
+	args := Arguments{
+		PushTarget: &gcptypes.PushConfig{},
+		Clustering: cluster.LeaderBlock{LeaderOnly: true},
+	}
+	require.EqualError(t, args.Validate(), "clustering leader_only is only supported with 'pull'")
+
+	args = Arguments{
+		PullTarget: &gcptypes.PullConfig{},
+		Clustering: cluster.LeaderBlock{LeaderOnly: true},
+	}
+	require.NoError(t, args.Validate())
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/grafana/alloy/internal/featuregate"
 	"github.com/grafana/alloy/internal/runner"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/internal/service/cluster"
 	"github.com/oklog/run"
 	"k8s.io/client-go/rest"
 )
@@ -49,6 +50,8 @@ type Arguments struct {
 
 	// Client settings to connect to Kubernetes.
 	Client kubernetes.ClientArguments `alloy:"client,block,optional"`
+
+	Clustering cluster.LeaderBlock `alloy:"clustering,block,optional"`
 }
 
 // DefaultArguments holds default settings for loki.source.kubernetes_events.
@@ -89,6 +92,7 @@ type Component struct {
 	mut        sync.Mutex
 	args       Arguments
 	restConfig *rest.Config
+	leadership *cluster.Leadership // Set if the component only runs on the leader.
 
 	tasksMut sync.RWMutex
 	tasks    []eventControllerTask
@@ -100,6 +104,7 @@ type Component struct {
 var (
 	_ component.Component      = (*Component)(nil)
 	_ component.DebugComponent = (*Component)(nil)
+	_ cluster.LeaderComponent  = (*Component)(nil)
 )
 
 // New creates a new loki.source.kubernetes_events component.
@@ -153,6 +158,11 @@ func (c *Component) Run(ctx context.Context) error {
 				tasks := c.tasks
 				c.tasksMut.RUnlock()
 
+				// Stop watching events if another instance leads the component.
+				if !c.leading() {
+					tasks = nil
+				}
+
 				if err := c.runner.ApplyTasks(ctx, tasks); err != nil {
 					level.Error(c.log).Log("msg", "failed to apply event watchers", "err", err)
 				}
@@ -207,6 +217,19 @@ func (c *Component) Update(args component.Arguments) error {
 		}
 	}
 
+	if newArgs.Clustering.LeaderOnly && c.leadership == nil {
+		leadership, err := cluster.NewComponentLeadership(c.opts)
+		if err != nil {
+			return err
+		}
+		if _, err := leadership.Update(); err != nil {
+			level.Error(c.log).Log("msg", "checking leadership failed", "err", err)
+		}
+		c.leadership = leadership
+	} else if !newArgs.Clustering.LeaderOnly {
+		c.leadership = nil
+	}
+
 	// Create a task for each defined namespace.
 	var newTasks []eventControllerTask
 	for _, namespace := range getNamespaces(newArgs) {
@@ -236,6 +259,41 @@ func (c *Component) Update(args component.Arguments) error {
 	return nil
 }
 
+// NotifyClusterChange implements [cluster.Component].
+func (c *Component) NotifyClusterChange() {
+	leadership := c.Leadership()
+	if leadership == nil {
+		return
+	}
+
+	changed, err := leadership.Update()
+	if err != nil {
+		level.Error(c.log).Log("msg", "checking leadership failed", "err", err)
+		return
+	}
+	if changed {
+		select {
+		case c.newTasksCh <- struct{}{}:
+		default:
+			// no-op: task reload already queued.
+		}
+	}
+}
+
+// Leadership implements [cluster.LeaderComponent].
+func (c *Component) Leadership() *cluster.Leadership {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.leadership
+}
+
+// leading returns true if the component should watch events on this
+// instance.
+func (c *Component) leading() bool {
+	leadership := c.Leadership()
+	return leadership == nil || leadership.IsLeader()
+}
+
 // getNamespaces gets a list of namespaces to watch from the arguments. If the
 // list of namespaces is empty, returns a slice to watch all namespaces.
 func getNamespaces(args Arguments) []string {
@@ -126,11 +126,11 @@ type fakeLeadership struct {
 	updateErr error
 }
 
-func (f *fakeLeadership) update() (bool, error) {
+func (f *fakeLeadership) Update() (bool, error) {
 	return f.changed, f.updateErr
 }
 
-func (f *fakeLeadership) isLeader() bool {
+func (f *fakeLeadership) IsLeader() bool {
 	return f.leader
 }
 
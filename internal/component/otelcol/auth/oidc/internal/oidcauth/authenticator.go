@@ -0,0 +1,264 @@
+package oidcauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/extensionauth"
+	"go.uber.org/zap"
+)
+
+var (
+	errNoToken      = errors.New("no bearer token found")
+	errNotBearer    = errors.New("authorization isn't a bearer token")
+	errNoExpiration = errors.New("token has no expiration time")
+)
+
+// Names of the attributes exposed in the client authentication data, in
+// addition to the claims of the token.
+const (
+	attributeSubject    = "subject"
+	attributeMembership = "membership"
+	attributeRaw        = "raw"
+)
+
+type authenticator struct {
+	cfg    *Config
+	logger *zap.Logger
+	now    func() time.Time
+
+	keys *keySet
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+var _ extensionauth.Server = (*authenticator)(nil)
+
+func newAuthenticator(cfg *Config, logger *zap.Logger) *authenticator {
+	return &authenticator{
+		cfg:    cfg,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Start implements component.Component. It fetches the signing keys and keeps
+// refreshing them in the background.
+func (a *authenticator) Start(ctx context.Context, _ component.Host) error {
+	tlsConfig, err := a.cfg.TLS.LoadTLSConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS config: %w", err)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	httpClient := &http.Client{Transport: transport, Timeout: 30 * time.Second}
+
+	jwksURL := a.cfg.JWKSURL
+	if jwksURL == "" {
+		jwksURL, err = discoverJWKSURL(ctx, httpClient, a.cfg.IssuerURL)
+		if err != nil {
+			return err
+		}
+	}
+
+	a.keys = &keySet{client: httpClient, url: jwksURL, now: a.now}
+	if err := a.keys.fetch(ctx); err != nil {
+		return err
+	}
+
+	refreshCtx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
+	a.wg.Add(1)
+	go a.refresh(refreshCtx)
+	return nil
+}
+
+func (a *authenticator) refresh(ctx context.Context) {
+	defer a.wg.Done()
+
+	ticker := time.NewTicker(a.cfg.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Keep the previous keys on failure; they stay valid until the
+			// provider rotates them.
+			if err := a.keys.fetch(ctx); err != nil {
+				a.logger.Warn("failed to refresh signing keys", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Shutdown implements component.Component.
+func (a *authenticator) Shutdown(context.Context) error {
+	if a.cancel != nil {
+		a.cancel()
+	}
+	a.wg.Wait()
+	return nil
+}
+
+// Authenticate implements extensionauth.Server.
+func (a *authenticator) Authenticate(ctx context.Context, sources map[string][]string) (context.Context, error) {
+	raw, err := a.token(sources)
+	if err != nil {
+		return ctx, err
+	}
+
+	claims, err := a.verify(ctx, raw)
+	if err != nil {
+		return ctx, err
+	}
+
+	data := &authData{raw: raw, claims: claims}
+	if a.cfg.UsernameClaim != "" {
+		subject, ok := claims[a.cfg.UsernameClaim].(string)
+		if !ok {
+			return ctx, fmt.Errorf("claim %q isn't a string", a.cfg.UsernameClaim)
+		}
+		data.subject = subject
+	}
+	if a.cfg.GroupsClaim != "" {
+		data.membership = stringSlice(claims[a.cfg.GroupsClaim])
+	}
+
+	info := client.FromContext(ctx)
+	info.Auth = data
+	return client.NewContext(ctx, info), nil
+}
+
+// token returns the bearer token from sources.
+func (a *authenticator) token(sources map[string][]string) (string, error) {
+	var values []string
+	for k, v := range sources {
+		if strings.EqualFold(k, a.cfg.Attribute) {
+			values = v
+			break
+		}
+	}
+	if len(values) == 0 || values[0] == "" {
+		return "", errNoToken
+	}
+
+	scheme, token, ok := strings.Cut(values[0], " ")
+	if !ok || !strings.EqualFold(scheme, "bearer") {
+		return "", errNotBearer
+	}
+	return strings.TrimSpace(token), nil
+}
+
+// verify checks the signature and the standard claims of the token and
+// returns all of its claims.
+func (a *authenticator) verify(ctx context.Context, raw string) (map[string]any, error) {
+	algs := make([]jose.SignatureAlgorithm, 0, len(a.cfg.SigningAlgorithms))
+	for _, alg := range a.cfg.SigningAlgorithms {
+		algs = append(algs, jose.SignatureAlgorithm(alg))
+	}
+
+	tok, err := jwt.ParseSigned(raw, algs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+
+	keys, err := a.keys.lookup(ctx, tok.Headers[0].KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		std    jwt.Claims
+		claims map[string]any
+	)
+	for _, key := range keys {
+		if err = tok.Claims(key.Key, &std, &claims); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid token signature: %w", err)
+	}
+
+	if std.Expiry == nil {
+		return nil, errNoExpiration
+	}
+	expected := jwt.Expected{
+		Issuer:      a.cfg.IssuerURL,
+		AnyAudience: a.cfg.Audiences,
+		Time:        a.now(),
+	}
+	if err := std.ValidateWithLeeway(expected, a.cfg.ClockSkew); err != nil {
+		return nil, fmt.Errorf("invalid token claims: %w", err)
+	}
+	return claims, nil
+}
+
+// stringSlice converts a claim holding a string or a list of strings.
+func stringSlice(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		res := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				res = append(res, s)
+			}
+		}
+		return res
+	default:
+		return nil
+	}
+}
+
+// authData exposes the validated token to other components.
+type authData struct {
+	raw        string
+	subject    string
+	membership []string
+	claims     map[string]any
+}
+
+var _ client.AuthData = (*authData)(nil)
+
+// GetAttribute implements client.AuthData.
+func (d *authData) GetAttribute(name string) any {
+	switch name {
+	case attributeSubject:
+		return d.subject
+	case attributeMembership:
+		return d.membership
+	case attributeRaw:
+		return d.raw
+	default:
+		return d.claims[name]
+	}
+}
+
+// GetAttributeNames implements client.AuthData.
+func (d *authData) GetAttributeNames() []string {
+	names := []string{attributeSubject, attributeMembership, attributeRaw}
+	for name := range d.claims {
+		switch name {
+		case attributeSubject, attributeMembership, attributeRaw:
+		default:
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names[3:])
+	return names
+}
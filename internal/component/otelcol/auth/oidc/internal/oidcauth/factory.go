@@ -0,0 +1,80 @@
+// Package oidcauth implements an OpenTelemetry Collector server
+// authentication extension which validates JWTs issued by an OpenID Connect
+// provider.
+//
+// The upstream oidcauthextension (as of v0.128.0) can't be wrapped instead:
+//   - Its client.AuthData only exposes the "subject", "membership", and "raw"
+//     attributes, so other claims, such as a tenant claim, can't be used for
+//     routing.
+//   - It accepts a single audience and always discovers the signing keys from
+//     the issuer, with no way to set the JWKS URL.
+//   - Its only TLS setting is a CA file, and it doesn't allow restricting the
+//     signing algorithms or setting the clock skew.
+package oidcauth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/extension"
+)
+
+// Type is the type of the extension.
+var Type = component.MustNewType("oidc")
+
+// Config configures the extension.
+type Config struct {
+	// IssuerURL is the expected issuer of the tokens. Unless JWKSURL is set,
+	// the signing keys are discovered from the OpenID configuration of the
+	// issuer.
+	IssuerURL string
+
+	// JWKSURL overrides the URL the signing keys are fetched from.
+	JWKSURL string
+
+	// Audiences are the accepted values of the "aud" claim. Required.
+	Audiences []string
+
+	// Attribute is the name of the header or gRPC metadata holding the token.
+	Attribute string
+
+	// UsernameClaim is the claim exposed as the "subject" attribute.
+	UsernameClaim string
+
+	// GroupsClaim is the claim exposed as the "membership" attribute.
+	GroupsClaim string
+
+	// SigningAlgorithms are the accepted signature algorithms.
+	SigningAlgorithms []string
+
+	// ClockSkew is the leeway used when checking time based claims.
+	ClockSkew time.Duration
+
+	// RefreshInterval is how often the signing keys are fetched again.
+	RefreshInterval time.Duration
+
+	// TLS configures the client used to fetch the OpenID configuration and the
+	// signing keys.
+	TLS configtls.ClientConfig
+}
+
+// NewFactory returns a factory for the extension.
+func NewFactory() extension.Factory {
+	return extension.NewFactory(
+		Type,
+		func() component.Config { return &Config{} },
+		func(_ context.Context, set extension.Settings, cfg component.Config) (extension.Extension, error) {
+			c := cfg.(*Config)
+			// Without audiences, tokens issued by the provider for any other
+			// application would be accepted.
+			if len(c.Audiences) == 0 {
+				return nil, errors.New("at least one audience must be set")
+			}
+			return newAuthenticator(c, set.Logger), nil
+		},
+		component.StabilityLevelDevelopment,
+	)
+}
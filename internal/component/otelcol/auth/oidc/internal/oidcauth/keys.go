@@ -0,0 +1,125 @@
+package oidcauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+// minRefetchInterval limits how often the keys are fetched when a token is
+// signed with an unknown key, so that invalid tokens can't be used to flood
+// the provider with requests.
+const minRefetchInterval = 10 * time.Second
+
+// maxResponseSize limits the size of the documents read from the provider.
+const maxResponseSize = 1 << 20
+
+// keySet caches the signing keys of the provider.
+type keySet struct {
+	client *http.Client
+	url    string
+	now    func() time.Time
+
+	mut       sync.RWMutex
+	keys      jose.JSONWebKeySet
+	lastFetch time.Time
+}
+
+// discoverJWKSURL returns the JWKS URL advertised in the OpenID configuration
+// of issuer.
+func discoverJWKSURL(ctx context.Context, client *http.Client, issuer string) (string, error) {
+	wellKnown := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+
+	var doc struct {
+		Issuer  string `json:"issuer"`
+		JWKSURL string `json:"jwks_uri"`
+	}
+	if err := getJSON(ctx, client, wellKnown, &doc); err != nil {
+		return "", fmt.Errorf("failed to fetch OpenID configuration: %w", err)
+	}
+
+	// The issuer must match exactly, otherwise tokens issued by the provider
+	// would never validate.
+	if doc.Issuer != issuer {
+		return "", fmt.Errorf("OpenID configuration issuer %q doesn't match issuer_url %q", doc.Issuer, issuer)
+	}
+	if doc.JWKSURL == "" {
+		return "", errors.New("OpenID configuration doesn't advertise a jwks_uri")
+	}
+	return doc.JWKSURL, nil
+}
+
+// fetch fetches the keys.
+func (ks *keySet) fetch(ctx context.Context) error {
+	var keys jose.JSONWebKeySet
+	if err := getJSON(ctx, ks.client, ks.url, &keys); err != nil {
+		return fmt.Errorf("failed to fetch signing keys: %w", err)
+	}
+
+	ks.mut.Lock()
+	defer ks.mut.Unlock()
+	ks.keys = keys
+	ks.lastFetch = ks.now()
+	return nil
+}
+
+// lookup returns the keys matching kid. If kid is empty, all keys are
+// returned. If no key matches, the keys are fetched again, at most once every
+// minRefetchInterval.
+func (ks *keySet) lookup(ctx context.Context, kid string) ([]jose.JSONWebKey, error) {
+	if keys := ks.find(kid); len(keys) > 0 {
+		return keys, nil
+	}
+
+	ks.mut.RLock()
+	recent := ks.now().Sub(ks.lastFetch) < minRefetchInterval
+	ks.mut.RUnlock()
+	if recent {
+		return nil, fmt.Errorf("no signing key found for key ID %q", kid)
+	}
+
+	if err := ks.fetch(ctx); err != nil {
+		return nil, err
+	}
+	if keys := ks.find(kid); len(keys) > 0 {
+		return keys, nil
+	}
+	return nil, fmt.Errorf("no signing key found for key ID %q", kid)
+}
+
+func (ks *keySet) find(kid string) []jose.JSONWebKey {
+	ks.mut.RLock()
+	defer ks.mut.RUnlock()
+
+	if kid == "" {
+		return ks.keys.Keys
+	}
+	return ks.keys.Key(kid)
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: unexpected status %s", url, resp.Status)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(v)
+}
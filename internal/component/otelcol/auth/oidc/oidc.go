@@ -0,0 +1,180 @@
+// Package oidc provides an otelcol.auth.oidc component.
+package oidc
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/otelcol"
+	"github.com/grafana/alloy/internal/component/otelcol/auth"
+	"github.com/grafana/alloy/internal/component/otelcol/auth/oidc/internal/oidcauth"
+	otelcolCfg "github.com/grafana/alloy/internal/component/otelcol/config"
+	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/syntax"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pipeline"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:      "otelcol.auth.oidc",
+		Stability: featuregate.StabilityExperimental,
+		Args:      Arguments{},
+		Exports:   auth.Exports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return auth.New(opts, oidcauth.NewFactory(), args.(Arguments))
+		},
+	})
+}
+
+// Arguments configures the otelcol.auth.oidc component.
+type Arguments struct {
+	IssuerURL         string        `alloy:"issuer_url,attr"`
+	Audiences         []string      `alloy:"audiences,attr"`
+	JWKSURL           string        `alloy:"jwks_url,attr,optional"`
+	Attribute         string        `alloy:"attribute,attr,optional"`
+	UsernameClaim     string        `alloy:"username_claim,attr,optional"`
+	GroupsClaim       string        `alloy:"groups_claim,attr,optional"`
+	SigningAlgorithms []string      `alloy:"signing_algorithms,attr,optional"`
+	ClockSkew         time.Duration `alloy:"clock_skew,attr,optional"`
+	RefreshInterval   time.Duration `alloy:"refresh_interval,attr,optional"`
+
+	// TLS configures the client used to reach the OpenID Connect provider.
+	TLS otelcol.TLSClientArguments `alloy:"tls,block,optional"`
+
+	// DebugMetrics configures component internal metrics. Optional.
+	DebugMetrics otelcolCfg.DebugMetricsArguments `alloy:"debug_metrics,block,optional"`
+}
+
+var (
+	_ auth.Arguments   = Arguments{}
+	_ syntax.Defaulter = (*Arguments)(nil)
+	_ syntax.Validator = (*Arguments)(nil)
+)
+
+// DefaultArguments holds default settings for Arguments.
+var DefaultArguments = Arguments{
+	Attribute:     "authorization",
+	UsernameClaim: "sub",
+	SigningAlgorithms: []string{
+		"RS256", "RS384", "RS512",
+		"PS256", "PS384", "PS512",
+		"ES256", "ES384", "ES512",
+		"EdDSA",
+	},
+	ClockSkew:       time.Minute,
+	RefreshInterval: 5 * time.Minute,
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (args *Arguments) SetToDefault() {
+	*args = DefaultArguments
+	args.SigningAlgorithms = slices.Clone(DefaultArguments.SigningAlgorithms)
+	args.DebugMetrics.SetToDefault()
+}
+
+// Validate implements syntax.Validator.
+func (args *Arguments) Validate() error {
+	if err := validateURL("issuer_url", args.IssuerURL); err != nil {
+		return err
+	}
+	if args.JWKSURL != "" {
+		if err := validateURL("jwks_url", args.JWKSURL); err != nil {
+			return err
+		}
+	}
+	if len(args.Audiences) == 0 {
+		return errors.New("audiences must not be empty")
+	}
+	if args.Attribute == "" {
+		return errors.New("attribute must not be empty")
+	}
+	if len(args.SigningAlgorithms) == 0 {
+		return errors.New("signing_algorithms must not be empty")
+	}
+	for _, alg := range args.SigningAlgorithms {
+		if !supportedAlgorithm(alg) {
+			return fmt.Errorf("unsupported signing algorithm %q", alg)
+		}
+	}
+	if args.ClockSkew < 0 {
+		return errors.New("clock_skew must not be negative")
+	}
+	if args.RefreshInterval <= 0 {
+		return errors.New("refresh_interval must be greater than 0")
+	}
+	return nil
+}
+
+func validateURL(name, value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("%s must be an absolute http or https URL", name)
+	}
+	return nil
+}
+
+// asymmetricAlgorithms are the signature algorithms which can be verified
+// with a public key set. Symmetric algorithms such as HS256 are left out.
+var asymmetricAlgorithms = map[string]struct{}{
+	"RS256": {}, "RS384": {}, "RS512": {},
+	"PS256": {}, "PS384": {}, "PS512": {},
+	"ES256": {}, "ES384": {}, "ES512": {},
+	"EdDSA": {},
+}
+
+// supportedAlgorithm reports whether alg is an asymmetric signature
+// algorithm.
+func supportedAlgorithm(alg string) bool {
+	_, ok := asymmetricAlgorithms[alg]
+	return ok
+}
+
+// ConvertClient implements auth.Arguments.
+func (args Arguments) ConvertClient() (otelcomponent.Config, error) {
+	return nil, nil
+}
+
+// ConvertServer implements auth.Arguments.
+func (args Arguments) ConvertServer() (otelcomponent.Config, error) {
+	return &oidcauth.Config{
+		IssuerURL:         args.IssuerURL,
+		JWKSURL:           args.JWKSURL,
+		Audiences:         args.Audiences,
+		Attribute:         args.Attribute,
+		UsernameClaim:     args.UsernameClaim,
+		GroupsClaim:       args.GroupsClaim,
+		SigningAlgorithms: args.SigningAlgorithms,
+		ClockSkew:         args.ClockSkew,
+		RefreshInterval:   args.RefreshInterval,
+		TLS:               *args.TLS.Convert(),
+	}, nil
+}
+
+// AuthFeatures implements auth.Arguments.
+func (args Arguments) AuthFeatures() auth.AuthFeature {
+	return auth.ServerAuthSupported
+}
+
+// Extensions implements auth.Arguments.
+func (args Arguments) Extensions() map[otelcomponent.ID]otelcomponent.Component {
+	return nil
+}
+
+// Exporters implements auth.Arguments.
+func (args Arguments) Exporters() map[pipeline.Signal]map[otelcomponent.ID]otelcomponent.Component {
+	return nil
+}
+
+// DebugMetricsConfig implements auth.Arguments.
+func (args Arguments) DebugMetricsConfig() otelcolCfg.DebugMetricsArguments {
+	return args.DebugMetrics
+}
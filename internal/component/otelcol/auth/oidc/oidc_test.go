@@ -0,0 +1,300 @@
+package oidc_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/otelcol/auth"
+	"github.com/grafana/alloy/internal/component/otelcol/auth/oidc"
+	"github.com/grafana/alloy/internal/runtime/componenttest"
+	"github.com/grafana/alloy/internal/util"
+	"github.com/grafana/alloy/syntax"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/client"
+	extauth "go.opentelemetry.io/collector/extension/extensionauth"
+)
+
+func TestArguments_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  string
+		err  string
+	}{
+		{
+			name: "relative issuer",
+			cfg: `
+				issuer_url = "example.com"
+				audiences  = ["alloy"]
+			`,
+			err: "issuer_url must be an absolute http or https URL",
+		},
+		{
+			name: "missing audiences",
+			cfg:  `issuer_url = "https://example.com"`,
+			err:  `missing required attribute "audiences"`,
+		},
+		{
+			name: "empty audiences",
+			cfg: `
+				issuer_url = "https://example.com"
+				audiences  = []
+			`,
+			err: "audiences must not be empty",
+		},
+		{
+			name: "symmetric algorithm",
+			cfg: `
+				issuer_url         = "https://example.com"
+				audiences          = ["alloy"]
+				signing_algorithms = ["HS256"]
+			`,
+			err: `unsupported signing algorithm "HS256"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var args oidc.Arguments
+			require.ErrorContains(t, syntax.Unmarshal([]byte(tc.cfg), &args), tc.err)
+		})
+	}
+}
+
+func TestServerAuth(t *testing.T) {
+	provider := newTestProvider(t)
+
+	ctx := componenttest.TestContext(t)
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	server := newTestServerAuth(t, ctx, fmt.Sprintf(`
+		issuer_url   = %q
+		audiences    = ["alloy"]
+		groups_claim = "groups"
+	`, provider.issuer()))
+
+	t.Run("valid token", func(t *testing.T) {
+		token := provider.sign(t, map[string]any{
+			"aud":    "alloy",
+			"sub":    "collector-1",
+			"groups": []string{"team-a", "team-b"},
+			"tenant": "acme",
+		})
+
+		authCtx, err := server.Authenticate(ctx, map[string][]string{"Authorization": {"Bearer " + token}})
+		require.NoError(t, err)
+
+		data := client.FromContext(authCtx).Auth
+		require.NotNil(t, data)
+		require.Equal(t, "collector-1", data.GetAttribute("subject"))
+		require.Equal(t, []string{"team-a", "team-b"}, data.GetAttribute("membership"))
+		require.Equal(t, "acme", data.GetAttribute("tenant"))
+		require.Equal(t, token, data.GetAttribute("raw"))
+	})
+
+	t.Run("lowercase gRPC metadata", func(t *testing.T) {
+		token := provider.sign(t, map[string]any{"aud": "alloy", "sub": "collector-1"})
+		_, err := server.Authenticate(ctx, map[string][]string{"authorization": {"bearer " + token}})
+		require.NoError(t, err)
+	})
+
+	invalid := []struct {
+		name    string
+		headers map[string][]string
+		err     string
+	}{
+		{
+			name:    "missing token",
+			headers: map[string][]string{},
+			err:     "no bearer token found",
+		},
+		{
+			name:    "basic auth",
+			headers: map[string][]string{"Authorization": {"Basic Zm9vOmJhcg=="}},
+			err:     "isn't a bearer token",
+		},
+		{
+			name:    "wrong audience",
+			headers: provider.bearer(t, map[string]any{"aud": "other", "sub": "collector-1"}),
+			err:     "invalid audience",
+		},
+		{
+			name:    "wrong issuer",
+			headers: provider.bearer(t, map[string]any{"aud": "alloy", "sub": "collector-1", "iss": "https://evil.example.com"}),
+			err:     "invalid issuer",
+		},
+		{
+			name:    "expired",
+			headers: provider.bearer(t, map[string]any{"aud": "alloy", "sub": "collector-1", "exp": time.Now().Add(-time.Hour).Unix()}),
+			err:     "token is expired",
+		},
+		{
+			name:    "no expiration",
+			headers: provider.bearer(t, map[string]any{"aud": "alloy", "sub": "collector-1", "exp": nil}),
+			err:     "token has no expiration time",
+		},
+	}
+	for _, tc := range invalid {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := server.Authenticate(ctx, tc.headers)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+
+	t.Run("unknown signing key", func(t *testing.T) {
+		other := newTestProvider(t)
+		token := other.sign(t, map[string]any{"aud": "alloy", "sub": "collector-1", "iss": provider.issuer()})
+		_, err := server.Authenticate(ctx, map[string][]string{"Authorization": {"Bearer " + token}})
+		require.Error(t, err)
+	})
+}
+
+// TestServerAuth_KeyRotation ensures that a token signed with a key that
+// wasn't published when the component started is accepted once the key is
+// published.
+func TestServerAuth_KeyRotation(t *testing.T) {
+	provider := newTestProvider(t)
+
+	ctx := componenttest.TestContext(t)
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	server := newTestServerAuth(t, ctx, fmt.Sprintf(`
+		issuer_url       = %q
+		audiences        = ["alloy"]
+		refresh_interval = "100ms"
+	`, provider.issuer()))
+
+	provider.rotate(t)
+	token := provider.sign(t, map[string]any{"aud": "alloy", "sub": "collector-1"})
+
+	require.Eventually(t, func() bool {
+		_, err := server.Authenticate(ctx, map[string][]string{"Authorization": {"Bearer " + token}})
+		return err == nil
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func newTestServerAuth(t *testing.T, ctx context.Context, cfg string) extauth.Server {
+	t.Helper()
+
+	ctrl, err := componenttest.NewControllerFromID(util.TestLogger(t), "otelcol.auth.oidc")
+	require.NoError(t, err)
+
+	var args oidc.Arguments
+	require.NoError(t, syntax.Unmarshal([]byte(cfg), &args))
+
+	go func() {
+		require.NoError(t, ctrl.Run(ctx, args))
+	}()
+	require.NoError(t, ctrl.WaitRunning(time.Second), "component never started")
+	require.NoError(t, ctrl.WaitExports(time.Second), "component never exported anything")
+
+	startedComponent, err := ctrl.GetComponent()
+	require.NoError(t, err)
+	authComponent := startedComponent.(*auth.Auth)
+	require.Eventually(t, func() bool {
+		return authComponent.CurrentHealth().Health == component.HealthTypeHealthy
+	}, 5*time.Second, 10*time.Millisecond, "component never became healthy")
+
+	ext, err := ctrl.Exports().(auth.Exports).Handler.GetExtension(auth.Server)
+	require.NoError(t, err)
+	server, ok := ext.Extension.(extauth.Server)
+	require.True(t, ok, "extension did not implement server authentication")
+
+	_, err = ctrl.Exports().(auth.Exports).Handler.GetExtension(auth.Client)
+	require.ErrorIs(t, err, auth.ErrNotClientExtension)
+
+	return server
+}
+
+// testProvider is a local stand-in for an OpenID Connect provider.
+type testProvider struct {
+	srv *httptest.Server
+
+	mut  sync.Mutex
+	keys []jose.JSONWebKey
+	key  *rsa.PrivateKey
+	kid  string
+}
+
+func newTestProvider(t *testing.T) *testProvider {
+	p := &testProvider{}
+	p.rotate(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   p.issuer(),
+			"jwks_uri": p.srv.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, _ *http.Request) {
+		p.mut.Lock()
+		defer p.mut.Unlock()
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: p.keys})
+	})
+	p.srv = httptest.NewServer(mux)
+	t.Cleanup(p.srv.Close)
+	return p
+}
+
+func (p *testProvider) issuer() string {
+	return p.srv.URL
+}
+
+// rotate generates a new signing key and publishes it next to the previous
+// ones.
+func (p *testProvider) rotate(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	p.key = key
+	p.kid = fmt.Sprintf("key-%d", len(p.keys))
+	p.keys = append(p.keys, jose.JSONWebKey{Key: key.Public(), KeyID: p.kid, Algorithm: string(jose.RS256), Use: "sig"})
+}
+
+// sign signs claims, adding an issuer and an expiration time unless claims
+// overrides them. A nil claim is removed.
+func (p *testProvider) sign(t *testing.T, claims map[string]any) string {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	all := map[string]any{
+		"iss": p.issuer(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	for k, v := range claims {
+		if v == nil {
+			delete(all, k)
+			continue
+		}
+		all[k] = v
+	}
+
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.RS256, Key: p.key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", p.kid),
+	)
+	require.NoError(t, err)
+
+	token, err := jwt.Signed(signer).Claims(all).Serialize()
+	require.NoError(t, err)
+	return token
+}
+
+func (p *testProvider) bearer(t *testing.T, claims map[string]any) map[string][]string {
+	return map[string][]string{"Authorization": {"Bearer " + p.sign(t, claims)}}
+}
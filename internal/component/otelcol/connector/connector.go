@@ -10,7 +10,10 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	otelcomponent "go.opentelemetry.io/collector/component"
 	otelconnector "go.opentelemetry.io/collector/connector"
+	otelconsumer "go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.opentelemetry.io/collector/pipeline"
 	sdkprometheus "go.opentelemetry.io/otel/exporters/prometheus"
 	"go.opentelemetry.io/otel/sdk/metric"
@@ -39,6 +42,15 @@ const (
 	ConnectorLogsToTraces
 	ConnectorLogsToMetrics
 	ConnectorLogsToLogs
+
+	// ConnectorAnyToMetrics is used by connectors which accept every signal
+	// and output metrics.
+	ConnectorAnyToMetrics
+
+	// ConnectorRouter is used by connectors which route each signal to one or
+	// more pipelines of the same signal. Arguments of such connectors must
+	// implement RouterArguments.
+	ConnectorRouter
 )
 
 // Arguments is an extension of component.Arguments which contains necessary
@@ -67,6 +79,22 @@ type Arguments interface {
 	DebugMetricsConfig() otelcolCfg.DebugMetricsArguments
 }
 
+// RouterArguments is implemented by the Arguments of connectors using
+// ConnectorRouter.
+type RouterArguments interface {
+	Arguments
+
+	// RouteConsumers returns the consumers of every pipeline the connector
+	// can route to, keyed by pipeline name. Each name is turned into a
+	// pipeline ID for every signal, such as traces/<name> or logs/<name>.
+	RouteConsumers() map[string]*otelcol.ConsumerArguments
+
+	// ConvertRoutes converts the Arguments into the configuration of the
+	// connector routing the given signal. It is used instead of Convert, as
+	// the pipeline IDs in the configuration differ between signals.
+	ConvertRoutes(signal pipeline.Signal) (otelcomponent.Config, error)
+}
+
 // Connector is an Alloy component shim which manages an OpenTelemetry
 // Collector connector component.
 type Connector struct {
@@ -202,20 +230,73 @@ func (p *Connector) Update(args component.Arguments) error {
 		}
 
 		if len(next.Metrics) > 0 {
-			fanout := fanoutconsumer.Metrics(next.Metrics)
-			metricsInterceptor := interceptconsumer.Metrics(fanout,
-				func(ctx context.Context, md pmetric.Metrics) error {
-					livedebuggingpublisher.PublishMetricsIfActive(p.debugDataPublisher, p.opts.ID, md, otelcol.GetComponentMetadata(next.Metrics))
-					return fanout.ConsumeMetrics(ctx, md)
-				},
-			)
-			tracesConnector, err = p.factory.CreateTracesToMetrics(p.ctx, settings, connectorConfig, metricsInterceptor)
+			tracesConnector, err = p.factory.CreateTracesToMetrics(p.ctx, settings, connectorConfig, p.metricsOutput(next.Metrics))
 			if err != nil && !errors.Is(err, pipeline.ErrSignalNotSupported) {
 				return err
 			} else if tracesConnector != nil {
 				components = append(components, tracesConnector)
 			}
 		}
+	case ConnectorAnyToMetrics:
+		if len(next.Traces) > 0 || len(next.Logs) > 0 {
+			return errors.New("this connector can only output metrics")
+		}
+
+		if len(next.Metrics) > 0 {
+			metricsOutput := p.metricsOutput(next.Metrics)
+
+			tracesConnector, err = p.factory.CreateTracesToMetrics(p.ctx, settings, connectorConfig, metricsOutput)
+			if err != nil && !errors.Is(err, pipeline.ErrSignalNotSupported) {
+				return err
+			} else if tracesConnector != nil {
+				components = append(components, tracesConnector)
+			}
+
+			metricsConnector, err = p.factory.CreateMetricsToMetrics(p.ctx, settings, connectorConfig, metricsOutput)
+			if err != nil && !errors.Is(err, pipeline.ErrSignalNotSupported) {
+				return err
+			} else if metricsConnector != nil {
+				components = append(components, metricsConnector)
+			}
+
+			logsConnector, err = p.factory.CreateLogsToMetrics(p.ctx, settings, connectorConfig, metricsOutput)
+			if err != nil && !errors.Is(err, pipeline.ErrSignalNotSupported) {
+				return err
+			} else if logsConnector != nil {
+				components = append(components, logsConnector)
+			}
+		}
+	case ConnectorRouter:
+		routerArgs, ok := p.args.(RouterArguments)
+		if !ok {
+			return errors.New("router connectors must implement RouterArguments")
+		}
+		routes := routerArgs.RouteConsumers()
+
+		if hasSignal(routes, pipeline.SignalTraces) {
+			tracesConnector, err = p.createRouterTraces(settings, routerArgs, routes)
+			if err != nil && !errors.Is(err, pipeline.ErrSignalNotSupported) {
+				return err
+			} else if tracesConnector != nil {
+				components = append(components, tracesConnector)
+			}
+		}
+		if hasSignal(routes, pipeline.SignalMetrics) {
+			metricsConnector, err = p.createRouterMetrics(settings, routerArgs, routes)
+			if err != nil && !errors.Is(err, pipeline.ErrSignalNotSupported) {
+				return err
+			} else if metricsConnector != nil {
+				components = append(components, metricsConnector)
+			}
+		}
+		if hasSignal(routes, pipeline.SignalLogs) {
+			logsConnector, err = p.createRouterLogs(settings, routerArgs, routes)
+			if err != nil && !errors.Is(err, pipeline.ErrSignalNotSupported) {
+				return err
+			} else if logsConnector != nil {
+				components = append(components, logsConnector)
+			}
+		}
 	default:
 		return errors.New("unsupported connector type")
 	}
@@ -229,6 +310,126 @@ func (p *Connector) Update(args component.Arguments) error {
 	return nil
 }
 
+func (p *Connector) createRouterTraces(settings otelconnector.Settings, args RouterArguments, routes map[string]*otelcol.ConsumerArguments) (otelconnector.Traces, error) {
+	cfg, err := args.ConvertRoutes(pipeline.SignalTraces)
+	if err != nil {
+		return nil, err
+	}
+	return p.factory.CreateTracesToTraces(p.ctx, settings, cfg, otelconnector.NewTracesRouter(p.tracesRoutes(routes)))
+}
+
+func (p *Connector) createRouterMetrics(settings otelconnector.Settings, args RouterArguments, routes map[string]*otelcol.ConsumerArguments) (otelconnector.Metrics, error) {
+	cfg, err := args.ConvertRoutes(pipeline.SignalMetrics)
+	if err != nil {
+		return nil, err
+	}
+	return p.factory.CreateMetricsToMetrics(p.ctx, settings, cfg, otelconnector.NewMetricsRouter(p.metricsRoutes(routes)))
+}
+
+func (p *Connector) createRouterLogs(settings otelconnector.Settings, args RouterArguments, routes map[string]*otelcol.ConsumerArguments) (otelconnector.Logs, error) {
+	cfg, err := args.ConvertRoutes(pipeline.SignalLogs)
+	if err != nil {
+		return nil, err
+	}
+	return p.factory.CreateLogsToLogs(p.ctx, settings, cfg, otelconnector.NewLogsRouter(p.logsRoutes(routes)))
+}
+
+// metricsOutput creates the consumer which connectors outputting metrics send
+// them to.
+func (p *Connector) metricsOutput(next []otelcol.Consumer) otelconsumer.Metrics {
+	fanout := fanoutconsumer.Metrics(next)
+	return interceptconsumer.Metrics(fanout,
+		func(ctx context.Context, md pmetric.Metrics) error {
+			livedebuggingpublisher.PublishMetricsIfActive(p.debugDataPublisher, p.opts.ID, md, otelcol.GetComponentMetadata(next))
+			return fanout.ConsumeMetrics(ctx, md)
+		},
+	)
+}
+
+// hasSignal reports whether any route sends the given signal anywhere.
+func hasSignal(routes map[string]*otelcol.ConsumerArguments, signal pipeline.Signal) bool {
+	for _, next := range routes {
+		if next == nil {
+			continue
+		}
+		switch signal {
+		case pipeline.SignalTraces:
+			if len(next.Traces) > 0 {
+				return true
+			}
+		case pipeline.SignalMetrics:
+			if len(next.Metrics) > 0 {
+				return true
+			}
+		case pipeline.SignalLogs:
+			if len(next.Logs) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tracesRoutes creates a consumer for the traces pipeline of every route.
+// Routes without traces consumers drop the traces sent to them.
+func (p *Connector) tracesRoutes(routes map[string]*otelcol.ConsumerArguments) map[pipeline.ID]otelconsumer.Traces {
+	res := make(map[pipeline.ID]otelconsumer.Traces, len(routes))
+	for name, next := range routes {
+		var consumers []otelcol.Consumer
+		if next != nil {
+			consumers = next.Traces
+		}
+		fanout := fanoutconsumer.Traces(consumers)
+		res[pipeline.NewIDWithName(pipeline.SignalTraces, name)] = interceptconsumer.Traces(fanout,
+			func(ctx context.Context, td ptrace.Traces) error {
+				livedebuggingpublisher.PublishTracesIfActive(p.debugDataPublisher, p.opts.ID, td, otelcol.GetComponentMetadata(consumers))
+				return fanout.ConsumeTraces(ctx, td)
+			},
+		)
+	}
+	return res
+}
+
+// metricsRoutes creates a consumer for the metrics pipeline of every route.
+// Routes without metrics consumers drop the metrics sent to them.
+func (p *Connector) metricsRoutes(routes map[string]*otelcol.ConsumerArguments) map[pipeline.ID]otelconsumer.Metrics {
+	res := make(map[pipeline.ID]otelconsumer.Metrics, len(routes))
+	for name, next := range routes {
+		var consumers []otelcol.Consumer
+		if next != nil {
+			consumers = next.Metrics
+		}
+		fanout := fanoutconsumer.Metrics(consumers)
+		res[pipeline.NewIDWithName(pipeline.SignalMetrics, name)] = interceptconsumer.Metrics(fanout,
+			func(ctx context.Context, md pmetric.Metrics) error {
+				livedebuggingpublisher.PublishMetricsIfActive(p.debugDataPublisher, p.opts.ID, md, otelcol.GetComponentMetadata(consumers))
+				return fanout.ConsumeMetrics(ctx, md)
+			},
+		)
+	}
+	return res
+}
+
+// logsRoutes creates a consumer for the logs pipeline of every route. Routes
+// without logs consumers drop the logs sent to them.
+func (p *Connector) logsRoutes(routes map[string]*otelcol.ConsumerArguments) map[pipeline.ID]otelconsumer.Logs {
+	res := make(map[pipeline.ID]otelconsumer.Logs, len(routes))
+	for name, next := range routes {
+		var consumers []otelcol.Consumer
+		if next != nil {
+			consumers = next.Logs
+		}
+		fanout := fanoutconsumer.Logs(consumers)
+		res[pipeline.NewIDWithName(pipeline.SignalLogs, name)] = interceptconsumer.Logs(fanout,
+			func(ctx context.Context, ld plog.Logs) error {
+				livedebuggingpublisher.PublishLogsIfActive(p.debugDataPublisher, p.opts.ID, ld, otelcol.GetComponentMetadata(consumers))
+				return fanout.ConsumeLogs(ctx, ld)
+			},
+		)
+	}
+	return res
+}
+
 // CurrentHealth implements component.HealthComponent.
 func (p *Connector) CurrentHealth() component.Health {
 	return p.sched.CurrentHealth()
@@ -0,0 +1,185 @@
+// Package count provides an otelcol.connector.count component.
+package count
+
+import (
+	"fmt"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/countconnector"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pipeline"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/otelcol"
+	otelcolCfg "github.com/grafana/alloy/internal/component/otelcol/config"
+	"github.com/grafana/alloy/internal/component/otelcol/connector"
+	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/syntax"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:      "otelcol.connector.count",
+		Stability: featuregate.StabilityExperimental,
+		Args:      Arguments{},
+		Exports:   otelcol.ConsumerExports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			fact := countconnector.NewFactory()
+			return connector.New(opts, fact, args.(Arguments))
+		},
+	})
+}
+
+// Names and descriptions of the metrics emitted for a signal when no metric is
+// configured for it. These match the defaults of the upstream connector, which
+// are only applied when its configuration is unmarshaled from YAML.
+const (
+	defaultSpansName             = "trace.span.count"
+	defaultSpansDescription      = "The number of spans observed."
+	defaultSpanEventsName        = "trace.span.event.count"
+	defaultSpanEventsDescription = "The number of span events observed."
+	defaultMetricsName           = "metric.count"
+	defaultMetricsDescription    = "The number of metrics observed."
+	defaultDataPointsName        = "metric.datapoint.count"
+	defaultDataPointsDescription = "The number of data points observed."
+	defaultLogsName              = "log.record.count"
+	defaultLogsDescription       = "The number of log records observed."
+)
+
+// Arguments configures the otelcol.connector.count component.
+type Arguments struct {
+	Spans      []MetricArguments `alloy:"spans,block,optional"`
+	SpanEvents []MetricArguments `alloy:"spanevents,block,optional"`
+	Metrics    []MetricArguments `alloy:"metrics,block,optional"`
+	DataPoints []MetricArguments `alloy:"datapoints,block,optional"`
+	Logs       []MetricArguments `alloy:"logs,block,optional"`
+
+	// Output configures where to send the generated metrics. Required.
+	Output *otelcol.ConsumerArguments `alloy:"output,block"`
+
+	// DebugMetrics configures component internal metrics. Optional.
+	DebugMetrics otelcolCfg.DebugMetricsArguments `alloy:"debug_metrics,block,optional"`
+}
+
+var (
+	_ connector.Arguments = Arguments{}
+	_ syntax.Defaulter    = (*Arguments)(nil)
+	_ syntax.Validator    = (*Arguments)(nil)
+)
+
+// SetToDefault implements syntax.Defaulter.
+func (args *Arguments) SetToDefault() {
+	*args = Arguments{}
+	args.DebugMetrics.SetToDefault()
+}
+
+// Validate implements syntax.Validator.
+func (args *Arguments) Validate() error {
+	names := map[string]struct{}{}
+	for _, group := range [][]MetricArguments{args.Spans, args.SpanEvents, args.Metrics, args.DataPoints, args.Logs} {
+		for _, m := range group {
+			if _, ok := names[m.Name]; ok {
+				return fmt.Errorf("metric %q is defined more than once", m.Name)
+			}
+			names[m.Name] = struct{}{}
+		}
+	}
+
+	cfg, err := args.Convert()
+	if err != nil {
+		return err
+	}
+	return cfg.(*countconnector.Config).Validate()
+}
+
+// Convert implements connector.Arguments.
+func (args Arguments) Convert() (otelcomponent.Config, error) {
+	return &countconnector.Config{
+		Spans:      convertMetrics(args.Spans, defaultSpansName, defaultSpansDescription),
+		SpanEvents: convertMetrics(args.SpanEvents, defaultSpanEventsName, defaultSpanEventsDescription),
+		Metrics:    convertMetrics(args.Metrics, defaultMetricsName, defaultMetricsDescription),
+		DataPoints: convertMetrics(args.DataPoints, defaultDataPointsName, defaultDataPointsDescription),
+		Logs:       convertMetrics(args.Logs, defaultLogsName, defaultLogsDescription),
+	}, nil
+}
+
+// convertMetrics converts the metrics configured for a kind of telemetry, or
+// returns the default metric if none are configured.
+func convertMetrics(metrics []MetricArguments, defaultName, defaultDescription string) map[string]countconnector.MetricInfo {
+	if len(metrics) == 0 {
+		return map[string]countconnector.MetricInfo{
+			defaultName: {Description: defaultDescription},
+		}
+	}
+
+	res := make(map[string]countconnector.MetricInfo, len(metrics))
+	for _, m := range metrics {
+		res[m.Name] = m.convert()
+	}
+	return res
+}
+
+// Extensions implements connector.Arguments.
+func (args Arguments) Extensions() map[otelcomponent.ID]otelcomponent.Component {
+	return nil
+}
+
+// Exporters implements connector.Arguments.
+func (args Arguments) Exporters() map[pipeline.Signal]map[otelcomponent.ID]otelcomponent.Component {
+	return nil
+}
+
+// NextConsumers implements connector.Arguments.
+func (args Arguments) NextConsumers() *otelcol.ConsumerArguments {
+	return args.Output
+}
+
+// ConnectorType implements connector.Arguments.
+func (Arguments) ConnectorType() int {
+	return connector.ConnectorAnyToMetrics
+}
+
+// DebugMetricsConfig implements connector.Arguments.
+func (args Arguments) DebugMetricsConfig() otelcolCfg.DebugMetricsArguments {
+	return args.DebugMetrics
+}
+
+// MetricArguments configures a count metric.
+type MetricArguments struct {
+	Name        string `alloy:"name,attr"`
+	Description string `alloy:"description,attr,optional"`
+
+	// Conditions are OTTL conditions. Telemetry is counted if it matches any
+	// of them. If empty, all telemetry is counted.
+	Conditions []string `alloy:"conditions,attr,optional"`
+
+	// Attributes partition the count. Each attribute is looked up on the
+	// attributes of the counted telemetry.
+	Attributes []AttributeArguments `alloy:"attribute,block,optional"`
+}
+
+func (args MetricArguments) convert() countconnector.MetricInfo {
+	var attrs []countconnector.AttributeConfig
+	for _, attr := range args.Attributes {
+		attrCfg := countconnector.AttributeConfig{Key: attr.Key}
+		if attr.DefaultValue != "" {
+			attrCfg.DefaultValue = attr.DefaultValue
+		}
+		attrs = append(attrs, attrCfg)
+	}
+
+	return countconnector.MetricInfo{
+		Description: args.Description,
+		Conditions:  args.Conditions,
+		Attributes:  attrs,
+	}
+}
+
+// AttributeArguments configures an attribute of a count metric.
+type AttributeArguments struct {
+	Key string `alloy:"key,attr"`
+
+	// DefaultValue is used when the attribute isn't found. If empty,
+	// telemetry without the attribute isn't counted.
+	DefaultValue string `alloy:"default_value,attr,optional"`
+}
@@ -0,0 +1,201 @@
+package count_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/countconnector"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/grafana/alloy/internal/component/otelcol"
+	"github.com/grafana/alloy/internal/component/otelcol/connector/count"
+	"github.com/grafana/alloy/internal/component/otelcol/internal/fakeconsumer"
+	"github.com/grafana/alloy/internal/runtime/componenttest"
+	"github.com/grafana/alloy/internal/util"
+	"github.com/grafana/alloy/syntax"
+)
+
+func TestArguments_UnmarshalAlloy(t *testing.T) {
+	tests := []struct {
+		testName string
+		cfg      string
+		expected countconnector.Config
+		errorMsg string
+	}{
+		{
+			testName: "Defaults",
+			cfg: `
+				output {}
+			`,
+			expected: countconnector.Config{
+				Spans:      map[string]countconnector.MetricInfo{"trace.span.count": {Description: "The number of spans observed."}},
+				SpanEvents: map[string]countconnector.MetricInfo{"trace.span.event.count": {Description: "The number of span events observed."}},
+				Metrics:    map[string]countconnector.MetricInfo{"metric.count": {Description: "The number of metrics observed."}},
+				DataPoints: map[string]countconnector.MetricInfo{"metric.datapoint.count": {Description: "The number of data points observed."}},
+				Logs:       map[string]countconnector.MetricInfo{"log.record.count": {Description: "The number of log records observed."}},
+			},
+		},
+		{
+			testName: "Explicit values",
+			cfg: `
+				logs {
+					name        = "log.record.count.by_severity"
+					description = "Log records by severity."
+					conditions  = ["severity_number >= SEVERITY_NUMBER_WARN"]
+					attribute {
+						key = "service.name"
+					}
+					attribute {
+						key           = "env"
+						default_value = "unknown"
+					}
+				}
+				output {}
+			`,
+			expected: countconnector.Config{
+				Spans:      map[string]countconnector.MetricInfo{"trace.span.count": {Description: "The number of spans observed."}},
+				SpanEvents: map[string]countconnector.MetricInfo{"trace.span.event.count": {Description: "The number of span events observed."}},
+				Metrics:    map[string]countconnector.MetricInfo{"metric.count": {Description: "The number of metrics observed."}},
+				DataPoints: map[string]countconnector.MetricInfo{"metric.datapoint.count": {Description: "The number of data points observed."}},
+				Logs: map[string]countconnector.MetricInfo{
+					"log.record.count.by_severity": {
+						Description: "Log records by severity.",
+						Conditions:  []string{"severity_number >= SEVERITY_NUMBER_WARN"},
+						Attributes: []countconnector.AttributeConfig{
+							{Key: "service.name"},
+							{Key: "env", DefaultValue: "unknown"},
+						},
+					},
+				},
+			},
+		},
+		{
+			testName: "Duplicate name",
+			cfg: `
+				spans {
+					name = "dup"
+				}
+				logs {
+					name = "dup"
+				}
+				output {}
+			`,
+			errorMsg: `metric "dup" is defined more than once`,
+		},
+		{
+			testName: "Metrics attributes",
+			cfg: `
+				metrics {
+					name = "metric.count.by_env"
+					attribute {
+						key = "env"
+					}
+				}
+				output {}
+			`,
+			errorMsg: `metrics attributes not supported: metric "metric.count.by_env"`,
+		},
+		{
+			testName: "Invalid condition",
+			cfg: `
+				spans {
+					name       = "invalid"
+					conditions = ["not a condition"]
+				}
+				output {}
+			`,
+			errorMsg: `spans condition: metric "invalid"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.testName, func(t *testing.T) {
+			var args count.Arguments
+			err := syntax.Unmarshal([]byte(tc.cfg), &args)
+			if tc.errorMsg != "" {
+				require.ErrorContains(t, err, tc.errorMsg)
+				return
+			}
+			require.NoError(t, err)
+
+			actualPtr, err := args.Convert()
+			require.NoError(t, err)
+			require.Equal(t, &tc.expected, actualPtr.(*countconnector.Config))
+		})
+	}
+}
+
+func TestCount(t *testing.T) {
+	ctx := componenttest.TestContext(t)
+	l := util.TestLogger(t)
+
+	ctrl, err := componenttest.NewControllerFromID(l, "otelcol.connector.count")
+	require.NoError(t, err)
+
+	cfg := `
+		logs {
+			name       = "log.errors"
+			conditions = ["severity_text == \"ERROR\""]
+			attribute {
+				key           = "env"
+				default_value = "unknown"
+			}
+		}
+		output {
+			// no-op: will be overridden by test code.
+		}
+	`
+	var args count.Arguments
+	require.NoError(t, syntax.Unmarshal([]byte(cfg), &args))
+
+	metricsCh := make(chan pmetric.Metrics, 1)
+	args.Output = &otelcol.ConsumerArguments{
+		Metrics: []otelcol.Consumer{&fakeconsumer.Consumer{
+			ConsumeMetricsFunc: func(_ context.Context, md pmetric.Metrics) error {
+				metricsCh <- md
+				return nil
+			},
+		}},
+	}
+
+	go func() {
+		require.NoError(t, ctrl.Run(ctx, args))
+	}()
+	require.NoError(t, ctrl.WaitRunning(time.Second))
+	require.NoError(t, ctrl.WaitExports(time.Second))
+	input := ctrl.Exports().(otelcol.ConsumerExports).Input
+
+	ld := plog.NewLogs()
+	records := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	for _, severity := range []string{"ERROR", "ERROR", "INFO"} {
+		records.AppendEmpty().SetSeverityText(severity)
+	}
+	records.At(0).Attributes().PutStr("env", "prod")
+
+	require.Eventually(t, func() bool {
+		return input.ConsumeLogs(ctx, ld) == nil
+	}, time.Second, 10*time.Millisecond)
+
+	var md pmetric.Metrics
+	select {
+	case md = <-metricsCh:
+	case <-time.After(time.Second):
+		require.FailNow(t, "timed out waiting for metrics")
+	}
+
+	metric := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
+	require.Equal(t, "log.errors", metric.Name())
+	require.True(t, metric.Sum().IsMonotonic())
+	require.Equal(t, pmetric.AggregationTemporalityDelta, metric.Sum().AggregationTemporality())
+
+	counts := map[string]int64{}
+	dps := metric.Sum().DataPoints()
+	for i := 0; i < dps.Len(); i++ {
+		env, _ := dps.At(i).Attributes().Get("env")
+		counts[env.Str()] = dps.At(i).IntValue()
+	}
+	require.Equal(t, map[string]int64{"prod": 1, "unknown": 1}, counts)
+}
@@ -0,0 +1,160 @@
+// Package routing provides an otelcol.connector.routing component.
+package routing
+
+import (
+	"fmt"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/routingconnector"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pipeline"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/otelcol"
+	otelcolCfg "github.com/grafana/alloy/internal/component/otelcol/config"
+	"github.com/grafana/alloy/internal/component/otelcol/connector"
+	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/syntax"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:      "otelcol.connector.routing",
+		Stability: featuregate.StabilityExperimental,
+		Args:      Arguments{},
+		Exports:   otelcol.ConsumerExports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			fact := routingconnector.NewFactory()
+			return connector.New(opts, fact, args.(Arguments))
+		},
+	})
+}
+
+// Names of the pipelines the upstream connector routes to. Each route gets
+// its own pipeline, and unmatched telemetry is sent to the default pipeline.
+const defaultPipeline = "default"
+
+func routePipeline(i int) string {
+	return fmt.Sprintf("route_%d", i)
+}
+
+// Arguments configures the otelcol.connector.routing component.
+type Arguments struct {
+	// ErrorMode determines how the component reacts to errors that occur while
+	// evaluating a condition.
+	ErrorMode ottl.ErrorMode `alloy:"error_mode,attr,optional"`
+
+	Routes []RouteArguments `alloy:"route,block"`
+
+	// Output configures where to send telemetry which doesn't match any route.
+	// Required.
+	Output *otelcol.ConsumerArguments `alloy:"output,block"`
+
+	// DebugMetrics configures component internal metrics. Optional.
+	DebugMetrics otelcolCfg.DebugMetricsArguments `alloy:"debug_metrics,block,optional"`
+}
+
+var (
+	_ connector.RouterArguments = Arguments{}
+	_ syntax.Defaulter          = (*Arguments)(nil)
+	_ syntax.Validator          = (*Arguments)(nil)
+)
+
+// DefaultArguments holds default settings for Arguments.
+var DefaultArguments = Arguments{
+	ErrorMode: ottl.PropagateError,
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (args *Arguments) SetToDefault() {
+	*args = DefaultArguments
+	args.DebugMetrics.SetToDefault()
+}
+
+// Validate implements syntax.Validator.
+func (args *Arguments) Validate() error {
+	cfg, err := args.ConvertRoutes(pipeline.SignalTraces)
+	if err != nil {
+		return err
+	}
+	return cfg.(*routingconnector.Config).Validate()
+}
+
+// Convert implements connector.Arguments. The connector uses ConvertRoutes
+// instead, so Convert returns the configuration used for traces.
+func (args Arguments) Convert() (otelcomponent.Config, error) {
+	return args.ConvertRoutes(pipeline.SignalTraces)
+}
+
+// ConvertRoutes implements connector.RouterArguments.
+func (args Arguments) ConvertRoutes(signal pipeline.Signal) (otelcomponent.Config, error) {
+	table := make([]routingconnector.RoutingTableItem, 0, len(args.Routes))
+	for i, route := range args.Routes {
+		table = append(table, routingconnector.RoutingTableItem{
+			Context:   route.Context,
+			Condition: route.Condition,
+			Statement: route.Statement,
+			Pipelines: []pipeline.ID{pipeline.NewIDWithName(signal, routePipeline(i))},
+		})
+	}
+
+	return &routingconnector.Config{
+		ErrorMode:        args.ErrorMode,
+		DefaultPipelines: []pipeline.ID{pipeline.NewIDWithName(signal, defaultPipeline)},
+		Table:            table,
+	}, nil
+}
+
+// RouteConsumers implements connector.RouterArguments.
+func (args Arguments) RouteConsumers() map[string]*otelcol.ConsumerArguments {
+	routes := make(map[string]*otelcol.ConsumerArguments, len(args.Routes)+1)
+	routes[defaultPipeline] = args.Output
+	for i, route := range args.Routes {
+		routes[routePipeline(i)] = route.Output
+	}
+	return routes
+}
+
+// Extensions implements connector.Arguments.
+func (args Arguments) Extensions() map[otelcomponent.ID]otelcomponent.Component {
+	return nil
+}
+
+// Exporters implements connector.Arguments.
+func (args Arguments) Exporters() map[pipeline.Signal]map[otelcomponent.ID]otelcomponent.Component {
+	return nil
+}
+
+// NextConsumers implements connector.Arguments.
+func (args Arguments) NextConsumers() *otelcol.ConsumerArguments {
+	return args.Output
+}
+
+// ConnectorType implements connector.Arguments.
+func (Arguments) ConnectorType() int {
+	return connector.ConnectorRouter
+}
+
+// DebugMetricsConfig implements connector.Arguments.
+func (args Arguments) DebugMetricsConfig() otelcolCfg.DebugMetricsArguments {
+	return args.DebugMetrics
+}
+
+// RouteArguments configures a single route of the otelcol.connector.routing
+// component.
+type RouteArguments struct {
+	// Context is the OTTL context the condition or statement is evaluated in.
+	Context string `alloy:"context,attr,optional"`
+
+	// Condition is the OTTL condition telemetry must match to be sent to the
+	// route.
+	Condition string `alloy:"condition,attr,optional"`
+
+	// Statement is an OTTL statement using the route() function, as an
+	// alternative to Condition.
+	Statement string `alloy:"statement,attr,optional"`
+
+	// Output configures where to send telemetry matching the route. Required.
+	Output *otelcol.ConsumerArguments `alloy:"output,block"`
+}
@@ -0,0 +1,218 @@
+package routing_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/routingconnector"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/pipeline"
+
+	"github.com/grafana/alloy/internal/component/otelcol"
+	"github.com/grafana/alloy/internal/component/otelcol/connector/routing"
+	"github.com/grafana/alloy/internal/component/otelcol/internal/fakeconsumer"
+	"github.com/grafana/alloy/internal/runtime/componenttest"
+	"github.com/grafana/alloy/internal/util"
+	"github.com/grafana/alloy/syntax"
+)
+
+func TestArguments_UnmarshalAlloy(t *testing.T) {
+	cfg := `
+		error_mode = "ignore"
+
+		route {
+			condition = "attributes[\"tenant\"] == \"acme\""
+			output {}
+		}
+		route {
+			context   = "span"
+			statement = "route() where name == \"health\""
+			output {}
+		}
+		output {}
+	`
+	var args routing.Arguments
+	require.NoError(t, syntax.Unmarshal([]byte(cfg), &args))
+
+	actual, err := args.ConvertRoutes(pipeline.SignalMetrics)
+	require.NoError(t, err)
+
+	expected := &routingconnector.Config{
+		ErrorMode:        ottl.IgnoreError,
+		DefaultPipelines: []pipeline.ID{pipeline.NewIDWithName(pipeline.SignalMetrics, "default")},
+		Table: []routingconnector.RoutingTableItem{
+			{
+				Condition: `attributes["tenant"] == "acme"`,
+				Pipelines: []pipeline.ID{pipeline.NewIDWithName(pipeline.SignalMetrics, "route_0")},
+			},
+			{
+				Context:   "span",
+				Statement: `route() where name == "health"`,
+				Pipelines: []pipeline.ID{pipeline.NewIDWithName(pipeline.SignalMetrics, "route_1")},
+			},
+		},
+	}
+	require.Equal(t, expected, actual)
+	require.Len(t, args.RouteConsumers(), 3)
+}
+
+func TestArguments_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  string
+		err  string
+	}{
+		{
+			name: "no routes",
+			cfg: `
+				output {}
+			`,
+			err: `missing required block "route"`,
+		},
+		{
+			name: "invalid context",
+			cfg: `
+				route {
+					context   = "event"
+					condition = "true"
+					output {}
+				}
+				output {}
+			`,
+			err: `invalid context: event`,
+		},
+		{
+			name: "condition and statement",
+			cfg: `
+				route {
+					condition = "true"
+					statement = "route()"
+					output {}
+				}
+				output {}
+			`,
+			err: `both condition and statement provided`,
+		},
+		{
+			name: "invalid error mode",
+			cfg: `
+				error_mode = "panic"
+				route {
+					condition = "true"
+					output {}
+				}
+				output {}
+			`,
+			err: `unknown error mode panic`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var args routing.Arguments
+			err := syntax.Unmarshal([]byte(tc.cfg), &args)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestRouting(t *testing.T) {
+	ctx := componenttest.TestContext(t)
+	l := util.TestLogger(t)
+
+	ctrl, err := componenttest.NewControllerFromID(l, "otelcol.connector.routing")
+	require.NoError(t, err)
+
+	cfg := `
+		route {
+			condition = "attributes[\"tenant\"] == \"acme\""
+			output {}
+		}
+		route {
+			context   = "log"
+			condition = "severity_text == \"DEBUG\""
+			output {}
+		}
+		output {}
+	`
+	var args routing.Arguments
+	require.NoError(t, syntax.Unmarshal([]byte(cfg), &args))
+
+	// The acme route receives traces and logs, the debug route has no
+	// consumers so that matching logs are dropped.
+	acmeTraces, acmeLogs := make(chan ptrace.Traces, 1), make(chan plog.Logs, 1)
+	defaultTraces, defaultLogs := make(chan ptrace.Traces, 1), make(chan plog.Logs, 1)
+	args.Routes[0].Output = &otelcol.ConsumerArguments{
+		Traces: []otelcol.Consumer{tracesConsumer(acmeTraces)},
+		Logs:   []otelcol.Consumer{logsConsumer(acmeLogs)},
+	}
+	args.Output = &otelcol.ConsumerArguments{
+		Traces: []otelcol.Consumer{tracesConsumer(defaultTraces)},
+		Logs:   []otelcol.Consumer{logsConsumer(defaultLogs)},
+	}
+
+	go func() {
+		require.NoError(t, ctrl.Run(ctx, args))
+	}()
+	require.NoError(t, ctrl.WaitRunning(time.Second))
+	require.NoError(t, ctrl.WaitExports(time.Second))
+	input := ctrl.Exports().(otelcol.ConsumerExports).Input
+
+	td := ptrace.NewTraces()
+	for _, tenant := range []string{"acme", "other"} {
+		rs := td.ResourceSpans().AppendEmpty()
+		rs.Resource().Attributes().PutStr("tenant", tenant)
+		rs.ScopeSpans().AppendEmpty().Spans().AppendEmpty().SetName(tenant)
+	}
+	require.Eventually(t, func() bool {
+		return input.ConsumeTraces(ctx, td) == nil
+	}, time.Second, 10*time.Millisecond)
+
+	require.Equal(t, "acme", receive(t, acmeTraces).ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).Name())
+	require.Equal(t, "other", receive(t, defaultTraces).ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).Name())
+
+	ld := plog.NewLogs()
+	records := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	records.AppendEmpty().SetSeverityText("DEBUG")
+	records.AppendEmpty().SetSeverityText("INFO")
+	require.NoError(t, input.ConsumeLogs(ctx, ld))
+
+	out := receive(t, defaultLogs)
+	require.Equal(t, 1, out.LogRecordCount())
+	require.Equal(t, "INFO", out.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).SeverityText())
+	require.Empty(t, acmeLogs)
+}
+
+func tracesConsumer(ch chan<- ptrace.Traces) otelcol.Consumer {
+	return &fakeconsumer.Consumer{
+		ConsumeTracesFunc: func(_ context.Context, td ptrace.Traces) error {
+			ch <- td
+			return nil
+		},
+	}
+}
+
+func logsConsumer(ch chan<- plog.Logs) otelcol.Consumer {
+	return &fakeconsumer.Consumer{
+		ConsumeLogsFunc: func(_ context.Context, ld plog.Logs) error {
+			ch <- ld
+			return nil
+		},
+	}
+}
+
+func receive[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(time.Second):
+		require.FailNow(t, "timed out waiting for telemetry")
+	}
+	var zero T
+	return zero
+}
@@ -181,12 +181,17 @@ func (e *Exporter) Update(args component.Arguments) error {
 	}
 
 	mp := metric.NewMeterProvider(metricOpts...)
+
+	// Failed exports are reported to the host so that they're reflected in the
+	// health of the component.
+	status := &exportStatus{host: host}
+
 	settings := otelexporter.Settings{
 		ID: otelcomponent.NewIDWithName(e.factory.Type(), e.opts.ID),
 		TelemetrySettings: otelcomponent.TelemetrySettings{
 			Logger: zapadapter.New(e.opts.Logger),
 
-			TracerProvider: e.opts.Tracer,
+			TracerProvider: status.tracerProvider(e.opts.Tracer),
 			MeterProvider:  mp,
 		},
 
@@ -239,7 +244,7 @@ func (e *Exporter) Update(args component.Arguments) error {
 	}
 
 	updateConsumersFunc := func() {
-		e.consumer.SetConsumers(tracesExporter, metricsExporter, logsExporter)
+		e.consumer.SetConsumers(status.traces(tracesExporter), status.metrics(metricsExporter), status.logs(logsExporter))
 	}
 
 	// Schedule the components to run once our component is running.
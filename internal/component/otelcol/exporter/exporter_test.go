@@ -3,6 +3,7 @@ package exporter_test
 import (
 	"context"
 	"errors"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -14,8 +15,10 @@ import (
 	"github.com/grafana/alloy/internal/util"
 	"github.com/stretchr/testify/require"
 	otelcomponent "go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configretry"
 	otelconsumer "go.opentelemetry.io/collector/consumer"
 	otelexporter "go.opentelemetry.io/collector/exporter"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.opentelemetry.io/collector/pipeline"
 )
@@ -76,6 +79,101 @@ func TestExporter(t *testing.T) {
 	}
 }
 
+func TestExporterHealth(t *testing.T) {
+	ctx := componenttest.TestContext(t)
+
+	var fail atomic.Bool
+	innerExporter := &fakeExporter{
+		ConsumeTracesFunc: func(context.Context, ptrace.Traces) error {
+			if fail.Load() {
+				return errors.New("connection refused")
+			}
+			return nil
+		},
+	}
+
+	te := newTestEnvironment(t, innerExporter)
+	te.Start()
+
+	require.NoError(t, te.Controller.WaitRunning(time.Second), "test component did not start")
+	require.NoError(t, te.Controller.WaitExports(time.Second), "test component did not generate exports")
+	ce := te.Controller.Exports().(otelcol.ConsumerExports)
+
+	inner, err := te.Controller.GetComponent()
+	require.NoError(t, err)
+	health := func() component.Health { return inner.(component.HealthComponent).CurrentHealth() }
+
+	require.Eventually(t, func() bool {
+		return ce.Input.ConsumeTraces(ctx, createTestTraces()) == nil
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, component.HealthTypeHealthy, health().Health)
+
+	// A failed export marks the component as unhealthy until an export
+	// succeeds again.
+	fail.Store(true)
+	require.Error(t, ce.Input.ConsumeTraces(ctx, createTestTraces()))
+	require.Equal(t, component.HealthTypeUnhealthy, health().Health)
+	require.Contains(t, health().Message, "connection refused")
+
+	fail.Store(false)
+	require.NoError(t, ce.Input.ConsumeTraces(ctx, createTestTraces()))
+	require.Equal(t, component.HealthTypeHealthy, health().Health)
+}
+
+// TestExporterHealth_Queued ensures that the health of exporters with a
+// sending queue reflects the exports done from the queue.
+func TestExporterHealth_Queued(t *testing.T) {
+	ctx := componenttest.TestContext(t)
+
+	var fail atomic.Bool
+	te := newTestEnvironmentWithFactory(t, func(ctx context.Context, set otelexporter.Settings, cfg otelcomponent.Config) (otelexporter.Traces, error) {
+		return exporterhelper.NewTraces(ctx, set, cfg,
+			func(context.Context, ptrace.Traces) error {
+				if fail.Load() {
+					return errors.New("connection refused")
+				}
+				return nil
+			},
+			exporterhelper.WithQueue(exporterhelper.NewDefaultQueueConfig()),
+			exporterhelper.WithRetry(configretry.BackOffConfig{Enabled: false}),
+		)
+	})
+	te.Start()
+
+	require.NoError(t, te.Controller.WaitRunning(time.Second), "test component did not start")
+	require.NoError(t, te.Controller.WaitExports(time.Second), "test component did not generate exports")
+	ce := te.Controller.Exports().(otelcol.ConsumerExports)
+
+	inner, err := te.Controller.GetComponent()
+	require.NoError(t, err)
+	health := func() component.Health { return inner.(component.HealthComponent).CurrentHealth() }
+
+	require.Eventually(t, func() bool {
+		return ce.Input.ConsumeTraces(ctx, createTestTraces()) == nil
+	}, time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool {
+		return health().Health == component.HealthTypeHealthy
+	}, time.Second, 10*time.Millisecond)
+
+	// The queue accepts the data, but exporting it fails.
+	fail.Store(true)
+	require.NoError(t, ce.Input.ConsumeTraces(ctx, createTestTraces()))
+	require.Eventually(t, func() bool {
+		return health().Health == component.HealthTypeUnhealthy
+	}, time.Second, 10*time.Millisecond)
+	require.Contains(t, health().Message, "connection refused")
+
+	// Queueing more data doesn't mark the component as healthy.
+	fail.Store(false)
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, component.HealthTypeUnhealthy, health().Health)
+
+	require.NoError(t, ce.Input.ConsumeTraces(ctx, createTestTraces()))
+	require.Eventually(t, func() bool {
+		return health().Health == component.HealthTypeHealthy
+	}, time.Second, 10*time.Millisecond)
+}
+
 type testEnvironment struct {
 	t *testing.T
 
@@ -85,13 +183,21 @@ type testEnvironment struct {
 func newTestEnvironment(t *testing.T, fe *fakeExporter) *testEnvironment {
 	t.Helper()
 
+	return newTestEnvironmentWithFactory(t, func(context.Context, otelexporter.Settings, otelcomponent.Config) (otelexporter.Traces, error) {
+		return fe, nil
+	})
+}
+
+func newTestEnvironmentWithFactory(t *testing.T, createTraces otelexporter.CreateTracesFunc) *testEnvironment {
+	t.Helper()
+
 	reg := component.Registration{
 		Name:    "testcomponent",
 		Args:    fakeExporterArgs{},
 		Exports: otelcol.ConsumerExports{},
 		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
-			// Create a factory which always returns our instance of fakeExporter
-			// defined above.
+			// Create a factory which always creates traces exporters with
+			// createTraces.
 			factory := otelexporter.NewFactory(
 				otelcomponent.MustNewType("testcomponent"),
 				func() otelcomponent.Config {
@@ -99,9 +205,7 @@ func newTestEnvironment(t *testing.T, fe *fakeExporter) *testEnvironment {
 					require.NoError(t, err)
 					return res
 				},
-				otelexporter.WithTraces(func(ctx context.Context, ecs otelexporter.Settings, e otelcomponent.Config) (otelexporter.Traces, error) {
-					return fe, nil
-				}, otelcomponent.StabilityLevelUndefined),
+				otelexporter.WithTraces(createTraces, otelcomponent.StabilityLevelUndefined),
 			)
 
 			return exporter.New(opts, factory, args.(exporter.Arguments), exporter.TypeSignalConstFunc(exporter.TypeAll))
@@ -0,0 +1,170 @@
+// Package file provides an otelcol.exporter.file component.
+package file
+
+import (
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/fileexporter"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pipeline"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/otelcol"
+	otelcolCfg "github.com/grafana/alloy/internal/component/otelcol/config"
+	"github.com/grafana/alloy/internal/component/otelcol/exporter"
+	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/syntax"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:      "otelcol.exporter.file",
+		Stability: featuregate.StabilityExperimental,
+		Args:      Arguments{},
+		Exports:   otelcol.ConsumerExports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			fact := fileexporter.NewFactory()
+			return exporter.New(opts, fact, args.(Arguments), exporter.TypeSignalConstFunc(exporter.TypeAll))
+		},
+	})
+}
+
+// Arguments configures the otelcol.exporter.file component.
+type Arguments struct {
+	Path          string        `alloy:"path,attr"`
+	Append        bool          `alloy:"append,attr,optional"`
+	Format        string        `alloy:"format,attr,optional"`
+	Compression   string        `alloy:"compression,attr,optional"`
+	FlushInterval time.Duration `alloy:"flush_interval,attr,optional"`
+
+	Rotation *RotationArguments `alloy:"rotation,block,optional"`
+	GroupBy  *GroupByArguments  `alloy:"group_by,block,optional"`
+
+	// DebugMetrics configures component internal metrics. Optional.
+	DebugMetrics otelcolCfg.DebugMetricsArguments `alloy:"debug_metrics,block,optional"`
+}
+
+var (
+	_ exporter.Arguments = Arguments{}
+	_ syntax.Defaulter   = (*Arguments)(nil)
+	_ syntax.Validator   = (*Arguments)(nil)
+)
+
+// DefaultArguments holds default settings for Arguments.
+var DefaultArguments = Arguments{
+	Format:        "json",
+	FlushInterval: time.Second,
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (args *Arguments) SetToDefault() {
+	*args = DefaultArguments
+	args.DebugMetrics.SetToDefault()
+}
+
+// Validate implements syntax.Validator.
+func (args *Arguments) Validate() error {
+	cfg, err := args.Convert()
+	if err != nil {
+		return err
+	}
+	return cfg.(*fileexporter.Config).Validate()
+}
+
+// Convert implements exporter.Arguments.
+func (args Arguments) Convert() (otelcomponent.Config, error) {
+	return &fileexporter.Config{
+		Path:          args.Path,
+		Append:        args.Append,
+		Rotation:      args.Rotation.Convert(),
+		FormatType:    args.Format,
+		Compression:   args.Compression,
+		FlushInterval: args.FlushInterval,
+		GroupBy:       args.GroupBy.Convert(),
+	}, nil
+}
+
+// Extensions implements exporter.Arguments.
+func (args Arguments) Extensions() map[otelcomponent.ID]otelcomponent.Component {
+	return nil
+}
+
+// Exporters implements exporter.Arguments.
+func (args Arguments) Exporters() map[pipeline.Signal]map[otelcomponent.ID]otelcomponent.Component {
+	return nil
+}
+
+// DebugMetricsConfig implements exporter.Arguments.
+func (args Arguments) DebugMetricsConfig() otelcolCfg.DebugMetricsArguments {
+	return args.DebugMetrics
+}
+
+// RotationArguments configures the rotation of the file.
+type RotationArguments struct {
+	MaxMegabytes int  `alloy:"max_megabytes,attr,optional"`
+	MaxDays      int  `alloy:"max_days,attr,optional"`
+	MaxBackups   int  `alloy:"max_backups,attr,optional"`
+	LocalTime    bool `alloy:"localtime,attr,optional"`
+}
+
+var _ syntax.Defaulter = (*RotationArguments)(nil)
+
+// DefaultRotationArguments holds default settings for RotationArguments.
+var DefaultRotationArguments = RotationArguments{
+	MaxMegabytes: 100,
+	MaxBackups:   100,
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (args *RotationArguments) SetToDefault() {
+	*args = DefaultRotationArguments
+}
+
+// Convert converts args into the upstream type.
+func (args *RotationArguments) Convert() *fileexporter.Rotation {
+	if args == nil {
+		return nil
+	}
+
+	return &fileexporter.Rotation{
+		MaxMegabytes: args.MaxMegabytes,
+		MaxDays:      args.MaxDays,
+		MaxBackups:   args.MaxBackups,
+		LocalTime:    args.LocalTime,
+	}
+}
+
+// GroupByArguments configures writing telemetry to a file per value of a
+// resource attribute.
+type GroupByArguments struct {
+	Enabled           bool   `alloy:"enabled,attr,optional"`
+	ResourceAttribute string `alloy:"resource_attribute,attr,optional"`
+	MaxOpenFiles      int    `alloy:"max_open_files,attr,optional"`
+}
+
+var _ syntax.Defaulter = (*GroupByArguments)(nil)
+
+// DefaultGroupByArguments holds default settings for GroupByArguments.
+var DefaultGroupByArguments = GroupByArguments{
+	ResourceAttribute: "fileexporter.path_segment",
+	MaxOpenFiles:      100,
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (args *GroupByArguments) SetToDefault() {
+	*args = DefaultGroupByArguments
+}
+
+// Convert converts args into the upstream type.
+func (args *GroupByArguments) Convert() *fileexporter.GroupBy {
+	if args == nil {
+		return nil
+	}
+
+	return &fileexporter.GroupBy{
+		Enabled:           args.Enabled,
+		ResourceAttribute: args.ResourceAttribute,
+		MaxOpenFiles:      args.MaxOpenFiles,
+	}
+}
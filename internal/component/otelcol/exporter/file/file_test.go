@@ -0,0 +1,111 @@
+package file_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/fileexporter"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/component/otelcol/exporter/file"
+	"github.com/grafana/alloy/syntax"
+)
+
+func TestArguments_UnmarshalAlloy(t *testing.T) {
+	tests := []struct {
+		testName string
+		cfg      string
+		expected fileexporter.Config
+		errorMsg string
+	}{
+		{
+			testName: "Defaults",
+			cfg: `
+				path = "/tmp/otlp.jsonl"
+			`,
+			expected: fileexporter.Config{
+				Path:          "/tmp/otlp.jsonl",
+				FormatType:    "json",
+				FlushInterval: time.Second,
+			},
+		},
+		{
+			testName: "Rotation and group by",
+			cfg: `
+				path           = "/tmp/otlp/*.bin"
+				format         = "proto"
+				compression    = "zstd"
+				flush_interval = "5s"
+
+				rotation {
+					max_days  = 2
+					localtime = true
+				}
+
+				group_by {
+					enabled = true
+				}
+			`,
+			expected: fileexporter.Config{
+				Path:          "/tmp/otlp/*.bin",
+				FormatType:    "proto",
+				Compression:   "zstd",
+				FlushInterval: 5 * time.Second,
+				Rotation: &fileexporter.Rotation{
+					MaxMegabytes: 100,
+					MaxDays:      2,
+					MaxBackups:   100,
+					LocalTime:    true,
+				},
+				GroupBy: &fileexporter.GroupBy{
+					Enabled:           true,
+					ResourceAttribute: "fileexporter.path_segment",
+					MaxOpenFiles:      100,
+				},
+			},
+		},
+		{
+			testName: "Unsupported compression",
+			cfg: `
+				path        = "/tmp/otlp.jsonl"
+				compression = "gzip"
+			`,
+			errorMsg: "compression is not supported",
+		},
+		{
+			testName: "Append and rotation",
+			cfg: `
+				path   = "/tmp/otlp.jsonl"
+				append = true
+				rotation {}
+			`,
+			errorMsg: "append and rotation enabled at the same time is not supported",
+		},
+		{
+			testName: "Group by without wildcard",
+			cfg: `
+				path = "/tmp/otlp.jsonl"
+				group_by {
+					enabled = true
+				}
+			`,
+			errorMsg: "path must contain exactly one * when group_by is enabled",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.testName, func(t *testing.T) {
+			var args file.Arguments
+			err := syntax.Unmarshal([]byte(tc.cfg), &args)
+			if tc.errorMsg != "" {
+				require.ErrorContains(t, err, tc.errorMsg)
+				return
+			}
+			require.NoError(t, err)
+
+			actualPtr, err := args.Convert()
+			require.NoError(t, err)
+			require.Equal(t, &tc.expected, actualPtr.(*fileexporter.Config))
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package exporter
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+
+	otelcomponent "go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componentstatus"
+	otelconsumer "go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// exporterhelperScope is the instrumentation scope of the spans created by
+// exporterhelper for each export, once it's out of the sending queue and
+// retries are done.
+const exporterhelperScope = "go.opentelemetry.io/collector/exporter/exporterhelper"
+
+// exportStatus reports the outcome of exports to the host of the exporter, so
+// that the component is unhealthy from a failed export until an export
+// succeeds again.
+//
+// Exporters built with exporterhelper accept data as soon as it's in their
+// sending queue, so the outcome of their exports is taken from the span
+// exporterhelper ends after the retries. Errors returned by the consumers,
+// such as a full sending queue, are reported too.
+//
+// Only changes are reported, so that the health isn't updated for every
+// export.
+type exportStatus struct {
+	host    otelcomponent.Host
+	failing atomic.Bool
+
+	// helper is set once an export span from exporterhelper is seen. From
+	// then on, only those spans report successful exports.
+	helper atomic.Bool
+}
+
+func (s *exportStatus) report(err error) {
+	switch {
+	case err != nil:
+		// Always report errors so that the health holds the latest one.
+		s.failing.Store(true)
+		componentstatus.ReportStatus(s.host, componentstatus.NewRecoverableErrorEvent(err))
+	case s.failing.Swap(false):
+		componentstatus.ReportStatus(s.host, componentstatus.NewEvent(componentstatus.StatusOK))
+	}
+}
+
+// reportConsumed reports the outcome of a call to a consumer of the exporter.
+func (s *exportStatus) reportConsumed(err error) {
+	if err == nil && s.helper.Load() {
+		// The data may only be queued.
+		return
+	}
+	s.report(err)
+}
+
+// tracerProvider wraps the tracer provider of the exporter to get the outcome
+// of the exports from the spans of exporterhelper.
+func (s *exportStatus) tracerProvider(tp trace.TracerProvider) trace.TracerProvider {
+	return &statusTracerProvider{TracerProvider: tp, status: s}
+}
+
+func (s *exportStatus) traces(next otelconsumer.Traces) otelconsumer.Traces {
+	if next == nil {
+		return nil
+	}
+	return &statusTraces{Traces: next, status: s}
+}
+
+func (s *exportStatus) metrics(next otelconsumer.Metrics) otelconsumer.Metrics {
+	if next == nil {
+		return nil
+	}
+	return &statusMetrics{Metrics: next, status: s}
+}
+
+func (s *exportStatus) logs(next otelconsumer.Logs) otelconsumer.Logs {
+	if next == nil {
+		return nil
+	}
+	return &statusLogs{Logs: next, status: s}
+}
+
+type statusTraces struct {
+	otelconsumer.Traces
+	status *exportStatus
+}
+
+// ConsumeTraces implements otelconsumer.Traces.
+func (c *statusTraces) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
+	err := c.Traces.ConsumeTraces(ctx, td)
+	c.status.reportConsumed(err)
+	return err
+}
+
+type statusMetrics struct {
+	otelconsumer.Metrics
+	status *exportStatus
+}
+
+// ConsumeMetrics implements otelconsumer.Metrics.
+func (c *statusMetrics) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
+	err := c.Metrics.ConsumeMetrics(ctx, md)
+	c.status.reportConsumed(err)
+	return err
+}
+
+type statusLogs struct {
+	otelconsumer.Logs
+	status *exportStatus
+}
+
+// ConsumeLogs implements otelconsumer.Logs.
+func (c *statusLogs) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	err := c.Logs.ConsumeLogs(ctx, ld)
+	c.status.reportConsumed(err)
+	return err
+}
+
+type statusTracerProvider struct {
+	trace.TracerProvider
+	status *exportStatus
+}
+
+// Tracer implements trace.TracerProvider.
+func (p *statusTracerProvider) Tracer(name string, opts ...trace.TracerOption) trace.Tracer {
+	tracer := p.TracerProvider.Tracer(name, opts...)
+	if name != exporterhelperScope {
+		return tracer
+	}
+	return &statusTracer{Tracer: tracer, status: p.status}
+}
+
+type statusTracer struct {
+	trace.Tracer
+	status *exportStatus
+}
+
+// Start implements trace.Tracer.
+func (t *statusTracer) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	ctx, span := t.Tracer.Start(ctx, name, opts...)
+	if !strings.HasPrefix(name, "exporter/") {
+		return ctx, span
+	}
+	t.status.helper.Store(true)
+	s := &statusSpan{Span: span, status: t.status}
+	return trace.ContextWithSpan(ctx, s), s
+}
+
+// statusSpan reports the outcome of an export when it ends.
+type statusSpan struct {
+	trace.Span
+	status *exportStatus
+	err    error
+}
+
+// IsRecording implements trace.Span. exporterhelper only sets the status of
+// recording spans, so the span always claims to be recording, even if the
+// wrapped span is dropped.
+func (s *statusSpan) IsRecording() bool {
+	return true
+}
+
+// SetStatus implements trace.Span.
+func (s *statusSpan) SetStatus(code codes.Code, description string) {
+	s.Span.SetStatus(code, description)
+	if code == codes.Error {
+		s.err = errors.New(description)
+	}
+}
+
+// End implements trace.Span.
+func (s *statusSpan) End(opts ...trace.SpanEndOption) {
+	s.Span.End(opts...)
+	s.status.report(s.err)
+}
@@ -7,18 +7,23 @@ package extension
 
 import (
 	"context"
+	"net/http"
 	"os"
+	"sync"
+	"time"
 
 	"github.com/grafana/alloy/internal/build"
 	"github.com/grafana/alloy/internal/component"
 	otelcolCfg "github.com/grafana/alloy/internal/component/otelcol/config"
 	"github.com/grafana/alloy/internal/component/otelcol/internal/lazycollector"
 	"github.com/grafana/alloy/internal/component/otelcol/internal/scheduler"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
 	"github.com/grafana/alloy/internal/util/zapadapter"
 	"github.com/grafana/alloy/syntax"
 	"github.com/prometheus/client_golang/prometheus"
 	otelcomponent "go.opentelemetry.io/collector/component"
 	otelextension "go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/extension/extensioncapabilities"
 	"go.opentelemetry.io/collector/pipeline"
 	sdkprometheus "go.opentelemetry.io/otel/exporters/prometheus"
 	"go.opentelemetry.io/otel/sdk/metric"
@@ -49,6 +54,26 @@ type Arguments interface {
 	ExportsHandler() bool
 }
 
+// ZPagesArguments is implemented by the Arguments of extensions which serve the
+// zPages of the host they run in, such as the zpages extension.
+type ZPagesArguments interface {
+	// ZPages returns the function registering the zPages of the host.
+	ZPages(component.Options) (func(mux *http.ServeMux, pathPrefix string), error)
+}
+
+// PipelineWatcherArguments is implemented by the Arguments of extensions which
+// implement extensioncapabilities.PipelineWatcher, such as the health_check
+// extension. Alloy doesn't have pipelines, so the extension is told they're
+// ready whenever PipelinesReady returns true.
+type PipelineWatcherArguments interface {
+	// PipelinesReady returns whether the components watched by the extension
+	// are ready.
+	PipelinesReady(component.Options) (bool, error)
+}
+
+// pipelinesReadyInterval is how often PipelinesReady is checked.
+const pipelinesReadyInterval = time.Second
+
 // Exports is a common Exports type for Alloy components which expose
 // OpenTelemetry Collector storage extensions.
 type Exports struct {
@@ -78,6 +103,19 @@ type Extension struct {
 
 	sched     *scheduler.Scheduler
 	collector *lazycollector.Collector
+
+	watcherMut sync.Mutex
+	watcher    *pipelineWatcher // Set if the extension watches pipelines.
+}
+
+// pipelineWatcher tells an extension whether the pipelines it watches are
+// ready.
+type pipelineWatcher struct {
+	extension extensioncapabilities.PipelineWatcher
+	ready     func() (bool, error)
+
+	reported bool // Whether the state has been reported at least once.
+	last     bool // Last reported state.
 }
 
 var (
@@ -116,20 +154,75 @@ func New(opts component.Options, f otelextension.Factory, args Arguments) (*Exte
 func (e *Extension) Run(ctx context.Context) error {
 	e.opts.Logger.Log("level", "info", "msg", "starting extension", "component", e.opts.ID)
 	defer e.cancel()
+	go e.watchPipelines(ctx)
 	return e.sched.Run(ctx)
 }
 
+// watchPipelines periodically reports whether the pipelines are ready to the
+// extension, if it watches pipelines.
+func (e *Extension) watchPipelines(ctx context.Context) {
+	ticker := time.NewTicker(pipelinesReadyInterval)
+	defer ticker.Stop()
+
+	for {
+		e.updatePipelinesReady()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *Extension) updatePipelinesReady() {
+	e.watcherMut.Lock()
+	defer e.watcherMut.Unlock()
+
+	w := e.watcher
+	if w == nil {
+		return
+	}
+
+	ready, err := w.ready()
+	if err != nil {
+		level.Debug(e.opts.Logger).Log("msg", "failed to check if pipelines are ready", "err", err)
+		ready = false
+	}
+	// Only report changes, the upstream extensions log every call.
+	if w.reported && ready == w.last {
+		return
+	}
+	w.reported, w.last = true, ready
+
+	if ready {
+		err = w.extension.Ready()
+	} else {
+		err = w.extension.NotReady()
+	}
+	if err != nil {
+		level.Warn(e.opts.Logger).Log("msg", "failed to report pipelines state to extension", "ready", ready, "err", err)
+	}
+}
+
 // Update implements component.Component. It will convert the Arguments into
 // configuration for OpenTelemetry Collector extension
 // configuration and manage the underlying OpenTelemetry Collector extension.
 func (e *Extension) Update(args component.Arguments) error {
 	rargs := args.(Arguments)
 
-	host := scheduler.NewHost(
-		e.opts.Logger,
+	hostOpts := []scheduler.HostOption{
 		scheduler.WithHostExtensions(rargs.Extensions()),
 		scheduler.WithHostExporters(rargs.Exporters()),
-	)
+	}
+	if za, ok := rargs.(ZPagesArguments); ok {
+		zPages, err := za.ZPages(e.opts)
+		if err != nil {
+			return err
+		}
+		hostOpts = append(hostOpts, scheduler.WithHostZPages(zPages))
+	}
+	host := scheduler.NewHost(e.opts.Logger, hostOpts...)
 
 	reg := prometheus.NewRegistry()
 	e.collector.Set(reg)
@@ -184,6 +277,20 @@ func (e *Extension) Update(args component.Arguments) error {
 		})
 	}
 
+	// The new extension doesn't know the state of the pipelines yet.
+	var watcher *pipelineWatcher
+	if pw, ok := ext.(extensioncapabilities.PipelineWatcher); ok {
+		if wa, ok := rargs.(PipelineWatcherArguments); ok {
+			watcher = &pipelineWatcher{
+				extension: pw,
+				ready:     func() (bool, error) { return wa.PipelinesReady(e.opts) },
+			}
+		}
+	}
+	e.watcherMut.Lock()
+	e.watcher = watcher
+	e.watcherMut.Unlock()
+
 	// Schedule the components to run once our component is running.
 	e.sched.Schedule(e.ctx, func() {}, host, components...)
 	return nil
@@ -2,6 +2,7 @@ package extension_test
 
 import (
 	"context"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -33,6 +34,60 @@ func TestExtension(t *testing.T) {
 	require.NoError(t, waitCreated.Wait(time.Second), "extension never created")
 }
 
+func TestExtension_PipelineWatcher(t *testing.T) {
+	watcher := &fakePipelineWatcher{}
+	reg := component.Registration{
+		Name: "testcomponent",
+		Args: fakeWatcherArgs{},
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			factory := otelextension.NewFactory(
+				otelcomponent.MustNewType("testcomponent"),
+				func() otelcomponent.Config { return nil },
+				func(context.Context, otelextension.Settings, otelcomponent.Config) (otelextension.Extension, error) {
+					return watcher, nil
+				}, otelcomponent.StabilityLevelUndefined,
+			)
+			return extension.New(opts, factory, args.(extension.Arguments))
+		},
+	}
+	ctrl := componenttest.NewControllerFromReg(util.TestLogger(t), reg)
+
+	args := fakeWatcherArgs{ready: &atomic.Bool{}}
+	go func() {
+		require.NoError(t, ctrl.Run(componenttest.TestContext(t), args))
+	}()
+
+	// The pipelines aren't ready yet.
+	require.Eventually(t, func() bool { return watcher.notReady.Load() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	// Only changes are reported.
+	args.ready.Store(true)
+	require.Eventually(t, func() bool { return watcher.ready.Load() == 1 }, 5*time.Second, 10*time.Millisecond)
+	time.Sleep(2 * time.Second)
+	require.Equal(t, int32(1), watcher.ready.Load())
+	require.Equal(t, int32(1), watcher.notReady.Load())
+}
+
+type fakePipelineWatcher struct {
+	ready, notReady atomic.Int32
+}
+
+func (*fakePipelineWatcher) Start(context.Context, otelcomponent.Host) error { return nil }
+func (*fakePipelineWatcher) Shutdown(context.Context) error                  { return nil }
+func (w *fakePipelineWatcher) Ready() error                                  { w.ready.Add(1); return nil }
+func (w *fakePipelineWatcher) NotReady() error                               { w.notReady.Add(1); return nil }
+
+type fakeWatcherArgs struct {
+	fakeExtensionArgs
+	ready *atomic.Bool
+}
+
+var _ extension.PipelineWatcherArguments = fakeWatcherArgs{}
+
+func (fa fakeWatcherArgs) PipelinesReady(component.Options) (bool, error) {
+	return fa.ready.Load(), nil
+}
+
 type testEnvironment struct {
 	t *testing.T
 
@@ -0,0 +1,194 @@
+// Package health_check provides an otelcol.extension.health_check component.
+package health_check
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/healthcheckextension"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pipeline"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/otelcol"
+	otelcolCfg "github.com/grafana/alloy/internal/component/otelcol/config"
+	"github.com/grafana/alloy/internal/component/otelcol/extension"
+	"github.com/grafana/alloy/internal/component/otelcol/extension/internal/componenthealth"
+	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/syntax"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:      "otelcol.extension.health_check",
+		Stability: featuregate.StabilityExperimental,
+		Args:      Arguments{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			fact := healthcheckextension.NewFactory()
+			return extension.New(opts, fact, args.(Arguments))
+		},
+	})
+}
+
+// Arguments configures the otelcol.extension.health_check component.
+type Arguments struct {
+	HTTPServer otelcol.HTTPServerArguments `alloy:",squash"`
+
+	// Path is the path the health is served at.
+	Path string `alloy:"path,attr,optional"`
+
+	// ResponseBody replaces the JSON body of the responses.
+	ResponseBody *ResponseBodyArguments `alloy:"response_body,block,optional"`
+
+	// Components are the IDs of the components which must be healthy. If
+	// empty, all the otelcol components of the module must be healthy.
+	Components []string `alloy:"components,attr,optional"`
+
+	// IncludeDownstream also checks the components which Components send
+	// data to, directly or through other components, so that a pipeline can
+	// be checked by listing its receivers.
+	IncludeDownstream bool `alloy:"include_downstream,attr,optional"`
+
+	// DebugMetrics configures component internal metrics. Optional.
+	DebugMetrics otelcolCfg.DebugMetricsArguments `alloy:"debug_metrics,block,optional"`
+}
+
+// ResponseBodyArguments configures the bodies of the responses.
+type ResponseBodyArguments struct {
+	Healthy   string `alloy:"healthy,attr,optional"`
+	Unhealthy string `alloy:"unhealthy,attr,optional"`
+}
+
+var (
+	_ extension.Arguments                = Arguments{}
+	_ extension.PipelineWatcherArguments = Arguments{}
+	_ syntax.Defaulter                   = (*Arguments)(nil)
+	_ syntax.Validator                   = (*Arguments)(nil)
+)
+
+// SetToDefault implements syntax.Defaulter.
+func (args *Arguments) SetToDefault() {
+	*args = Arguments{
+		HTTPServer: otelcol.HTTPServerArguments{
+			Endpoint:              "0.0.0.0:13133",
+			CompressionAlgorithms: append([]string(nil), otelcol.DefaultCompressionAlgorithms...),
+		},
+		Path: "/",
+	}
+	args.DebugMetrics.SetToDefault()
+}
+
+// Validate implements syntax.Validator.
+func (args *Arguments) Validate() error {
+	if !strings.HasPrefix(args.Path, "/") {
+		return fmt.Errorf("path %q must start with a /", args.Path)
+	}
+	if args.IncludeDownstream && len(args.Components) == 0 {
+		return errors.New("include_downstream requires components to be set")
+	}
+
+	cfg, err := args.Convert(component.Options{})
+	if err != nil {
+		return err
+	}
+	return cfg.(*healthcheckextension.Config).Validate()
+}
+
+// Convert implements extension.Arguments.
+func (args Arguments) Convert(_ component.Options) (otelcomponent.Config, error) {
+	httpServer, err := args.HTTPServer.Convert()
+	if err != nil {
+		return nil, err
+	}
+	if httpServer == nil {
+		return nil, errors.New("the HTTP server must be configured")
+	}
+
+	// Start from the default configuration to keep the defaults of the
+	// deprecated check_collector_pipeline settings, which aren't exposed.
+	cfg := healthcheckextension.NewFactory().CreateDefaultConfig().(*healthcheckextension.Config)
+	cfg.ServerConfig = *httpServer
+	cfg.Path = args.Path
+	if args.ResponseBody != nil {
+		cfg.ResponseBody = &healthcheckextension.ResponseBodySettings{
+			Healthy:   args.ResponseBody.Healthy,
+			Unhealthy: args.ResponseBody.Unhealthy,
+		}
+	}
+	return cfg, nil
+}
+
+// PipelinesReady implements extension.PipelineWatcherArguments. The pipelines
+// are ready when the checked components are healthy.
+func (args Arguments) PipelinesReady(opts component.Options) (bool, error) {
+	source, err := componenthealth.NewSource(opts)
+	if err != nil {
+		return false, err
+	}
+
+	if len(args.Components) == 0 {
+		statuses, err := source.Otelcol()
+		if err != nil {
+			return false, err
+		}
+		for _, s := range statuses {
+			if !s.Healthy() {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+
+	statuses, err := source.List()
+	if err != nil {
+		return false, err
+	}
+	byID := make(map[string]componenthealth.Status, len(statuses))
+	for _, s := range statuses {
+		byID[s.ID] = s
+	}
+
+	checked := make(map[string]bool, len(args.Components))
+	queue := slices.Clone(args.Components)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if checked[id] {
+			continue
+		}
+		checked[id] = true
+
+		// Components which aren't found aren't healthy.
+		s, ok := byID[id]
+		if !ok || !s.Healthy() {
+			return false, nil
+		}
+		if args.IncludeDownstream {
+			queue = append(queue, s.DataFlowEdgesTo...)
+		}
+	}
+	return true, nil
+}
+
+// Extensions implements extension.Arguments.
+func (args Arguments) Extensions() map[otelcomponent.ID]otelcomponent.Component {
+	return args.HTTPServer.Extensions()
+}
+
+// Exporters implements extension.Arguments.
+func (args Arguments) Exporters() map[pipeline.Signal]map[otelcomponent.ID]otelcomponent.Component {
+	return nil
+}
+
+// DebugMetricsConfig implements extension.Arguments.
+func (args Arguments) DebugMetricsConfig() otelcolCfg.DebugMetricsArguments {
+	return args.DebugMetrics
+}
+
+// ExportsHandler implements extension.Arguments.
+func (args Arguments) ExportsHandler() bool {
+	return false
+}
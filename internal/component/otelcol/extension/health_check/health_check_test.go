@@ -0,0 +1,159 @@
+package health_check_test
+
+import (
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/healthcheckextension"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/otelcol/extension/health_check"
+	"github.com/grafana/alloy/syntax"
+)
+
+func TestArguments(t *testing.T) {
+	cfg := `
+		endpoint   = "127.0.0.1:13134"
+		path       = "/health"
+		components = ["otelcol.exporter.otlp.default"]
+
+		response_body {
+			healthy   = "ok"
+			unhealthy = "ko"
+		}
+	`
+	var args health_check.Arguments
+	require.NoError(t, syntax.Unmarshal([]byte(cfg), &args))
+
+	converted, err := args.Convert(component.Options{})
+	require.NoError(t, err)
+
+	actual := converted.(*healthcheckextension.Config)
+	require.Equal(t, "127.0.0.1:13134", actual.ServerConfig.Endpoint)
+	require.Equal(t, "/health", actual.Path)
+	require.Equal(t, &healthcheckextension.ResponseBodySettings{Healthy: "ok", Unhealthy: "ko"}, actual.ResponseBody)
+	require.NoError(t, actual.Validate())
+}
+
+func TestArguments_Defaults(t *testing.T) {
+	var args health_check.Arguments
+	require.NoError(t, syntax.Unmarshal([]byte(""), &args))
+	require.Equal(t, "0.0.0.0:13133", args.HTTPServer.Endpoint)
+	require.Equal(t, "/", args.Path)
+
+	converted, err := args.Convert(component.Options{})
+	require.NoError(t, err)
+	require.Nil(t, converted.(*healthcheckextension.Config).ResponseBody)
+}
+
+func TestArguments_Validate(t *testing.T) {
+	var args health_check.Arguments
+	require.EqualError(t, syntax.Unmarshal([]byte(`path = "health"`), &args), `path "health" must start with a /`)
+	require.EqualError(t, syntax.Unmarshal([]byte(`include_downstream = true`), &args), "include_downstream requires components to be set")
+}
+
+type fakeProvider []*component.Info
+
+func (p fakeProvider) GetComponent(component.ID, component.InfoOptions) (*component.Info, error) {
+	return nil, component.ErrComponentNotFound
+}
+
+func (p fakeProvider) ListComponents(string, component.InfoOptions) ([]*component.Info, error) {
+	return p, nil
+}
+
+func TestArguments_PipelinesReady(t *testing.T) {
+	opts := component.Options{
+		ID: "otelcol.extension.health_check.default",
+		GetServiceData: func(string) (any, error) {
+			return fakeProvider{
+				{
+					ID:              component.ID{LocalID: "otelcol.receiver.otlp.default"},
+					ComponentName:   "otelcol.receiver.otlp",
+					Health:          component.Health{Health: component.HealthTypeHealthy},
+					DataFlowEdgesTo: []string{"otelcol.processor.batch.default"},
+				},
+				{
+					ID:              component.ID{LocalID: "otelcol.processor.batch.default"},
+					ComponentName:   "otelcol.processor.batch",
+					Health:          component.Health{Health: component.HealthTypeHealthy},
+					DataFlowEdgesTo: []string{"otelcol.exporter.otlp.default"},
+				},
+				{
+					ID:              component.ID{LocalID: "otelcol.receiver.otlp.logs"},
+					ComponentName:   "otelcol.receiver.otlp",
+					Health:          component.Health{Health: component.HealthTypeHealthy},
+					DataFlowEdgesTo: []string{"otelcol.exporter.debug.default"},
+				},
+				{
+					ID:            component.ID{LocalID: "otelcol.exporter.debug.default"},
+					ComponentName: "otelcol.exporter.debug",
+					Health:        component.Health{Health: component.HealthTypeHealthy},
+				},
+				{
+					ID:            component.ID{LocalID: "otelcol.exporter.otlp.default"},
+					ComponentName: "otelcol.exporter.otlp",
+					Health:        component.Health{Health: component.HealthTypeUnhealthy, Message: "connection refused"},
+				},
+				{
+					ID:            component.ID{LocalID: "prometheus.scrape.default"},
+					ComponentName: "prometheus.scrape",
+					Health:        component.Health{Health: component.HealthTypeUnhealthy},
+				},
+				{
+					ID:            component.ID{LocalID: "otelcol.extension.health_check.default"},
+					ComponentName: "otelcol.extension.health_check",
+					Health:        component.Health{Health: component.HealthTypeUnknown},
+				},
+			}, nil
+		},
+	}
+
+	tests := []struct {
+		name              string
+		components        []string
+		includeDownstream bool
+		ready             bool
+	}{
+		{
+			name:  "all otelcol components",
+			ready: false,
+		},
+		{
+			name:       "healthy component",
+			components: []string{"otelcol.receiver.otlp.default"},
+			ready:      true,
+		},
+		{
+			name:       "unhealthy component",
+			components: []string{"otelcol.receiver.otlp.default", "otelcol.exporter.otlp.default"},
+			ready:      false,
+		},
+		{
+			name:       "missing component",
+			components: []string{"otelcol.receiver.otlp.other"},
+			ready:      false,
+		},
+		{
+			name:              "unhealthy downstream component",
+			components:        []string{"otelcol.receiver.otlp.default"},
+			includeDownstream: true,
+			ready:             false,
+		},
+		{
+			name:              "healthy downstream components",
+			components:        []string{"otelcol.receiver.otlp.logs"},
+			includeDownstream: true,
+			ready:             true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			args := health_check.Arguments{Components: tc.components, IncludeDownstream: tc.includeDownstream}
+			ready, err := args.PipelinesReady(opts)
+			require.NoError(t, err)
+			require.Equal(t, tc.ready, ready)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Package componenthealth lists the health of the components running
+// alongside an otelcol extension, for the extensions which report on it.
+package componenthealth
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/service/otel"
+)
+
+// Source lists the components of the module an extension runs in.
+type Source struct {
+	provider component.Provider
+	moduleID string
+	selfID   string
+}
+
+// NewSource returns a Source listing the components of the module of the
+// component configured by opts.
+func NewSource(opts component.Options) (*Source, error) {
+	data, err := opts.GetServiceData(otel.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+	provider, ok := data.(component.Provider)
+	if !ok {
+		return nil, fmt.Errorf("the %s service does not expose the running components", otel.ServiceName)
+	}
+
+	id := component.ParseID(opts.ID)
+	return &Source{
+		provider: provider,
+		moduleID: id.ModuleID,
+		selfID:   id.LocalID,
+	}, nil
+}
+
+// Status is the health of a component.
+type Status struct {
+	ID     string // Local ID of the component.
+	Name   string // Name of the component, such as otelcol.exporter.otlp.
+	Health component.Health
+
+	// IDs of the components the component sends data to.
+	DataFlowEdgesTo []string
+}
+
+// Kind returns the kind of an otelcol component, such as "exporter", or an
+// empty string for other components.
+func (s Status) Kind() string {
+	rest, ok := strings.CutPrefix(s.Name, "otelcol.")
+	if !ok {
+		return ""
+	}
+	kind, _, _ := strings.Cut(rest, ".")
+	return kind
+}
+
+// Healthy returns true if the component is healthy. Components whose health
+// isn't known yet, for example because they haven't been evaluated, aren't
+// healthy.
+func (s Status) Healthy() bool {
+	return s.Health.Health == component.HealthTypeHealthy
+}
+
+// List returns the status of the components of the module, sorted by ID. The
+// extension itself isn't listed.
+func (s *Source) List() ([]Status, error) {
+	infos, err := s.provider.ListComponents(s.moduleID, component.InfoOptions{GetHealth: true})
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]Status, 0, len(infos))
+	for _, info := range infos {
+		if info.ID.LocalID == s.selfID {
+			continue
+		}
+		res = append(res, Status{
+			ID:              info.ID.LocalID,
+			Name:            info.ComponentName,
+			Health:          info.Health,
+			DataFlowEdgesTo: info.DataFlowEdgesTo,
+		})
+	}
+	slices.SortFunc(res, func(a, b Status) int { return strings.Compare(a.ID, b.ID) })
+	return res, nil
+}
+
+// Otelcol returns the status of the otelcol components of the module.
+func (s *Source) Otelcol() ([]Status, error) {
+	all, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(all, func(st Status) bool { return st.Kind() == "" }), nil
+}
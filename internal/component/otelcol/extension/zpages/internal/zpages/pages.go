@@ -0,0 +1,128 @@
+// Package zpages serves the zPages of the host of the zpages extension, which
+// list the running Alloy components and their health.
+package zpages
+
+import (
+	"html/template"
+	"net/http"
+	"path"
+	"runtime"
+	"time"
+
+	"github.com/go-kit/log"
+	"go.opentelemetry.io/collector/featuregate"
+
+	"github.com/grafana/alloy/internal/build"
+	"github.com/grafana/alloy/internal/component/otelcol/extension/internal/componenthealth"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+)
+
+// Pages serves the servicez, pipelinez, extensionz, and featurez pages. The
+// tracez page is served by the upstream extension.
+type Pages struct {
+	log       log.Logger
+	source    *componenthealth.Source
+	startTime time.Time
+}
+
+// New returns the pages of the components listed by source.
+func New(l log.Logger, source *componenthealth.Source) *Pages {
+	return &Pages{
+		log:       l,
+		source:    source,
+		startTime: time.Now(),
+	}
+}
+
+// Register registers the pages on mux under pathPrefix.
+func (p *Pages) Register(mux *http.ServeMux, pathPrefix string) {
+	mux.HandleFunc(path.Join(pathPrefix, "servicez"), p.serveServicez)
+	mux.HandleFunc(path.Join(pathPrefix, "pipelinez"), p.servePipelinez)
+	mux.HandleFunc(path.Join(pathPrefix, "extensionz"), p.serveExtensionz)
+	mux.HandleFunc(path.Join(pathPrefix, "featurez"), p.serveFeaturez)
+}
+
+func (p *Pages) render(w http.ResponseWriter, name string, data any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := templates.ExecuteTemplate(w, name, data); err != nil {
+		level.Error(p.log).Log("msg", "failed to render zpages page", "page", name, "err", err)
+	}
+}
+
+func (p *Pages) serveServicez(w http.ResponseWriter, _ *http.Request) {
+	p.render(w, "servicez", map[string]any{
+		"Version":   build.Version,
+		"Revision":  build.Revision,
+		"Branch":    build.Branch,
+		"BuildDate": build.BuildDate,
+		"GoVersion": runtime.Version(),
+		"StartTime": p.startTime.Format(time.RFC3339),
+		"Uptime":    time.Since(p.startTime).Truncate(time.Second).String(),
+	})
+}
+
+// pipelineKinds are the kinds of otelcol components listed in pipelinez, in
+// the order data flows through them.
+var pipelineKinds = []string{"receiver", "processor", "connector", "exporter"}
+
+type componentGroup struct {
+	Kind       string
+	Components []componenthealth.Status
+}
+
+func (p *Pages) servePipelinez(w http.ResponseWriter, _ *http.Request) {
+	p.serveComponents(w, "Pipelines", pipelineKinds)
+}
+
+func (p *Pages) serveExtensionz(w http.ResponseWriter, _ *http.Request) {
+	p.serveComponents(w, "Extensions", []string{"extension", "auth"})
+}
+
+func (p *Pages) serveComponents(w http.ResponseWriter, title string, kinds []string) {
+	statuses, err := p.source.Otelcol()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	groups := make([]componentGroup, 0, len(kinds))
+	for _, kind := range kinds {
+		g := componentGroup{Kind: kind}
+		for _, s := range statuses {
+			if s.Kind() == kind {
+				g.Components = append(g.Components, s)
+			}
+		}
+		groups = append(groups, g)
+	}
+	p.render(w, "components", map[string]any{
+		"Title":  title,
+		"Groups": groups,
+	})
+}
+
+type featureRow struct {
+	ID          string
+	Enabled     bool
+	Stage       string
+	Description string
+	URL         string
+}
+
+func (p *Pages) serveFeaturez(w http.ResponseWriter, _ *http.Request) {
+	var rows []featureRow
+	featuregate.GlobalRegistry().VisitAll(func(g *featuregate.Gate) {
+		rows = append(rows, featureRow{
+			ID:          g.ID(),
+			Enabled:     g.IsEnabled(),
+			Stage:       g.Stage().String(),
+			Description: g.Description(),
+			URL:         g.ReferenceURL(),
+		})
+	})
+	p.render(w, "featurez", rows)
+}
+
+var templates = template.Must(template.New("zpages").Funcs(template.FuncMap{
+	"time": func(t time.Time) string { return t.Format(time.RFC3339Nano) },
+}).Parse(templatesText))
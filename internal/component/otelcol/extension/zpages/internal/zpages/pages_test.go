@@ -0,0 +1,70 @@
+package zpages
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	alloycomponent "github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/otelcol/extension/internal/componenthealth"
+	"github.com/grafana/alloy/internal/util"
+)
+
+type fakeProvider []*alloycomponent.Info
+
+func (p fakeProvider) GetComponent(alloycomponent.ID, alloycomponent.InfoOptions) (*alloycomponent.Info, error) {
+	return nil, alloycomponent.ErrComponentNotFound
+}
+
+func (p fakeProvider) ListComponents(string, alloycomponent.InfoOptions) ([]*alloycomponent.Info, error) {
+	return p, nil
+}
+
+func TestPages(t *testing.T) {
+	source, err := componenthealth.NewSource(alloycomponent.Options{
+		ID: "otelcol.extension.zpages.default",
+		GetServiceData: func(string) (any, error) {
+			return fakeProvider{
+				{
+					ID:              alloycomponent.ID{LocalID: "otelcol.receiver.otlp.default"},
+					ComponentName:   "otelcol.receiver.otlp",
+					Health:          alloycomponent.Health{Health: alloycomponent.HealthTypeHealthy},
+					DataFlowEdgesTo: []string{"otelcol.exporter.otlp.default"},
+				},
+				{
+					ID:            alloycomponent.ID{LocalID: "otelcol.exporter.otlp.default"},
+					ComponentName: "otelcol.exporter.otlp",
+					Health:        alloycomponent.Health{Health: alloycomponent.HealthTypeUnhealthy, Message: "connection refused"},
+				},
+				{
+					ID:            alloycomponent.ID{LocalID: "otelcol.auth.basic.default"},
+					ComponentName: "otelcol.auth.basic",
+					Health:        alloycomponent.Health{Health: alloycomponent.HealthTypeHealthy},
+				},
+			}, nil
+		},
+	})
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	New(util.TestLogger(t), source).Register(mux, "/debug")
+
+	get := func(path string) string {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusOK, rec.Code, path)
+		return rec.Body.String()
+	}
+
+	require.Contains(t, get("/debug/servicez"), "Uptime")
+
+	pipelinez := get("/debug/pipelinez")
+	require.Contains(t, pipelinez, "otelcol.receiver.otlp.default")
+	require.Contains(t, pipelinez, "connection refused")
+	require.NotContains(t, pipelinez, "otelcol.auth.basic.default")
+
+	require.Contains(t, get("/debug/extensionz"), "otelcol.auth.basic.default")
+	require.Contains(t, get("/debug/featurez"), "<table>")
+}
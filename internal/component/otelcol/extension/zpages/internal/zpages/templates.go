@@ -0,0 +1,85 @@
+package zpages
+
+// templatesText holds the templates of the pages. Every page is rendered
+// with the shared header and footer.
+const templatesText = `
+{{define "header"}}<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.}} - Grafana Alloy zPages</title>
+<style>
+body { font-family: sans-serif; margin: 1em 2em; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+th { background: #eee; }
+.healthy { color: #1a7f37; }
+.unhealthy, .exited { color: #cf222e; }
+.unknown { color: #9a6700; }
+nav a { margin-right: 1em; }
+</style>
+</head>
+<body>
+<nav>
+<a href="servicez">Service</a>
+<a href="pipelinez">Pipelines</a>
+<a href="extensionz">Extensions</a>
+<a href="featurez">Feature gates</a>
+<a href="tracez">Spans</a>
+</nav>
+<h1>{{.}}</h1>
+{{end}}
+
+{{define "footer"}}</body>
+</html>
+{{end}}
+
+{{define "servicez"}}{{template "header" "Service"}}
+<table>
+<tr><th>Version</th><td>{{.Version}}</td></tr>
+<tr><th>Revision</th><td>{{.Revision}}</td></tr>
+<tr><th>Branch</th><td>{{.Branch}}</td></tr>
+<tr><th>Build date</th><td>{{.BuildDate}}</td></tr>
+<tr><th>Go version</th><td>{{.GoVersion}}</td></tr>
+<tr><th>Start time</th><td>{{.StartTime}}</td></tr>
+<tr><th>Uptime</th><td>{{.Uptime}}</td></tr>
+</table>
+{{template "footer"}}{{end}}
+
+{{define "components"}}{{template "header" .Title}}
+{{range .Groups}}
+<h2>{{.Kind}}s</h2>
+{{if .Components}}
+<table>
+<tr><th>Component</th><th>Name</th><th>Health</th><th>Message</th><th>Last update</th><th>Sends data to</th></tr>
+{{range .Components}}
+<tr>
+<td>{{.ID}}</td>
+<td>{{.Name}}</td>
+<td class="{{.Health.Health}}">{{.Health.Health}}</td>
+<td>{{.Health.Message}}</td>
+<td>{{if not .Health.UpdateTime.IsZero}}{{time .Health.UpdateTime}}{{end}}</td>
+<td>{{range .DataFlowEdgesTo}}{{.}}<br>{{end}}</td>
+</tr>
+{{end}}
+</table>
+{{else}}
+<p>No {{.Kind}} is running.</p>
+{{end}}
+{{end}}
+{{template "footer"}}{{end}}
+
+{{define "featurez"}}{{template "header" "Feature gates"}}
+<table>
+<tr><th>ID</th><th>Enabled</th><th>Stage</th><th>Description</th></tr>
+{{range .}}
+<tr>
+<td>{{if .URL}}<a href="{{.URL}}">{{.ID}}</a>{{else}}{{.ID}}{{end}}</td>
+<td>{{.Enabled}}</td>
+<td>{{.Stage}}</td>
+<td>{{.Description}}</td>
+</tr>
+{{end}}
+</table>
+{{template "footer"}}{{end}}
+`
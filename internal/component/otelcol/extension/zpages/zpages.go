@@ -0,0 +1,124 @@
+// Package zpages provides an otelcol.extension.zpages component.
+package zpages
+
+import (
+	"errors"
+	"net/http"
+
+	otelcomponent "go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/zpagesextension"
+	"go.opentelemetry.io/collector/pipeline"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/otelcol"
+	otelcolCfg "github.com/grafana/alloy/internal/component/otelcol/config"
+	"github.com/grafana/alloy/internal/component/otelcol/extension"
+	"github.com/grafana/alloy/internal/component/otelcol/extension/internal/componenthealth"
+	"github.com/grafana/alloy/internal/component/otelcol/extension/zpages/internal/zpages"
+	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/syntax"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:      "otelcol.extension.zpages",
+		Stability: featuregate.StabilityExperimental,
+		Args:      Arguments{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			fact := zpagesextension.NewFactory()
+			return extension.New(opts, fact, args.(Arguments))
+		},
+	})
+}
+
+// Arguments configures the otelcol.extension.zpages component.
+type Arguments struct {
+	HTTPServer otelcol.HTTPServerArguments `alloy:",squash"`
+
+	// Expvar configures the expvarz page.
+	Expvar ExpvarArguments `alloy:"expvar,block,optional"`
+
+	// DebugMetrics configures component internal metrics. Optional.
+	DebugMetrics otelcolCfg.DebugMetricsArguments `alloy:"debug_metrics,block,optional"`
+}
+
+// ExpvarArguments configures the expvarz page.
+type ExpvarArguments struct {
+	Enabled bool `alloy:"enabled,attr,optional"`
+}
+
+var (
+	_ extension.Arguments       = Arguments{}
+	_ extension.ZPagesArguments = Arguments{}
+	_ syntax.Defaulter          = (*Arguments)(nil)
+	_ syntax.Validator          = (*Arguments)(nil)
+)
+
+// SetToDefault implements syntax.Defaulter.
+func (args *Arguments) SetToDefault() {
+	*args = Arguments{
+		HTTPServer: otelcol.HTTPServerArguments{
+			Endpoint:              "127.0.0.1:55679",
+			CompressionAlgorithms: append([]string(nil), otelcol.DefaultCompressionAlgorithms...),
+		},
+	}
+	args.DebugMetrics.SetToDefault()
+}
+
+// Validate implements syntax.Validator.
+func (args *Arguments) Validate() error {
+	cfg, err := args.Convert(component.Options{})
+	if err != nil {
+		return err
+	}
+	return cfg.(*zpagesextension.Config).Validate()
+}
+
+// Convert implements extension.Arguments.
+func (args Arguments) Convert(_ component.Options) (otelcomponent.Config, error) {
+	httpServer, err := args.HTTPServer.Convert()
+	if err != nil {
+		return nil, err
+	}
+	if httpServer == nil {
+		return nil, errors.New("the HTTP server must be configured")
+	}
+
+	return &zpagesextension.Config{
+		ServerConfig: *httpServer,
+		Expvar: zpagesextension.ExpvarConfig{
+			Enabled: args.Expvar.Enabled,
+		},
+	}, nil
+}
+
+// ZPages implements extension.ZPagesArguments. The pages list the components
+// of the module the extension runs in.
+func (args Arguments) ZPages(opts component.Options) (func(mux *http.ServeMux, pathPrefix string), error) {
+	source, err := componenthealth.NewSource(opts)
+	if err != nil {
+		return nil, err
+	}
+	return zpages.New(opts.Logger, source).Register, nil
+}
+
+// Extensions implements extension.Arguments.
+func (args Arguments) Extensions() map[otelcomponent.ID]otelcomponent.Component {
+	return args.HTTPServer.Extensions()
+}
+
+// Exporters implements extension.Arguments.
+func (args Arguments) Exporters() map[pipeline.Signal]map[otelcomponent.ID]otelcomponent.Component {
+	return nil
+}
+
+// DebugMetricsConfig implements extension.Arguments.
+func (args Arguments) DebugMetricsConfig() otelcolCfg.DebugMetricsArguments {
+	return args.DebugMetrics
+}
+
+// ExportsHandler implements extension.Arguments.
+func (args Arguments) ExportsHandler() bool {
+	return false
+}
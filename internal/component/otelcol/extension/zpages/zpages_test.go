@@ -0,0 +1,67 @@
+package zpages_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/extension/zpagesextension"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/otelcol/extension/zpages"
+	"github.com/grafana/alloy/internal/service/otel"
+	"github.com/grafana/alloy/syntax"
+)
+
+func TestArguments(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      string
+		endpoint string
+		expvar   bool
+	}{
+		{
+			name:     "defaults",
+			endpoint: "127.0.0.1:55679",
+		},
+		{
+			name: "custom values",
+			cfg: `
+				endpoint = "0.0.0.0:55680"
+				expvar {
+					enabled = true
+				}
+			`,
+			endpoint: "0.0.0.0:55680",
+			expvar:   true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var args zpages.Arguments
+			require.NoError(t, syntax.Unmarshal([]byte(tc.cfg), &args))
+
+			converted, err := args.Convert(component.Options{})
+			require.NoError(t, err)
+
+			actual := converted.(*zpagesextension.Config)
+			require.Equal(t, tc.endpoint, actual.ServerConfig.Endpoint)
+			require.Equal(t, tc.expvar, actual.Expvar.Enabled)
+		})
+	}
+}
+
+func TestArguments_ZPages(t *testing.T) {
+	var args zpages.Arguments
+	require.NoError(t, syntax.Unmarshal([]byte(""), &args))
+
+	register, err := args.ZPages(component.Options{
+		ID: "otelcol.extension.zpages.default",
+		GetServiceData: func(name string) (any, error) {
+			require.Equal(t, otel.ServiceName, name)
+			return &otel.Service{}, nil
+		},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, register)
+}
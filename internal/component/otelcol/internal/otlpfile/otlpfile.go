@@ -0,0 +1,249 @@
+// Package otlpfile reads the files written by otelcol.exporter.file so that
+// otelcol.receiver.otlp_file can replay them.
+//
+// With the JSON format and no compression, the upstream file exporter writes
+// each export request on its own line as OTLP JSON. With the proto format or
+// with compression, each request is encoded, compressed with zstd if
+// compression is enabled, and written as the length of the encoded request as
+// a 4 byte big-endian integer, followed by the encoded request.
+//
+// Proto requests don't record which signal they hold, so the signal of a proto
+// file must be known in advance.
+package otlpfile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// Supported compression algorithms.
+const (
+	CompressionNone = ""
+	CompressionZstd = "zstd"
+)
+
+// ValidateCompression returns an error if compression isn't supported.
+func ValidateCompression(compression string) error {
+	switch compression {
+	case CompressionNone, CompressionZstd:
+		return nil
+	default:
+		return fmt.Errorf("unsupported compression %q, must be empty or %q", compression, CompressionZstd)
+	}
+}
+
+// Supported formats.
+const (
+	FormatJSON  = "json"
+	FormatProto = "proto"
+)
+
+// ValidateFormat returns an error if format isn't supported.
+func ValidateFormat(format string) error {
+	switch format {
+	case FormatJSON, FormatProto:
+		return nil
+	default:
+		return fmt.Errorf("unsupported format %q, must be %q or %q", format, FormatJSON, FormatProto)
+	}
+}
+
+// Signal identifies the kind of telemetry held by a Record.
+type Signal byte
+
+// Supported signals.
+const (
+	SignalTraces Signal = iota + 1
+	SignalMetrics
+	SignalLogs
+)
+
+// ParseSignal returns the signal named s, which is one of "traces", "metrics",
+// or "logs".
+func ParseSignal(s string) (Signal, error) {
+	switch s {
+	case "traces":
+		return SignalTraces, nil
+	case "metrics":
+		return SignalMetrics, nil
+	case "logs":
+		return SignalLogs, nil
+	default:
+		return 0, fmt.Errorf("unsupported signal %q, must be one of \"traces\", \"metrics\", or \"logs\"", s)
+	}
+}
+
+// Record is a single export request read from a file. Exactly one of Traces,
+// Metrics, and Logs is set, depending on Signal.
+type Record struct {
+	Signal  Signal
+	Traces  ptrace.Traces
+	Metrics pmetric.Metrics
+	Logs    plog.Logs
+}
+
+// maxRecordSize guards against allocating huge buffers when reading a
+// corrupted file.
+const maxRecordSize = 256 << 20
+
+// Decoder reads records from a file.
+type Decoder struct {
+	r      *bufio.Reader
+	zstd   *zstd.Decoder
+	proto  bool
+	signal Signal
+}
+
+// NewDecoder returns a Decoder reading records from r, which was written with
+// format and compression. signal is the signal held by proto files and is
+// ignored for JSON files, which record their signal.
+func NewDecoder(r io.Reader, format, compression string, signal Signal) (*Decoder, error) {
+	d := &Decoder{r: bufio.NewReader(r)}
+	if format == FormatProto {
+		if signal == 0 {
+			return nil, errors.New("the signal of proto files must be set")
+		}
+		d.proto = true
+		d.signal = signal
+	}
+	if compression == CompressionZstd {
+		zr, err := zstd.NewReader(nil)
+		if err != nil {
+			return nil, err
+		}
+		d.zstd = zr
+	}
+	return d, nil
+}
+
+// Close releases the resources held by the decoder. It doesn't close the
+// underlying reader.
+func (d *Decoder) Close() {
+	if d.zstd != nil {
+		d.zstd.Close()
+	}
+}
+
+// Next returns the next record. It returns io.EOF when there are no more
+// records.
+func (d *Decoder) Next() (Record, error) {
+	if !d.proto && d.zstd == nil {
+		return d.nextLine()
+	}
+
+	buf, err := d.nextFramed()
+	if err != nil {
+		return Record{}, err
+	}
+	if d.proto {
+		return decodeProto(buf, d.signal)
+	}
+	return decodeJSON(buf)
+}
+
+func (d *Decoder) nextLine() (Record, error) {
+	for {
+		line, err := d.r.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) == 0 {
+			if err != nil {
+				return Record{}, err
+			}
+			continue
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return Record{}, err
+		}
+		return decodeJSON(line)
+	}
+}
+
+// nextFramed reads the next length-prefixed request and decompresses it if
+// needed.
+func (d *Decoder) nextFramed() ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(d.r, header[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, errors.New("truncated record header")
+		}
+		return nil, err
+	}
+
+	size := binary.BigEndian.Uint32(header[:])
+	if size > maxRecordSize {
+		return nil, fmt.Errorf("record of %d bytes exceeds the maximum of %d bytes", size, maxRecordSize)
+	}
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(d.r, buf); err != nil {
+		return nil, fmt.Errorf("truncated record: %w", err)
+	}
+
+	if d.zstd == nil {
+		return buf, nil
+	}
+	buf, err := d.zstd.DecodeAll(buf, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid zstd record: %w", err)
+	}
+	return buf, nil
+}
+
+func decodeProto(buf []byte, signal Signal) (Record, error) {
+	var (
+		rec = Record{Signal: signal}
+		err error
+	)
+	switch signal {
+	case SignalTraces:
+		rec.Traces, err = (&ptrace.ProtoUnmarshaler{}).UnmarshalTraces(buf)
+	case SignalMetrics:
+		rec.Metrics, err = (&pmetric.ProtoUnmarshaler{}).UnmarshalMetrics(buf)
+	case SignalLogs:
+		rec.Logs, err = (&plog.ProtoUnmarshaler{}).UnmarshalLogs(buf)
+	default:
+		return Record{}, fmt.Errorf("unknown signal %d", signal)
+	}
+	if err != nil {
+		return Record{}, fmt.Errorf("invalid OTLP proto: %w", err)
+	}
+	return rec, nil
+}
+
+func decodeJSON(line []byte) (Record, error) {
+	var keys struct {
+		ResourceSpans   json.RawMessage `json:"resourceSpans"`
+		ResourceMetrics json.RawMessage `json:"resourceMetrics"`
+		ResourceLogs    json.RawMessage `json:"resourceLogs"`
+	}
+	if err := json.Unmarshal(line, &keys); err != nil {
+		return Record{}, fmt.Errorf("invalid OTLP JSON: %w", err)
+	}
+
+	var (
+		rec Record
+		err error
+	)
+	switch {
+	case keys.ResourceSpans != nil:
+		rec.Signal = SignalTraces
+		rec.Traces, err = (&ptrace.JSONUnmarshaler{}).UnmarshalTraces(line)
+	case keys.ResourceMetrics != nil:
+		rec.Signal = SignalMetrics
+		rec.Metrics, err = (&pmetric.JSONUnmarshaler{}).UnmarshalMetrics(line)
+	case keys.ResourceLogs != nil:
+		rec.Signal = SignalLogs
+		rec.Logs, err = (&plog.JSONUnmarshaler{}).UnmarshalLogs(line)
+	default:
+		return Record{}, errors.New("invalid OTLP JSON: no resourceSpans, resourceMetrics, or resourceLogs")
+	}
+	return rec, err
+}
@@ -0,0 +1,210 @@
+package otlpfile
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/fileexporter"
+	"github.com/stretchr/testify/require"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/exporter/exportertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+var testTime = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// TestDecoder reads JSON files written by the upstream file exporter, with
+// and without compression.
+func TestDecoder(t *testing.T) {
+	for _, compression := range []string{CompressionNone, CompressionZstd} {
+		t.Run("compression="+compression, func(t *testing.T) {
+			path := writeFile(t, FormatJSON, compression, SignalTraces, SignalMetrics, SignalLogs)
+			signals := readFile(t, path, FormatJSON, compression, 0)
+			require.Equal(t, []Signal{SignalTraces, SignalMetrics, SignalLogs}, signals)
+		})
+	}
+}
+
+// TestDecoder_Proto reads proto files written by the upstream file exporter,
+// which hold a single signal each.
+func TestDecoder_Proto(t *testing.T) {
+	for _, compression := range []string{CompressionNone, CompressionZstd} {
+		for _, signal := range []Signal{SignalTraces, SignalMetrics, SignalLogs} {
+			t.Run(fmt.Sprintf("compression=%s/signal=%d", compression, signal), func(t *testing.T) {
+				path := writeFile(t, FormatProto, compression, signal, signal)
+				signals := readFile(t, path, FormatProto, compression, signal)
+				require.Equal(t, []Signal{signal, signal}, signals)
+			})
+		}
+	}
+}
+
+func TestDecoder_ProtoWithoutSignal(t *testing.T) {
+	_, err := NewDecoder(bytes.NewReader(nil), FormatProto, CompressionNone, 0)
+	require.ErrorContains(t, err, "signal of proto files must be set")
+}
+
+func TestDecoder_Truncated(t *testing.T) {
+	data, err := os.ReadFile(writeFile(t, FormatJSON, CompressionZstd, SignalTraces))
+	require.NoError(t, err)
+
+	dec, err := NewDecoder(bytes.NewReader(data[:10]), FormatJSON, CompressionZstd, 0)
+	require.NoError(t, err)
+	defer dec.Close()
+	_, err = dec.Next()
+	require.ErrorContains(t, err, "truncated record")
+}
+
+func TestParseSignal(t *testing.T) {
+	signal, err := ParseSignal("metrics")
+	require.NoError(t, err)
+	require.Equal(t, SignalMetrics, signal)
+
+	_, err = ParseSignal("profiles")
+	require.ErrorContains(t, err, `unsupported signal "profiles"`)
+}
+
+// readFile decodes the file at path, checks that each record holds the test
+// data of its signal, and returns the signals of the records.
+func readFile(t *testing.T, path, format, compression string, signal Signal) []Signal {
+	t.Helper()
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	dec, err := NewDecoder(f, format, compression, signal)
+	require.NoError(t, err)
+	defer dec.Close()
+
+	var signals []Signal
+	for {
+		rec, err := dec.Next()
+		if errors.Is(err, io.EOF) {
+			return signals
+		}
+		require.NoError(t, err)
+		signals = append(signals, rec.Signal)
+
+		// Compare the JSON encodings, which don't depend on how the records
+		// were built.
+		switch rec.Signal {
+		case SignalTraces:
+			requireSameEncoding(t, (&ptrace.JSONMarshaler{}).MarshalTraces, testTraces(), rec.Traces)
+		case SignalMetrics:
+			requireSameEncoding(t, (&pmetric.JSONMarshaler{}).MarshalMetrics, testMetrics(), rec.Metrics)
+		case SignalLogs:
+			requireSameEncoding(t, (&plog.JSONMarshaler{}).MarshalLogs, testLogs(), rec.Logs)
+		}
+	}
+}
+
+// writeFile writes the test data of signals, in order, to a file with the
+// upstream file exporter and returns its path.
+func writeFile(t *testing.T, format, compression string, signals ...Signal) string {
+	t.Helper()
+
+	ctx := context.Background()
+	fact := fileexporter.NewFactory()
+	cfg := fact.CreateDefaultConfig().(*fileexporter.Config)
+	cfg.Path = filepath.Join(t.TempDir(), "otlp.jsonl")
+	cfg.FormatType = format
+	cfg.Compression = compression
+	cfg.Rotation = nil
+	set := exportertest.NewNopSettings(fact.Type())
+
+	traces, err := fact.CreateTraces(ctx, set, cfg)
+	require.NoError(t, err)
+	metrics, err := fact.CreateMetrics(ctx, set, cfg)
+	require.NoError(t, err)
+	logs, err := fact.CreateLogs(ctx, set, cfg)
+	require.NoError(t, err)
+
+	for _, c := range []otelcomponent.Component{traces, metrics, logs} {
+		require.NoError(t, c.Start(ctx, componenttest.NewNopHost()))
+	}
+	for _, signal := range signals {
+		switch signal {
+		case SignalTraces:
+			require.NoError(t, traces.ConsumeTraces(ctx, testTraces()))
+		case SignalMetrics:
+			require.NoError(t, metrics.ConsumeMetrics(ctx, testMetrics()))
+		case SignalLogs:
+			require.NoError(t, logs.ConsumeLogs(ctx, testLogs()))
+		}
+	}
+	for _, c := range []otelcomponent.Component{traces, metrics, logs} {
+		require.NoError(t, c.Shutdown(ctx))
+	}
+	return cfg.Path
+}
+
+func TestShiftTimestamps(t *testing.T) {
+	rec := Record{Signal: SignalMetrics, Metrics: testMetrics()}
+	require.Equal(t, pcommon.NewTimestampFromTime(testTime.Add(-time.Minute)), EarliestTimestamp(rec))
+
+	ShiftTimestamps(rec, time.Hour)
+
+	dp := rec.Metrics.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Sum().DataPoints().At(0)
+	require.Equal(t, testTime.Add(time.Hour), dp.Timestamp().AsTime())
+	require.Equal(t, testTime.Add(59*time.Minute), dp.StartTimestamp().AsTime())
+	require.Equal(t, testTime.Add(time.Hour), dp.Exemplars().At(0).Timestamp().AsTime())
+
+	// Unset timestamps stay unset.
+	rec = Record{Signal: SignalLogs, Logs: testLogs()}
+	ShiftTimestamps(rec, time.Hour)
+	lr := rec.Logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	require.Equal(t, testTime.Add(time.Hour), lr.Timestamp().AsTime())
+	require.Equal(t, pcommon.Timestamp(0), lr.ObservedTimestamp())
+}
+
+func requireSameEncoding[T any](t *testing.T, encode func(T) ([]byte, error), expected, actual T) {
+	t.Helper()
+	expectedBytes, err := encode(expected)
+	require.NoError(t, err)
+	actualBytes, err := encode(actual)
+	require.NoError(t, err)
+	require.JSONEq(t, string(expectedBytes), string(actualBytes))
+}
+
+func testTraces() ptrace.Traces {
+	td := ptrace.NewTraces()
+	rs := td.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().PutStr("service.name", "api")
+	span := rs.ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	span.SetName("GET /")
+	span.SetStartTimestamp(pcommon.NewTimestampFromTime(testTime))
+	span.SetEndTimestamp(pcommon.NewTimestampFromTime(testTime.Add(time.Second)))
+	return td
+}
+
+func testMetrics() pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	m := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	m.SetName("requests")
+	dp := m.SetEmptySum().DataPoints().AppendEmpty()
+	dp.SetIntValue(42)
+	dp.SetStartTimestamp(pcommon.NewTimestampFromTime(testTime.Add(-time.Minute)))
+	dp.SetTimestamp(pcommon.NewTimestampFromTime(testTime))
+	dp.Exemplars().AppendEmpty().SetTimestamp(pcommon.NewTimestampFromTime(testTime))
+	return md
+}
+
+func testLogs() plog.Logs {
+	ld := plog.NewLogs()
+	lr := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	lr.Body().SetStr("hello")
+	lr.SetTimestamp(pcommon.NewTimestampFromTime(testTime))
+	return ld
+}
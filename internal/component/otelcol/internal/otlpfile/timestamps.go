@@ -0,0 +1,117 @@
+package otlpfile
+
+import (
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// EarliestTimestamp returns the earliest non-zero timestamp of the record, or
+// zero if the record has no timestamp.
+func EarliestTimestamp(rec Record) pcommon.Timestamp {
+	var earliest pcommon.Timestamp
+	visitTimestamps(rec, func(ts pcommon.Timestamp) pcommon.Timestamp {
+		if ts != 0 && (earliest == 0 || ts < earliest) {
+			earliest = ts
+		}
+		return ts
+	})
+	return earliest
+}
+
+// ShiftTimestamps moves every non-zero timestamp of the record by d.
+func ShiftTimestamps(rec Record, d time.Duration) {
+	visitTimestamps(rec, func(ts pcommon.Timestamp) pcommon.Timestamp {
+		if ts == 0 {
+			return ts
+		}
+		return pcommon.NewTimestampFromTime(ts.AsTime().Add(d))
+	})
+}
+
+// visitTimestamps calls fn with every timestamp of the record and replaces
+// the timestamp with the value returned by fn.
+func visitTimestamps(rec Record, fn func(pcommon.Timestamp) pcommon.Timestamp) {
+	switch rec.Signal {
+	case SignalTraces:
+		for _, rs := range rec.Traces.ResourceSpans().All() {
+			for _, ss := range rs.ScopeSpans().All() {
+				for _, span := range ss.Spans().All() {
+					span.SetStartTimestamp(fn(span.StartTimestamp()))
+					span.SetEndTimestamp(fn(span.EndTimestamp()))
+					for _, event := range span.Events().All() {
+						event.SetTimestamp(fn(event.Timestamp()))
+					}
+				}
+			}
+		}
+
+	case SignalMetrics:
+		for _, rm := range rec.Metrics.ResourceMetrics().All() {
+			for _, sm := range rm.ScopeMetrics().All() {
+				for _, m := range sm.Metrics().All() {
+					visitMetricTimestamps(m, fn)
+				}
+			}
+		}
+
+	case SignalLogs:
+		for _, rl := range rec.Logs.ResourceLogs().All() {
+			for _, sl := range rl.ScopeLogs().All() {
+				for _, lr := range sl.LogRecords().All() {
+					lr.SetTimestamp(fn(lr.Timestamp()))
+					lr.SetObservedTimestamp(fn(lr.ObservedTimestamp()))
+				}
+			}
+		}
+	}
+}
+
+// dataPoint is implemented by every kind of metric data point.
+type dataPoint interface {
+	StartTimestamp() pcommon.Timestamp
+	SetStartTimestamp(pcommon.Timestamp)
+	Timestamp() pcommon.Timestamp
+	SetTimestamp(pcommon.Timestamp)
+}
+
+func visitDataPoint(dp dataPoint, fn func(pcommon.Timestamp) pcommon.Timestamp) {
+	dp.SetStartTimestamp(fn(dp.StartTimestamp()))
+	dp.SetTimestamp(fn(dp.Timestamp()))
+}
+
+func visitExemplars(exemplars pmetric.ExemplarSlice, fn func(pcommon.Timestamp) pcommon.Timestamp) {
+	for _, ex := range exemplars.All() {
+		ex.SetTimestamp(fn(ex.Timestamp()))
+	}
+}
+
+func visitMetricTimestamps(m pmetric.Metric, fn func(pcommon.Timestamp) pcommon.Timestamp) {
+	switch m.Type() {
+	case pmetric.MetricTypeGauge:
+		for _, dp := range m.Gauge().DataPoints().All() {
+			visitDataPoint(dp, fn)
+			visitExemplars(dp.Exemplars(), fn)
+		}
+	case pmetric.MetricTypeSum:
+		for _, dp := range m.Sum().DataPoints().All() {
+			visitDataPoint(dp, fn)
+			visitExemplars(dp.Exemplars(), fn)
+		}
+	case pmetric.MetricTypeHistogram:
+		for _, dp := range m.Histogram().DataPoints().All() {
+			visitDataPoint(dp, fn)
+			visitExemplars(dp.Exemplars(), fn)
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		for _, dp := range m.ExponentialHistogram().DataPoints().All() {
+			visitDataPoint(dp, fn)
+			visitExemplars(dp.Exemplars(), fn)
+		}
+	case pmetric.MetricTypeSummary:
+		for _, dp := range m.Summary().DataPoints().All() {
+			visitDataPoint(dp, fn)
+		}
+	}
+}
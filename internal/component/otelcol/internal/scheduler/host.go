@@ -1,9 +1,13 @@
 package scheduler
 
 import (
+	"net/http"
+	"sync"
+
 	"github.com/go-kit/log"
 
 	otelcomponent "go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componentstatus"
 	"go.opentelemetry.io/collector/pipeline"
 )
 
@@ -13,6 +17,10 @@ type Host struct {
 
 	extensions map[otelcomponent.ID]otelcomponent.Component
 	exporters  map[pipeline.Signal]map[otelcomponent.ID]otelcomponent.Component
+	zPages     func(mux *http.ServeMux, pathPrefix string)
+
+	reportMut sync.RWMutex
+	report    func(*componentstatus.Event) // Set by the Scheduler running the host.
 }
 
 // NewHost creates a new Host.
@@ -41,9 +49,45 @@ func WithHostExporters(exporters map[pipeline.Signal]map[otelcomponent.ID]otelco
 	}
 }
 
-var _ otelcomponent.Host = (*Host)(nil)
+// WithHostZPages provides the function registering the zPages of the Host,
+// which are served by the zpages extension.
+func WithHostZPages(register func(mux *http.ServeMux, pathPrefix string)) HostOption {
+	return func(h *Host) {
+		h.zPages = register
+	}
+}
+
+var (
+	_ otelcomponent.Host       = (*Host)(nil)
+	_ componentstatus.Reporter = (*Host)(nil)
+)
 
 // GetExtensions implements otelcomponent.Host.
 func (h *Host) GetExtensions() map[otelcomponent.ID]otelcomponent.Component {
 	return h.extensions
 }
+
+// RegisterZPages registers the zPages of the Host on mux. It's called by the
+// upstream zpages extension.
+func (h *Host) RegisterZPages(mux *http.ServeMux, pathPrefix string) {
+	if h.zPages != nil {
+		h.zPages(mux, pathPrefix)
+	}
+}
+
+// Report implements componentstatus.Reporter. Events are forwarded to the
+// Scheduler running the components, which updates the health of the Alloy
+// component accordingly.
+func (h *Host) Report(ev *componentstatus.Event) {
+	h.reportMut.RLock()
+	defer h.reportMut.RUnlock()
+	if h.report != nil {
+		h.report(ev)
+	}
+}
+
+func (h *Host) setReporter(report func(*componentstatus.Event)) {
+	h.reportMut.Lock()
+	defer h.reportMut.Unlock()
+	h.report = report
+}
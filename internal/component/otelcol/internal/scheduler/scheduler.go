@@ -10,6 +10,7 @@ import (
 
 	"github.com/go-kit/log"
 	otelcomponent "go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componentstatus"
 	"go.uber.org/multierr"
 
 	"github.com/grafana/alloy/internal/component"
@@ -160,6 +161,10 @@ func (cs *Scheduler) stopComponents(ctx context.Context, cc ...otelcomponent.Com
 func (cs *Scheduler) startComponents(ctx context.Context, h otelcomponent.Host, cc ...otelcomponent.Component) (started []otelcomponent.Component) {
 	var errs error
 
+	if host, ok := h.(*Host); ok {
+		host.setReporter(cs.reportStatus)
+	}
+
 	for _, c := range cc {
 		if err := c.Start(ctx, h); err != nil {
 			level.Error(cs.log).Log("msg", "failed to start scheduled component", "err", err)
@@ -200,3 +205,23 @@ func (cs *Scheduler) setHealth(h component.Health) {
 	defer cs.healthMut.Unlock()
 	cs.health = h
 }
+
+// reportStatus updates the health from a status event reported by one of the
+// running components. Events which don't describe the health of a running
+// component, such as StatusStopping, are ignored.
+func (cs *Scheduler) reportStatus(ev *componentstatus.Event) {
+	switch ev.Status() {
+	case componentstatus.StatusOK:
+		cs.setHealth(component.Health{
+			Health:     component.HealthTypeHealthy,
+			Message:    "scheduled components reported they are healthy",
+			UpdateTime: ev.Timestamp(),
+		})
+	case componentstatus.StatusRecoverableError, componentstatus.StatusPermanentError, componentstatus.StatusFatalError:
+		cs.setHealth(component.Health{
+			Health:     component.HealthTypeUnhealthy,
+			Message:    fmt.Sprintf("scheduled component reported an error: %s", ev.Err()),
+			UpdateTime: ev.Timestamp(),
+		})
+	}
+}
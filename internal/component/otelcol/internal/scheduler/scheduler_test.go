@@ -2,6 +2,7 @@ package scheduler_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -10,7 +11,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	otelcomponent "go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componentstatus"
 
+	alloycomponent "github.com/grafana/alloy/internal/component"
 	"github.com/grafana/alloy/internal/component/otelcol/internal/scheduler"
 	"github.com/grafana/alloy/internal/runtime/componenttest"
 	"github.com/grafana/alloy/internal/util"
@@ -148,6 +151,35 @@ func TestScheduler(t *testing.T) {
 		cancel()
 		require.NoError(t, stopped.Wait(5*time.Second), "component did not shutdown")
 	})
+
+	t.Run("Reported status updates health", func(t *testing.T) {
+		var (
+			l  = util.TestLogger(t)
+			cs = scheduler.New(l)
+			h  = scheduler.NewHost(l)
+		)
+
+		// Run our scheduler in the background.
+		go func() {
+			err := cs.Run(componenttest.TestContext(t))
+			require.NoError(t, err)
+		}()
+
+		component, started, _ := newTriggerComponent()
+		cs.Schedule(t.Context(), func() {}, h, component)
+		require.NoError(t, started.Wait(5*time.Second), "component did not start")
+		require.Eventually(t, func() bool {
+			return cs.CurrentHealth().Health == alloycomponent.HealthTypeHealthy
+		}, 5*time.Second, 10*time.Millisecond)
+
+		componentstatus.ReportStatus(h, componentstatus.NewRecoverableErrorEvent(errors.New("connection refused")))
+		health := cs.CurrentHealth()
+		require.Equal(t, alloycomponent.HealthTypeUnhealthy, health.Health)
+		require.Contains(t, health.Message, "connection refused")
+
+		componentstatus.ReportStatus(h, componentstatus.NewEvent(componentstatus.StatusOK))
+		require.Equal(t, alloycomponent.HealthTypeHealthy, cs.CurrentHealth().Health)
+	})
 }
 
 func newTriggerComponent() (component otelcomponent.Component, started, stopped *util.WaitTrigger) {
@@ -0,0 +1,180 @@
+package logdedup
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatautil"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// Names of the attributes holding the time the first and last duplicates of a
+// deduplicated log record were received.
+const (
+	firstObservedAttribute = "first_observed_timestamp"
+	lastObservedAttribute  = "last_observed_timestamp"
+)
+
+// aggregator collects identical log records until they're flushed. Records
+// are identical if they have the same resource, scope, body, severity, and
+// compared attributes.
+type aggregator struct {
+	countAttribute string
+	location       *time.Location
+	attributes     attributeFilter
+
+	resources map[[16]byte]*resourceAggregator
+}
+
+type resourceAggregator struct {
+	resource pcommon.Resource
+	scopes   map[[16]byte]*scopeAggregator
+}
+
+type scopeAggregator struct {
+	scope   pcommon.InstrumentationScope
+	records map[[16]byte]*recordAggregator
+	// order holds the keys of records in the order they were first received.
+	order [][16]byte
+}
+
+type recordAggregator struct {
+	record    plog.LogRecord
+	count     int64
+	firstSeen time.Time
+	lastSeen  time.Time
+}
+
+func newAggregator(countAttribute string, location *time.Location, attributes attributeFilter) *aggregator {
+	return &aggregator{
+		countAttribute: countAttribute,
+		location:       location,
+		attributes:     attributes,
+		resources:      make(map[[16]byte]*resourceAggregator),
+	}
+}
+
+// add records a log record received at now.
+func (a *aggregator) add(resource pcommon.Resource, scope pcommon.InstrumentationScope, lr plog.LogRecord, now time.Time) {
+	resourceKey := pdatautil.MapHash(resource.Attributes())
+	ra, ok := a.resources[resourceKey]
+	if !ok {
+		ra = &resourceAggregator{
+			resource: pcommon.NewResource(),
+			scopes:   make(map[[16]byte]*scopeAggregator),
+		}
+		resource.CopyTo(ra.resource)
+		a.resources[resourceKey] = ra
+	}
+
+	scopeKey := pdatautil.Hash(
+		pdatautil.WithString(scope.Name()),
+		pdatautil.WithString(scope.Version()),
+		pdatautil.WithMap(scope.Attributes()),
+	)
+	sa, ok := ra.scopes[scopeKey]
+	if !ok {
+		sa = &scopeAggregator{
+			scope:   pcommon.NewInstrumentationScope(),
+			records: make(map[[16]byte]*recordAggregator),
+		}
+		scope.CopyTo(sa.scope)
+		ra.scopes[scopeKey] = sa
+	}
+
+	attrs := a.attributes.apply(lr.Attributes())
+	recordKey := pdatautil.Hash(
+		pdatautil.WithValue(lr.Body()),
+		pdatautil.WithString(lr.SeverityText()),
+		pdatautil.WithString(strconv.Itoa(int(lr.SeverityNumber()))),
+		pdatautil.WithMap(attrs),
+	)
+	rec, ok := sa.records[recordKey]
+	if !ok {
+		rec = &recordAggregator{record: plog.NewLogRecord(), firstSeen: now}
+		lr.CopyTo(rec.record)
+		attrs.CopyTo(rec.record.Attributes())
+		sa.records[recordKey] = rec
+		sa.order = append(sa.order, recordKey)
+	}
+	rec.count++
+	rec.lastSeen = now
+}
+
+// flush returns the deduplicated log records and resets the aggregator.
+func (a *aggregator) flush(now time.Time) plog.Logs {
+	ld := plog.NewLogs()
+	for _, ra := range a.resources {
+		rl := ld.ResourceLogs().AppendEmpty()
+		ra.resource.MoveTo(rl.Resource())
+
+		for _, sa := range ra.scopes {
+			sl := rl.ScopeLogs().AppendEmpty()
+			sa.scope.MoveTo(sl.Scope())
+
+			for _, key := range sa.order {
+				rec := sa.records[key]
+				lr := sl.LogRecords().AppendEmpty()
+				rec.record.MoveTo(lr)
+
+				lr.SetObservedTimestamp(pcommon.NewTimestampFromTime(now))
+				lr.Attributes().PutInt(a.countAttribute, rec.count)
+				lr.Attributes().PutStr(firstObservedAttribute, rec.firstSeen.In(a.location).Format(time.RFC3339Nano))
+				lr.Attributes().PutStr(lastObservedAttribute, rec.lastSeen.In(a.location).Format(time.RFC3339Nano))
+			}
+		}
+	}
+
+	a.resources = make(map[[16]byte]*resourceAggregator)
+	return ld
+}
+
+// empty returns true if no log record was added since the last flush.
+func (a *aggregator) empty() bool {
+	return len(a.resources) == 0
+}
+
+// attributeFilter selects the log attributes records are compared by. If
+// include is set, only those attributes are compared. Otherwise, all
+// attributes but exclude are compared.
+type attributeFilter struct {
+	include map[string]struct{}
+	exclude map[string]struct{}
+}
+
+func newAttributeFilter(include, exclude []string) attributeFilter {
+	var f attributeFilter
+	if len(include) > 0 {
+		f.include = toSet(include)
+	}
+	if len(exclude) > 0 {
+		f.exclude = toSet(exclude)
+	}
+	return f
+}
+
+// apply returns the compared attributes of attrs.
+func (f attributeFilter) apply(attrs pcommon.Map) pcommon.Map {
+	res := pcommon.NewMap()
+	for k, v := range attrs.All() {
+		if f.include != nil {
+			if _, ok := f.include[k]; !ok {
+				continue
+			}
+		}
+		if _, ok := f.exclude[k]; ok {
+			continue
+		}
+		v.CopyTo(res.PutEmpty(k))
+	}
+	return res
+}
+
+func toSet(keys []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(keys))
+	for _, k := range keys {
+		set[k] = struct{}{}
+	}
+	return set
+}
@@ -0,0 +1,326 @@
+// Package logdedup provides an otelcol.processor.logdedup component.
+//
+// Unlike most otelcol components, it doesn't wrap the upstream processor: the
+// first release of the logdedupprocessor module requires a newer OpenTelemetry
+// Collector than the one Alloy is built with. It should be replaced by a
+// wrapper once Alloy catches up.
+package logdedup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/contexts/ottllog"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/ottl/ottlfuncs"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	otelconsumer "go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/otelcol"
+	"github.com/grafana/alloy/internal/component/otelcol/internal/fanoutconsumer"
+	"github.com/grafana/alloy/internal/component/otelcol/internal/lazyconsumer"
+	"github.com/grafana/alloy/internal/component/otelcol/internal/livedebuggingpublisher"
+	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/internal/service/livedebugging"
+	"github.com/grafana/alloy/internal/util/zapadapter"
+	"github.com/grafana/alloy/syntax"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:      "otelcol.processor.logdedup",
+		Stability: featuregate.StabilityExperimental,
+		Args:      Arguments{},
+		Exports:   otelcol.ConsumerExports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return New(opts, args.(Arguments))
+		},
+	})
+}
+
+// Arguments configures the otelcol.processor.logdedup component.
+type Arguments struct {
+	// Interval is how often deduplicated log records are emitted.
+	Interval time.Duration `alloy:"interval,attr,optional"`
+	// LogCountAttribute is the attribute holding the number of duplicates.
+	LogCountAttribute string `alloy:"log_count_attribute,attr,optional"`
+	// Timezone is used to format the first and last observed timestamps.
+	Timezone string `alloy:"timezone,attr,optional"`
+
+	// IncludeAttributes restricts the attributes records are compared by.
+	IncludeAttributes []string `alloy:"include_attributes,attr,optional"`
+	// ExcludeAttributes are attributes ignored when comparing records.
+	ExcludeAttributes []string `alloy:"exclude_attributes,attr,optional"`
+
+	// ExcludeConditions are OTTL conditions. Log records matching any of them
+	// are forwarded unchanged instead of being deduplicated.
+	ExcludeConditions []string `alloy:"exclude_conditions,attr,optional"`
+	// ErrorMode determines how the component reacts to errors that occur while
+	// evaluating a condition.
+	ErrorMode ottl.ErrorMode `alloy:"error_mode,attr,optional"`
+
+	// Output configures where to send processed data. Required.
+	Output *otelcol.ConsumerArguments `alloy:"output,block"`
+}
+
+var (
+	_ syntax.Defaulter = (*Arguments)(nil)
+	_ syntax.Validator = (*Arguments)(nil)
+)
+
+// DefaultArguments holds default settings for Arguments.
+var DefaultArguments = Arguments{
+	Interval:          10 * time.Second,
+	LogCountAttribute: "log_count",
+	Timezone:          "UTC",
+	ErrorMode:         ottl.PropagateError,
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (args *Arguments) SetToDefault() {
+	*args = DefaultArguments
+}
+
+// Validate implements syntax.Validator.
+func (args *Arguments) Validate() error {
+	if args.Interval <= 0 {
+		return errors.New("interval must be greater than 0")
+	}
+	if args.LogCountAttribute == "" {
+		return errors.New("log_count_attribute must not be empty")
+	}
+	switch args.LogCountAttribute {
+	case firstObservedAttribute, lastObservedAttribute:
+		return fmt.Errorf("log_count_attribute must not be %q", args.LogCountAttribute)
+	}
+	if _, err := time.LoadLocation(args.Timezone); err != nil {
+		return fmt.Errorf("invalid timezone %q: %w", args.Timezone, err)
+	}
+	if len(args.IncludeAttributes) > 0 && len(args.ExcludeAttributes) > 0 {
+		return errors.New("include_attributes and exclude_attributes can't both be set")
+	}
+	return nil
+}
+
+// Component is the otelcol.processor.logdedup component.
+type Component struct {
+	opts               component.Options
+	debugDataPublisher livedebugging.DebugDataPublisher
+
+	mut        sync.Mutex
+	args       Arguments
+	agg        *aggregator
+	conditions *ottl.ConditionSequence[ottllog.TransformContext]
+	nextLogs   otelconsumer.Logs
+
+	// intervalChanged is signaled when Update changes the interval.
+	intervalChanged chan struct{}
+}
+
+var (
+	_ component.Component     = (*Component)(nil)
+	_ component.LiveDebugging = (*Component)(nil)
+)
+
+// New creates a new otelcol.processor.logdedup component.
+func New(opts component.Options, args Arguments) (*Component, error) {
+	if args.Output.Traces != nil || args.Output.Metrics != nil {
+		level.Warn(opts.Logger).Log("msg", "non-logs output detected; this component only works for logs outputs")
+	}
+
+	debugDataPublisher, err := opts.GetServiceData(livedebugging.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Component{
+		opts:               opts,
+		debugDataPublisher: debugDataPublisher.(livedebugging.DebugDataPublisher),
+		intervalChanged:    make(chan struct{}, 1),
+	}
+	if err := c.Update(args); err != nil {
+		return nil, err
+	}
+
+	// Export the consumer. This will remain the same throughout the
+	// component's lifetime, so we do this during component construction.
+	export := lazyconsumer.New(context.Background(), opts.ID)
+	export.SetConsumers(nil, nil, &logsConsumer{c: c})
+	opts.OnStateChange(otelcol.ConsumerExports{Input: export})
+
+	return c, nil
+}
+
+// Run implements component.Component.
+func (c *Component) Run(ctx context.Context) error {
+	c.mut.Lock()
+	interval := c.args.Interval
+	c.mut.Unlock()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Emit the remaining records so that they aren't lost when the
+			// component stops.
+			c.flush(context.Background())
+			return nil
+		case <-c.intervalChanged:
+			c.mut.Lock()
+			ticker.Reset(c.args.Interval)
+			c.mut.Unlock()
+		case <-ticker.C:
+			c.flush(ctx)
+		}
+	}
+}
+
+// Update implements component.Component.
+func (c *Component) Update(args component.Arguments) error {
+	newArgs := args.(Arguments)
+
+	location, err := time.LoadLocation(newArgs.Timezone)
+	if err != nil {
+		return err
+	}
+
+	var conditions *ottl.ConditionSequence[ottllog.TransformContext]
+	if len(newArgs.ExcludeConditions) > 0 {
+		settings := otelcomponent.TelemetrySettings{Logger: zapadapter.New(c.opts.Logger)}
+		parser, err := ottllog.NewParser(ottlfuncs.StandardConverters[ottllog.TransformContext](), settings)
+		if err != nil {
+			return err
+		}
+		parsed, err := parser.ParseConditions(newArgs.ExcludeConditions)
+		if err != nil {
+			return err
+		}
+		seq := ottl.NewConditionSequence(parsed, settings, ottl.WithConditionSequenceErrorMode[ottllog.TransformContext](newArgs.ErrorMode))
+		conditions = &seq
+	}
+
+	attributes := newAttributeFilter(newArgs.IncludeAttributes, newArgs.ExcludeAttributes)
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	// Records aggregated with the previous settings are kept, so that they're
+	// emitted on the next flush.
+	if c.agg == nil {
+		c.agg = newAggregator(newArgs.LogCountAttribute, location, attributes)
+	} else {
+		c.agg.countAttribute = newArgs.LogCountAttribute
+		c.agg.location = location
+		c.agg.attributes = attributes
+	}
+	if c.args.Interval != 0 && c.args.Interval != newArgs.Interval {
+		select {
+		case c.intervalChanged <- struct{}{}:
+		default:
+		}
+	}
+
+	c.args = newArgs
+	c.conditions = conditions
+	c.nextLogs = fanoutconsumer.Logs(newArgs.Output.Logs)
+	return nil
+}
+
+// LiveDebugging implements component.LiveDebugging.
+func (c *Component) LiveDebugging() {}
+
+// consumeLogs aggregates the log records of ld and forwards the excluded ones
+// immediately.
+func (c *Component) consumeLogs(ctx context.Context, ld plog.Logs) error {
+	c.mut.Lock()
+	excluded, err := c.aggregate(ctx, ld, time.Now())
+	next, nextLogs := c.args.Output.Logs, c.nextLogs
+	c.mut.Unlock()
+
+	if err != nil {
+		return err
+	}
+	if excluded.LogRecordCount() == 0 {
+		return nil
+	}
+	livedebuggingpublisher.PublishLogsIfActive(c.debugDataPublisher, c.opts.ID, excluded, otelcol.GetComponentMetadata(next))
+	return nextLogs.ConsumeLogs(ctx, excluded)
+}
+
+// aggregate adds the log records of ld to the aggregator, and returns the
+// records matching the exclusion conditions. c.mut must be held.
+func (c *Component) aggregate(ctx context.Context, ld plog.Logs, now time.Time) (plog.Logs, error) {
+	excluded := plog.NewLogs()
+	for _, rl := range ld.ResourceLogs().All() {
+		for _, sl := range rl.ScopeLogs().All() {
+			var (
+				excludedRecords plog.LogRecordSlice
+				hasExcluded     bool
+			)
+			for _, lr := range sl.LogRecords().All() {
+				if c.conditions != nil {
+					tCtx := ottllog.NewTransformContext(lr, sl.Scope(), rl.Resource(), sl, rl)
+					match, err := c.conditions.Eval(ctx, tCtx)
+					if err != nil {
+						return plog.Logs{}, err
+					}
+					if match {
+						if !hasExcluded {
+							excludedRL := excluded.ResourceLogs().AppendEmpty()
+							rl.Resource().CopyTo(excludedRL.Resource())
+							excludedSL := excludedRL.ScopeLogs().AppendEmpty()
+							sl.Scope().CopyTo(excludedSL.Scope())
+							excludedRecords, hasExcluded = excludedSL.LogRecords(), true
+						}
+						lr.CopyTo(excludedRecords.AppendEmpty())
+						continue
+					}
+				}
+				c.agg.add(rl.Resource(), sl.Scope(), lr, now)
+			}
+		}
+	}
+	return excluded, nil
+}
+
+// flush emits the deduplicated log records.
+func (c *Component) flush(ctx context.Context) {
+	c.mut.Lock()
+	if c.agg.empty() {
+		c.mut.Unlock()
+		return
+	}
+	ld := c.agg.flush(time.Now())
+	next, nextLogs := c.args.Output.Logs, c.nextLogs
+	c.mut.Unlock()
+
+	livedebuggingpublisher.PublishLogsIfActive(c.debugDataPublisher, c.opts.ID, ld, otelcol.GetComponentMetadata(next))
+	if err := nextLogs.ConsumeLogs(ctx, ld); err != nil {
+		level.Error(c.opts.Logger).Log("msg", "failed to send deduplicated logs", "err", err)
+	}
+}
+
+type logsConsumer struct {
+	c *Component
+}
+
+var _ otelconsumer.Logs = (*logsConsumer)(nil)
+
+// Capabilities implements otelconsumer.Logs.
+func (lc *logsConsumer) Capabilities() otelconsumer.Capabilities {
+	return otelconsumer.Capabilities{MutatesData: false}
+}
+
+// ConsumeLogs implements otelconsumer.Logs.
+func (lc *logsConsumer) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	return lc.c.consumeLogs(ctx, ld)
+}
@@ -0,0 +1,144 @@
+package logdedup_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/alloy/internal/component/otelcol"
+	"github.com/grafana/alloy/internal/component/otelcol/internal/fakeconsumer"
+	"github.com/grafana/alloy/internal/component/otelcol/processor/logdedup"
+	"github.com/grafana/alloy/internal/runtime/componenttest"
+	"github.com/grafana/alloy/internal/util"
+	"github.com/grafana/alloy/syntax"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func TestArguments_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  string
+		err  string
+	}{
+		{
+			name: "invalid timezone",
+			cfg: `
+				timezone = "Mars/Olympus_Mons"
+				output {}
+			`,
+			err: `invalid timezone "Mars/Olympus_Mons"`,
+		},
+		{
+			name: "include and exclude",
+			cfg: `
+				include_attributes = ["a"]
+				exclude_attributes = ["b"]
+				output {}
+			`,
+			err: "include_attributes and exclude_attributes can't both be set",
+		},
+		{
+			name: "reserved count attribute",
+			cfg: `
+				log_count_attribute = "first_observed_timestamp"
+				output {}
+			`,
+			err: `log_count_attribute must not be "first_observed_timestamp"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var args logdedup.Arguments
+			require.ErrorContains(t, syntax.Unmarshal([]byte(tc.cfg), &args), tc.err)
+		})
+	}
+}
+
+func Test(t *testing.T) {
+	ctx := componenttest.TestContext(t)
+	ctrl, err := componenttest.NewControllerFromID(util.TestLogger(t), "otelcol.processor.logdedup")
+	require.NoError(t, err)
+
+	cfg := `
+		interval           = "100ms"
+		exclude_attributes = ["request.id"]
+		exclude_conditions = ["severity_number >= SEVERITY_NUMBER_ERROR"]
+
+		output {
+			// no-op: will be overridden by test code.
+		}
+	`
+	var args logdedup.Arguments
+	require.NoError(t, syntax.Unmarshal([]byte(cfg), &args))
+
+	logCh := make(chan plog.Logs, 10)
+	args.Output = &otelcol.ConsumerArguments{
+		Logs: []otelcol.Consumer{&fakeconsumer.Consumer{
+			ConsumeLogsFunc: func(_ context.Context, ld plog.Logs) error {
+				logCh <- ld
+				return nil
+			},
+		}},
+	}
+
+	go func() {
+		require.NoError(t, ctrl.Run(ctx, args))
+	}()
+	require.NoError(t, ctrl.WaitRunning(time.Second), "component never started")
+	require.NoError(t, ctrl.WaitExports(time.Second), "component never exported anything")
+
+	ld := plog.NewLogs()
+	records := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	for i, id := range []string{"a", "b", "c"} {
+		lr := records.AppendEmpty()
+		lr.Body().SetStr("connection refused")
+		lr.SetSeverityNumber(plog.SeverityNumberWarn)
+		lr.Attributes().PutStr("request.id", id)
+		lr.Attributes().PutInt("attempt", int64(i%2))
+	}
+	crash := records.AppendEmpty()
+	crash.Body().SetStr("panic: nil pointer dereference")
+	crash.SetSeverityNumber(plog.SeverityNumberFatal)
+
+	exports := ctrl.Exports().(otelcol.ConsumerExports)
+	require.NoError(t, exports.Input.ConsumeLogs(ctx, ld))
+
+	// Excluded records are forwarded immediately.
+	excluded := receiveLogs(t, logCh)
+	require.Equal(t, 1, excluded.LogRecordCount())
+	require.Equal(t, "panic: nil pointer dereference", excluded.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().Str())
+
+	// The other records are deduplicated by attempt, ignoring request.id.
+	deduped := receiveLogs(t, logCh)
+	require.Equal(t, 2, deduped.LogRecordCount())
+
+	counts := map[int64]int64{}
+	out := deduped.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	for i := 0; i < out.Len(); i++ {
+		attrs := out.At(i).Attributes()
+		_, hasRequestID := attrs.Get("request.id")
+		require.False(t, hasRequestID)
+
+		attempt, _ := attrs.Get("attempt")
+		count, _ := attrs.Get("log_count")
+		counts[attempt.Int()] = count.Int()
+
+		_, err := time.Parse(time.RFC3339Nano, attrs.AsRaw()["first_observed_timestamp"].(string))
+		require.NoError(t, err)
+	}
+	require.Equal(t, map[int64]int64{0: 2, 1: 1}, counts)
+}
+
+func receiveLogs(t *testing.T, ch chan plog.Logs) plog.Logs {
+	t.Helper()
+
+	select {
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "failed waiting for logs")
+		return plog.Logs{}
+	case ld := <-ch:
+		return ld
+	}
+}
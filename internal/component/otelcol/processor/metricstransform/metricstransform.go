@@ -0,0 +1,357 @@
+// Package metricstransform provides an otelcol.processor.metricstransform
+// component.
+package metricstransform
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstransformprocessor"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pipeline"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/otelcol"
+	otelcolCfg "github.com/grafana/alloy/internal/component/otelcol/config"
+	"github.com/grafana/alloy/internal/component/otelcol/processor"
+	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/syntax"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:      "otelcol.processor.metricstransform",
+		Stability: featuregate.StabilityExperimental,
+		Args:      Arguments{},
+		Exports:   otelcol.ConsumerExports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			fact := metricstransformprocessor.NewFactory()
+			return processor.New(opts, fact, args.(Arguments))
+		},
+	})
+}
+
+// Supported values for the match_type argument.
+const (
+	matchStrict = "strict"
+	matchRegexp = "regexp"
+)
+
+// Supported values for the action argument of a transform.
+const (
+	actionUpdate  = "update"
+	actionInsert  = "insert"
+	actionCombine = "combine"
+	actionGroup   = "group"
+)
+
+// Supported values for the action argument of an operation.
+const (
+	opAddLabel             = "add_label"
+	opUpdateLabel          = "update_label"
+	opDeleteLabelValue     = "delete_label_value"
+	opToggleScalarDataType = "toggle_scalar_data_type"
+	opScaleValue           = "experimental_scale_value"
+	opAggregateLabels      = "aggregate_labels"
+	opAggregateLabelValues = "aggregate_label_values"
+)
+
+// Supported values for the aggregation_type argument.
+const (
+	aggSum    = "sum"
+	aggMean   = "mean"
+	aggMax    = "max"
+	aggMin    = "min"
+	aggCount  = "count"
+	aggMedian = "median"
+)
+
+// Supported values for the submatch_case argument.
+const (
+	caseLower = "lower"
+	caseUpper = "upper"
+)
+
+// Arguments configures the otelcol.processor.metricstransform component.
+type Arguments struct {
+	// Transforms are applied in order to the metrics of every resource and
+	// instrumentation scope.
+	Transforms []TransformArguments `alloy:"transform,block,optional"`
+
+	// Output configures where to send processed data. Required.
+	Output *otelcol.ConsumerArguments `alloy:"output,block"`
+
+	// DebugMetrics configures component internal metrics. Optional.
+	DebugMetrics otelcolCfg.DebugMetricsArguments `alloy:"debug_metrics,block,optional"`
+}
+
+var (
+	_ processor.Arguments = Arguments{}
+	_ syntax.Defaulter    = (*Arguments)(nil)
+	_ syntax.Validator    = (*Arguments)(nil)
+)
+
+// SetToDefault implements syntax.Defaulter.
+func (args *Arguments) SetToDefault() {
+	*args = Arguments{}
+	args.DebugMetrics.SetToDefault()
+}
+
+// Validate implements syntax.Validator.
+func (args *Arguments) Validate() error {
+	for i, t := range args.Transforms {
+		if err := t.validate(); err != nil {
+			return fmt.Errorf("transform %d (%q): %w", i, t.Include, err)
+		}
+	}
+	return nil
+}
+
+// Convert implements processor.Arguments.
+func (args Arguments) Convert() (otelcomponent.Config, error) {
+	var result metricstransformprocessor.Config
+	if len(args.Transforms) == 0 {
+		return &result, nil
+	}
+
+	// The transforms of the upstream configuration have an unexported type,
+	// so they can only be set by decoding them.
+	transforms := make([]map[string]any, 0, len(args.Transforms))
+	for _, t := range args.Transforms {
+		transforms = append(transforms, t.convert())
+	}
+	if err := mapstructure.Decode(map[string]any{"transforms": transforms}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Extensions implements processor.Arguments.
+func (args Arguments) Extensions() map[otelcomponent.ID]otelcomponent.Component {
+	return nil
+}
+
+// Exporters implements processor.Arguments.
+func (args Arguments) Exporters() map[pipeline.Signal]map[otelcomponent.ID]otelcomponent.Component {
+	return nil
+}
+
+// NextConsumers implements processor.Arguments.
+func (args Arguments) NextConsumers() *otelcol.ConsumerArguments {
+	return args.Output
+}
+
+// DebugMetricsConfig implements processor.Arguments.
+func (args Arguments) DebugMetricsConfig() otelcolCfg.DebugMetricsArguments {
+	return args.DebugMetrics
+}
+
+// TransformArguments configures a transform applied to the metrics matching
+// Include.
+type TransformArguments struct {
+	Include   string `alloy:"include,attr"`
+	MatchType string `alloy:"match_type,attr,optional"`
+	// MatchLabels restricts the transform to the data points whose attributes
+	// match. Values are regular expressions if MatchType is regexp.
+	MatchLabels map[string]string `alloy:"experimental_match_labels,attr,optional"`
+
+	Action  string `alloy:"action,attr"`
+	NewName string `alloy:"new_name,attr,optional"`
+	// GroupResourceLabels are the resource attributes of the resource the
+	// matching metrics are moved to when Action is group.
+	GroupResourceLabels map[string]string `alloy:"group_resource_labels,attr,optional"`
+	// AggregationType is used to merge the data points of combined metrics.
+	AggregationType string `alloy:"aggregation_type,attr,optional"`
+	// SubmatchCase changes the case of the regular expression submatches added
+	// as labels of combined metrics.
+	SubmatchCase string `alloy:"submatch_case,attr,optional"`
+
+	Operations []OperationArguments `alloy:"operation,block,optional"`
+}
+
+var _ syntax.Defaulter = (*TransformArguments)(nil)
+
+// SetToDefault implements syntax.Defaulter.
+func (args *TransformArguments) SetToDefault() {
+	*args = TransformArguments{MatchType: matchStrict}
+}
+
+func (args *TransformArguments) validate() error {
+	if args.Include == "" {
+		return errors.New("include must not be empty")
+	}
+
+	switch args.MatchType {
+	case matchStrict:
+	case matchRegexp:
+		if _, err := regexp.Compile(args.Include); err != nil {
+			return fmt.Errorf("invalid include regex: %w", err)
+		}
+		for label, expr := range args.MatchLabels {
+			if _, err := regexp.Compile(expr); err != nil {
+				return fmt.Errorf("invalid match_labels regex for label %q: %w", label, err)
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported match_type %q", args.MatchType)
+	}
+
+	switch args.Action {
+	case actionUpdate:
+	case actionInsert:
+		if args.NewName == "" {
+			return errors.New("new_name must be set when action is insert")
+		}
+	case actionCombine:
+		if args.MatchType != matchRegexp {
+			return errors.New("match_type must be regexp when action is combine")
+		}
+		if args.NewName == "" {
+			return errors.New("new_name must be set when action is combine")
+		}
+		if args.AggregationType == "" {
+			return errors.New("aggregation_type must be set when action is combine")
+		}
+	case actionGroup:
+		if len(args.GroupResourceLabels) == 0 {
+			return errors.New("group_resource_labels must be set when action is group")
+		}
+	default:
+		return fmt.Errorf("unsupported action %q", args.Action)
+	}
+
+	if args.AggregationType != "" {
+		if err := validateAggregationType(args.AggregationType); err != nil {
+			return err
+		}
+	}
+
+	switch args.SubmatchCase {
+	case "", caseLower, caseUpper:
+	default:
+		return fmt.Errorf("unsupported submatch_case %q", args.SubmatchCase)
+	}
+
+	for i, op := range args.Operations {
+		if err := op.validate(); err != nil {
+			return fmt.Errorf("operation %d (%s): %w", i, op.Action, err)
+		}
+	}
+	return nil
+}
+
+func (args *TransformArguments) convert() map[string]any {
+	operations := make([]map[string]any, 0, len(args.Operations))
+	for _, op := range args.Operations {
+		operations = append(operations, op.convert())
+	}
+
+	return map[string]any{
+		"include":                   args.Include,
+		"match_type":                args.MatchType,
+		"experimental_match_labels": args.MatchLabels,
+		"action":                    args.Action,
+		"new_name":                  args.NewName,
+		"group_resource_labels":     args.GroupResourceLabels,
+		"aggregation_type":          args.AggregationType,
+		"submatch_case":             args.SubmatchCase,
+		"operations":                operations,
+	}
+}
+
+// OperationArguments configures an operation applied to the metrics matched
+// by a transform.
+type OperationArguments struct {
+	Action string `alloy:"action,attr"`
+
+	Label            string                 `alloy:"label,attr,optional"`
+	NewLabel         string                 `alloy:"new_label,attr,optional"`
+	LabelValue       string                 `alloy:"label_value,attr,optional"`
+	NewValue         string                 `alloy:"new_value,attr,optional"`
+	LabelSet         []string               `alloy:"label_set,attr,optional"`
+	AggregatedValues []string               `alloy:"aggregated_values,attr,optional"`
+	AggregationType  string                 `alloy:"aggregation_type,attr,optional"`
+	Scale            float64                `alloy:"experimental_scale,attr,optional"`
+	ValueActions     []ValueActionArguments `alloy:"value_action,block,optional"`
+}
+
+// ValueActionArguments renames a label value.
+type ValueActionArguments struct {
+	Value    string `alloy:"value,attr"`
+	NewValue string `alloy:"new_value,attr"`
+}
+
+func (args *OperationArguments) convert() map[string]any {
+	valueActions := make([]map[string]any, 0, len(args.ValueActions))
+	for _, va := range args.ValueActions {
+		valueActions = append(valueActions, map[string]any{
+			"value":     va.Value,
+			"new_value": va.NewValue,
+		})
+	}
+
+	return map[string]any{
+		"action":             args.Action,
+		"label":              args.Label,
+		"new_label":          args.NewLabel,
+		"label_value":        args.LabelValue,
+		"new_value":          args.NewValue,
+		"label_set":          args.LabelSet,
+		"aggregated_values":  args.AggregatedValues,
+		"aggregation_type":   args.AggregationType,
+		"experimental_scale": args.Scale,
+		"value_actions":      valueActions,
+	}
+}
+
+func (args *OperationArguments) validate() error {
+	switch args.Action {
+	case opAddLabel:
+		if args.NewLabel == "" || args.NewValue == "" {
+			return errors.New("new_label and new_value must be set")
+		}
+	case opUpdateLabel:
+		if args.Label == "" {
+			return errors.New("label must be set")
+		}
+		if args.NewLabel == "" && len(args.ValueActions) == 0 {
+			return errors.New("new_label or a value_action block must be set")
+		}
+	case opDeleteLabelValue:
+		if args.Label == "" || args.LabelValue == "" {
+			return errors.New("label and label_value must be set")
+		}
+	case opToggleScalarDataType:
+	case opScaleValue:
+		if args.Scale == 0 {
+			return errors.New("experimental_scale must be set")
+		}
+	case opAggregateLabels:
+		if args.AggregationType == "" {
+			return errors.New("aggregation_type must be set")
+		}
+	case opAggregateLabelValues:
+		if args.Label == "" || args.NewValue == "" || len(args.AggregatedValues) == 0 || args.AggregationType == "" {
+			return errors.New("label, aggregated_values, new_value, and aggregation_type must be set")
+		}
+	default:
+		return fmt.Errorf("unsupported action %q", args.Action)
+	}
+
+	if args.AggregationType != "" {
+		return validateAggregationType(args.AggregationType)
+	}
+	return nil
+}
+
+func validateAggregationType(aggregationType string) error {
+	switch aggregationType {
+	case aggSum, aggMean, aggMax, aggMin, aggCount, aggMedian:
+		return nil
+	default:
+		return fmt.Errorf("unsupported aggregation_type %q", aggregationType)
+	}
+}
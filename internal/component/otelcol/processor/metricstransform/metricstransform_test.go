@@ -0,0 +1,286 @@
+package metricstransform_test
+
+import (
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstransformprocessor"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/component/otelcol/processor/metricstransform"
+	"github.com/grafana/alloy/internal/component/otelcol/processor/processortest"
+	"github.com/grafana/alloy/internal/runtime/componenttest"
+	"github.com/grafana/alloy/internal/util"
+	"github.com/grafana/alloy/syntax"
+)
+
+func TestArguments_UnmarshalAlloy(t *testing.T) {
+	cfg := `
+		transform {
+			include                   = "system.cpu.usage"
+			action                    = "update"
+			experimental_match_labels = {"cpu" = "0"}
+
+			operation {
+				action    = "update_label"
+				label     = "state"
+				new_label = "cpu_state"
+
+				value_action {
+					value     = "idle"
+					new_value = "-"
+				}
+			}
+		}
+		transform {
+			include               = "^k8s\\.pod\\."
+			match_type            = "regexp"
+			action                = "group"
+			group_resource_labels = {"source" = "kubelet"}
+		}
+		output {}
+	`
+	var args metricstransform.Arguments
+	require.NoError(t, syntax.Unmarshal([]byte(cfg), &args))
+
+	actualPtr, err := args.Convert()
+	require.NoError(t, err)
+	actual := actualPtr.(*metricstransformprocessor.Config)
+	require.Len(t, actual.Transforms, 2)
+
+	update := actual.Transforms[0]
+	require.Equal(t, metricstransformprocessor.FilterConfig{
+		Include:     "system.cpu.usage",
+		MatchType:   "strict",
+		MatchLabels: map[string]string{"cpu": "0"},
+	}, update.MetricIncludeFilter)
+	require.Equal(t, metricstransformprocessor.Update, update.Action)
+	require.Len(t, update.Operations, 1)
+	require.Equal(t, "state", update.Operations[0].Label)
+	require.Equal(t, "cpu_state", update.Operations[0].NewLabel)
+	require.Equal(t, []metricstransformprocessor.ValueAction{{Value: "idle", NewValue: "-"}}, update.Operations[0].ValueActions)
+
+	group := actual.Transforms[1]
+	require.Equal(t, metricstransformprocessor.Group, group.Action)
+	require.Equal(t, map[string]string{"source": "kubelet"}, group.GroupResourceLabels)
+}
+
+func TestArguments_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  string
+		err  string
+	}{
+		{
+			name: "combine without regexp",
+			cfg: `
+				transform {
+					include          = "a"
+					action           = "combine"
+					new_name         = "b"
+					aggregation_type = "sum"
+				}
+				output {}
+			`,
+			err: "match_type must be regexp when action is combine",
+		},
+		{
+			name: "insert without new name",
+			cfg: `
+				transform {
+					include = "a"
+					action  = "insert"
+				}
+				output {}
+			`,
+			err: "new_name must be set when action is insert",
+		},
+		{
+			name: "group without resource labels",
+			cfg: `
+				transform {
+					include = "a"
+					action  = "group"
+				}
+				output {}
+			`,
+			err: "group_resource_labels must be set when action is group",
+		},
+		{
+			name: "invalid aggregation type",
+			cfg: `
+				transform {
+					include = "a"
+					action  = "update"
+					operation {
+						action           = "aggregate_labels"
+						label_set        = ["a"]
+						aggregation_type = "p99"
+					}
+				}
+				output {}
+			`,
+			err: `unsupported aggregation_type "p99"`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var args metricstransform.Arguments
+			require.ErrorContains(t, syntax.Unmarshal([]byte(tc.cfg), &args), tc.err)
+		})
+	}
+}
+
+func TestMetricsTransform_Rename(t *testing.T) {
+	cfg := `
+		transform {
+			include    = "^legacy_(.*)_ms$"
+			match_type = "regexp"
+			action     = "update"
+			new_name   = "${1}_seconds"
+
+			operation {
+				action    = "add_label"
+				new_label = "unit"
+				new_value = "s"
+			}
+		}
+		output {
+			// no-op: will be overridden by test code.
+		}
+	`
+	input := `{
+		"resourceMetrics": [{
+			"scopeMetrics": [{
+				"metrics": [{
+					"name": "legacy_request_ms",
+					"gauge": {
+						"dataPoints": [{ "asInt": "1" }]
+					}
+				}, {
+					"name": "other",
+					"gauge": {
+						"dataPoints": [{ "asInt": "2" }]
+					}
+				}]
+			}]
+		}]
+	}`
+	expected := `{
+		"resourceMetrics": [{
+			"scopeMetrics": [{
+				"metrics": [{
+					"name": "request_seconds",
+					"gauge": {
+						"dataPoints": [{
+							"attributes": [{ "key": "unit", "value": { "stringValue": "s" } }],
+							"asInt": "1"
+						}]
+					}
+				}, {
+					"name": "other",
+					"gauge": {
+						"dataPoints": [{ "asInt": "2" }]
+					}
+				}]
+			}]
+		}]
+	}`
+
+	testRunProcessor(t, cfg, processortest.NewMetricSignal(input, expected))
+}
+
+func TestMetricsTransform_Combine(t *testing.T) {
+	cfg := `
+		transform {
+			include          = "^system\\.cpu\\.usage\\.(?P<state>.*)$"
+			match_type       = "regexp"
+			action           = "combine"
+			new_name         = "system.cpu.usage"
+			aggregation_type = "sum"
+
+			operation {
+				action           = "aggregate_labels"
+				label_set        = ["state"]
+				aggregation_type = "sum"
+			}
+		}
+		output {
+			// no-op: will be overridden by test code.
+		}
+	`
+	input := `{
+		"resourceMetrics": [{
+			"scopeMetrics": [{
+				"metrics": [{
+					"name": "system.cpu.usage.user",
+					"sum": {
+						"dataPoints": [{
+							"attributes": [{ "key": "cpu", "value": { "stringValue": "0" } }],
+							"asInt": "10"
+						}],
+						"aggregationTemporality": 2,
+						"isMonotonic": true
+					}
+				}, {
+					"name": "system.cpu.usage.idle",
+					"sum": {
+						"dataPoints": [{
+							"attributes": [{ "key": "cpu", "value": { "stringValue": "0" } }],
+							"asInt": "20"
+						}, {
+							"attributes": [{ "key": "cpu", "value": { "stringValue": "1" } }],
+							"asInt": "5"
+						}],
+						"aggregationTemporality": 2,
+						"isMonotonic": true
+					}
+				}]
+			}]
+		}]
+	}`
+	expected := `{
+		"resourceMetrics": [{
+			"scopeMetrics": [{
+				"metrics": [{
+					"name": "system.cpu.usage",
+					"sum": {
+						"dataPoints": [{
+							"attributes": [{ "key": "state", "value": { "stringValue": "user" } }],
+							"asInt": "10"
+						}, {
+							"attributes": [{ "key": "state", "value": { "stringValue": "idle" } }],
+							"asInt": "25"
+						}],
+						"aggregationTemporality": 2,
+						"isMonotonic": true
+					}
+				}]
+			}]
+		}]
+	}`
+
+	testRunProcessor(t, cfg, processortest.NewMetricSignal(input, expected))
+}
+
+func testRunProcessor(t *testing.T, processorConfig string, testSignal processortest.Signal) {
+	ctx := componenttest.TestContext(t)
+	l := util.TestLogger(t)
+
+	ctrl, err := componenttest.NewControllerFromID(l, "otelcol.processor.metricstransform")
+	require.NoError(t, err)
+
+	var args metricstransform.Arguments
+	require.NoError(t, syntax.Unmarshal([]byte(processorConfig), &args))
+
+	// Override the arguments so signals get forwarded to the test channel.
+	args.Output = testSignal.MakeOutput()
+
+	processortest.TestRunProcessor(processortest.ProcessorRunConfig{
+		Ctx:        ctx,
+		T:          t,
+		Args:       args,
+		TestSignal: testSignal,
+		Ctrl:       ctrl,
+		L:          l,
+	})
+}
@@ -0,0 +1,322 @@
+package redaction
+
+import (
+	"context"
+	"crypto/md5"  //nolint:gosec
+	"crypto/sha1" //nolint:gosec
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/redactionprocessor"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	otelprocessor "go.opentelemetry.io/collector/processor"
+	"golang.org/x/crypto/sha3"
+
+	"github.com/grafana/alloy/internal/component/loki/secretfilter"
+)
+
+// Summary attributes added for the secrets masked by the Gitleaks rules. They
+// match the attributes of the upstream processor, whose counts they add to.
+const (
+	summaryPrefix          = "redaction."
+	summaryMaskedKeys      = "redaction.masked.keys"
+	summaryMaskedCount     = "redaction.masked.count"
+	summaryBodyMaskedKeys  = "redaction.body.masked.keys"
+	summaryBodyMaskedCount = "redaction.body.masked.count"
+	summaryKeysSeparator   = ","
+)
+
+// gitleaksConfig is the configuration passed to the factory returned by
+// newFactory when the gitleaks block is set.
+type gitleaksConfig struct {
+	*redactionprocessor.Config
+
+	secrets *secretMasker
+}
+
+// newFactory returns a factory for the upstream redaction processor. When it's
+// passed a gitleaksConfig, the processors it creates mask the secrets found by
+// the Gitleaks rules in the output of the upstream processor.
+//
+// The upstream processor only supports allowlists which apply to every blocked
+// value, while the allowlists of Gitleaks rules only apply to their rule.
+func newFactory() otelprocessor.Factory {
+	upstream := redactionprocessor.NewFactory()
+
+	return otelprocessor.NewFactory(
+		upstream.Type(),
+		upstream.CreateDefaultConfig,
+		otelprocessor.WithTraces(func(ctx context.Context, set otelprocessor.Settings, cfg otelcomponent.Config, next consumer.Traces) (otelprocessor.Traces, error) {
+			c, ok := cfg.(*gitleaksConfig)
+			if !ok {
+				return upstream.CreateTraces(ctx, set, cfg, next)
+			}
+			masked, err := consumer.NewTraces(func(ctx context.Context, td ptrace.Traces) error {
+				c.secrets.maskTraces(td)
+				return next.ConsumeTraces(ctx, td)
+			}, consumer.WithCapabilities(consumer.Capabilities{MutatesData: true}))
+			if err != nil {
+				return nil, err
+			}
+			return upstream.CreateTraces(ctx, set, c.Config, masked)
+		}, upstream.TracesStability()),
+		otelprocessor.WithMetrics(func(ctx context.Context, set otelprocessor.Settings, cfg otelcomponent.Config, next consumer.Metrics) (otelprocessor.Metrics, error) {
+			c, ok := cfg.(*gitleaksConfig)
+			if !ok {
+				return upstream.CreateMetrics(ctx, set, cfg, next)
+			}
+			masked, err := consumer.NewMetrics(func(ctx context.Context, md pmetric.Metrics) error {
+				c.secrets.maskMetrics(md)
+				return next.ConsumeMetrics(ctx, md)
+			}, consumer.WithCapabilities(consumer.Capabilities{MutatesData: true}))
+			if err != nil {
+				return nil, err
+			}
+			return upstream.CreateMetrics(ctx, set, c.Config, masked)
+		}, upstream.MetricsStability()),
+		otelprocessor.WithLogs(func(ctx context.Context, set otelprocessor.Settings, cfg otelcomponent.Config, next consumer.Logs) (otelprocessor.Logs, error) {
+			c, ok := cfg.(*gitleaksConfig)
+			if !ok {
+				return upstream.CreateLogs(ctx, set, cfg, next)
+			}
+			masked, err := consumer.NewLogs(func(ctx context.Context, ld plog.Logs) error {
+				c.secrets.maskLogs(ld)
+				return next.ConsumeLogs(ctx, ld)
+			}, consumer.WithCapabilities(consumer.Capabilities{MutatesData: true}))
+			if err != nil {
+				return nil, err
+			}
+			return upstream.CreateLogs(ctx, set, c.Config, masked)
+		}, upstream.LogsStability()),
+	)
+}
+
+// secretMasker masks the secrets found by Gitleaks rules in attribute values
+// and log bodies. Like loki.secretfilter, only the secret found by a rule is
+// masked, and a secret is kept if it matches the global allowlist or the
+// allowlist of the rule which found it.
+type secretMasker struct {
+	rules   []secretfilter.Rule
+	global  []secretfilter.AllowRule
+	allowed []*regexp.Regexp // Values matching allowed_values are never masked.
+	ignored []string         // Keys from ignored_keys are never masked.
+	hash    redactionprocessor.HashFunction
+	summary string
+}
+
+func newSecretMasker(args Arguments, hash redactionprocessor.HashFunction) (*secretMasker, error) {
+	rules, global, err := secretfilter.LoadRules(log.NewNopLogger(), args.Gitleaks.rulesConfig())
+	if err != nil {
+		return nil, fmt.Errorf("loading gitleaks rules: %w", err)
+	}
+
+	m := &secretMasker{
+		rules:   rules,
+		global:  global,
+		ignored: args.IgnoredKeys,
+		hash:    hash,
+		summary: args.Summary,
+	}
+	for _, v := range args.AllowedValues {
+		re, err := regexp.Compile(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed_values regex %q: %w", v, err)
+		}
+		m.allowed = append(m.allowed, re)
+	}
+	return m, nil
+}
+
+func (m *secretMasker) maskTraces(td ptrace.Traces) {
+	for _, rs := range td.ResourceSpans().All() {
+		m.maskAttributes(rs.Resource().Attributes())
+		for _, ss := range rs.ScopeSpans().All() {
+			for _, span := range ss.Spans().All() {
+				m.maskAttributes(span.Attributes())
+				for _, event := range span.Events().All() {
+					m.maskAttributes(event.Attributes())
+				}
+			}
+		}
+	}
+}
+
+func (m *secretMasker) maskLogs(ld plog.Logs) {
+	for _, rl := range ld.ResourceLogs().All() {
+		m.maskAttributes(rl.Resource().Attributes())
+		for _, sl := range rl.ScopeLogs().All() {
+			for _, record := range sl.LogRecords().All() {
+				m.maskAttributes(record.Attributes())
+
+				var maskedKeys []string
+				m.maskValue("body", record.Body(), &maskedKeys)
+				m.addSummary(record.Attributes(), maskedKeys, summaryBodyMaskedKeys, summaryBodyMaskedCount)
+			}
+		}
+	}
+}
+
+func (m *secretMasker) maskMetrics(md pmetric.Metrics) {
+	for _, rm := range md.ResourceMetrics().All() {
+		m.maskAttributes(rm.Resource().Attributes())
+		for _, sm := range rm.ScopeMetrics().All() {
+			for _, metric := range sm.Metrics().All() {
+				switch metric.Type() {
+				case pmetric.MetricTypeGauge:
+					for _, dp := range metric.Gauge().DataPoints().All() {
+						m.maskAttributes(dp.Attributes())
+					}
+				case pmetric.MetricTypeSum:
+					for _, dp := range metric.Sum().DataPoints().All() {
+						m.maskAttributes(dp.Attributes())
+					}
+				case pmetric.MetricTypeHistogram:
+					for _, dp := range metric.Histogram().DataPoints().All() {
+						m.maskAttributes(dp.Attributes())
+					}
+				case pmetric.MetricTypeExponentialHistogram:
+					for _, dp := range metric.ExponentialHistogram().DataPoints().All() {
+						m.maskAttributes(dp.Attributes())
+					}
+				case pmetric.MetricTypeSummary:
+					for _, dp := range metric.Summary().DataPoints().All() {
+						m.maskAttributes(dp.Attributes())
+					}
+				case pmetric.MetricTypeEmpty:
+				}
+			}
+		}
+	}
+}
+
+// maskAttributes masks the secrets in the string attributes of attrs and adds
+// the masked keys to the summary.
+func (m *secretMasker) maskAttributes(attrs pcommon.Map) {
+	var maskedKeys []string
+	for k, v := range attrs.All() {
+		if v.Type() != pcommon.ValueTypeStr || strings.HasPrefix(k, summaryPrefix) || slices.Contains(m.ignored, k) {
+			continue
+		}
+		if masked, ok := m.maskString(v.Str()); ok {
+			v.SetStr(masked)
+			maskedKeys = append(maskedKeys, k)
+		}
+	}
+	m.addSummary(attrs, maskedKeys, summaryMaskedKeys, summaryMaskedCount)
+}
+
+// maskValue masks the secrets in a log body, descending into maps and slices.
+// The paths of the masked values are added to maskedKeys.
+func (m *secretMasker) maskValue(path string, v pcommon.Value, maskedKeys *[]string) {
+	switch v.Type() {
+	case pcommon.ValueTypeMap:
+		for k, child := range v.Map().All() {
+			if slices.Contains(m.ignored, k) {
+				continue
+			}
+			childPath := k
+			if path != "body" {
+				childPath = path + "." + k
+			}
+			m.maskValue(childPath, child, maskedKeys)
+		}
+	case pcommon.ValueTypeSlice:
+		for i, child := range v.Slice().All() {
+			childPath := fmt.Sprintf("[%d]", i)
+			if path != "body" {
+				childPath = path + "." + childPath
+			}
+			m.maskValue(childPath, child, maskedKeys)
+		}
+	case pcommon.ValueTypeStr:
+		if masked, ok := m.maskString(v.Str()); ok {
+			v.SetStr(masked)
+			*maskedKeys = append(*maskedKeys, path)
+		}
+	default:
+	}
+}
+
+// maskString masks the secrets found in s. It returns false if s is
+// unchanged.
+func (m *secretMasker) maskString(s string) (string, bool) {
+	for _, re := range m.allowed {
+		if re.MatchString(s) {
+			return s, false
+		}
+	}
+
+	res := s
+	for _, r := range m.rules {
+		for _, secret := range r.FindSecrets(res) {
+			if r.AllowedBy(secret, m.global) != nil {
+				continue
+			}
+			res = strings.ReplaceAll(res, secret, m.mask(secret))
+		}
+	}
+	return res, res != s
+}
+
+// mask returns what a secret is replaced with, which matches the upstream
+// processor.
+//
+//nolint:gosec
+func (m *secretMasker) mask(secret string) string {
+	var h hash.Hash
+	switch m.hash {
+	case redactionprocessor.SHA1:
+		h = sha1.New()
+	case redactionprocessor.SHA3:
+		h = sha3.New256()
+	case redactionprocessor.MD5:
+		h = md5.New()
+	default:
+		return mask
+	}
+	h.Write([]byte(secret))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// addSummary adds maskedKeys to the summary attributes of the upstream
+// processor.
+func (m *secretMasker) addSummary(attrs pcommon.Map, maskedKeys []string, keysAttr, countAttr string) {
+	if len(maskedKeys) == 0 {
+		return
+	}
+
+	added := int64(len(maskedKeys))
+	if m.summary == summaryDebug {
+		// Keys already masked by the upstream processor are only counted once.
+		keys := maskedKeys
+		if existing, ok := attrs.Get(keysAttr); ok && existing.Str() != "" {
+			keys = strings.Split(existing.Str(), summaryKeysSeparator)
+			added = 0
+			for _, k := range maskedKeys {
+				if !slices.Contains(keys, k) {
+					keys = append(keys, k)
+					added++
+				}
+			}
+		}
+		slices.Sort(keys)
+		attrs.PutStr(keysAttr, strings.Join(keys, summaryKeysSeparator))
+	}
+	if m.summary == summaryInfo || m.summary == summaryDebug {
+		count := added
+		if existing, ok := attrs.Get(countAttr); ok {
+			count += existing.Int()
+		}
+		attrs.PutInt(countAttr, count)
+	}
+}
@@ -0,0 +1,195 @@
+// Package redaction provides an otelcol.processor.redaction component.
+package redaction
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/redactionprocessor"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pipeline"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/loki/secretfilter"
+	"github.com/grafana/alloy/internal/component/otelcol"
+	otelcolCfg "github.com/grafana/alloy/internal/component/otelcol/config"
+	"github.com/grafana/alloy/internal/component/otelcol/processor"
+	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/syntax"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:      "otelcol.processor.redaction",
+		Stability: featuregate.StabilityExperimental,
+		Args:      Arguments{},
+		Exports:   otelcol.ConsumerExports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			fact := newFactory()
+			return processor.New(opts, fact, args.(Arguments))
+		},
+	})
+}
+
+// Supported values for the summary argument.
+const (
+	summaryDebug  = "debug"
+	summaryInfo   = "info"
+	summarySilent = "silent"
+)
+
+// mask is the string the upstream processor replaces blocked values with.
+// Gitleaks rules matching it are excluded.
+const mask = "****"
+
+// Arguments configures the otelcol.processor.redaction component.
+type Arguments struct {
+	// AllowAllKeys disables the removal of attributes which aren't in
+	// AllowedKeys.
+	AllowAllKeys bool `alloy:"allow_all_keys,attr,optional"`
+	// AllowedKeys is the list of attribute keys which are kept.
+	AllowedKeys []string `alloy:"allowed_keys,attr,optional"`
+	// IgnoredKeys is the list of attribute keys which are never removed or
+	// masked.
+	IgnoredKeys []string `alloy:"ignored_keys,attr,optional"`
+	// BlockedKeyPatterns is a list of regular expressions. The values of
+	// attributes whose key matches any of them are masked.
+	BlockedKeyPatterns []string `alloy:"blocked_key_patterns,attr,optional"`
+	// BlockedValues is a list of regular expressions. Parts of attribute
+	// values and log bodies matching any of them are masked.
+	BlockedValues []string `alloy:"blocked_values,attr,optional"`
+	// AllowedValues is a list of regular expressions. Values matching any of
+	// them are never masked.
+	AllowedValues []string `alloy:"allowed_values,attr,optional"`
+	// HashFunction is used to replace masked values by their hash instead of a
+	// fixed mask.
+	HashFunction string `alloy:"hash_function,attr,optional"`
+	// Summary controls which attributes describing the redaction are added.
+	Summary string `alloy:"summary,attr,optional"`
+
+	// Gitleaks enables masking secrets found by the Gitleaks rules used by
+	// loki.secretfilter.
+	Gitleaks *GitleaksArguments `alloy:"gitleaks,block,optional"`
+
+	// Output configures where to send processed data. Required.
+	Output *otelcol.ConsumerArguments `alloy:"output,block"`
+
+	// DebugMetrics configures component internal metrics. Optional.
+	DebugMetrics otelcolCfg.DebugMetricsArguments `alloy:"debug_metrics,block,optional"`
+}
+
+var (
+	_ processor.Arguments = Arguments{}
+	_ syntax.Defaulter    = (*Arguments)(nil)
+	_ syntax.Validator    = (*Arguments)(nil)
+)
+
+// DefaultArguments holds default settings for Arguments.
+var DefaultArguments = Arguments{
+	Summary: summaryInfo,
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (args *Arguments) SetToDefault() {
+	*args = DefaultArguments
+	args.DebugMetrics.SetToDefault()
+}
+
+// Validate implements syntax.Validator.
+func (args *Arguments) Validate() error {
+	var hash redactionprocessor.HashFunction
+	if err := hash.UnmarshalText([]byte(args.HashFunction)); err != nil {
+		return fmt.Errorf("invalid hash_function: %w", err)
+	}
+
+	switch args.Summary {
+	case summaryDebug, summaryInfo, summarySilent:
+	default:
+		return fmt.Errorf("unsupported summary %q", args.Summary)
+	}
+
+	for _, list := range []struct {
+		name    string
+		regexes []string
+	}{
+		{"blocked_key_patterns", args.BlockedKeyPatterns},
+		{"blocked_values", args.BlockedValues},
+		{"allowed_values", args.AllowedValues},
+	} {
+		for _, v := range list.regexes {
+			if _, err := regexp.Compile(v); err != nil {
+				return fmt.Errorf("invalid %s regex %q: %w", list.name, v, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Convert implements processor.Arguments.
+func (args Arguments) Convert() (otelcomponent.Config, error) {
+	var hash redactionprocessor.HashFunction
+	if err := hash.UnmarshalText([]byte(args.HashFunction)); err != nil {
+		return nil, err
+	}
+
+	cfg := &redactionprocessor.Config{
+		AllowAllKeys:       args.AllowAllKeys,
+		AllowedKeys:        args.AllowedKeys,
+		BlockedKeyPatterns: args.BlockedKeyPatterns,
+		HashFunction:       hash,
+		IgnoredKeys:        args.IgnoredKeys,
+		BlockedValues:      args.BlockedValues,
+		AllowedValues:      args.AllowedValues,
+		Summary:            args.Summary,
+	}
+	if args.Gitleaks == nil {
+		return cfg, nil
+	}
+
+	secrets, err := newSecretMasker(args, hash)
+	if err != nil {
+		return nil, err
+	}
+	return &gitleaksConfig{Config: cfg, secrets: secrets}, nil
+}
+
+// Extensions implements processor.Arguments.
+func (args Arguments) Extensions() map[otelcomponent.ID]otelcomponent.Component {
+	return nil
+}
+
+// Exporters implements processor.Arguments.
+func (args Arguments) Exporters() map[pipeline.Signal]map[otelcomponent.ID]otelcomponent.Component {
+	return nil
+}
+
+// NextConsumers implements processor.Arguments.
+func (args Arguments) NextConsumers() *otelcol.ConsumerArguments {
+	return args.Output
+}
+
+// DebugMetricsConfig implements processor.Arguments.
+func (args Arguments) DebugMetricsConfig() otelcolCfg.DebugMetricsArguments {
+	return args.DebugMetrics
+}
+
+// GitleaksArguments configures the Gitleaks rules used to find secrets.
+type GitleaksArguments struct {
+	// Config is the path to a custom gitleaks.toml file. If empty, the
+	// ruleset embedded in loki.secretfilter is used.
+	Config         string   `alloy:"config,attr,optional"`
+	Types          []string `alloy:"types,attr,optional"`
+	IncludeGeneric bool     `alloy:"include_generic,attr,optional"`
+	AllowList      []string `alloy:"allowlist,attr,optional"`
+}
+
+func (args *GitleaksArguments) rulesConfig() secretfilter.RulesConfig {
+	return secretfilter.RulesConfig{
+		GitleaksConfig: args.Config,
+		Types:          args.Types,
+		RedactWith:     mask,
+		IncludeGeneric: args.IncludeGeneric,
+		AllowList:      args.AllowList,
+	}
+}
@@ -0,0 +1,259 @@
+// Package hostmetrics provides an otelcol.receiver.hostmetrics component.
+package hostmetrics
+
+import (
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/hostmetricsreceiver"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/pipeline"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/otelcol"
+	otelcolCfg "github.com/grafana/alloy/internal/component/otelcol/config"
+	"github.com/grafana/alloy/internal/component/otelcol/receiver"
+	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/syntax"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:      "otelcol.receiver.hostmetrics",
+		Stability: featuregate.StabilityExperimental,
+		Args:      Arguments{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return receiver.New(opts, newFactory(), args.(Arguments))
+		},
+	})
+}
+
+// Arguments configures the otelcol.receiver.hostmetrics component.
+type Arguments struct {
+	Controller otelcol.ControllerArguments `alloy:",squash"`
+
+	// RootPath is the path the host's root filesystem is mounted at, when
+	// running in a container.
+	RootPath string `alloy:"root_path,attr,optional"`
+
+	CPU        *CPUArguments        `alloy:"cpu,block,optional"`
+	Memory     *MemoryArguments     `alloy:"memory,block,optional"`
+	Disk       *DiskArguments       `alloy:"disk,block,optional"`
+	Filesystem *FilesystemArguments `alloy:"filesystem,block,optional"`
+	Network    *NetworkArguments    `alloy:"network,block,optional"`
+	Load       *LoadArguments       `alloy:"load,block,optional"`
+	Paging     *PagingArguments     `alloy:"paging,block,optional"`
+	Processes  *ProcessesArguments  `alloy:"processes,block,optional"`
+	Process    *ProcessArguments    `alloy:"process,block,optional"`
+
+	// DebugMetrics configures component internal metrics. Optional.
+	DebugMetrics otelcolCfg.DebugMetricsArguments `alloy:"debug_metrics,block,optional"`
+
+	// Output configures where to send received data. Required.
+	Output *otelcol.ConsumerArguments `alloy:"output,block"`
+}
+
+var (
+	_ receiver.Arguments = Arguments{}
+	_ syntax.Defaulter   = (*Arguments)(nil)
+	_ syntax.Validator   = (*Arguments)(nil)
+)
+
+// SetToDefault implements syntax.Defaulter.
+func (args *Arguments) SetToDefault() {
+	*args = Arguments{}
+	args.Controller.SetToDefault()
+	args.DebugMetrics.SetToDefault()
+}
+
+// Validate implements syntax.Validator.
+func (args *Arguments) Validate() error {
+	if err := args.Controller.Validate(); err != nil {
+		return err
+	}
+
+	cfg, err := args.Convert()
+	if err != nil {
+		return err
+	}
+	return cfg.(*hostmetricsreceiver.Config).Validate()
+}
+
+// Convert implements receiver.Arguments. The configuration of each scraper
+// lives in an internal package of the upstream receiver, so the scrapers are
+// configured through the same map the upstream receiver reads from YAML.
+func (args Arguments) Convert() (otelcomponent.Config, error) {
+	scrapers := map[string]any{}
+	if args.CPU != nil {
+		scrapers["cpu"] = nil
+	}
+	if args.Memory != nil {
+		scrapers["memory"] = nil
+	}
+	if args.Disk != nil {
+		scrapers["disk"] = args.Disk.convert()
+	}
+	if args.Filesystem != nil {
+		scrapers["filesystem"] = args.Filesystem.convert()
+	}
+	if args.Network != nil {
+		scrapers["network"] = args.Network.convert()
+	}
+	if args.Load != nil {
+		scrapers["load"] = args.Load.convert()
+	}
+	if args.Paging != nil {
+		scrapers["paging"] = nil
+	}
+	if args.Processes != nil {
+		scrapers["processes"] = nil
+	}
+	if args.Process != nil {
+		scrapers["process"] = args.Process.convert()
+	}
+
+	cfg := hostmetricsreceiver.NewFactory().CreateDefaultConfig().(*hostmetricsreceiver.Config)
+	err := cfg.Unmarshal(confmap.NewFromStringMap(map[string]any{
+		"collection_interval": args.Controller.CollectionInterval,
+		"initial_delay":       args.Controller.InitialDelay,
+		"timeout":             args.Controller.Timeout,
+		"root_path":           args.RootPath,
+		"scrapers":            scrapers,
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Extensions implements receiver.Arguments.
+func (args Arguments) Extensions() map[otelcomponent.ID]otelcomponent.Component {
+	return nil
+}
+
+// Exporters implements receiver.Arguments.
+func (args Arguments) Exporters() map[pipeline.Signal]map[otelcomponent.ID]otelcomponent.Component {
+	return nil
+}
+
+// NextConsumers implements receiver.Arguments.
+func (args Arguments) NextConsumers() *otelcol.ConsumerArguments {
+	return args.Output
+}
+
+// DebugMetricsConfig implements receiver.Arguments.
+func (args Arguments) DebugMetricsConfig() otelcolCfg.DebugMetricsArguments {
+	return args.DebugMetrics
+}
+
+// CPUArguments configures the cpu scraper.
+type CPUArguments struct{}
+
+// MemoryArguments configures the memory scraper.
+type MemoryArguments struct{}
+
+// DiskArguments configures the disk scraper.
+type DiskArguments struct {
+	IncludeDevices []string `alloy:"include_devices,attr,optional"`
+	ExcludeDevices []string `alloy:"exclude_devices,attr,optional"`
+}
+
+func (args *DiskArguments) convert() map[string]any {
+	return matchConfigs(
+		matchConfig{"include", "devices", args.IncludeDevices},
+		matchConfig{"exclude", "devices", args.ExcludeDevices},
+	)
+}
+
+// FilesystemArguments configures the filesystem scraper.
+type FilesystemArguments struct {
+	IncludeVirtualFilesystems bool     `alloy:"include_virtual_filesystems,attr,optional"`
+	IncludeDevices            []string `alloy:"include_devices,attr,optional"`
+	ExcludeDevices            []string `alloy:"exclude_devices,attr,optional"`
+	IncludeFSTypes            []string `alloy:"include_fs_types,attr,optional"`
+	ExcludeFSTypes            []string `alloy:"exclude_fs_types,attr,optional"`
+	IncludeMountPoints        []string `alloy:"include_mount_points,attr,optional"`
+	ExcludeMountPoints        []string `alloy:"exclude_mount_points,attr,optional"`
+}
+
+func (args *FilesystemArguments) convert() map[string]any {
+	res := matchConfigs(
+		matchConfig{"include_devices", "devices", args.IncludeDevices},
+		matchConfig{"exclude_devices", "devices", args.ExcludeDevices},
+		matchConfig{"include_fs_types", "fs_types", args.IncludeFSTypes},
+		matchConfig{"exclude_fs_types", "fs_types", args.ExcludeFSTypes},
+		matchConfig{"include_mount_points", "mount_points", args.IncludeMountPoints},
+		matchConfig{"exclude_mount_points", "mount_points", args.ExcludeMountPoints},
+	)
+	res["include_virtual_filesystems"] = args.IncludeVirtualFilesystems
+	return res
+}
+
+// NetworkArguments configures the network scraper.
+type NetworkArguments struct {
+	IncludeInterfaces []string `alloy:"include_interfaces,attr,optional"`
+	ExcludeInterfaces []string `alloy:"exclude_interfaces,attr,optional"`
+}
+
+func (args *NetworkArguments) convert() map[string]any {
+	return matchConfigs(
+		matchConfig{"include", "interfaces", args.IncludeInterfaces},
+		matchConfig{"exclude", "interfaces", args.ExcludeInterfaces},
+	)
+}
+
+// LoadArguments configures the load scraper.
+type LoadArguments struct {
+	// CPUAverage divides the load averages by the number of logical CPUs.
+	CPUAverage bool `alloy:"cpu_average,attr,optional"`
+}
+
+func (args *LoadArguments) convert() map[string]any {
+	return map[string]any{
+		"cpu_average": args.CPUAverage,
+	}
+}
+
+// PagingArguments configures the paging scraper.
+type PagingArguments struct{}
+
+// ProcessesArguments configures the processes scraper.
+type ProcessesArguments struct{}
+
+// ProcessArguments configures the process scraper.
+type ProcessArguments struct {
+	IncludeNames      []string `alloy:"include_names,attr,optional"`
+	ExcludeNames      []string `alloy:"exclude_names,attr,optional"`
+	MuteProcessErrors bool     `alloy:"mute_process_errors,attr,optional"`
+}
+
+func (args *ProcessArguments) convert() map[string]any {
+	res := matchConfigs(
+		matchConfig{"include", "names", args.IncludeNames},
+		matchConfig{"exclude", "names", args.ExcludeNames},
+	)
+	res["mute_process_all_errors"] = args.MuteProcessErrors
+	return res
+}
+
+// matchConfig is a list of regular expressions used by a scraper to filter
+// devices, interfaces, or processes.
+type matchConfig struct {
+	key     string
+	listKey string
+	exprs   []string
+}
+
+// matchConfigs returns the upstream configuration of the non-empty lists.
+func matchConfigs(configs ...matchConfig) map[string]any {
+	res := map[string]any{}
+	for _, c := range configs {
+		if len(c.exprs) == 0 {
+			continue
+		}
+		res[c.key] = map[string]any{
+			"match_type": "regexp",
+			c.listKey:    c.exprs,
+		}
+	}
+	return res
+}
@@ -0,0 +1,175 @@
+package hostmetrics_test
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/grafana/alloy/internal/component/otelcol"
+	"github.com/grafana/alloy/internal/component/otelcol/internal/fakeconsumer"
+	"github.com/grafana/alloy/internal/component/otelcol/receiver/hostmetrics"
+	"github.com/grafana/alloy/internal/runtime/componenttest"
+	"github.com/grafana/alloy/internal/util"
+	"github.com/grafana/alloy/syntax"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/hostmetricsreceiver"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestArguments_UnmarshalAlloy(t *testing.T) {
+	cfg := `
+		collection_interval = "30s"
+
+		cpu {}
+		load {
+			cpu_average = true
+		}
+		network {
+			exclude_interfaces = ["lo"]
+		}
+		process {
+			include_names       = ["alloy.*"]
+			mute_process_errors = true
+		}
+
+		output {}
+	`
+	var args hostmetrics.Arguments
+	require.NoError(t, syntax.Unmarshal([]byte(cfg), &args))
+
+	actualPtr, err := args.Convert()
+	require.NoError(t, err)
+	actual := actualPtr.(*hostmetricsreceiver.Config)
+
+	require.Equal(t, 30*time.Second, actual.CollectionInterval)
+	require.Equal(t, time.Second, actual.InitialDelay)
+
+	var scrapers []string
+	for typ := range actual.Scrapers {
+		scrapers = append(scrapers, typ.String())
+	}
+	require.ElementsMatch(t, []string{"cpu", "load", "network", "process"}, scrapers)
+}
+
+func TestArguments_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  string
+		err  string
+	}{
+		{
+			name: "no scrapers",
+			cfg:  `output {}`,
+			err:  "must specify at least one scraper",
+		},
+		{
+			name: "invalid interval",
+			cfg: `
+				collection_interval = "0s"
+				cpu {}
+				output {}
+			`,
+			err: `"collection_interval": requires positive value`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var args hostmetrics.Arguments
+			require.ErrorContains(t, syntax.Unmarshal([]byte(tc.cfg), &args), tc.err)
+		})
+	}
+}
+
+func Test(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("host metrics are only checked on Linux")
+	}
+
+	ctx := componenttest.TestContext(t)
+	ctrl, err := componenttest.NewControllerFromID(util.TestLogger(t), "otelcol.receiver.hostmetrics")
+	require.NoError(t, err)
+
+	cfg := `
+		collection_interval = "100ms"
+		initial_delay       = "0s"
+
+		cpu {}
+		memory {}
+		load {}
+		process {
+			include_names       = ["hostmetrics.*"]
+			mute_process_errors = true
+		}
+
+		output {
+			// no-op: will be overridden by test code.
+		}
+	`
+	var args hostmetrics.Arguments
+	require.NoError(t, syntax.Unmarshal([]byte(cfg), &args))
+
+	metricCh := make(chan pmetric.Metrics)
+	args.Output = &otelcol.ConsumerArguments{
+		Metrics: []otelcol.Consumer{&fakeconsumer.Consumer{
+			ConsumeMetricsFunc: func(ctx context.Context, md pmetric.Metrics) error {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case metricCh <- md:
+					return nil
+				}
+			},
+		}},
+	}
+
+	go func() {
+		require.NoError(t, ctrl.Run(ctx, args))
+	}()
+
+	var md pmetric.Metrics
+	select {
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "failed waiting for metrics")
+	case md = <-metricCh:
+	}
+
+	names := make(map[string]bool)
+	var pids []int64
+	for i := 0; i < md.ResourceMetrics().Len(); i++ {
+		rm := md.ResourceMetrics().At(i)
+		if pid, ok := rm.Resource().Attributes().Get("process.pid"); ok {
+			pids = append(pids, pid.Int())
+		}
+
+		ms := rm.ScopeMetrics().At(0).Metrics()
+		for j := 0; j < ms.Len(); j++ {
+			names[ms.At(j).Name()] = true
+		}
+	}
+
+	for _, name := range []string{
+		"system.cpu.time",
+		"system.memory.usage",
+		"system.cpu.load_average.1m",
+		"process.cpu.time",
+		"process.memory.usage",
+	} {
+		require.True(t, names[name], "missing metric %s", name)
+	}
+	require.Contains(t, pids, int64(os.Getpid()))
+
+	// Every resource has the attributes added by the system detector of
+	// otelcol.processor.resourcedetection.
+	for i := 0; i < md.ResourceMetrics().Len(); i++ {
+		attrs := md.ResourceMetrics().At(i).Resource().Attributes()
+		hostName, ok := attrs.Get("host.name")
+		require.True(t, ok, "missing host.name")
+		require.NotEmpty(t, hostName.Str())
+		osType, ok := attrs.Get("os.type")
+		require.True(t, ok, "missing os.type")
+		require.Equal(t, "linux", osType.Str())
+	}
+}
@@ -0,0 +1,84 @@
+package hostmetrics
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/Showmax/go-fqdn"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/hostmetricsreceiver"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	otelreceiver "go.opentelemetry.io/collector/receiver"
+)
+
+// Resource attributes added to every resource, as done by the system detector
+// of otelcol.processor.resourcedetection.
+const (
+	attributeHostName = "host.name"
+	attributeOSType   = "os.type"
+)
+
+// newFactory returns a factory for the upstream hostmetrics receiver whose
+// receivers add the host.name and os.type resource attributes.
+func newFactory() otelreceiver.Factory {
+	upstream := hostmetricsreceiver.NewFactory()
+
+	return otelreceiver.NewFactory(
+		upstream.Type(),
+		upstream.CreateDefaultConfig,
+		otelreceiver.WithMetrics(func(ctx context.Context, set otelreceiver.Settings, cfg otelcomponent.Config, next consumer.Metrics) (otelreceiver.Metrics, error) {
+			hostName, err := detectHostName()
+			if err != nil {
+				return nil, err
+			}
+			osType := goosToOSType(runtime.GOOS)
+
+			withResource, err := consumer.NewMetrics(func(ctx context.Context, md pmetric.Metrics) error {
+				for _, rm := range md.ResourceMetrics().All() {
+					attrs := rm.Resource().Attributes()
+					if _, ok := attrs.Get(attributeHostName); !ok {
+						attrs.PutStr(attributeHostName, hostName)
+					}
+					if _, ok := attrs.Get(attributeOSType); !ok {
+						attrs.PutStr(attributeOSType, osType)
+					}
+				}
+				return next.ConsumeMetrics(ctx, md)
+			}, consumer.WithCapabilities(consumer.Capabilities{MutatesData: true}))
+			if err != nil {
+				return nil, err
+			}
+			return upstream.CreateMetrics(ctx, set, cfg, withResource)
+		}, upstream.MetricsStability()),
+	)
+}
+
+// detectHostName returns the host name from the same sources as the default
+// hostname_sources of the system detector: the FQDN of the host, falling back
+// to the host name reported by the OS.
+func detectHostName() (string, error) {
+	if name, err := fqdn.FqdnHostname(); err == nil {
+		return name, nil
+	}
+	name, err := os.Hostname()
+	if err != nil {
+		return "", fmt.Errorf("failed to detect host name: %w", err)
+	}
+	return name, nil
+}
+
+// goosToOSType converts runtime.GOOS to the value of the os.type attribute,
+// which only differs for a few operating systems.
+func goosToOSType(goos string) string {
+	switch goos {
+	case "dragonfly":
+		return "dragonflybsd"
+	case "zos":
+		return "z_os"
+	default:
+		return goos
+	}
+}
@@ -0,0 +1,229 @@
+// Package httpcheck provides an otelcol.receiver.httpcheck component.
+package httpcheck
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/httpcheckreceiver"
+	otelcomponent "go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/pipeline"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/discovery"
+	"github.com/grafana/alloy/internal/component/otelcol"
+	otelcolCfg "github.com/grafana/alloy/internal/component/otelcol/config"
+	"github.com/grafana/alloy/internal/component/otelcol/receiver"
+	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/syntax"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:      "otelcol.receiver.httpcheck",
+		Stability: featuregate.StabilityExperimental,
+		Args:      Arguments{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			fact := httpcheckreceiver.NewFactory()
+			return receiver.New(opts, fact, args.(Arguments))
+		},
+	})
+}
+
+// Arguments configures the otelcol.receiver.httpcheck component.
+type Arguments struct {
+	Controller otelcol.ControllerArguments `alloy:",squash"`
+
+	// Targets are discovered targets to check. The URL of a target is built
+	// from its __scheme__, __address__, __metrics_path__, and __param_*
+	// labels.
+	Targets []discovery.Target `alloy:"targets,attr,optional"`
+
+	// Target holds statically configured targets.
+	Target []TargetArguments `alloy:"target,block,optional"`
+
+	TLS otelcol.TLSClientArguments `alloy:"tls,block,optional"`
+
+	// DebugMetrics configures component internal metrics. Optional.
+	DebugMetrics otelcolCfg.DebugMetricsArguments `alloy:"debug_metrics,block,optional"`
+
+	// Output configures where to send received data. Required.
+	Output *otelcol.ConsumerArguments `alloy:"output,block"`
+}
+
+var (
+	_ receiver.Arguments = Arguments{}
+	_ syntax.Defaulter   = (*Arguments)(nil)
+	_ syntax.Validator   = (*Arguments)(nil)
+)
+
+// SetToDefault implements syntax.Defaulter.
+func (args *Arguments) SetToDefault() {
+	*args = Arguments{}
+	args.Controller.SetToDefault()
+	args.DebugMetrics.SetToDefault()
+}
+
+// Validate implements syntax.Validator.
+func (args *Arguments) Validate() error {
+	if err := args.Controller.Validate(); err != nil {
+		return err
+	}
+
+	// Discovered targets may legitimately be empty for a while, so only
+	// require that some source of targets is configured. The upstream
+	// receiver reports an error on each collection while it has no targets.
+	if len(args.Target) == 0 && args.Targets == nil {
+		return errors.New("at least one target block or the targets argument must be set")
+	}
+
+	cfg, err := args.Convert()
+	if err != nil {
+		return err
+	}
+	for i, t := range cfg.(*httpcheckreceiver.Config).Targets {
+		if i >= len(args.Target) {
+			break
+		}
+		if err := t.Validate(); err != nil {
+			return fmt.Errorf("target %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Convert implements receiver.Arguments. The upstream target configuration is
+// unexported, so targets are created through the same map the upstream
+// receiver reads from YAML, and their client settings are filled in after.
+func (args Arguments) Convert() (otelcomponent.Config, error) {
+	targets := make([]any, 0, len(args.Target)+1)
+	for _, t := range args.Target {
+		targets = append(targets, map[string]any{
+			"endpoint": t.Endpoint,
+			"method":   t.Method,
+		})
+	}
+	if endpoints := discoveredEndpoints(args.Targets); len(endpoints) > 0 {
+		targets = append(targets, map[string]any{
+			"endpoints": endpoints,
+			"method":    http.MethodGet,
+		})
+	}
+
+	cfg := httpcheckreceiver.NewFactory().CreateDefaultConfig().(*httpcheckreceiver.Config)
+	err := confmap.NewFromStringMap(map[string]any{
+		"collection_interval": args.Controller.CollectionInterval,
+		"initial_delay":       args.Controller.InitialDelay,
+		"timeout":             args.Controller.Timeout,
+		"targets":             targets,
+	}).Unmarshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	tls := args.TLS.Convert()
+	for i, t := range cfg.Targets {
+		t.TLS = *tls
+		if i < len(args.Target) {
+			t.Headers = make(map[string]configopaque.String, len(args.Target[i].Headers))
+			for k, v := range args.Target[i].Headers {
+				t.Headers[k] = configopaque.String(v)
+			}
+		}
+	}
+	return cfg, nil
+}
+
+// paramLabelPrefix is the prefix of the labels holding the URL parameters of
+// a discovered target.
+const paramLabelPrefix = "__param_"
+
+// discoveredEndpoints returns the URLs to check for discovered targets. Like
+// prometheus.scrape, the URL is built from the __scheme__, __address__,
+// __metrics_path__, and __param_<name> labels, but __metrics_path__ defaults
+// to "/". Targets with no usable address are skipped.
+func discoveredEndpoints(targets []discovery.Target) []string {
+	var res []string
+	for _, t := range targets {
+		address, ok := t.Get("__address__")
+		if !ok || address == "" {
+			continue
+		}
+
+		var u *url.URL
+		if strings.HasPrefix(address, "http://") || strings.HasPrefix(address, "https://") {
+			parsed, err := url.Parse(address)
+			if err != nil {
+				continue
+			}
+			u = parsed
+		} else {
+			scheme, ok := t.Get("__scheme__")
+			if !ok || scheme == "" {
+				scheme = "http"
+			}
+			u = &url.URL{Scheme: scheme, Host: address}
+		}
+
+		if path, ok := t.Get("__metrics_path__"); ok && path != "" {
+			u.Path = path
+		} else if u.Path == "" {
+			u.Path = "/"
+		}
+
+		params := u.Query()
+		t.ForEachLabel(func(key, value string) bool {
+			if name, ok := strings.CutPrefix(key, paramLabelPrefix); ok {
+				params.Set(name, value)
+			}
+			return true
+		})
+		u.RawQuery = params.Encode()
+
+		res = append(res, u.String())
+	}
+	return res
+}
+
+// Extensions implements receiver.Arguments.
+func (args Arguments) Extensions() map[otelcomponent.ID]otelcomponent.Component {
+	return nil
+}
+
+// Exporters implements receiver.Arguments.
+func (args Arguments) Exporters() map[pipeline.Signal]map[otelcomponent.ID]otelcomponent.Component {
+	return nil
+}
+
+// NextConsumers implements receiver.Arguments.
+func (args Arguments) NextConsumers() *otelcol.ConsumerArguments {
+	return args.Output
+}
+
+// DebugMetricsConfig implements receiver.Arguments.
+func (args Arguments) DebugMetricsConfig() otelcolCfg.DebugMetricsArguments {
+	return args.DebugMetrics
+}
+
+// TargetArguments configures a statically defined target.
+type TargetArguments struct {
+	Endpoint string            `alloy:"endpoint,attr"`
+	Method   string            `alloy:"method,attr,optional"`
+	Headers  map[string]string `alloy:"headers,attr,optional"`
+}
+
+// DefaultTargetArguments holds default settings for TargetArguments.
+var DefaultTargetArguments = TargetArguments{
+	Method: http.MethodGet,
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (args *TargetArguments) SetToDefault() {
+	*args = DefaultTargetArguments
+}
@@ -0,0 +1,253 @@
+package httpcheck_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/httpcheckreceiver"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/grafana/alloy/internal/component/discovery"
+	"github.com/grafana/alloy/internal/component/otelcol"
+	"github.com/grafana/alloy/internal/component/otelcol/internal/fakeconsumer"
+	"github.com/grafana/alloy/internal/component/otelcol/receiver/httpcheck"
+	"github.com/grafana/alloy/internal/runtime/componenttest"
+	"github.com/grafana/alloy/internal/util"
+	"github.com/grafana/alloy/syntax"
+)
+
+func TestArguments_UnmarshalAlloy(t *testing.T) {
+	cfg := `
+		collection_interval = "30s"
+		targets             = [
+			{"__address__" = "shop:8080"},
+			{"__address__" = "cart:8443", "__scheme__" = "https"},
+			{"__address__" = "api:9090", "__metrics_path__" = "/healthz", "__param_check" = "deep", "__param_format" = "json"},
+			{"__address__" = "https://auth:8443/ready?full=1", "__param_format" = "json"},
+			{"service" = "no-address"},
+		]
+
+		target {
+			endpoint = "https://example.com/healthz"
+			method   = "HEAD"
+			headers  = {
+				"User-Agent" = "alloy-httpcheck",
+			}
+		}
+
+		tls {
+			insecure_skip_verify = true
+		}
+
+		output {}
+	`
+	var args httpcheck.Arguments
+	require.NoError(t, syntax.Unmarshal([]byte(cfg), &args))
+
+	actualPtr, err := args.Convert()
+	require.NoError(t, err)
+	actual := actualPtr.(*httpcheckreceiver.Config)
+
+	require.Equal(t, 30*time.Second, actual.CollectionInterval)
+	require.Len(t, actual.Targets, 2)
+
+	static := actual.Targets[0]
+	require.Equal(t, "https://example.com/healthz", static.Endpoint)
+	require.Equal(t, http.MethodHead, static.Method)
+	require.Equal(t, map[string]configopaque.String{"User-Agent": "alloy-httpcheck"}, static.Headers)
+	require.True(t, static.TLS.InsecureSkipVerify)
+
+	discovered := actual.Targets[1]
+	require.Equal(t, []string{
+		"http://shop:8080/",
+		"https://cart:8443/",
+		"http://api:9090/healthz?check=deep&format=json",
+		"https://auth:8443/ready?format=json&full=1",
+	}, discovered.Endpoints)
+	require.Equal(t, http.MethodGet, discovered.Method)
+	require.True(t, discovered.TLS.InsecureSkipVerify)
+}
+
+func TestArguments_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  string
+		err  string
+	}{
+		{
+			name: "invalid interval",
+			cfg: `
+				collection_interval = "0s"
+				target {
+					endpoint = "http://example.com"
+				}
+				output {}
+			`,
+			err: `"collection_interval": requires positive value`,
+		},
+		{
+			name: "no targets",
+			cfg: `
+				output {}
+			`,
+			err: "at least one target block or the targets argument must be set",
+		},
+		{
+			name: "invalid endpoint",
+			cfg: `
+				target {
+					endpoint = "example.com"
+				}
+				output {}
+			`,
+			err: `target 0: "endpoint" must be in the form of <scheme>://<hostname>[:<port>]`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var args httpcheck.Arguments
+			require.ErrorContains(t, syntax.Unmarshal([]byte(tc.cfg), &args), tc.err)
+		})
+	}
+}
+
+func TestArguments_EmptyDiscoveredTargets(t *testing.T) {
+	var args httpcheck.Arguments
+	require.NoError(t, syntax.Unmarshal([]byte(`
+		targets = []
+		output {}
+	`), &args))
+}
+
+func Test(t *testing.T) {
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Check") != "alloy" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ok.Close()
+
+	secure := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer secure.Close()
+
+	discovered := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" || r.URL.Query().Get("check") != "deep" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer discovered.Close()
+
+	ctx := componenttest.TestContext(t)
+	ctrl, err := componenttest.NewControllerFromID(util.TestLogger(t), "otelcol.receiver.httpcheck")
+	require.NoError(t, err)
+
+	cfg := fmt.Sprintf(`
+		collection_interval = "1s"
+		initial_delay       = "0s"
+
+		target {
+			endpoint = %q
+			headers  = {
+				"X-Check" = "alloy",
+			}
+		}
+		target {
+			endpoint = %q
+			method   = "HEAD"
+		}
+		target {
+			endpoint = "http://127.0.0.1:1"
+		}
+
+		tls {
+			insecure_skip_verify = true
+		}
+
+		output {
+			// no-op: will be overridden by test code.
+		}
+	`, ok.URL, secure.URL)
+	var args httpcheck.Arguments
+	require.NoError(t, syntax.Unmarshal([]byte(cfg), &args))
+
+	args.Targets = []discovery.Target{discovery.NewTargetFromMap(map[string]string{
+		"__address__":      strings.TrimPrefix(discovered.URL, "http://"),
+		"__metrics_path__": "/healthz",
+		"__param_check":    "deep",
+	})}
+
+	metricCh := make(chan pmetric.Metrics)
+	args.Output = &otelcol.ConsumerArguments{
+		Metrics: []otelcol.Consumer{&fakeconsumer.Consumer{
+			ConsumeMetricsFunc: func(ctx context.Context, md pmetric.Metrics) error {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case metricCh <- md:
+					return nil
+				}
+			},
+		}},
+	}
+
+	go func() {
+		require.NoError(t, ctrl.Run(ctx, args))
+	}()
+
+	var md pmetric.Metrics
+	select {
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "failed waiting for metrics")
+	case md = <-metricCh:
+	}
+
+	metrics := make(map[string]pmetric.Metric)
+	ms := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	for i := 0; i < ms.Len(); i++ {
+		metrics[ms.At(i).Name()] = ms.At(i)
+	}
+	require.Contains(t, metrics, "httpcheck.status")
+	require.Contains(t, metrics, "httpcheck.duration")
+	require.Contains(t, metrics, "httpcheck.error")
+
+	// Collect the status class reported for every URL.
+	classes := make(map[string]string)
+	dps := metrics["httpcheck.status"].Sum().DataPoints()
+	for i := 0; i < dps.Len(); i++ {
+		dp := dps.At(i)
+		if dp.IntValue() != 1 {
+			continue
+		}
+		attrs := dp.Attributes().AsRaw()
+		classes[attrs["http.url"].(string)] = attrs["http.status_class"].(string)
+		if attrs["http.url"] == secure.URL {
+			require.Equal(t, "HEAD", attrs["http.method"])
+		}
+	}
+	require.Equal(t, map[string]string{
+		ok.URL:                                 "2xx",
+		secure.URL:                             "5xx",
+		discovered.URL + "/healthz?check=deep": "2xx",
+	}, classes)
+
+	require.Equal(t, 4, metrics["httpcheck.duration"].Gauge().DataPoints().Len())
+
+	errs := metrics["httpcheck.error"].Sum().DataPoints()
+	require.Equal(t, 1, errs.Len())
+	require.Equal(t, "http://127.0.0.1:1", errs.At(0).Attributes().AsRaw()["http.url"])
+	require.NotEmpty(t, errs.At(0).Attributes().AsRaw()["error.message"])
+}
@@ -0,0 +1,312 @@
+// Package otlp_file provides an otelcol.receiver.otlp_file component.
+package otlp_file
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"golang.org/x/time/rate"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/otelcol"
+	"github.com/grafana/alloy/internal/component/otelcol/internal/otlpfile"
+	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/internal/service/livedebugging"
+	"github.com/grafana/alloy/syntax"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:      "otelcol.receiver.otlp_file",
+		Stability: featuregate.StabilityExperimental,
+		Args:      Arguments{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return New(opts, args.(Arguments))
+		},
+	})
+}
+
+// Supported values for Arguments.Timestamps.
+const (
+	timestampsOriginal = "original"
+	timestampsRebase   = "rebase"
+)
+
+// Arguments configures the otelcol.receiver.otlp_file component.
+type Arguments struct {
+	Include     []string `alloy:"include,attr"`
+	Format      string   `alloy:"format,attr,optional"`
+	Compression string   `alloy:"compression,attr,optional"`
+
+	// Signal is the signal held by the files. It's required with the proto
+	// format, which doesn't record the signal of each request.
+	Signal string `alloy:"signal,attr,optional"`
+
+	// Timestamps controls whether timestamps are replayed as recorded or
+	// shifted so that the recording starts when the replay starts.
+	Timestamps string `alloy:"timestamps,attr,optional"`
+
+	// RateLimit is the maximum number of batches emitted per second. Zero
+	// means no limit.
+	RateLimit float64 `alloy:"rate_limit,attr,optional"`
+
+	// Loop replays the files again once all of them have been replayed.
+	Loop bool `alloy:"loop,attr,optional"`
+
+	// Output configures where to send received data. Required.
+	Output *otelcol.ConsumerArguments `alloy:"output,block"`
+}
+
+var (
+	_ syntax.Defaulter = (*Arguments)(nil)
+	_ syntax.Validator = (*Arguments)(nil)
+)
+
+// DefaultArguments holds default settings for Arguments.
+var DefaultArguments = Arguments{
+	Format:      otlpfile.FormatJSON,
+	Compression: otlpfile.CompressionNone,
+	Timestamps:  timestampsOriginal,
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (args *Arguments) SetToDefault() {
+	*args = DefaultArguments
+}
+
+// Validate implements syntax.Validator.
+func (args *Arguments) Validate() error {
+	if len(args.Include) == 0 {
+		return errors.New("include must not be empty")
+	}
+	for _, pattern := range args.Include {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid include pattern %q: %w", pattern, err)
+		}
+	}
+	if err := otlpfile.ValidateFormat(args.Format); err != nil {
+		return err
+	}
+	if err := otlpfile.ValidateCompression(args.Compression); err != nil {
+		return err
+	}
+	switch {
+	case args.Format == otlpfile.FormatProto && args.Signal == "":
+		return fmt.Errorf("signal must be set when format is %q", otlpfile.FormatProto)
+	case args.Format != otlpfile.FormatProto && args.Signal != "":
+		return fmt.Errorf("signal can only be set when format is %q", otlpfile.FormatProto)
+	case args.Signal != "":
+		if _, err := otlpfile.ParseSignal(args.Signal); err != nil {
+			return err
+		}
+	}
+	switch args.Timestamps {
+	case timestampsOriginal, timestampsRebase:
+	default:
+		return fmt.Errorf("unsupported timestamps %q, must be one of %q or %q", args.Timestamps, timestampsOriginal, timestampsRebase)
+	}
+	if args.RateLimit < 0 {
+		return errors.New("rate_limit must not be negative")
+	}
+	return nil
+}
+
+// replayChanged reports whether the arguments which control the replay
+// differ between a and b.
+func replayChanged(a, b Arguments) bool {
+	a.Output, b.Output = nil, nil
+	return !reflect.DeepEqual(a, b)
+}
+
+// Component is the otelcol.receiver.otlp_file component.
+type Component struct {
+	log                log.Logger
+	opts               component.Options
+	debugDataPublisher livedebugging.DebugDataPublisher
+
+	mut     sync.RWMutex
+	args    Arguments
+	sink    *sink
+	restart chan struct{}
+}
+
+var (
+	_ component.Component     = (*Component)(nil)
+	_ component.LiveDebugging = (*Component)(nil)
+)
+
+// New creates a new otelcol.receiver.otlp_file component.
+func New(opts component.Options, args Arguments) (*Component, error) {
+	debugDataPublisher, err := opts.GetServiceData(livedebugging.ServiceName)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Component{
+		log:                opts.Logger,
+		opts:               opts,
+		debugDataPublisher: debugDataPublisher.(livedebugging.DebugDataPublisher),
+		restart:            make(chan struct{}, 1),
+	}
+	if err := c.Update(args); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Run implements component.Component. The files are replayed from the start
+// every time the replay arguments change.
+func (c *Component) Run(ctx context.Context) error {
+	for {
+		replayCtx, cancel := context.WithCancel(ctx)
+
+		c.mut.RLock()
+		args := c.args
+		c.mut.RUnlock()
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.replay(replayCtx, args)
+		}()
+
+		select {
+		case <-ctx.Done():
+			cancel()
+			wg.Wait()
+			return nil
+		case <-c.restart:
+			cancel()
+			wg.Wait()
+		}
+	}
+}
+
+// Update implements component.Component.
+func (c *Component) Update(args component.Arguments) error {
+	newArgs := args.(Arguments)
+
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	restart := c.sink != nil && replayChanged(c.args, newArgs)
+	c.args = newArgs
+	c.sink = newSink(c.debugDataPublisher, c.opts.ID, newArgs.Output)
+
+	if restart {
+		select {
+		case c.restart <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// LiveDebugging implements component.LiveDebugging.
+func (c *Component) LiveDebugging() {}
+
+// replay replays the files matched by args once, or until ctx is canceled if
+// args.Loop is set.
+func (c *Component) replay(ctx context.Context, args Arguments) {
+	files, err := matchFiles(args.Include)
+	if err != nil {
+		level.Error(c.log).Log("msg", "failed to list files", "err", err)
+		return
+	}
+	if len(files) == 0 {
+		level.Warn(c.log).Log("msg", "no files match the include patterns", "include", fmt.Sprintf("%q", args.Include))
+		return
+	}
+
+	limiter := rate.NewLimiter(rate.Inf, 1)
+	if args.RateLimit > 0 {
+		limiter = rate.NewLimiter(rate.Limit(args.RateLimit), 1)
+	}
+
+	for {
+		r := &replayer{
+			log:     c.log,
+			args:    args,
+			limiter: limiter,
+			sink: func() *sink {
+				c.mut.RLock()
+				defer c.mut.RUnlock()
+				return c.sink
+			},
+		}
+		for _, file := range files {
+			if ctx.Err() != nil {
+				return
+			}
+			r.replayFile(ctx, file)
+		}
+		level.Info(c.log).Log("msg", "replay finished", "files", len(files), "batches", r.batches)
+
+		if !args.Loop || ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// matchFiles returns the sorted, deduplicated files matching patterns.
+// Rotated files written by otelcol.exporter.file sort before the file they
+// were rotated from, so sorting replays them in the order they were written.
+func matchFiles(patterns []string) ([]string, error) {
+	seen := map[string]struct{}{}
+	var files []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matches {
+			if _, ok := seen[m]; ok {
+				continue
+			}
+			seen[m] = struct{}{}
+			files = append(files, m)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// replayer replays files to a sink.
+type replayer struct {
+	log     log.Logger
+	args    Arguments
+	limiter *rate.Limiter
+	sink    func() *sink
+
+	// offset is the shift applied to timestamps when rebasing. It's computed
+	// from the first record holding a timestamp.
+	offset    time.Duration
+	offsetSet bool
+
+	batches int
+}
+
+func (r *replayer) shift(rec otlpfile.Record) {
+	if r.args.Timestamps != timestampsRebase {
+		return
+	}
+	if !r.offsetSet {
+		earliest := otlpfile.EarliestTimestamp(rec)
+		if earliest == 0 {
+			return
+		}
+		r.offset = time.Since(earliest.AsTime())
+		r.offsetSet = true
+	}
+	otlpfile.ShiftTimestamps(rec, r.offset)
+}
@@ -0,0 +1,233 @@
+package otlp_file_test
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/alloy/internal/component/otelcol"
+	"github.com/grafana/alloy/internal/component/otelcol/internal/fakeconsumer"
+	otlpfilereceiver "github.com/grafana/alloy/internal/component/otelcol/receiver/otlp_file"
+	"github.com/grafana/alloy/internal/runtime/componenttest"
+	"github.com/grafana/alloy/internal/util"
+	"github.com/grafana/alloy/syntax"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func TestArguments_Validate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  string
+		err  string
+	}{
+		{
+			name: "invalid compression",
+			cfg: `
+				include     = ["/tmp/*.jsonl"]
+				compression = "gzip"
+				output {}
+			`,
+			err: `unsupported compression "gzip"`,
+		},
+		{
+			name: "invalid format",
+			cfg: `
+				include = ["/tmp/*.jsonl"]
+				format  = "text"
+				output {}
+			`,
+			err: `unsupported format "text"`,
+		},
+		{
+			name: "proto without signal",
+			cfg: `
+				include = ["/tmp/*.bin"]
+				format  = "proto"
+				output {}
+			`,
+			err: `signal must be set when format is "proto"`,
+		},
+		{
+			name: "signal with json",
+			cfg: `
+				include = ["/tmp/*.jsonl"]
+				signal  = "traces"
+				output {}
+			`,
+			err: `signal can only be set when format is "proto"`,
+		},
+		{
+			name: "invalid signal",
+			cfg: `
+				include = ["/tmp/*.bin"]
+				format  = "proto"
+				signal  = "profiles"
+				output {}
+			`,
+			err: `unsupported signal "profiles"`,
+		},
+		{
+			name: "invalid timestamps",
+			cfg: `
+				include    = ["/tmp/*.jsonl"]
+				timestamps = "now"
+				output {}
+			`,
+			err: `unsupported timestamps "now"`,
+		},
+		{
+			name: "invalid pattern",
+			cfg: `
+				include = ["/tmp/[.jsonl"]
+				output {}
+			`,
+			err: `invalid include pattern "/tmp/[.jsonl"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var args otlpfilereceiver.Arguments
+			require.ErrorContains(t, syntax.Unmarshal([]byte(tc.cfg), &args), tc.err)
+		})
+	}
+}
+
+// Test replays a recorded file and ensures that timestamps are rebased while
+// preserving the timing between batches.
+func Test(t *testing.T) {
+	dir := t.TempDir()
+	recorded := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	writeTraces(t, filepath.Join(dir, "traces.jsonl"), "json", recorded, recorded.Add(time.Minute))
+
+	ctx := componenttest.TestContext(t)
+	ctrl, err := componenttest.NewControllerFromID(util.TestLogger(t), "otelcol.receiver.otlp_file")
+	require.NoError(t, err)
+
+	cfg := fmt.Sprintf(`
+		include    = [%q]
+		timestamps = "rebase"
+		rate_limit = 100
+
+		output {
+			// no-op: will be overridden by test code.
+		}
+	`, filepath.Join(dir, "*.jsonl"))
+
+	var args otlpfilereceiver.Arguments
+	require.NoError(t, syntax.Unmarshal([]byte(cfg), &args))
+
+	traceCh := make(chan ptrace.Traces)
+	args.Output = &otelcol.ConsumerArguments{
+		Traces: []otelcol.Consumer{&fakeconsumer.Consumer{
+			ConsumeTracesFunc: func(ctx context.Context, td ptrace.Traces) error {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case traceCh <- td:
+					return nil
+				}
+			},
+		}},
+	}
+
+	start := time.Now()
+	go func() {
+		require.NoError(t, ctrl.Run(ctx, args))
+	}()
+
+	var starts []time.Time
+	for i := 0; i < 2; i++ {
+		select {
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "failed waiting for traces")
+		case td := <-traceCh:
+			starts = append(starts, td.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).StartTimestamp().AsTime())
+		}
+	}
+
+	require.WithinDuration(t, start, starts[0], 5*time.Second)
+	require.Equal(t, time.Minute, starts[1].Sub(starts[0]))
+}
+
+// TestProto replays a file recorded with the proto format.
+func TestProto(t *testing.T) {
+	dir := t.TempDir()
+	recorded := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	writeTraces(t, filepath.Join(dir, "traces.bin"), "proto", recorded, recorded.Add(time.Minute))
+
+	ctx := componenttest.TestContext(t)
+	ctrl, err := componenttest.NewControllerFromID(util.TestLogger(t), "otelcol.receiver.otlp_file")
+	require.NoError(t, err)
+
+	cfg := fmt.Sprintf(`
+		include = [%q]
+		format  = "proto"
+		signal  = "traces"
+
+		output {
+			// no-op: will be overridden by test code.
+		}
+	`, filepath.Join(dir, "*.bin"))
+
+	var args otlpfilereceiver.Arguments
+	require.NoError(t, syntax.Unmarshal([]byte(cfg), &args))
+
+	traceCh := make(chan ptrace.Traces)
+	args.Output = &otelcol.ConsumerArguments{
+		Traces: []otelcol.Consumer{&fakeconsumer.Consumer{
+			ConsumeTracesFunc: func(ctx context.Context, td ptrace.Traces) error {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case traceCh <- td:
+					return nil
+				}
+			},
+		}},
+	}
+
+	go func() {
+		require.NoError(t, ctrl.Run(ctx, args))
+	}()
+
+	for _, expected := range []time.Time{recorded, recorded.Add(time.Minute)} {
+		select {
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "failed waiting for traces")
+		case td := <-traceCh:
+			require.Equal(t, expected, td.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).StartTimestamp().AsTime())
+		}
+	}
+}
+
+func writeTraces(t *testing.T, path, format string, timestamps ...time.Time) {
+	t.Helper()
+
+	var data []byte
+	for _, ts := range timestamps {
+		td := ptrace.NewTraces()
+		span := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+		span.SetStartTimestamp(pcommon.NewTimestampFromTime(ts))
+		span.SetEndTimestamp(pcommon.NewTimestampFromTime(ts.Add(time.Second)))
+
+		// This is how otelcol.exporter.file writes uncompressed records.
+		if format == "proto" {
+			record, err := (&ptrace.ProtoMarshaler{}).MarshalTraces(td)
+			require.NoError(t, err)
+			data = binary.BigEndian.AppendUint32(data, uint32(len(record)))
+			data = append(data, record...)
+			continue
+		}
+		record, err := (&ptrace.JSONMarshaler{}).MarshalTraces(td)
+		require.NoError(t, err)
+		data = append(append(data, record...), '\n')
+	}
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+}
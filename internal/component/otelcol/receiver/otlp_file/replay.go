@@ -0,0 +1,96 @@
+package otlp_file
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+
+	otelconsumer "go.opentelemetry.io/collector/consumer"
+
+	"github.com/grafana/alloy/internal/component/otelcol"
+	"github.com/grafana/alloy/internal/component/otelcol/internal/fanoutconsumer"
+	"github.com/grafana/alloy/internal/component/otelcol/internal/livedebuggingpublisher"
+	"github.com/grafana/alloy/internal/component/otelcol/internal/otlpfile"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/internal/service/livedebugging"
+)
+
+// replayFile replays a single file. Errors are logged; a corrupted file is
+// replayed up to the first invalid record.
+func (r *replayer) replayFile(ctx context.Context, path string) {
+	f, err := os.Open(path)
+	if err != nil {
+		level.Error(r.log).Log("msg", "failed to open file", "path", path, "err", err)
+		return
+	}
+	defer f.Close()
+
+	// The signal was checked by Validate, and is ignored for JSON files.
+	signal, _ := otlpfile.ParseSignal(r.args.Signal)
+	dec, err := otlpfile.NewDecoder(f, r.args.Format, r.args.Compression, signal)
+	if err != nil {
+		level.Error(r.log).Log("msg", "failed to read file", "path", path, "err", err)
+		return
+	}
+	defer dec.Close()
+
+	level.Debug(r.log).Log("msg", "replaying file", "path", path)
+	for {
+		rec, err := dec.Next()
+		if errors.Is(err, io.EOF) {
+			return
+		} else if err != nil {
+			level.Error(r.log).Log("msg", "failed to decode record, skipping the rest of the file", "path", path, "err", err)
+			return
+		}
+
+		if err := r.limiter.Wait(ctx); err != nil {
+			return
+		}
+		r.shift(rec)
+
+		if err := r.sink().consume(ctx, rec); err != nil {
+			level.Error(r.log).Log("msg", "failed to consume replayed record", "path", path, "err", err)
+		}
+		r.batches++
+	}
+}
+
+// sink sends replayed records to the consumers of the component.
+type sink struct {
+	debugDataPublisher livedebugging.DebugDataPublisher
+	componentID        string
+	output             *otelcol.ConsumerArguments
+
+	traces  otelconsumer.Traces
+	metrics otelconsumer.Metrics
+	logs    otelconsumer.Logs
+}
+
+func newSink(debugDataPublisher livedebugging.DebugDataPublisher, componentID string, output *otelcol.ConsumerArguments) *sink {
+	return &sink{
+		debugDataPublisher: debugDataPublisher,
+		componentID:        componentID,
+		output:             output,
+
+		traces:  fanoutconsumer.Traces(output.Traces),
+		metrics: fanoutconsumer.Metrics(output.Metrics),
+		logs:    fanoutconsumer.Logs(output.Logs),
+	}
+}
+
+func (s *sink) consume(ctx context.Context, rec otlpfile.Record) error {
+	switch rec.Signal {
+	case otlpfile.SignalTraces:
+		livedebuggingpublisher.PublishTracesIfActive(s.debugDataPublisher, s.componentID, rec.Traces, otelcol.GetComponentMetadata(s.output.Traces))
+		return s.traces.ConsumeTraces(ctx, rec.Traces)
+	case otlpfile.SignalMetrics:
+		livedebuggingpublisher.PublishMetricsIfActive(s.debugDataPublisher, s.componentID, rec.Metrics, otelcol.GetComponentMetadata(s.output.Metrics))
+		return s.metrics.ConsumeMetrics(ctx, rec.Metrics)
+	case otlpfile.SignalLogs:
+		livedebuggingpublisher.PublishLogsIfActive(s.debugDataPublisher, s.componentID, rec.Logs, otelcol.GetComponentMetadata(s.output.Logs))
+		return s.logs.ConsumeLogs(ctx, rec.Logs)
+	}
+	return nil
+}
@@ -11,6 +11,7 @@ import (
 	yaceModel "github.com/prometheus-community/yet-another-cloudwatch-exporter/pkg/model"
 
 	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/internal/service/cluster"
 	"github.com/grafana/alloy/internal/static/integrations/cloudwatch_exporter"
 	"github.com/grafana/alloy/syntax"
 )
@@ -40,6 +41,12 @@ type Arguments struct {
 	CustomNamespace       []CustomNamespaceJob  `alloy:"custom_namespace,block,optional"`
 	DecoupledScrape       DecoupledScrapeConfig `alloy:"decoupled_scraping,block,optional"`
 	UseAWSSDKVersion2     bool                  `alloy:"aws_sdk_version_v2,attr,optional"`
+	Clustering            *cluster.LeaderBlock  `alloy:"clustering,block,optional"`
+}
+
+// LeaderOnly implements exporter.LeaderOnlyArguments.
+func (a Arguments) LeaderOnly() bool {
+	return a.Clustering != nil && a.Clustering.LeaderOnly
 }
 
 // DecoupledScrapeConfig is the configuration for decoupled scraping feature.
@@ -343,6 +350,9 @@ func toYACECustomNamespaceJob(cn CustomNamespaceJob) *yaceConf.CustomNamespace {
 
 // getHash calculates the MD5 hash of the Alloy representation of the config.
 func getHash(a Arguments) string {
+	// Clustering settings don't change the metrics of the exporter, so they
+	// don't change the instance label.
+	a.Clustering = nil
 	bytes, err := syntax.Marshal(a)
 	if err != nil {
 		return "<unknown>"
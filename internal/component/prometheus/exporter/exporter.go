@@ -14,6 +14,7 @@ import (
 	"github.com/grafana/alloy/internal/component"
 	"github.com/grafana/alloy/internal/component/discovery"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/internal/service/cluster"
 	http_service "github.com/grafana/alloy/internal/service/http"
 	"github.com/grafana/alloy/internal/static/integrations"
 )
@@ -26,6 +27,12 @@ type Exports struct {
 	Targets []discovery.Target `alloy:"targets,attr"`
 }
 
+// LeaderOnlyArguments is implemented by the arguments of exporters which can
+// be configured to only run on the leader of the cluster.
+type LeaderOnlyArguments interface {
+	LeaderOnly() bool
+}
+
 type Component struct {
 	opts component.Options
 
@@ -39,8 +46,12 @@ type Component struct {
 
 	exporter       integrations.Integration
 	metricsHandler http.Handler
+	targets        []discovery.Target
+	leadership     *cluster.Leadership // Set if the exporter only runs on the leader.
 }
 
+var _ cluster.LeaderComponent = (*Component)(nil)
+
 // New creates a new exporter component.
 func New(creator Creator, name string) func(component.Options, component.Arguments) (component.Component, error) {
 	return newExporter(creator, name, nil)
@@ -74,7 +85,12 @@ func (c *Component) Run(ctx context.Context) error {
 			c.mut.Lock()
 			exporter := c.exporter
 			c.metricsHandler = c.getHttpHandler(exporter)
+			leading := c.leading()
 			c.mut.Unlock()
+			if !leading {
+				// Another instance runs the exporter.
+				continue
+			}
 			go func() {
 				if err := exporter.Run(newCtx); err != nil && err != context.Canceled {
 					level.Error(c.opts.Logger).Log("msg", "error running exporter", "err", err)
@@ -104,10 +120,25 @@ func (c *Component) Update(args component.Arguments) error {
 	} else {
 		targets = c.targetBuilderFunc(c.baseTarget, args)
 	}
+	c.targets = targets
 
-	c.opts.OnStateChange(Exports{
-		Targets: targets,
-	})
+	if a, ok := args.(LeaderOnlyArguments); ok && a.LeaderOnly() {
+		if c.leadership == nil {
+			leadership, err := cluster.NewComponentLeadership(c.opts)
+			if err != nil {
+				c.mut.Unlock()
+				return err
+			}
+			if _, err := leadership.Update(); err != nil {
+				level.Error(c.opts.Logger).Log("msg", "checking leadership failed", "err", err)
+			}
+			c.leadership = leadership
+		}
+	} else {
+		c.leadership = nil
+	}
+
+	c.exportTargets()
 	c.mut.Unlock()
 	select {
 	case c.reload <- struct{}{}:
@@ -116,6 +147,55 @@ func (c *Component) Update(args component.Arguments) error {
 	return err
 }
 
+// NotifyClusterChange implements [cluster.Component].
+func (c *Component) NotifyClusterChange() {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if c.leadership == nil {
+		return
+	}
+	changed, err := c.leadership.Update()
+	if err != nil {
+		level.Error(c.opts.Logger).Log("msg", "checking leadership failed", "err", err)
+		return
+	}
+	if !changed {
+		return
+	}
+
+	c.exportTargets()
+	select {
+	case c.reload <- struct{}{}:
+	default:
+	}
+}
+
+// Leadership implements [cluster.LeaderComponent].
+func (c *Component) Leadership() *cluster.Leadership {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.leadership
+}
+
+// leading returns true if the exporter runs on this instance. c.mut must be
+// held by the caller.
+func (c *Component) leading() bool {
+	return c.leadership == nil || c.leadership.IsLeader()
+}
+
+// exportTargets exports the targets of the exporter, or no targets if another
+// instance runs the exporter. c.mut must be held by the caller.
+func (c *Component) exportTargets() {
+	targets := c.targets
+	if !c.leading() {
+		targets = []discovery.Target{}
+	}
+	c.opts.OnStateChange(Exports{
+		Targets: targets,
+	})
+}
+
 // Handler serves metrics endpoint from the integration implementation.
 func (c *Component) Handler() http.Handler {
 	c.mut.Lock()
@@ -0,0 +1,349 @@
+package json
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/ohler55/ojg/jp"
+	"github.com/ohler55/ojg/oj"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	common_config "github.com/prometheus/common/config"
+
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/internal/static/integrations"
+	integrations_config "github.com/grafana/alloy/internal/static/integrations/config"
+)
+
+var (
+	scrapeSuccessDesc = prometheus.NewDesc(
+		"json_scrape_success",
+		"Whether fetching and parsing the target succeeded.",
+		nil, nil,
+	)
+	scrapeDurationDesc = prometheus.NewDesc(
+		"json_scrape_duration_seconds",
+		"Duration of fetching and parsing the target.",
+		nil, nil,
+	)
+)
+
+// integration fetches JSON documents from targets on demand and converts them
+// into metrics.
+type integration struct {
+	logger  log.Logger
+	modules map[string]*module
+}
+
+var _ integrations.Integration = (*integration)(nil)
+
+type module struct {
+	cfg     Module
+	client  *http.Client
+	metrics []*compiledMetric
+}
+
+type compiledMetric struct {
+	cfg         Metric
+	desc        *prometheus.Desc
+	path        jp.Expr
+	valuePath   jp.Expr
+	labelNames  []string
+	labelPaths  []jp.Expr
+	metricValue prometheus.ValueType
+}
+
+func newIntegration(logger log.Logger, args Arguments) (*integration, error) {
+	i := &integration{
+		logger:  logger,
+		modules: make(map[string]*module, len(args.Modules)),
+	}
+
+	for _, m := range args.Modules {
+		client, err := common_config.NewClientFromConfig(*m.HTTPClientConfig.Convert(), "json_exporter")
+		if err != nil {
+			return nil, fmt.Errorf("module %q: failed to create HTTP client: %w", m.Name, err)
+		}
+
+		mod := &module{cfg: m, client: client}
+		for _, metric := range m.Metrics {
+			cm, err := compileMetric(metric)
+			if err != nil {
+				return nil, fmt.Errorf("module %q: metric %q: %w", m.Name, metric.Name, err)
+			}
+			mod.metrics = append(mod.metrics, cm)
+		}
+		i.modules[m.Name] = mod
+	}
+	return i, nil
+}
+
+func compileMetric(m Metric) (*compiledMetric, error) {
+	var err error
+	cm := &compiledMetric{cfg: m, metricValue: prometheus.GaugeValue}
+	if m.Type == MetricTypeCounter {
+		cm.metricValue = prometheus.CounterValue
+	}
+
+	if cm.path, err = jp.ParseString(m.Path); err != nil {
+		return nil, err
+	}
+	if m.ValuePath != "" {
+		if cm.valuePath, err = jp.ParseString(m.ValuePath); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(m.Labels)) {
+		x, err := jp.ParseString(m.Labels[name])
+		if err != nil {
+			return nil, err
+		}
+		cm.labelNames = append(cm.labelNames, name)
+		cm.labelPaths = append(cm.labelPaths, x)
+	}
+
+	cm.desc = prometheus.NewDesc(m.Name, m.Help, cm.labelNames, m.StaticLabels)
+	return cm, nil
+}
+
+// MetricsHandler implements Integration. The handler expects the URL of the
+// document to fetch in the target query parameter, and the name of the module
+// to use in the module query parameter.
+func (i *integration) MetricsHandler() (http.Handler, error) {
+	return http.HandlerFunc(i.handle), nil
+}
+
+func (i *integration) handle(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	target := params.Get("target")
+	if target == "" {
+		http.Error(w, "target parameter is missing", http.StatusBadRequest)
+		return
+	}
+
+	moduleName := params.Get("module")
+	if moduleName == "" && len(i.modules) == 1 {
+		for name := range i.modules {
+			moduleName = name
+		}
+	}
+	mod, ok := i.modules[moduleName]
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown module %q", moduleName), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), mod.cfg.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	metrics, duplicates, err := mod.scrape(ctx, target)
+	duration := time.Since(start)
+
+	success := 1.0
+	if err != nil {
+		level.Error(i.logger).Log("msg", "failed to scrape JSON target", "target", target, "module", moduleName, "err", err)
+		success = 0
+	}
+	if duplicates > 0 {
+		level.Warn(i.logger).Log("msg", "dropped samples with duplicate label sets", "target", target, "module", moduleName, "count", duplicates)
+	}
+	metrics = append(metrics,
+		prometheus.MustNewConstMetric(scrapeSuccessDesc, prometheus.GaugeValue, success),
+		prometheus.MustNewConstMetric(scrapeDurationDesc, prometheus.GaugeValue, duration.Seconds()),
+	)
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(metricsCollector(metrics))
+	promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+}
+
+// Run satisfies Integration.Run.
+func (i *integration) Run(ctx context.Context) error {
+	// We don't need to do anything here, so we can just wait for the context to
+	// finish.
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// ScrapeConfigs satisfies Integration.ScrapeConfigs.
+func (i *integration) ScrapeConfigs() []integrations_config.ScrapeConfig {
+	// Targets are built by the component.
+	return nil
+}
+
+// scrape fetches target and converts it into metrics. Samples which repeat
+// the name and labels of an earlier sample are dropped, as exposing them would
+// fail the whole scrape. scrape returns the number of dropped samples.
+func (m *module) scrape(ctx context.Context, target string) ([]prometheus.Metric, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, 0, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+	doc, err := oj.ParseString(string(body))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	var (
+		metrics    []prometheus.Metric
+		duplicates int
+		seen       = make(map[string]struct{})
+	)
+	for _, cm := range m.metrics {
+		ms, dropped, err := cm.extract(doc, seen)
+		if err != nil {
+			return nil, 0, fmt.Errorf("metric %q: %w", cm.cfg.Name, err)
+		}
+		metrics = append(metrics, ms...)
+		duplicates += dropped
+	}
+	return metrics, duplicates, nil
+}
+
+// extract evaluates the metric against a JSON document. Every result of path
+// produces a sample. If value_path is set, the results of path are objects,
+// and value_path and the label paths are evaluated relative to each object.
+// Samples whose series is already in seen are dropped and counted.
+func (cm *compiledMetric) extract(doc any, seen map[string]struct{}) ([]prometheus.Metric, int, error) {
+	var (
+		metrics    []prometheus.Metric
+		duplicates int
+	)
+	for _, result := range cm.path.Get(doc) {
+		raw := result
+		if cm.valuePath != nil {
+			raw = first(cm.valuePath.Get(result))
+		}
+
+		value, err := cm.toFloat(raw)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		labelValues := make([]string, 0, len(cm.labelPaths))
+		for _, x := range cm.labelPaths {
+			labelValues = append(labelValues, toString(first(x.Get(result))))
+		}
+
+		key := cm.seriesKey(labelValues)
+		if _, ok := seen[key]; ok {
+			duplicates++
+			continue
+		}
+		seen[key] = struct{}{}
+
+		metric, err := prometheus.NewConstMetric(cm.desc, cm.metricValue, value, labelValues...)
+		if err != nil {
+			return nil, 0, err
+		}
+		metrics = append(metrics, metric)
+	}
+	return metrics, duplicates, nil
+}
+
+// seriesKey identifies the series of a sample with the given label values.
+func (cm *compiledMetric) seriesKey(labelValues []string) string {
+	labels := maps.Clone(cm.cfg.StaticLabels)
+	if labels == nil {
+		labels = make(map[string]string, len(labelValues))
+	}
+	for i, name := range cm.labelNames {
+		labels[name] = labelValues[i]
+	}
+
+	var sb strings.Builder
+	sb.WriteString(cm.cfg.Name)
+	for _, name := range slices.Sorted(maps.Keys(labels)) {
+		sb.WriteString("\xff" + name + "\xff" + labels[name])
+	}
+	return sb.String()
+}
+
+func (cm *compiledMetric) toFloat(v any) (float64, error) {
+	if mapped, ok := cm.cfg.ValueMapping[toString(v)]; ok {
+		return mapped, nil
+	}
+
+	switch v := v.(type) {
+	case int64:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case string:
+		return strconv.ParseFloat(v, 64)
+	case nil:
+		return 0, fmt.Errorf("value not found")
+	default:
+		return 0, fmt.Errorf("value of type %T can't be converted to a number", v)
+	}
+}
+
+func first(results []any) any {
+	if len(results) == 0 {
+		return nil
+	}
+	return results[0]
+}
+
+func toString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// metricsCollector is a prometheus.Collector which emits a fixed set of
+// metrics.
+type metricsCollector []prometheus.Metric
+
+// Describe is an empty method, which marks the prometheus.Collector as
+// "unchecked".
+func (c metricsCollector) Describe(chan<- *prometheus.Desc) {}
+
+// Collect implements prometheus.Collector.
+func (c metricsCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, m := range c {
+		ch <- m
+	}
+}
@@ -0,0 +1,146 @@
+package json
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/component/common/config"
+)
+
+const statsDocument = `{
+	"status": "degraded",
+	"uptime": 3600,
+	"queues": [
+		{"name": "emails", "size": 12},
+		{"name": "invoices", "size": "3"}
+	]
+}`
+
+func TestIntegration_MetricsHandler(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, statsDocument)
+	}))
+	defer srv.Close()
+
+	i, err := newIntegration(log.NewNopLogger(), Arguments{
+		Modules: []Module{
+			{
+				Name:             "stats",
+				HTTPClientConfig: config.DefaultHTTPClientConfig,
+				Timeout:          5 * time.Second,
+				Metrics: []Metric{
+					{
+						Name:         "app_queue_size",
+						Type:         MetricTypeGauge,
+						Help:         "Number of items in each queue.",
+						Path:         "$.queues[*]",
+						ValuePath:    "$.size",
+						Labels:       map[string]string{"queue": "$.name"},
+						StaticLabels: map[string]string{"source": "json"},
+					},
+					{
+						Name: "app_uptime_seconds_total",
+						Type: MetricTypeCounter,
+						Path: "$.uptime",
+					},
+					{
+						Name:         "app_healthy",
+						Type:         MetricTypeGauge,
+						Path:         "$.status",
+						ValueMapping: map[string]float64{"ok": 1, "degraded": 0.5},
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	body := scrape(t, i, srv.URL, "")
+	require.Contains(t, body, `app_queue_size{queue="emails",source="json"} 12`)
+	require.Contains(t, body, `app_queue_size{queue="invoices",source="json"} 3`)
+	require.Contains(t, body, `app_uptime_seconds_total 3600`)
+	require.Contains(t, body, `app_healthy 0.5`)
+	require.Contains(t, body, `json_scrape_success 1`)
+}
+
+func TestIntegration_MetricsHandler_Failure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	i, err := newIntegration(log.NewNopLogger(), Arguments{
+		Modules: []Module{
+			{
+				Name:             "stats",
+				HTTPClientConfig: config.DefaultHTTPClientConfig,
+				Timeout:          5 * time.Second,
+				Metrics:          []Metric{{Name: "app_up", Type: MetricTypeGauge, Path: "$.up"}},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	body := scrape(t, i, srv.URL, "stats")
+	require.NotContains(t, body, "app_up")
+	require.Contains(t, body, `json_scrape_success 0`)
+}
+
+func TestIntegration_MetricsHandler_DuplicateSeries(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"queues": [{"name": "emails", "size": 12}, {"name": "emails", "size": 7}]}`)
+	}))
+	defer srv.Close()
+
+	i, err := newIntegration(log.NewNopLogger(), Arguments{
+		Modules: []Module{
+			{
+				Name:             "stats",
+				HTTPClientConfig: config.DefaultHTTPClientConfig,
+				Timeout:          5 * time.Second,
+				Metrics: []Metric{
+					{
+						Name:      "app_queue_size",
+						Type:      MetricTypeGauge,
+						Path:      "$.queues[*]",
+						ValuePath: "$.size",
+						Labels:    map[string]string{"queue": "$.name"},
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	// The second queue with the same name is dropped instead of failing the
+	// scrape.
+	body := scrape(t, i, srv.URL, "")
+	require.Contains(t, body, `app_queue_size{queue="emails"} 12`)
+	require.NotContains(t, body, `app_queue_size{queue="emails"} 7`)
+	require.Contains(t, body, `json_scrape_success 1`)
+}
+
+func scrape(t *testing.T, i *integration, target, module string) string {
+	t.Helper()
+
+	h, err := i.MetricsHandler()
+	require.NoError(t, err)
+
+	params := url.Values{"target": []string{target}}
+	if module != "" {
+		params.Set("module", module)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics?"+params.Encode(), nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	return rec.Body.String()
+}
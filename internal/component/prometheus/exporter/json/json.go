@@ -0,0 +1,206 @@
+package json
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ohler55/ojg/jp"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/common/config"
+	"github.com/grafana/alloy/internal/component/discovery"
+	"github.com/grafana/alloy/internal/component/prometheus/exporter"
+	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/internal/static/integrations"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:      "prometheus.exporter.json",
+		Stability: featuregate.StabilityExperimental,
+		Args:      Arguments{},
+		Exports:   exporter.Exports{},
+
+		Build: exporter.NewWithTargetBuilder(createExporter, "json", buildJSONTargets),
+	})
+}
+
+func createExporter(opts component.Options, args component.Arguments, defaultInstanceKey string) (integrations.Integration, string, error) {
+	a := args.(Arguments)
+	i, err := newIntegration(opts.Logger, a)
+	return i, defaultInstanceKey, err
+}
+
+// buildJSONTargets creates one target for each target to scrape. Scraping a
+// target makes the exporter fetch the target URL and convert the JSON
+// response into metrics.
+func buildJSONTargets(baseTarget discovery.Target, args component.Arguments) []discovery.Target {
+	var targets []discovery.Target
+
+	for _, tgt := range args.(Arguments).Targets {
+		address, _ := getAddress(tgt)
+
+		target := make(map[string]string, len(tgt)+baseTarget.Len())
+		// Set extra labels first, meaning that any other labels will override
+		for k, v := range tgt {
+			if k != "name" && k != "address" && k != "module" && k != model.AddressLabel {
+				target[k] = v
+			}
+		}
+		baseTarget.ForEachLabel(func(key string, value string) bool {
+			target[key] = value
+			return true
+		})
+
+		target["job"] = target["job"] + "/" + tgt["name"]
+		target["instance"] = address
+		target["__param_target"] = address
+		if module := tgt["module"]; module != "" {
+			target["__param_module"] = module
+		}
+
+		targets = append(targets, discovery.NewTargetFromMap(target))
+	}
+
+	return targets
+}
+
+// Supported metric types.
+const (
+	MetricTypeCounter = "counter"
+	MetricTypeGauge   = "gauge"
+)
+
+// Arguments controls the json exporter.
+type Arguments struct {
+	Targets []map[string]string `alloy:"targets,attr,optional"`
+	Modules []Module            `alloy:"module,block"`
+}
+
+// DefaultModule holds the default settings for a module.
+var DefaultModule = Module{
+	HTTPClientConfig: config.DefaultHTTPClientConfig,
+	Timeout:          10 * time.Second,
+}
+
+// Module describes how to fetch a target and how to convert its JSON
+// response into metrics.
+type Module struct {
+	Name             string                  `alloy:",label"`
+	HTTPClientConfig config.HTTPClientConfig `alloy:",squash"`
+	Timeout          time.Duration           `alloy:"timeout,attr,optional"`
+	Metrics          []Metric                `alloy:"metric,block"`
+}
+
+// Metric extracts a metric from a JSON document.
+type Metric struct {
+	Name         string             `alloy:"name,attr"`
+	Type         string             `alloy:"type,attr,optional"`
+	Help         string             `alloy:"help,attr,optional"`
+	Path         string             `alloy:"path,attr"`
+	ValuePath    string             `alloy:"value_path,attr,optional"`
+	Labels       map[string]string  `alloy:"labels,attr,optional"`
+	StaticLabels map[string]string  `alloy:"static_labels,attr,optional"`
+	ValueMapping map[string]float64 `alloy:"value_mapping,attr,optional"`
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (m *Module) SetToDefault() {
+	*m = DefaultModule
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (m *Metric) SetToDefault() {
+	*m = Metric{Type: MetricTypeGauge}
+}
+
+// Validate implements syntax.Validator.
+func (a *Arguments) Validate() error {
+	modules := make(map[string]struct{}, len(a.Modules))
+	for _, m := range a.Modules {
+		if _, ok := modules[m.Name]; ok {
+			return fmt.Errorf("module %q is defined more than once", m.Name)
+		}
+		modules[m.Name] = struct{}{}
+	}
+
+	for _, target := range a.Targets {
+		if _, hasName := target["name"]; !hasName {
+			return errors.New("all targets must have a `name`")
+		}
+		if _, hasAddress := getAddress(target); !hasAddress {
+			return errors.New("all targets must have an `address` or an `__address__` label")
+		}
+		module, hasModule := target["module"]
+		if !hasModule && len(a.Modules) > 1 {
+			return fmt.Errorf("target %q must set a `module` when more than one module is defined", target["name"])
+		}
+		if _, ok := modules[module]; hasModule && !ok {
+			return fmt.Errorf("target %q references unknown module %q", target["name"], module)
+		}
+	}
+	return nil
+}
+
+// Validate implements syntax.Validator.
+func (m *Module) Validate() error {
+	if m.Timeout <= 0 {
+		return errors.New("timeout must be positive")
+	}
+	if err := m.HTTPClientConfig.Validate(); err != nil {
+		return err
+	}
+
+	for _, metric := range m.Metrics {
+		if err := metric.validate(); err != nil {
+			return fmt.Errorf("module %q: metric %q: %w", m.Name, metric.Name, err)
+		}
+	}
+	return nil
+}
+
+func (m *Metric) validate() error {
+	if !model.IsValidMetricName(model.LabelValue(m.Name)) {
+		return errors.New("invalid metric name")
+	}
+	if m.Type != MetricTypeGauge && m.Type != MetricTypeCounter {
+		return fmt.Errorf("invalid type %q, must be %q or %q", m.Type, MetricTypeGauge, MetricTypeCounter)
+	}
+	if _, err := jp.ParseString(m.Path); err != nil {
+		return fmt.Errorf("invalid path %q: %w", m.Path, err)
+	}
+	if m.ValuePath != "" {
+		if _, err := jp.ParseString(m.ValuePath); err != nil {
+			return fmt.Errorf("invalid value_path %q: %w", m.ValuePath, err)
+		}
+	}
+	for name, path := range m.Labels {
+		if !model.LabelName(name).IsValid() {
+			return fmt.Errorf("invalid label name %q", name)
+		}
+		if _, err := jp.ParseString(path); err != nil {
+			return fmt.Errorf("invalid path %q for label %q: %w", path, name, err)
+		}
+	}
+	for name := range m.StaticLabels {
+		if !model.LabelName(name).IsValid() {
+			return fmt.Errorf("invalid label name %q", name)
+		}
+		if _, ok := m.Labels[name]; ok {
+			return fmt.Errorf("label %q is defined in both labels and static_labels", name)
+		}
+	}
+	return nil
+}
+
+func getAddress(data map[string]string) (string, bool) {
+	if value, ok := data["address"]; ok {
+		return value, true
+	}
+	if value, ok := data[model.AddressLabel]; ok {
+		return value, true
+	}
+	return "", false
+}
@@ -0,0 +1,190 @@
+package json
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/component/common/config"
+	"github.com/grafana/alloy/internal/component/discovery"
+	"github.com/grafana/alloy/syntax"
+)
+
+func TestAlloyUnmarshal(t *testing.T) {
+	alloyConfig := `
+	targets = [
+		{"name" = "billing", "address" = "http://billing:8080/stats", "team" = "payments"},
+	]
+
+	module "stats" {
+		timeout = "5s"
+
+		metric {
+			name          = "app_queue_size"
+			help          = "Number of items in each queue."
+			path          = "$.queues[*]"
+			value_path    = "$.size"
+			labels        = { "queue" = "$.name" }
+			static_labels = { "source" = "json" }
+		}
+
+		metric {
+			name          = "app_healthy"
+			path          = "$.status"
+			value_mapping = { "ok" = 1, "degraded" = 0.5 }
+		}
+	}
+	`
+
+	var args Arguments
+	require.NoError(t, syntax.Unmarshal([]byte(alloyConfig), &args))
+
+	expected := Arguments{
+		Targets: []map[string]string{
+			{"name": "billing", "address": "http://billing:8080/stats", "team": "payments"},
+		},
+		Modules: []Module{
+			{
+				Name:             "stats",
+				HTTPClientConfig: config.DefaultHTTPClientConfig,
+				Timeout:          5 * time.Second,
+				Metrics: []Metric{
+					{
+						Name:         "app_queue_size",
+						Type:         MetricTypeGauge,
+						Help:         "Number of items in each queue.",
+						Path:         "$.queues[*]",
+						ValuePath:    "$.size",
+						Labels:       map[string]string{"queue": "$.name"},
+						StaticLabels: map[string]string{"source": "json"},
+					},
+					{
+						Name:         "app_healthy",
+						Type:         MetricTypeGauge,
+						Path:         "$.status",
+						ValueMapping: map[string]float64{"ok": 1, "degraded": 0.5},
+					},
+				},
+			},
+		},
+	}
+	require.Equal(t, expected, args)
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      string
+		expectedErr string
+	}{
+		{
+			name:        "no module",
+			config:      `targets = [{"name" = "a", "address" = "http://localhost/stats"}]`,
+			expectedErr: `missing required block "module"`,
+		},
+		{
+			name: "target without name",
+			config: `
+			targets = [{"address" = "http://localhost/stats"}]
+			module "stats" {
+				metric {
+					name = "up"
+					path = "$.up"
+				}
+			}`,
+			expectedErr: "all targets must have a `name`",
+		},
+		{
+			name: "unknown module",
+			config: `
+			targets = [{"name" = "a", "address" = "http://localhost/stats", "module" = "other"}]
+			module "stats" {
+				metric {
+					name = "up"
+					path = "$.up"
+				}
+			}`,
+			expectedErr: `target "a" references unknown module "other"`,
+		},
+		{
+			name: "ambiguous module",
+			config: `
+			targets = [{"name" = "a", "address" = "http://localhost/stats"}]
+			module "one" {
+				metric {
+					name = "up"
+					path = "$.up"
+				}
+			}
+			module "two" {
+				metric {
+					name = "up"
+					path = "$.up"
+				}
+			}`,
+			expectedErr: `target "a" must set a ` + "`module`",
+		},
+		{
+			name: "invalid path",
+			config: `
+			module "stats" {
+				metric {
+					name = "up"
+					path = "$.[["
+				}
+			}`,
+			expectedErr: `invalid path "$.[["`,
+		},
+		{
+			name: "duplicate label",
+			config: `
+			module "stats" {
+				metric {
+					name          = "up"
+					path          = "$.items[*]"
+					value_path    = "$.up"
+					labels        = { "name" = "$.name" }
+					static_labels = { "name" = "fixed" }
+				}
+			}`,
+			expectedErr: `label "name" is defined in both labels and static_labels`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args Arguments
+			err := syntax.Unmarshal([]byte(tt.config), &args)
+			require.ErrorContains(t, err, tt.expectedErr)
+		})
+	}
+}
+
+func TestBuildJSONTargets(t *testing.T) {
+	baseTarget := discovery.NewTargetFromMap(map[string]string{
+		"__address__":      "alloy.internal:12345",
+		"__metrics_path__": "/api/v0/component/prometheus.exporter.json.default/metrics",
+		"job":              "integrations/json",
+		"instance":         "alloy-host",
+	})
+	args := Arguments{
+		Targets: []map[string]string{
+			{"name": "billing", "__address__": "http://billing:8080/stats", "module": "stats", "team": "payments"},
+		},
+	}
+
+	targets := buildJSONTargets(baseTarget, args)
+	require.Len(t, targets, 1)
+
+	expected := discovery.NewTargetFromMap(map[string]string{
+		"__address__":      "alloy.internal:12345",
+		"__metrics_path__": "/api/v0/component/prometheus.exporter.json.default/metrics",
+		"__param_target":   "http://billing:8080/stats",
+		"__param_module":   "stats",
+		"job":              "integrations/json/billing",
+		"instance":         "http://billing:8080/stats",
+		"team":             "payments",
+	})
+	require.Equal(t, expected, targets[0])
+}
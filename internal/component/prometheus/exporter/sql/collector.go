@@ -0,0 +1,309 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+
+	// Register the supported database drivers.
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	_ "github.com/microsoft/go-mssqldb"
+	_ "github.com/sijms/go-ora/v2"
+	_ "github.com/snowflakedb/gosnowflake"
+	_ "modernc.org/sqlite"
+)
+
+const namespace = "sql"
+
+var (
+	queryDurationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "query", "duration_seconds"),
+		"Duration of the last execution of the query.",
+		[]string{"query"}, nil,
+	)
+	queryUpDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "query", "up"),
+		"Whether the last execution of the query succeeded.",
+		[]string{"query"}, nil,
+	)
+	queryLastRunDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "query", "last_run_timestamp_seconds"),
+		"Timestamp of the last execution of the query.",
+		[]string{"query"}, nil,
+	)
+)
+
+// collector runs the configured queries against the database and converts
+// the result rows into metrics. Query results are cached for the interval of
+// each query, so that scraping more often than the interval doesn't put
+// extra load on the database.
+type collector struct {
+	logger  log.Logger
+	db      *sql.DB
+	timeout time.Duration
+	queries []*queryRunner
+	now     func() time.Time
+}
+
+var _ prometheus.Collector = (*collector)(nil)
+
+func newCollector(logger log.Logger, args Arguments) (*collector, error) {
+	db, err := sql.Open(args.Driver, string(args.ConnectionString))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s database: %w", args.Driver, err)
+	}
+	db.SetMaxOpenConns(args.MaxOpenConnections)
+	db.SetMaxIdleConns(args.MaxIdleConnections)
+
+	return newCollectorWithDB(logger, db, args), nil
+}
+
+func newCollectorWithDB(logger log.Logger, db *sql.DB, args Arguments) *collector {
+	c := &collector{
+		logger:  logger,
+		db:      db,
+		timeout: args.Timeout,
+		now:     time.Now,
+	}
+	for _, q := range args.Queries {
+		c.queries = append(c.queries, newQueryRunner(q))
+	}
+	return c
+}
+
+// Run keeps the database handle open until ctx is canceled.
+func (c *collector) Run(ctx context.Context) error {
+	<-ctx.Done()
+	if err := c.db.Close(); err != nil {
+		level.Warn(c.logger).Log("msg", "failed to close database", "err", err)
+	}
+	return ctx.Err()
+}
+
+// Describe implements prometheus.Collector. Metrics of different queries may
+// share a descriptor, which is only sent once.
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- queryDurationDesc
+	ch <- queryUpDesc
+	ch <- queryLastRunDesc
+
+	seen := make(map[string]struct{})
+	for _, q := range c.queries {
+		for _, desc := range q.descs {
+			if _, ok := seen[desc.String()]; ok {
+				continue
+			}
+			seen[desc.String()] = struct{}{}
+			ch <- desc
+		}
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	var wg sync.WaitGroup
+	for _, q := range c.queries {
+		wg.Add(1)
+		go func(q *queryRunner) {
+			defer wg.Done()
+			c.collectQuery(q, ch)
+		}(q)
+	}
+	wg.Wait()
+}
+
+func (c *collector) collectQuery(q *queryRunner, ch chan<- prometheus.Metric) {
+	q.mut.Lock()
+	defer q.mut.Unlock()
+
+	now := c.now()
+	if q.lastRun.IsZero() || now.Sub(q.lastRun) >= q.cfg.Interval {
+		timeout := q.cfg.Timeout
+		if timeout == 0 {
+			timeout = c.timeout
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		metrics, err := q.run(ctx, c.logger, c.db)
+		cancel()
+
+		q.lastRun = now
+		q.lastDuration = c.now().Sub(now)
+		q.lastErr = err
+		if err != nil {
+			level.Error(c.logger).Log("msg", "failed to run query", "query", q.cfg.Name, "err", err)
+			q.cached = nil
+		} else {
+			q.cached = metrics
+		}
+	}
+
+	for _, m := range q.cached {
+		ch <- m
+	}
+
+	up := 1.0
+	if q.lastErr != nil {
+		up = 0
+	}
+	ch <- prometheus.MustNewConstMetric(queryUpDesc, prometheus.GaugeValue, up, q.cfg.Name)
+	ch <- prometheus.MustNewConstMetric(queryDurationDesc, prometheus.GaugeValue, q.lastDuration.Seconds(), q.cfg.Name)
+	ch <- prometheus.MustNewConstMetric(queryLastRunDesc, prometheus.GaugeValue, float64(q.lastRun.UnixNano())/1e9, q.cfg.Name)
+}
+
+// queryRunner holds a query together with the result of its last execution.
+type queryRunner struct {
+	cfg   Query
+	descs []*prometheus.Desc
+
+	mut          sync.Mutex
+	lastRun      time.Time
+	lastDuration time.Duration
+	lastErr      error
+	cached       []prometheus.Metric
+}
+
+func newQueryRunner(cfg Query) *queryRunner {
+	q := &queryRunner{cfg: cfg}
+	for _, m := range cfg.Metrics {
+		q.descs = append(q.descs, prometheus.NewDesc(m.Name, m.Help, m.LabelColumns, m.StaticLabels))
+	}
+	return q
+}
+
+// run executes the query and converts the result rows into metrics. Rows with
+// a NULL value and rows that repeat the label set of an earlier row are
+// skipped with a warning, as they can't be exposed as a metric.
+func (q *queryRunner) run(ctx context.Context, logger log.Logger, db *sql.DB) ([]prometheus.Metric, error) {
+	rows, err := db.QueryContext(ctx, q.cfg.SQL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	columnIndex := make(map[string]int, len(columns))
+	for i, name := range columns {
+		columnIndex[name] = i
+	}
+	for _, m := range q.cfg.Metrics {
+		if _, ok := columnIndex[m.ValueColumn]; !ok {
+			return nil, fmt.Errorf("metric %q: value column %q not found in query result", m.Name, m.ValueColumn)
+		}
+		for _, l := range m.LabelColumns {
+			if _, ok := columnIndex[l]; !ok {
+				return nil, fmt.Errorf("metric %q: label column %q not found in query result", m.Name, l)
+			}
+		}
+	}
+
+	var (
+		metrics []prometheus.Metric
+		values  = make([]any, len(columns))
+		dest    = make([]any, len(columns))
+		seen    = make([]map[string]struct{}, len(q.cfg.Metrics))
+	)
+	for i := range seen {
+		seen[i] = make(map[string]struct{})
+	}
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		for i, m := range q.cfg.Metrics {
+			raw := values[columnIndex[m.ValueColumn]]
+			if raw == nil {
+				level.Warn(logger).Log("msg", "skipping row with NULL value", "query", q.cfg.Name, "metric", m.Name, "column", m.ValueColumn)
+				continue
+			}
+			value, err := toFloat(raw)
+			if err != nil {
+				return nil, fmt.Errorf("metric %q: column %q: %w", m.Name, m.ValueColumn, err)
+			}
+
+			labelValues := make([]string, 0, len(m.LabelColumns))
+			for _, l := range m.LabelColumns {
+				labelValues = append(labelValues, toString(values[columnIndex[l]]))
+			}
+
+			key := strings.Join(labelValues, "\xff")
+			if _, ok := seen[i][key]; ok {
+				level.Warn(logger).Log("msg", "skipping row with duplicate label set", "query", q.cfg.Name, "metric", m.Name, "labels", strings.Join(labelValues, ","))
+				continue
+			}
+			seen[i][key] = struct{}{}
+
+			metric, err := prometheus.NewConstMetric(q.descs[i], valueType(m.Type), value, labelValues...)
+			if err != nil {
+				return nil, fmt.Errorf("metric %q: %w", m.Name, err)
+			}
+			metrics = append(metrics, metric)
+		}
+	}
+	return metrics, rows.Err()
+}
+
+func valueType(metricType string) prometheus.ValueType {
+	if metricType == MetricTypeCounter {
+		return prometheus.CounterValue
+	}
+	return prometheus.GaugeValue
+}
+
+// toFloat converts a value scanned from a database column to a float64.
+func toFloat(v any) (float64, error) {
+	switch v := v.(type) {
+	case nil:
+		return 0, fmt.Errorf("value is NULL")
+	case int64:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case time.Time:
+		return float64(v.UnixNano()) / 1e9, nil
+	case []byte:
+		return strconv.ParseFloat(string(v), 64)
+	case string:
+		return strconv.ParseFloat(v, 64)
+	default:
+		return 0, fmt.Errorf("unsupported column type %T", v)
+	}
+}
+
+// toString converts a value scanned from a database column to a label value.
+func toString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
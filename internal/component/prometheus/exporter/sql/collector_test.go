@@ -0,0 +1,167 @@
+package sql
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCollector(t *testing.T, queries ...Query) *collector {
+	t.Helper()
+
+	args := DefaultArguments
+	args.Driver = DriverSQLite
+	args.ConnectionString = ":memory:"
+	// Every connection to ":memory:" opens a separate database.
+	args.MaxOpenConnections = 1
+	args.MaxIdleConnections = 1
+	args.Queries = queries
+
+	c, err := newCollector(log.NewNopLogger(), args)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = c.db.Close() })
+
+	_, err = c.db.Exec(`
+		CREATE TABLE jobs (queue TEXT, state TEXT);
+		INSERT INTO jobs VALUES ('emails', 'waiting'), ('emails', 'waiting'), ('invoices', 'waiting'), ('invoices', 'done');
+	`)
+	require.NoError(t, err)
+	return c
+}
+
+var queueDepthQuery = Query{
+	Name: "queue_depth",
+	SQL:  "SELECT queue, COUNT(*) AS depth FROM jobs WHERE state = 'waiting' GROUP BY queue",
+	Metrics: []Metric{
+		{
+			Name:         "app_queue_depth",
+			Type:         MetricTypeGauge,
+			Help:         "Number of waiting jobs.",
+			ValueColumn:  "depth",
+			LabelColumns: []string{"queue"},
+			StaticLabels: map[string]string{"app": "billing"},
+		},
+	},
+}
+
+func TestCollector(t *testing.T) {
+	c := newTestCollector(t, queueDepthQuery)
+
+	expected := `
+# HELP app_queue_depth Number of waiting jobs.
+# TYPE app_queue_depth gauge
+app_queue_depth{app="billing",queue="emails"} 2
+app_queue_depth{app="billing",queue="invoices"} 1
+# HELP sql_query_up Whether the last execution of the query succeeded.
+# TYPE sql_query_up gauge
+sql_query_up{query="queue_depth"} 1
+`
+	require.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "app_queue_depth", "sql_query_up"))
+}
+
+func TestCollector_Interval(t *testing.T) {
+	q := queueDepthQuery
+	q.Interval = time.Minute
+	c := newTestCollector(t, q)
+
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	expected := `
+# HELP app_queue_depth Number of waiting jobs.
+# TYPE app_queue_depth gauge
+app_queue_depth{app="billing",queue="emails"} 2
+app_queue_depth{app="billing",queue="invoices"} 1
+`
+	require.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "app_queue_depth"))
+
+	_, err := c.db.Exec(`INSERT INTO jobs VALUES ('emails', 'waiting')`)
+	require.NoError(t, err)
+
+	// The cached result is served until the interval elapses.
+	require.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "app_queue_depth"))
+
+	now = now.Add(time.Minute)
+	expected = `
+# HELP app_queue_depth Number of waiting jobs.
+# TYPE app_queue_depth gauge
+app_queue_depth{app="billing",queue="emails"} 3
+app_queue_depth{app="billing",queue="invoices"} 1
+`
+	require.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "app_queue_depth"))
+}
+
+func TestCollector_QueryError(t *testing.T) {
+	c := newTestCollector(t, Query{
+		Name: "broken",
+		SQL:  "SELECT missing FROM jobs",
+		Metrics: []Metric{
+			{Name: "app_missing", Type: MetricTypeGauge, ValueColumn: "missing"},
+		},
+	}, Query{
+		Name: "wrong_column",
+		SQL:  "SELECT COUNT(*) AS total FROM jobs",
+		Metrics: []Metric{
+			{Name: "app_jobs", Type: MetricTypeGauge, ValueColumn: "count"},
+		},
+	})
+
+	expected := `
+# HELP sql_query_up Whether the last execution of the query succeeded.
+# TYPE sql_query_up gauge
+sql_query_up{query="broken"} 0
+sql_query_up{query="wrong_column"} 0
+`
+	require.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "app_missing", "app_jobs", "sql_query_up"))
+}
+
+func TestCollector_SkipsNullAndDuplicateRows(t *testing.T) {
+	c := newTestCollector(t, Query{
+		Name: "jobs",
+		SQL:  "SELECT queue, CASE WHEN state = 'done' THEN NULL ELSE 1 END AS waiting FROM jobs",
+		Metrics: []Metric{
+			{Name: "app_job_waiting", Type: MetricTypeGauge, Help: "Whether a job is waiting.", ValueColumn: "waiting", LabelColumns: []string{"queue"}},
+		},
+	})
+
+	// The NULL row of the done invoice and the second waiting email are
+	// skipped, instead of failing the whole query.
+	expected := `
+# HELP app_job_waiting Whether a job is waiting.
+# TYPE app_job_waiting gauge
+app_job_waiting{queue="emails"} 1
+app_job_waiting{queue="invoices"} 1
+# HELP sql_query_up Whether the last execution of the query succeeded.
+# TYPE sql_query_up gauge
+sql_query_up{query="jobs"} 1
+`
+	require.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "app_job_waiting", "sql_query_up"))
+}
+
+func TestCollector_Register(t *testing.T) {
+	// Queries may report the same metric, which is described once.
+	other := queueDepthQuery
+	other.Name = "other_queue_depth"
+	c := newTestCollector(t, queueDepthQuery, other)
+
+	reg := prometheus.NewPedanticRegistry()
+	require.NoError(t, reg.Register(c))
+}
+
+func TestToFloat(t *testing.T) {
+	for _, v := range []any{int64(2), 2.0, []byte("2"), "2"} {
+		f, err := toFloat(v)
+		require.NoError(t, err)
+		require.Equal(t, 2.0, f)
+	}
+
+	_, err := toFloat(nil)
+	require.ErrorContains(t, err, "NULL")
+	_, err = toFloat(struct{}{})
+	require.ErrorContains(t, err, "unsupported column type struct {}")
+}
@@ -0,0 +1,233 @@
+package sql
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/component/prometheus/exporter"
+	"github.com/grafana/alloy/internal/featuregate"
+	"github.com/grafana/alloy/internal/static/integrations"
+	"github.com/grafana/alloy/syntax/alloytypes"
+)
+
+func init() {
+	component.Register(component.Registration{
+		Name:      "prometheus.exporter.sql",
+		Stability: featuregate.StabilityExperimental,
+		Args:      Arguments{},
+		Exports:   exporter.Exports{},
+
+		Build: exporter.New(createExporter, "sql"),
+	})
+}
+
+func createExporter(opts component.Options, args component.Arguments, defaultInstanceKey string) (integrations.Integration, string, error) {
+	a := args.(Arguments)
+
+	col, err := newCollector(opts.Logger, a)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return integrations.NewCollectorIntegration(
+		"sql",
+		integrations.WithCollectors(col),
+		integrations.WithRunner(col.Run),
+	), a.instanceKey(defaultInstanceKey), nil
+}
+
+// Supported database drivers. The names match the names the drivers register
+// themselves with in database/sql.
+const (
+	DriverMySQL     = "mysql"
+	DriverOracle    = "oracle"
+	DriverPostgres  = "postgres"
+	DriverSnowflake = "snowflake"
+	DriverSQLite    = "sqlite"
+	DriverSQLServer = "sqlserver"
+)
+
+var supportedDrivers = []string{
+	DriverMySQL,
+	DriverOracle,
+	DriverPostgres,
+	DriverSnowflake,
+	DriverSQLite,
+	DriverSQLServer,
+}
+
+// Supported metric types.
+const (
+	MetricTypeCounter = "counter"
+	MetricTypeGauge   = "gauge"
+)
+
+// DefaultArguments holds the default settings for the sql exporter.
+var DefaultArguments = Arguments{
+	MaxIdleConnections: 3,
+	MaxOpenConnections: 3,
+	Timeout:            10 * time.Second,
+}
+
+// Arguments controls the sql exporter.
+type Arguments struct {
+	Driver             string            `alloy:"driver,attr"`
+	ConnectionString   alloytypes.Secret `alloy:"connection_string,attr"`
+	MaxIdleConnections int               `alloy:"max_idle_connections,attr,optional"`
+	MaxOpenConnections int               `alloy:"max_open_connections,attr,optional"`
+	Timeout            time.Duration     `alloy:"timeout,attr,optional"`
+	Queries            []Query           `alloy:"query,block"`
+}
+
+// Query is a single SQL query whose result rows are turned into metrics.
+type Query struct {
+	Name     string        `alloy:",label"`
+	SQL      string        `alloy:"sql,attr"`
+	Interval time.Duration `alloy:"interval,attr,optional"`
+	Timeout  time.Duration `alloy:"timeout,attr,optional"`
+	Metrics  []Metric      `alloy:"metric,block"`
+}
+
+// Metric maps the columns of a query result to a metric.
+type Metric struct {
+	Name         string            `alloy:"name,attr"`
+	Type         string            `alloy:"type,attr,optional"`
+	Help         string            `alloy:"help,attr,optional"`
+	ValueColumn  string            `alloy:"value_column,attr"`
+	LabelColumns []string          `alloy:"label_columns,attr,optional"`
+	StaticLabels map[string]string `alloy:"static_labels,attr,optional"`
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (a *Arguments) SetToDefault() {
+	*a = DefaultArguments
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (m *Metric) SetToDefault() {
+	*m = Metric{Type: MetricTypeGauge}
+}
+
+// Validate implements syntax.Validator.
+func (a *Arguments) Validate() error {
+	if !slices.Contains(supportedDrivers, a.Driver) {
+		return fmt.Errorf("unsupported driver %q, must be one of %v", a.Driver, supportedDrivers)
+	}
+
+	if a.ConnectionString == "" {
+		return errors.New("connection_string must not be empty")
+	}
+
+	if a.MaxOpenConnections < 1 {
+		return errors.New("max_open_connections must be at least 1")
+	}
+
+	if a.MaxIdleConnections < 1 {
+		return errors.New("max_idle_connections must be at least 1")
+	}
+
+	if a.MaxIdleConnections > a.MaxOpenConnections {
+		return errors.New("max_idle_connections must not be greater than max_open_connections")
+	}
+
+	if a.Timeout <= 0 {
+		return errors.New("timeout must be positive")
+	}
+
+	queryNames := make(map[string]struct{}, len(a.Queries))
+	for _, q := range a.Queries {
+		if _, ok := queryNames[q.Name]; ok {
+			return fmt.Errorf("query %q is defined more than once", q.Name)
+		}
+		queryNames[q.Name] = struct{}{}
+
+		if err := q.validate(); err != nil {
+			return fmt.Errorf("query %q: %w", q.Name, err)
+		}
+	}
+
+	// Metrics of different queries may share a name, as long as they describe
+	// the same metric.
+	metrics := make(map[string]Metric)
+	for _, q := range a.Queries {
+		for _, m := range q.Metrics {
+			other, ok := metrics[m.Name]
+			if !ok {
+				metrics[m.Name] = m
+				continue
+			}
+			if m.Type != other.Type || m.Help != other.Help || !slices.Equal(m.labelNames(), other.labelNames()) {
+				return fmt.Errorf("metric %q is defined more than once with a different type, help or labels", m.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// labelNames returns the sorted names of the labels of m.
+func (m *Metric) labelNames() []string {
+	names := slices.Clone(m.LabelColumns)
+	for l := range m.StaticLabels {
+		names = append(names, l)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func (q *Query) validate() error {
+	if q.SQL == "" {
+		return errors.New("sql must not be empty")
+	}
+	if q.Interval < 0 {
+		return errors.New("interval must not be negative")
+	}
+	if q.Timeout < 0 {
+		return errors.New("timeout must not be negative")
+	}
+	if len(q.Metrics) == 0 {
+		return errors.New("at least one metric block is required")
+	}
+
+	for _, m := range q.Metrics {
+		if !model.IsValidMetricName(model.LabelValue(m.Name)) {
+			return fmt.Errorf("invalid metric name %q", m.Name)
+		}
+		if m.Type != MetricTypeGauge && m.Type != MetricTypeCounter {
+			return fmt.Errorf("metric %q: invalid type %q, must be %q or %q", m.Name, m.Type, MetricTypeGauge, MetricTypeCounter)
+		}
+		if m.ValueColumn == "" {
+			return fmt.Errorf("metric %q: value_column must not be empty", m.Name)
+		}
+		for _, l := range m.LabelColumns {
+			if !model.LabelName(l).IsValid() {
+				return fmt.Errorf("metric %q: invalid label column %q", m.Name, l)
+			}
+		}
+		for l := range m.StaticLabels {
+			if !model.LabelName(l).IsValid() {
+				return fmt.Errorf("metric %q: invalid static label %q", m.Name, l)
+			}
+			if slices.Contains(m.LabelColumns, l) {
+				return fmt.Errorf("metric %q: static label %q is also used as a label column", m.Name, l)
+			}
+		}
+	}
+	return nil
+}
+
+// instanceKey returns the host of the connection string if it's a URL, and
+// falls back to the default instance key otherwise.
+func (a *Arguments) instanceKey(defaultInstanceKey string) string {
+	u, err := url.Parse(string(a.ConnectionString))
+	if err != nil || u.Host == "" {
+		return defaultInstanceKey
+	}
+	return u.Host
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/grafana/alloy/internal/component/prometheus/operator"
 	"github.com/grafana/alloy/internal/runtime/logging/level"
 	"github.com/grafana/alloy/internal/service/cluster"
+	http_service "github.com/grafana/alloy/internal/service/http"
 	"github.com/grafana/alloy/internal/service/labelstore"
 	"gopkg.in/yaml.v3"
 )
@@ -168,6 +169,13 @@ func (c *Component) reportHealth(err error) {
 	}
 }
 
+// RequiredRole implements http_service.RoleComponent. The generated scrape
+// configs expose the internals of the component, so they require the
+// debugger role.
+func (c *Component) RequiredRole(path string) http_service.Role {
+	return http_service.RoleDebugger
+}
+
 func (c *Component) Handler() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// very simple path handling
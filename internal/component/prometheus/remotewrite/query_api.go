@@ -0,0 +1,353 @@
+package remotewrite
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/prometheus/prometheus/storage"
+
+	http_service "github.com/grafana/alloy/internal/service/http"
+)
+
+// Limits applied to queries evaluated against the WAL. Queries are only meant
+// for debugging, so the limits are deliberately conservative.
+const (
+	queryTimeout    = 2 * time.Minute
+	queryMaxSamples = 5_000_000
+	queryMaxPoints  = 11_000
+)
+
+var (
+	minTime = time.Unix(math.MinInt64/1000+62135596801, 0).UTC()
+	maxTime = time.Unix(math.MaxInt64/1000-62135596801, 999999999).UTC()
+)
+
+// Handler serves a read-only subset of the Prometheus HTTP API backed by the
+// samples which are still in the component's WAL.
+func (c *Component) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/query", c.handleQuery)
+	mux.HandleFunc("/api/v1/query_range", c.handleQueryRange)
+	mux.HandleFunc("/api/v1/series", c.handleSeries)
+	mux.HandleFunc("/api/v1/labels", c.handleLabels)
+	mux.HandleFunc("/api/v1/label/{name}/values", c.handleLabelValues)
+	return mux
+}
+
+// RequiredRole implements http_service.RoleComponent. Queries expose the raw
+// samples of the WAL, so they require the debugger role.
+func (c *Component) RequiredRole(path string) http_service.Role {
+	return http_service.RoleDebugger
+}
+
+func (c *Component) queryable() storage.Queryable {
+	return storage.QueryableFunc(func(mint, maxt int64) (storage.Querier, error) {
+		return c.walStore.Querier(mint, maxt)
+	})
+}
+
+func (c *Component) handleQuery(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	ts, err := parseTimeParam(r, "time", time.Now())
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	q, err := c.queryEngine.NewInstantQuery(r.Context(), c.queryable(), nil, r.FormValue("query"), ts)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	c.runQuery(r.Context(), w, q)
+}
+
+func (c *Component) handleQueryRange(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	start, err := parseTime(r.FormValue("start"))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("invalid parameter \"start\": %w", err))
+		return
+	}
+	end, err := parseTime(r.FormValue("end"))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("invalid parameter \"end\": %w", err))
+		return
+	}
+	if end.Before(start) {
+		writeAPIError(w, http.StatusBadRequest, errors.New("end timestamp must not be before start time"))
+		return
+	}
+	step, err := parseDuration(r.FormValue("step"))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("invalid parameter \"step\": %w", err))
+		return
+	}
+	if step <= 0 {
+		writeAPIError(w, http.StatusBadRequest, errors.New("zero or negative query resolution step widths are not accepted"))
+		return
+	}
+	if end.Sub(start)/step > queryMaxPoints {
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("exceeded maximum resolution of %d points per timeseries", queryMaxPoints))
+		return
+	}
+
+	q, err := c.queryEngine.NewRangeQuery(r.Context(), c.queryable(), nil, r.FormValue("query"), start, end, step)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	c.runQuery(r.Context(), w, q)
+}
+
+func (c *Component) runQuery(ctx context.Context, w http.ResponseWriter, q promql.Query) {
+	defer q.Close()
+
+	res := q.Exec(ctx)
+	if res.Err != nil {
+		writeAPIError(w, http.StatusUnprocessableEntity, res.Err)
+		return
+	}
+	warnings, infos := res.Warnings.AsStrings(q.String(), 0, 0)
+	writeJSON(w, http.StatusOK, apiResponse{
+		Status: "success",
+		Data: struct {
+			ResultType parser.ValueType `json:"resultType"`
+			Result     parser.Value     `json:"result"`
+		}{
+			ResultType: res.Value.Type(),
+			Result:     res.Value,
+		},
+		Warnings: warnings,
+		Infos:    infos,
+	})
+}
+
+func (c *Component) handleSeries(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	if len(r.Form["match[]"]) == 0 {
+		writeAPIError(w, http.StatusBadRequest, errors.New("no match[] parameter provided"))
+		return
+	}
+
+	q, matcherSets, err := c.labelQuery(r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	defer q.Close()
+
+	// Only the labels of the series are returned, so their samples don't need
+	// to be read from the WAL.
+	hints := &storage.SelectHints{Func: "series"}
+
+	res := []labels.Labels{}
+	seen := map[uint64]struct{}{}
+	for _, matchers := range matcherSets {
+		ss := q.Select(r.Context(), true, hints, matchers...)
+		for ss.Next() {
+			lset := ss.At().Labels()
+			if _, ok := seen[lset.Hash()]; ok {
+				continue
+			}
+			seen[lset.Hash()] = struct{}{}
+			res = append(res, lset)
+		}
+		if err := ss.Err(); err != nil {
+			writeAPIError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+	writeAPIResponse(w, res, nil)
+}
+
+func (c *Component) handleLabels(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	q, matcherSets, err := c.labelQuery(r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	defer q.Close()
+
+	res := []string{}
+	for _, matchers := range matcherSets {
+		names, _, err := q.LabelNames(r.Context(), nil, matchers...)
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, err)
+			return
+		}
+		res = append(res, names...)
+	}
+	writeAPIResponse(w, dedupSorted(res), nil)
+}
+
+func (c *Component) handleLabelValues(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	name := r.PathValue("name")
+	if !model.LabelName(name).IsValid() {
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("invalid label name: %q", name))
+		return
+	}
+	q, matcherSets, err := c.labelQuery(r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	defer q.Close()
+
+	res := []string{}
+	for _, matchers := range matcherSets {
+		values, _, err := q.LabelValues(r.Context(), name, nil, matchers...)
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, err)
+			return
+		}
+		res = append(res, values...)
+	}
+	writeAPIResponse(w, dedupSorted(res), nil)
+}
+
+// labelQuery parses the start, end and match[] parameters shared by the
+// metadata endpoints and returns a querier for the requested time range. If no
+// match[] parameters were given, a single empty matcher set is returned.
+func (c *Component) labelQuery(r *http.Request) (storage.Querier, [][]*labels.Matcher, error) {
+	start, err := parseTimeParam(r, "start", minTime)
+	if err != nil {
+		return nil, nil, err
+	}
+	end, err := parseTimeParam(r, "end", maxTime)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var matcherSets [][]*labels.Matcher
+	for _, s := range r.Form["match[]"] {
+		matchers, err := parser.ParseMetricSelector(s)
+		if err != nil {
+			return nil, nil, err
+		}
+		matcherSets = append(matcherSets, matchers)
+	}
+	if len(matcherSets) == 0 {
+		matcherSets = [][]*labels.Matcher{nil}
+	}
+
+	q, err := c.walStore.Querier(start.UnixMilli(), end.UnixMilli())
+	if err != nil {
+		return nil, nil, err
+	}
+	return q, matcherSets, nil
+}
+
+func dedupSorted(values []string) []string {
+	seen := make(map[string]struct{}, len(values))
+	res := make([]string, 0, len(values))
+	for _, v := range values {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		res = append(res, v)
+	}
+	slices.Sort(res)
+	return res
+}
+
+func parseTimeParam(r *http.Request, name string, defaultValue time.Time) (time.Time, error) {
+	val := r.FormValue(name)
+	if val == "" {
+		return defaultValue, nil
+	}
+	t, err := parseTime(val)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid parameter %q: %w", name, err)
+	}
+	return t, nil
+}
+
+// parseTime parses a timestamp given either as a Unix timestamp in seconds
+// with optional decimal places or as an RFC3339 string.
+func parseTime(s string) (time.Time, error) {
+	if t, err := strconv.ParseFloat(s, 64); err == nil {
+		s, ns := math.Modf(t)
+		ns = math.Round(ns*1000) / 1000
+		return time.Unix(int64(s), int64(ns*float64(time.Second))).UTC(), nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("cannot parse %q to a valid timestamp", s)
+}
+
+// parseDuration parses a duration given either in seconds with optional
+// decimal places or as a Prometheus duration string.
+func parseDuration(s string) (time.Duration, error) {
+	if d, err := strconv.ParseFloat(s, 64); err == nil {
+		ts := d * float64(time.Second)
+		if ts > float64(math.MaxInt64) || ts < float64(math.MinInt64) {
+			return 0, fmt.Errorf("cannot parse %q to a valid duration. It overflows int64", s)
+		}
+		return time.Duration(ts), nil
+	}
+	if d, err := model.ParseDuration(s); err == nil {
+		return time.Duration(d), nil
+	}
+	return 0, fmt.Errorf("cannot parse %q to a valid duration", s)
+}
+
+type apiResponse struct {
+	Status    string   `json:"status"`
+	Data      any      `json:"data,omitempty"`
+	ErrorType string   `json:"errorType,omitempty"`
+	Error     string   `json:"error,omitempty"`
+	Warnings  []string `json:"warnings,omitempty"`
+	Infos     []string `json:"infos,omitempty"`
+}
+
+func writeAPIResponse(w http.ResponseWriter, data any, warnings []string) {
+	writeJSON(w, http.StatusOK, apiResponse{Status: "success", Data: data, Warnings: warnings})
+}
+
+func writeAPIError(w http.ResponseWriter, code int, err error) {
+	errorType := "bad_data"
+	switch code {
+	case http.StatusUnprocessableEntity:
+		errorType = "execution"
+	case http.StatusInternalServerError:
+		errorType = "internal"
+	}
+	writeJSON(w, code, apiResponse{Status: "error", ErrorType: errorType, Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, code int, resp apiResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/prometheus/prometheus/model/labels"
 	"github.com/prometheus/prometheus/model/metadata"
 	"github.com/prometheus/prometheus/model/timestamp"
+	"github.com/prometheus/prometheus/promql"
 	"github.com/prometheus/prometheus/storage"
 	"github.com/prometheus/prometheus/storage/remote"
 	"go.uber.org/atomic"
@@ -65,7 +66,8 @@ type Component struct {
 	mut sync.RWMutex
 	cfg Arguments
 
-	receiver *prometheus.Interceptor
+	receiver    *prometheus.Interceptor
+	queryEngine *promql.Engine
 
 	debugDataPublisher livedebugging.DebugDataPublisher
 }
@@ -119,6 +121,16 @@ func New(o component.Options, c Arguments) (*Component, error) {
 		remoteStore:        remoteStore,
 		storage:            storage.NewFanout(fanoutLogger, walStorage, remoteStore),
 		debugDataPublisher: debugDataPublisher.(livedebugging.DebugDataPublisher),
+		queryEngine: promql.NewEngine(promql.EngineOpts{
+			Logger:     slog.New(logging.NewSlogGoKitHandler(log.With(o.Logger, "subcomponent", "query"))),
+			MaxSamples: queryMaxSamples,
+			Timeout:    queryTimeout,
+			NoStepSubqueryIntervalFn: func(int64) int64 {
+				return time.Minute.Milliseconds()
+			},
+			EnableAtModifier:     true,
+			EnableNegativeOffset: true,
+		}),
 	}
 	componentID := livedebugging.ComponentID(res.opts.ID)
 	res.receiver = prometheus.NewInterceptor(
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"testing"
 	"time"
 
@@ -180,6 +182,63 @@ func TestUpdate(t *testing.T) {
 	}})
 }
 
+func TestQueryAPI(t *testing.T) {
+	args := testArgsForConfig(t, `
+		endpoint {
+			url = "http://localhost:0/api/v1/write"
+		}
+	`)
+	tc, err := componenttest.NewControllerFromID(util.TestLogger(t), "prometheus.remote_write")
+	require.NoError(t, err)
+	go func() {
+		err = tc.Run(componenttest.TestContext(t), args)
+		require.NoError(t, err)
+	}()
+	require.NoError(t, tc.WaitRunning(5*time.Second))
+
+	sampleTime := time.Now()
+	sendMetric(t, tc, labels.FromStrings("__name__", "test_metric", "foo", "bar"), sampleTime.UnixMilli(), 12)
+	sendMetric(t, tc, labels.FromStrings("__name__", "test_metric", "foo", "baz"), sampleTime.UnixMilli(), 34)
+
+	c, err := tc.GetComponent()
+	require.NoError(t, err)
+	handler := c.(*remotewrite.Component).Handler()
+
+	get := func(path string, query url.Values) string {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path+"?"+query.Encode(), nil))
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		return rec.Body.String()
+	}
+
+	queryTime := strconv.FormatInt(sampleTime.Unix()+1, 10)
+
+	body := get("/api/v1/query", url.Values{
+		"query": {`sum(test_metric)`},
+		"time":  {queryTime},
+	})
+	require.JSONEq(t, `{
+		"status": "success",
+		"data": {
+			"resultType": "vector",
+			"result": [{"metric": {}, "value": [`+queryTime+`, "46"]}]
+		}
+	}`, body)
+
+	body = get("/api/v1/label/foo/values", nil)
+	require.JSONEq(t, `{"status": "success", "data": ["bar", "baz"]}`, body)
+
+	body = get("/api/v1/labels", url.Values{"match[]": {`{foo="bar"}`}})
+	require.JSONEq(t, `{"status": "success", "data": ["__name__", "foo"]}`, body)
+
+	body = get("/api/v1/series", url.Values{"match[]": {`test_metric{foo="baz"}`}})
+	require.JSONEq(t, `{"status": "success", "data": [{"__name__": "test_metric", "foo": "baz"}]}`, body)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/query?query=sum(", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
 func assertReceived(t *testing.T, writeResult chan *prompb.WriteRequest, expect []prompb.TimeSeries) {
 	select {
 	case <-time.After(time.Minute):
@@ -0,0 +1,140 @@
+package scrape
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/ckit/shard"
+	"github.com/prometheus/prometheus/config"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/scrape"
+
+	"github.com/grafana/alloy/internal/component/discovery"
+)
+
+// Supported values of the clustering cost_model argument.
+const (
+	CostModelNone   = "none"
+	CostModelSeries = "series"
+)
+
+// costReportInterval is how often the costs of targets are shared with the
+// cluster.
+const costReportInterval = 15 * time.Second
+
+// ClusteringArguments configures clustering for prometheus.scrape. It extends
+// cluster.ComponentBlock with settings specific to scraping.
+type ClusteringArguments struct {
+	Enabled bool `alloy:"enabled,attr"`
+
+	// CostModel decides how targets are weighted when they're distributed
+	// between peers.
+	CostModel string `alloy:"cost_model,attr,optional"`
+
+	// ReplicationFactor is the number of peers which scrape every target.
+	ReplicationFactor int `alloy:"replication_factor,attr,optional"`
+}
+
+// DefaultClusteringArguments holds the default clustering settings.
+var DefaultClusteringArguments = ClusteringArguments{
+	CostModel:         CostModelNone,
+	ReplicationFactor: 1,
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (args *ClusteringArguments) SetToDefault() {
+	*args = DefaultClusteringArguments
+}
+
+// Validate implements syntax.Validator.
+func (args *ClusteringArguments) Validate() error {
+	switch args.CostModel {
+	case CostModelNone, CostModelSeries:
+	default:
+		return fmt.Errorf("invalid clustering cost_model %q: must be either %q or %q", args.CostModel, CostModelNone, CostModelSeries)
+	}
+
+	if args.ReplicationFactor < 1 {
+		return fmt.Errorf("clustering replication_factor must be at least 1, got %d", args.ReplicationFactor)
+	}
+	if args.ReplicationFactor > 1 && args.CostModel != CostModelNone {
+		return fmt.Errorf("clustering replication_factor can't be used with cost_model %q", args.CostModel)
+	}
+	return nil
+}
+
+// seriesCosts tracks how many series the targets scraped by this instance
+// expose. The number of series is used as the cost of targets when they're
+// distributed by cost.
+type seriesCosts struct {
+	mut     sync.Mutex
+	enabled bool
+	keys    map[uint64]shard.Key // Shard keys of local targets, keyed by the hash of their public labels.
+	series  map[uint64]int       // Series of the last scrape of local targets, keyed by the hash of their public labels.
+}
+
+func newSeriesCosts() *seriesCosts {
+	return &seriesCosts{
+		keys:   make(map[uint64]shard.Key),
+		series: make(map[uint64]int),
+	}
+}
+
+// update sets the local targets to track and their shard keys.
+func (c *seriesCosts) update(enabled bool, targets []discovery.Target, keys []shard.Key, cfg *config.ScrapeConfig) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	c.enabled = enabled
+	if !enabled {
+		clear(c.keys)
+		clear(c.series)
+		return
+	}
+
+	lb := labels.NewBuilder(labels.EmptyLabels())
+	newKeys := make(map[uint64]shard.Key, len(targets))
+	for i, t := range targets {
+		lset, err := scrape.PopulateLabels(lb, cfg, t.LabelSet(), nil)
+		if err != nil || lset.IsEmpty() {
+			continue
+		}
+		newKeys[publicLabelsHash(lset)] = keys[i]
+	}
+	for key := range c.series {
+		if _, ok := newKeys[key]; !ok {
+			delete(c.series, key)
+		}
+	}
+	c.keys = newKeys
+}
+
+func (c *seriesCosts) isEnabled() bool {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return c.enabled
+}
+
+// record sets the number of series of the last scrape of a target.
+func (c *seriesCosts) record(key uint64, series int) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if _, ok := c.keys[key]; ok {
+		c.series[key] = series
+	}
+}
+
+// costs returns the number of series of every local target which was scraped
+// at least once, by shard key.
+func (c *seriesCosts) costs() map[shard.Key]float64 {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	res := make(map[shard.Key]float64, len(c.series))
+	for key, series := range c.series {
+		res[c.keys[key]] = float64(series)
+	}
+	return res
+}
@@ -0,0 +1,107 @@
+package scrape
+
+import (
+	"testing"
+
+	"github.com/grafana/ckit/shard"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/component/discovery"
+	"github.com/grafana/alloy/syntax"
+)
+
+func TestClusteringArguments(t *testing.T) {
+	var args Arguments
+	require.NoError(t, syntax.Unmarshal([]byte(`
+		targets    = []
+		forward_to = []
+	`), &args))
+	require.False(t, args.Clustering.Enabled)
+	require.Equal(t, CostModelNone, args.Clustering.CostModel)
+	require.Equal(t, 1, args.Clustering.ReplicationFactor)
+
+	require.NoError(t, syntax.Unmarshal([]byte(`
+		targets    = []
+		forward_to = []
+		clustering {
+			enabled    = true
+			cost_model = "series"
+		}
+	`), &args))
+	require.True(t, args.Clustering.Enabled)
+	require.Equal(t, CostModelSeries, args.Clustering.CostModel)
+
+	err := syntax.Unmarshal([]byte(`
+		targets    = []
+		forward_to = []
+		clustering {
+			enabled    = true
+			cost_model = "bytes"
+		}
+	`), &args)
+	require.ErrorContains(t, err, `invalid clustering cost_model "bytes"`)
+
+	require.NoError(t, syntax.Unmarshal([]byte(`
+		targets    = []
+		forward_to = []
+		clustering {
+			enabled            = true
+			replication_factor = 2
+		}
+	`), &args))
+	require.Equal(t, 2, args.Clustering.ReplicationFactor)
+
+	err = syntax.Unmarshal([]byte(`
+		targets    = []
+		forward_to = []
+		clustering {
+			enabled            = true
+			replication_factor = 0
+		}
+	`), &args)
+	require.ErrorContains(t, err, "replication_factor must be at least 1")
+
+	err = syntax.Unmarshal([]byte(`
+		targets    = []
+		forward_to = []
+		clustering {
+			enabled            = true
+			cost_model         = "series"
+			replication_factor = 2
+		}
+	`), &args)
+	require.ErrorContains(t, err, `replication_factor can't be used with cost_model "series"`)
+}
+
+func TestSeriesCosts(t *testing.T) {
+	var args Arguments
+	args.SetToDefault()
+	cfg := getPromScrapeConfigs("job", args)
+
+	targets := []discovery.Target{
+		discovery.NewTargetFromMap(map[string]string{"__address__": "a:80"}),
+		discovery.NewTargetFromMap(map[string]string{"__address__": "b:80"}),
+	}
+	keys := []shard.Key{1, 2}
+	targetKey := func(addr string) uint64 {
+		return labels.FromStrings("instance", addr, "job", "job").Hash()
+	}
+
+	c := newSeriesCosts()
+	c.update(true, targets, keys, cfg)
+	require.True(t, c.isEnabled())
+
+	c.record(targetKey("a:80"), 100)
+	c.record(targetKey("c:80"), 5) // Not a local target.
+	require.Equal(t, map[shard.Key]float64{1: 100}, c.costs())
+
+	// Targets which aren't local anymore are forgotten.
+	c.update(true, targets[1:], keys[1:], cfg)
+	c.record(targetKey("b:80"), 10)
+	require.Equal(t, map[shard.Key]float64{2: 10}, c.costs())
+
+	c.update(false, targets, keys, cfg)
+	require.False(t, c.isEnabled())
+	require.Empty(t, c.costs())
+}
@@ -0,0 +1,282 @@
+package scrape
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grafana/ckit/shard"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/config"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/scrape"
+	"github.com/prometheus/prometheus/util/osutil"
+
+	"github.com/grafana/alloy/internal/component/discovery"
+	"github.com/grafana/alloy/internal/service/cluster"
+)
+
+const (
+	// handoffCheckInterval is how often handoffs of targets are checked for
+	// progress.
+	handoffCheckInterval = time.Second
+
+	// handoffAlignAttempts is the number of salts tried to align the offset
+	// of a target with the schedule of its previous owner. The offset is
+	// within interval/handoffAlignAttempts of the schedule on average.
+	handoffAlignAttempts = 1024
+
+	// offsetSaltLabel is an internal target label which doesn't change the
+	// scraped series but changes the hash the scrape manager derives the
+	// scrape offset of a target from.
+	offsetSaltLabel = model.ReservedLabelPrefix + "scrape_offset_salt__"
+)
+
+// alignHandoffs adds the salt label to targets handed off by another node, so
+// that they're scraped at the same offset as on their previous owner. Targets
+// keep their salt while they're scraped by this instance. keys holds the shard
+// key of every target, and aligned the handoffs of targets which start to be
+// scraped.
+//
+// Targets aren't aligned once the predicted offsets were found to be
+// unreliable.
+func (c *Component) alignHandoffs(targets []discovery.Target, keys []shard.Key, aligned map[shard.Key]cluster.Handoff, cfg *config.ScrapeConfig) []discovery.Target {
+	if !c.offsets.reliable() {
+		clear(c.handoffSalts)
+		return targets
+	}
+
+	var (
+		lb   = labels.NewBuilder(labels.EmptyLabels())
+		seen = make(map[shard.Key]struct{}, len(keys))
+	)
+	for i, key := range keys {
+		seen[key] = struct{}{}
+
+		if handoff, ok := aligned[key]; ok && !handoff.LastRun.IsZero() {
+			lset, err := scrape.PopulateLabels(lb, cfg, targets[i].LabelSet(), nil)
+			if err != nil || lset.IsEmpty() {
+				continue
+			}
+			t := scrape.NewTarget(lset, cfg, nil, nil)
+			interval := targetInterval(t)
+			if interval <= 0 {
+				continue
+			}
+			var (
+				phase = time.Duration(handoff.LastRun.UnixNano() % int64(interval))
+				url   = t.URL().String()
+				seed  = c.offsets.seed()
+				salt  = alignedSalt(lset, url, interval, phase, seed)
+			)
+			c.handoffSalts[key] = salt
+			c.offsets.predict(publicLabelsHash(lset), salt, interval, targetOffset(lset, url, salt, interval, seed))
+		}
+
+		if salt, ok := c.handoffSalts[key]; ok {
+			targets[i] = withLabels(targets[i], map[string]string{offsetSaltLabel: salt})
+		}
+	}
+	for key := range c.handoffSalts {
+		if _, ok := seen[key]; !ok {
+			delete(c.handoffSalts, key)
+		}
+	}
+	return targets
+}
+
+// applyHandoffSalts adds the salt label to targets which were aligned by
+// alignHandoffs.
+func (c *Component) applyHandoffSalts(targets []discovery.Target) []discovery.Target {
+	for i, t := range targets {
+		key, ok := discovery.HandoffKey(func(name string) string {
+			v, _ := t.Get(name)
+			return v
+		})
+		if !ok {
+			continue
+		}
+		if salt, ok := c.handoffSalts[key]; ok {
+			targets[i] = withLabels(t, map[string]string{offsetSaltLabel: salt})
+		}
+	}
+	return targets
+}
+
+// alignedSalt returns the salt for which the scrape manager scrapes a target
+// with the given labels and URL closest to phase within interval.
+func alignedSalt(lset labels.Labels, url string, interval, phase time.Duration, seed uint64) string {
+	var (
+		best     string
+		bestDist = interval
+	)
+	for i := range handoffAlignAttempts {
+		salt := strconv.Itoa(i)
+		dist := targetOffset(lset, url, salt, interval, seed) - phase
+		if dist < 0 {
+			dist = -dist
+		}
+		dist = min(dist, interval-dist)
+		if dist < bestDist {
+			best, bestDist = salt, dist
+		}
+	}
+	return best
+}
+
+// offsetChecker checks that targets aligned by alignHandoffs are scraped at the
+// offsets predicted by targetOffset.
+//
+// targetOffset mirrors how the scrape manager picks the offset of a target,
+// which isn't exposed by Prometheus. The check guards against the two
+// diverging, in which case salts only restart scrape loops without aligning
+// them.
+type offsetChecker struct {
+	mut sync.Mutex
+
+	seedOnce   sync.Once
+	offsetSeed uint64
+
+	// Offsets predicted for salted targets which haven't been checked yet, and
+	// the results of past checks.
+	predicted       map[uint64]predictedOffset
+	matched, missed int
+	unreliable      bool
+}
+
+// predictedOffset is the offset within interval at which a target with the
+// given salt is expected to be scraped.
+type predictedOffset struct {
+	salt     string
+	interval time.Duration
+	offset   time.Duration
+	created  time.Time
+}
+
+func newOffsetChecker() *offsetChecker {
+	return &offsetChecker{predicted: make(map[uint64]predictedOffset)}
+}
+
+// predict records the offset at which the target with the given public labels
+// hash is expected to be scraped with salt.
+func (o *offsetChecker) predict(key uint64, salt string, interval, offset time.Duration) {
+	o.mut.Lock()
+	defer o.mut.Unlock()
+	o.predicted[key] = predictedOffset{salt: salt, interval: interval, offset: offset, created: time.Now()}
+}
+
+// reliable returns whether the predicted offsets matched the scrapes of the
+// scrape manager so far.
+func (o *offsetChecker) reliable() bool {
+	o.mut.Lock()
+	defer o.mut.Unlock()
+	return !o.unreliable
+}
+
+// check compares the predicted offsets with the time of the last scrape of
+// the active targets. It returns true if the predictions are found to be
+// unreliable, after which targets are no longer aligned.
+func (o *offsetChecker) check(active []*scrape.Target, now time.Time) bool {
+	o.mut.Lock()
+	defer o.mut.Unlock()
+
+	if o.unreliable || len(o.predicted) == 0 {
+		return false
+	}
+
+	lb := labels.NewBuilder(labels.EmptyLabels())
+	for _, t := range active {
+		key := t.Labels(lb).Hash()
+		p, ok := o.predicted[key]
+		if !ok || t.GetValue(offsetSaltLabel) != p.salt || targetInterval(t) != p.interval {
+			continue
+		}
+		lastScrape := t.LastScrape()
+		if lastScrape.Before(p.created) {
+			continue
+		}
+		delete(o.predicted, key)
+
+		phase := time.Duration(lastScrape.UnixNano() % int64(p.interval))
+		dist := phase - p.offset
+		if dist < 0 {
+			dist = -dist
+		}
+		dist = min(dist, p.interval-dist)
+		if dist <= max(p.interval/50, time.Second) {
+			o.matched++
+		} else {
+			o.missed++
+		}
+	}
+
+	// Forget predictions for targets which were never scraped with their salt.
+	for key, p := range o.predicted {
+		if now.Sub(p.created) > 3*p.interval {
+			delete(o.predicted, key)
+		}
+	}
+
+	if o.missed >= 3 && o.missed > o.matched {
+		o.unreliable = true
+		clear(o.predicted)
+		return true
+	}
+	return false
+}
+
+func (o *offsetChecker) seed() uint64 {
+	o.seedOnce.Do(func() {
+		// This mirrors the offset seed of the scrape manager, which is
+		// derived from the hostname and the (empty) external labels.
+		hostname, err := osutil.GetFQDN()
+		if err != nil {
+			return
+		}
+		h := fnv.New64a()
+		_, _ = fmt.Fprintf(h, "%s%s", hostname, labels.EmptyLabels().String())
+		o.offsetSeed = h.Sum64()
+	})
+	return o.offsetSeed
+}
+
+// targetOffset returns the offset within interval at which the scrape manager
+// scrapes a target with the given labels, salt and URL. It mirrors
+// scrape.Target.offset, which is checked by TestTargetOffset and at runtime by
+// offsetChecker.check.
+func targetOffset(lset labels.Labels, url, salt string, interval time.Duration, seed uint64) time.Duration {
+	lb := labels.NewBuilder(lset)
+	lb.Set(offsetSaltLabel, salt)
+
+	h := fnv.New64a()
+	_, _ = fmt.Fprintf(h, "%016d", lb.Labels().Hash())
+	_, _ = h.Write([]byte(url))
+	return time.Duration((h.Sum64() ^ seed) % uint64(interval))
+}
+
+// jobName returns the name of the scrape job of the component.
+func (c *Component) jobName() string {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+
+	if c.args.JobName != "" {
+		return c.args.JobName
+	}
+	return c.opts.ID
+}
+
+// scrapedTargets returns the time of the last scrape and the scrape interval
+// of the targets of jobName which are handed off, by shard key.
+func (c *Component) scrapedTargets(jobName string) map[shard.Key]cluster.Handoff {
+	res := make(map[shard.Key]cluster.Handoff)
+	for _, t := range c.scraper.TargetsActive()[jobName] {
+		key, ok := discovery.HandoffKey(t.GetValue)
+		if !ok {
+			continue
+		}
+		res[key] = cluster.Handoff{Key: key, LastRun: t.LastScrape(), Interval: targetInterval(t)}
+	}
+	return res
+}
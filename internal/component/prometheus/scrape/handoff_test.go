@@ -0,0 +1,185 @@
+package scrape
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/ckit/shard"
+	prometheus_client "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/common/promslog"
+	"github.com/prometheus/prometheus/config"
+	"github.com/prometheus/prometheus/discovery/targetgroup"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/scrape"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/component/discovery"
+	"github.com/grafana/alloy/internal/component/prometheus"
+	"github.com/grafana/alloy/internal/service/cluster"
+	"github.com/grafana/alloy/internal/service/labelstore"
+)
+
+func TestAlignHandoffs(t *testing.T) {
+	args := testScheduleArgs(t, ``)
+	cfg := getPromScrapeConfigs("test", args)
+	targets := testScheduleTargets(3)
+	keys := []shard.Key{1, 2, 3}
+
+	c := &Component{offsets: newOffsetChecker(), handoffSalts: make(map[shard.Key]string)}
+	// The previous owner scraped the target 7s into its interval.
+	phase := 7 * time.Second
+	lastRun := time.Unix(0, 0).Add(100*args.ScrapeInterval + phase)
+	aligned := map[shard.Key]cluster.Handoff{
+		2: {Key: 2, LastRun: lastRun, Interval: args.ScrapeInterval},
+	}
+	res := c.alignHandoffs(targets, keys, aligned, cfg)
+
+	// Only the handed off target is aligned.
+	_, ok := res[0].Get(offsetSaltLabel)
+	require.False(t, ok)
+	_, ok = res[2].Get(offsetSaltLabel)
+	require.False(t, ok)
+
+	lset, err := scrape.PopulateLabels(labels.NewBuilder(labels.EmptyLabels()), cfg, res[1].LabelSet(), nil)
+	require.NoError(t, err)
+	url := scrape.NewTarget(lset, cfg, nil, nil).URL().String()
+	offset := targetOffset(lset, url, lset.Get(offsetSaltLabel), args.ScrapeInterval, c.offsets.seed())
+	require.InDelta(t, phase, offset, float64(args.ScrapeInterval/100))
+
+	// The target keeps its salt while it's scraped locally.
+	res = c.alignHandoffs(testScheduleTargets(3), keys, nil, cfg)
+	require.Equal(t, getLabel(t, res[1], offsetSaltLabel), c.handoffSalts[2])
+
+	// The salt is forgotten once the target is gone.
+	c.alignHandoffs(testScheduleTargets(1), keys[:1], nil, cfg)
+	require.Empty(t, c.handoffSalts)
+}
+
+func TestAlignHandoffs_UnreliableOffsets(t *testing.T) {
+	args := testScheduleArgs(t, ``)
+	cfg := getPromScrapeConfigs("test", args)
+	targets := testScheduleTargets(1)
+
+	c := &Component{offsets: newOffsetChecker(), handoffSalts: map[shard.Key]string{1: "5"}}
+	c.offsets.unreliable = true
+	aligned := map[shard.Key]cluster.Handoff{
+		1: {Key: 1, LastRun: time.Unix(1000, 0), Interval: args.ScrapeInterval},
+	}
+	res := c.alignHandoffs(targets, []shard.Key{1}, aligned, cfg)
+	require.Equal(t, []discovery.Target{targets[0]}, res)
+	require.Empty(t, c.handoffSalts)
+}
+
+func TestOffsetCheckerCheck(t *testing.T) {
+	args := testScheduleArgs(t, ``)
+	cfg := getPromScrapeConfigs("test", args)
+	targets := testScheduleTargets(4)
+
+	// Targets scraped at their predicted offset are counted as matches.
+	o := newOffsetChecker()
+	created := time.Now()
+	active := testPredictedTargets(t, o, cfg, targets, 0)
+	require.False(t, o.check(active, created))
+	require.True(t, o.reliable())
+	require.Equal(t, 4, o.matched)
+
+	// Offsets which don't match the predictions stop targets from being
+	// aligned.
+	o = newOffsetChecker()
+	active = testPredictedTargets(t, o, cfg, targets, args.ScrapeInterval/2)
+	require.True(t, o.check(active, created))
+	require.False(t, o.reliable())
+}
+
+// testPredictedTargets predicts an offset for every target with a salt and
+// returns scrape targets which were last scraped skew after their predicted
+// offset.
+func testPredictedTargets(t *testing.T, o *offsetChecker, cfg *config.ScrapeConfig, targets []discovery.Target, skew time.Duration) []*scrape.Target {
+	t.Helper()
+
+	var (
+		res      []*scrape.Target
+		base     = time.Now().Truncate(time.Hour).Add(time.Hour)
+		interval = time.Duration(cfg.ScrapeInterval)
+	)
+	for _, target := range targets {
+		target = withLabels(target, map[string]string{offsetSaltLabel: "3"})
+		lset, err := scrape.PopulateLabels(labels.NewBuilder(labels.EmptyLabels()), cfg, target.LabelSet(), nil)
+		require.NoError(t, err)
+		st := scrape.NewTarget(lset, cfg, nil, nil)
+
+		offset := targetOffset(lset, st.URL().String(), "3", interval, o.seed())
+		o.predict(publicLabelsHash(lset), "3", interval, offset)
+		st.Report(base.Add(offset+skew), time.Second, nil)
+		res = append(res, st)
+	}
+	return res
+}
+
+func TestTargetOffset(t *testing.T) {
+	// targetOffset must predict the offset at which the scrape manager scrapes
+	// a target.
+	scrapes := make(chan time.Time, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		select {
+		case scrapes <- time.Now():
+		default:
+		}
+	}))
+	defer srv.Close()
+
+	args := testScheduleArgs(t, `
+		scrape_interval = "2s"
+		scrape_timeout  = "1s"
+	`)
+	cfg := getPromScrapeConfigs("test", args)
+
+	ls := labelstore.New(nil, prometheus_client.NewRegistry())
+	m, err := scrape.NewManager(
+		&scrape.Options{DiscoveryReloadInterval: model.Duration(10 * time.Millisecond)},
+		promslog.NewNopLogger(),
+		nil,
+		prometheus.NewInterceptor(nil, ls),
+		prometheus_client.NewRegistry(),
+	)
+	require.NoError(t, err)
+	promConfig, err := config.Load("", promslog.NewNopLogger())
+	require.NoError(t, err)
+	promConfig.ScrapeConfigs = []*config.ScrapeConfig{cfg}
+	require.NoError(t, m.ApplyConfig(promConfig))
+
+	tsets := make(chan map[string][]*targetgroup.Group)
+	go func() { _ = m.Run(tsets) }()
+	defer m.Stop()
+
+	target := discovery.NewTargetFromMap(map[string]string{
+		model.AddressLabel: strings.TrimPrefix(srv.URL, "http://"),
+		offsetSaltLabel:    "7",
+	})
+	tsets <- map[string][]*targetgroup.Group{
+		"test": {{Targets: []model.LabelSet{target.LabelSet()}, Source: "test"}},
+	}
+
+	var scraped time.Time
+	select {
+	case scraped = <-scrapes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("target wasn't scraped")
+	}
+
+	lset, err := scrape.PopulateLabels(labels.NewBuilder(labels.EmptyLabels()), cfg, target.LabelSet(), nil)
+	require.NoError(t, err)
+	url := scrape.NewTarget(lset, cfg, nil, nil).URL().String()
+	offset := targetOffset(lset, url, "7", args.ScrapeInterval, newOffsetChecker().seed())
+
+	dist := time.Duration(scraped.UnixNano()%int64(args.ScrapeInterval)) - offset
+	if dist < 0 {
+		dist = -dist
+	}
+	dist = min(dist, args.ScrapeInterval-dist)
+	require.Less(t, dist, 200*time.Millisecond)
+}
@@ -0,0 +1,471 @@
+package scrape
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"runtime/debug"
+	"runtime/metrics"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/config"
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/scrape"
+	"github.com/prometheus/prometheus/storage"
+
+	"github.com/grafana/alloy/internal/component/discovery"
+)
+
+const (
+	// maxPressureLevel limits how often intervals are doubled when Alloy is
+	// resource constrained.
+	maxPressureLevel = 6
+
+	// lookbackDelta is the default time after which queries consider a series
+	// stale if it has no new samples. Adaptive intervals must stay below it.
+	lookbackDelta = 5 * time.Minute
+)
+
+// reportSeriesNames are the series the scrape loop adds for every target.
+// They're ignored when deciding how often the samples of a target change.
+var reportSeriesNames = []string{
+	"up",
+	"scrape_duration_seconds",
+	"scrape_samples_scraped",
+	"scrape_samples_post_metric_relabeling",
+	"scrape_series_added",
+	"scrape_timeout_seconds",
+	"scrape_sample_limit",
+	"scrape_body_size_bytes",
+}
+
+// AdaptiveIntervalArguments configures adaptive scrape intervals.
+type AdaptiveIntervalArguments struct {
+	Enabled              bool          `alloy:"enabled,attr,optional"`
+	MinInterval          time.Duration `alloy:"min_interval,attr,optional"`
+	MaxInterval          time.Duration `alloy:"max_interval,attr,optional"`
+	MinChangeRatio       float64       `alloy:"min_change_ratio,attr,optional"`
+	MaxCPUUtilization    float64       `alloy:"max_cpu_utilization,attr,optional"`
+	MaxMemoryUtilization float64       `alloy:"max_memory_utilization,attr,optional"`
+	EvaluationInterval   time.Duration `alloy:"evaluation_interval,attr,optional"`
+}
+
+// DefaultAdaptiveIntervalArguments holds the default settings for adaptive
+// scrape intervals.
+var DefaultAdaptiveIntervalArguments = AdaptiveIntervalArguments{
+	Enabled:              false,
+	MaxInterval:          4 * time.Minute,
+	MinChangeRatio:       0.05,
+	MaxCPUUtilization:    0.8,
+	MaxMemoryUtilization: 0.9,
+	EvaluationInterval:   5 * time.Minute,
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (args *AdaptiveIntervalArguments) SetToDefault() {
+	*args = DefaultAdaptiveIntervalArguments
+}
+
+// validate checks the adaptive interval settings against the scrape interval
+// and timeout of the component.
+func (args *AdaptiveIntervalArguments) validate(scrapeInterval, scrapeTimeout time.Duration) error {
+	if !args.Enabled {
+		return nil
+	}
+
+	minInterval := args.minInterval(scrapeInterval)
+	if minInterval > scrapeInterval {
+		return fmt.Errorf("adaptive_interval min_interval (%s) greater than scrape_interval (%s)", minInterval, scrapeInterval)
+	}
+	if minInterval < scrapeTimeout {
+		return fmt.Errorf("adaptive_interval min_interval (%s) less than scrape_timeout (%s)", minInterval, scrapeTimeout)
+	}
+	if args.MaxInterval < scrapeInterval {
+		return fmt.Errorf("adaptive_interval max_interval (%s) less than scrape_interval (%s)", args.MaxInterval, scrapeInterval)
+	}
+	if args.MaxInterval >= lookbackDelta {
+		return fmt.Errorf("adaptive_interval max_interval (%s) must be less than %s, after which series are considered stale", args.MaxInterval, lookbackDelta)
+	}
+	if args.MinChangeRatio < 0 || args.MinChangeRatio > 1 {
+		return fmt.Errorf("adaptive_interval min_change_ratio must be between 0 and 1, got %g", args.MinChangeRatio)
+	}
+	if args.MaxCPUUtilization < 0 || args.MaxCPUUtilization > 1 {
+		return fmt.Errorf("adaptive_interval max_cpu_utilization must be between 0 and 1, got %g", args.MaxCPUUtilization)
+	}
+	if args.MaxMemoryUtilization < 0 || args.MaxMemoryUtilization > 1 {
+		return fmt.Errorf("adaptive_interval max_memory_utilization must be between 0 and 1, got %g", args.MaxMemoryUtilization)
+	}
+	if args.EvaluationInterval <= 0 {
+		return fmt.Errorf("adaptive_interval evaluation_interval must be greater than 0")
+	}
+	return nil
+}
+
+// evaluationInterval returns how often intervals are evaluated, falling back
+// to the default for arguments which weren't defaulted.
+func (args *AdaptiveIntervalArguments) evaluationInterval() time.Duration {
+	if args.EvaluationInterval <= 0 {
+		return DefaultAdaptiveIntervalArguments.EvaluationInterval
+	}
+	return args.EvaluationInterval
+}
+
+func (args *AdaptiveIntervalArguments) minInterval(scrapeInterval time.Duration) time.Duration {
+	if args.MinInterval == 0 {
+		return scrapeInterval
+	}
+	return args.MinInterval
+}
+
+// needsTargetInContext returns whether samples need to be attributed to their
+// target, which is only the case for adaptive intervals and series costs.
+func (arg *Arguments) needsTargetInContext() bool {
+	return arg.AdaptiveInterval.Enabled || (arg.Clustering.Enabled && arg.Clustering.CostModel == CostModelSeries)
+}
+
+// scheduler decides the scrape interval of every target scraped by this
+// instance.
+//
+// The adaptive interval of a target is applied by setting its
+// __scrape_interval__ label, so the scrape loop of a target is restarted when
+// its interval changes. This happens at most once per evaluation_interval, and
+// the end-of-run staleness markers of the replaced loop are disabled.
+type scheduler struct {
+	mut       sync.Mutex
+	targets   map[uint64]*targetSchedule // Keyed by the hash of the public target labels.
+	tracking  bool
+	args      Arguments
+	pressure  int
+	resources resourceUsage
+}
+
+type targetSchedule struct {
+	// interval is the adaptive interval of the target, before resource
+	// pressure is applied.
+	interval time.Duration
+
+	// applied is the interval the target was last passed to the scrape
+	// manager with.
+	applied time.Duration
+
+	// Samples of the last scrape, and the samples seen since the last
+	// evaluation.
+	lastValues     map[uint64]float64
+	changedSamples int
+	totalSamples   int
+}
+
+func newScheduler() *scheduler {
+	return &scheduler{
+		targets: make(map[uint64]*targetSchedule),
+	}
+}
+
+// apply returns targets with their scrape interval set to their adaptive
+// interval. If assign is true, targets without a schedule get a new one,
+// schedules of targets which aren't passed are removed, and targets get their
+// current adaptive interval. Otherwise targets get the interval they were last
+// assigned, which is used for targets moving to another instance.
+//
+// apply also returns the targets whose interval changed with their previous
+// interval, which match the scrape loops being replaced.
+func (s *scheduler) apply(targets []discovery.Target, cfg *config.ScrapeConfig, args Arguments, assign bool) (res, replaced []discovery.Target) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.tracking = args.AdaptiveInterval.Enabled
+	s.args = args
+	if !args.AdaptiveInterval.Enabled {
+		if assign {
+			clear(s.targets)
+		}
+		return targets, nil
+	}
+
+	var (
+		lb   = labels.NewBuilder(labels.EmptyLabels())
+		seen = make(map[uint64]struct{}, len(targets))
+	)
+	res = slices.Clone(targets)
+	for i, t := range targets {
+		lset, err := scrape.PopulateLabels(lb, cfg, t.LabelSet(), nil)
+		if err != nil || lset.IsEmpty() {
+			// The scrape manager drops or reports these targets itself.
+			continue
+		}
+
+		key := publicLabelsHash(lset)
+		state, ok := s.targets[key]
+		if !ok {
+			if !assign {
+				continue
+			}
+			state = &targetSchedule{interval: labelInterval(lset)}
+			s.targets[key] = state
+		}
+		seen[key] = struct{}{}
+
+		if assign {
+			interval := s.effectiveInterval(state, args)
+			if state.applied != 0 && state.applied != interval {
+				replaced = append(replaced, withInterval(t, state.applied))
+			}
+			state.applied = interval
+		}
+		res[i] = withInterval(t, state.applied)
+	}
+
+	if assign {
+		for key := range s.targets {
+			if _, ok := seen[key]; !ok {
+				delete(s.targets, key)
+			}
+		}
+	}
+	return res, replaced
+}
+
+// effectiveInterval applies resource pressure and the configured bounds to
+// the adaptive interval of a target.
+func (s *scheduler) effectiveInterval(state *targetSchedule, args Arguments) time.Duration {
+	adaptive := args.AdaptiveInterval
+	interval := state.interval << s.pressure
+	return min(max(interval, adaptive.minInterval(args.ScrapeInterval)), adaptive.MaxInterval)
+}
+
+// evaluate updates the adaptive intervals from the samples seen since the last
+// evaluation and the current resource usage. It returns whether the interval
+// of any target changed, in which case targets need to be applied again.
+func (s *scheduler) evaluate(args Arguments) bool {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	adaptive := args.AdaptiveInterval
+	if !adaptive.Enabled {
+		return false
+	}
+
+	cpu, memory := s.resources.utilization()
+	constrained := (adaptive.MaxCPUUtilization > 0 && cpu > adaptive.MaxCPUUtilization) ||
+		(adaptive.MaxMemoryUtilization > 0 && memory > adaptive.MaxMemoryUtilization)
+	switch {
+	case constrained && s.pressure < maxPressureLevel:
+		s.pressure++
+	case !constrained && s.pressure > 0:
+		s.pressure--
+	}
+
+	var (
+		minInterval = adaptive.minInterval(args.ScrapeInterval)
+		changed     bool
+	)
+	for _, state := range s.targets {
+		if state.totalSamples > 0 {
+			ratio := float64(state.changedSamples) / float64(state.totalSamples)
+			if ratio < adaptive.MinChangeRatio {
+				state.interval = min(2*state.interval, adaptive.MaxInterval)
+			} else {
+				state.interval = max(state.interval/2, minInterval)
+			}
+		}
+		state.changedSamples, state.totalSamples = 0, 0
+
+		if s.effectiveInterval(state, args) != state.applied {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// record counts how many of the given samples of a target changed since the
+// previous scrape.
+func (s *scheduler) record(key uint64, samples []trackedSample) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	state, ok := s.targets[key]
+	if !ok || !s.tracking {
+		return
+	}
+
+	// Only the series of the last scrape are kept, so that series which are no
+	// longer exposed are forgotten.
+	values := make(map[uint64]float64, len(samples))
+	for _, sample := range samples {
+		// The first sample of a series can't be compared with anything.
+		if last, ok := state.lastValues[sample.hash]; ok {
+			if last != sample.value {
+				state.changedSamples++
+			}
+			state.totalSamples++
+		}
+		values[sample.hash] = sample.value
+	}
+	state.lastValues = values
+}
+
+// targetInterval returns the scrape interval of a scraped target.
+func targetInterval(t *scrape.Target) time.Duration {
+	interval, err := model.ParseDuration(t.GetValue(model.ScrapeIntervalLabel))
+	if err != nil {
+		return 0
+	}
+	return time.Duration(interval)
+}
+
+// labelInterval returns the scrape interval of a target with the given
+// populated labels.
+func labelInterval(lset labels.Labels) time.Duration {
+	interval, err := model.ParseDuration(lset.Get(model.ScrapeIntervalLabel))
+	if err != nil {
+		return 0
+	}
+	return time.Duration(interval)
+}
+
+// publicLabelsHash hashes the labels of a target which aren't reserved, which
+// is the same for a target regardless of its schedule.
+func publicLabelsHash(lset labels.Labels) uint64 {
+	lb := labels.NewBuilder(lset)
+	lset.Range(func(l labels.Label) {
+		if strings.HasPrefix(l.Name, model.ReservedLabelPrefix) {
+			lb.Del(l.Name)
+		}
+	})
+	return lb.Labels().Hash()
+}
+
+func withLabels(t discovery.Target, extra map[string]string) discovery.Target {
+	m := t.AsMap()
+	for k, v := range extra {
+		m[k] = v
+	}
+	return discovery.NewTargetFromMap(m)
+}
+
+// withInterval returns t with its scrape interval set to interval.
+func withInterval(t discovery.Target, interval time.Duration) discovery.Target {
+	return withLabels(t, map[string]string{model.ScrapeIntervalLabel: model.Duration(interval).String()})
+}
+
+// resourceUsage measures the CPU and memory utilization of the process.
+type resourceUsage struct {
+	lastTotal, lastIdle float64
+}
+
+// utilization returns the fraction of available CPU time used since the last
+// call, and the fraction of the Go memory limit in use. The memory
+// utilization is zero if no memory limit is set.
+func (r *resourceUsage) utilization() (cpu, memory float64) {
+	samples := []metrics.Sample{
+		{Name: "/cpu/classes/total:cpu-seconds"},
+		{Name: "/cpu/classes/idle:cpu-seconds"},
+		{Name: "/memory/classes/total:bytes"},
+	}
+	metrics.Read(samples)
+
+	total, idle := samples[0].Value.Float64(), samples[1].Value.Float64()
+	if dTotal := total - r.lastTotal; r.lastTotal > 0 && dTotal > 0 {
+		cpu = 1 - (idle-r.lastIdle)/dTotal
+	}
+	r.lastTotal, r.lastIdle = total, idle
+
+	if limit := debug.SetMemoryLimit(-1); limit > 0 && limit < math.MaxInt64 {
+		memory = float64(samples[2].Value.Uint64()) / float64(limit)
+	}
+	return cpu, memory
+}
+
+type trackedSample struct {
+	hash  uint64
+	value float64
+}
+
+// trackingAppendable records the samples of every scrape for adaptive
+// intervals and the series of every scrape for the cost of targets before
+// passing them on.
+type trackingAppendable struct {
+	next      storage.Appendable
+	scheduler *scheduler
+	costs     *seriesCosts
+}
+
+var _ storage.Appendable = (*trackingAppendable)(nil)
+
+// Appender implements storage.Appendable.
+func (a *trackingAppendable) Appender(ctx context.Context) storage.Appender {
+	app := a.next.Appender(ctx)
+
+	a.scheduler.mut.Lock()
+	tracking := a.scheduler.tracking
+	a.scheduler.mut.Unlock()
+	if !tracking && !a.costs.isEnabled() {
+		return app
+	}
+
+	t, ok := scrape.TargetFromContext(ctx)
+	if !ok {
+		return app
+	}
+	key := t.Labels(labels.NewBuilder(labels.EmptyLabels())).Hash()
+	return &trackingAppender{
+		Appender:  app,
+		scheduler: a.scheduler,
+		costs:     a.costs,
+		tracking:  tracking,
+		key:       key,
+	}
+}
+
+type trackingAppender struct {
+	storage.Appender
+
+	scheduler *scheduler
+	costs     *seriesCosts
+	tracking  bool
+	key       uint64
+	samples   []trackedSample
+	series    int
+}
+
+// Append implements storage.Appender.
+func (a *trackingAppender) Append(ref storage.SeriesRef, l labels.Labels, t int64, v float64) (storage.SeriesRef, error) {
+	if !value.IsStaleNaN(v) && !slices.Contains(reportSeriesNames, l.Get(model.MetricNameLabel)) {
+		if a.tracking {
+			a.samples = append(a.samples, trackedSample{hash: l.Hash(), value: v})
+		}
+		a.series++
+	}
+	return a.Appender.Append(ref, l, t, v)
+}
+
+// AppendHistogram implements storage.Appender.
+func (a *trackingAppender) AppendHistogram(ref storage.SeriesRef, l labels.Labels, t int64, h *histogram.Histogram, fh *histogram.FloatHistogram) (storage.SeriesRef, error) {
+	stale := (h != nil && value.IsStaleNaN(h.Sum)) || (fh != nil && value.IsStaleNaN(fh.Sum))
+	if !stale {
+		a.series++
+	}
+	return a.Appender.AppendHistogram(ref, l, t, h, fh)
+}
+
+// Commit implements storage.Appender.
+func (a *trackingAppender) Commit() error {
+	if a.tracking {
+		a.scheduler.record(a.key, a.samples)
+	}
+	a.costs.record(a.key, a.series)
+	a.samples, a.series = nil, 0
+	return a.Appender.Commit()
+}
+
+// Rollback implements storage.Appender.
+func (a *trackingAppender) Rollback() error {
+	a.samples, a.series = nil, 0
+	return a.Appender.Rollback()
+}
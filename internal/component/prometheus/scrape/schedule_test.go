@@ -0,0 +1,210 @@
+package scrape
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/config"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/scrape"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/component/discovery"
+	"github.com/grafana/alloy/syntax"
+)
+
+func TestAdaptiveIntervalValidation(t *testing.T) {
+	tests := []struct {
+		name          string
+		config        string
+		errorContains string
+	}{
+		{
+			name: "valid",
+			config: `
+				scrape_interval = "30s"
+				adaptive_interval {
+					enabled      = true
+					min_interval = "15s"
+					max_interval = "4m"
+				}
+			`,
+		},
+		{
+			name: "disabled block isn't validated",
+			config: `
+				adaptive_interval {
+					max_interval = "1s"
+				}
+			`,
+		},
+		{
+			name: "max_interval less than scrape_interval",
+			config: `
+				adaptive_interval {
+					enabled      = true
+					max_interval = "30s"
+				}
+			`,
+			errorContains: "adaptive_interval max_interval (30s) less than scrape_interval (1m0s)",
+		},
+		{
+			name: "max_interval not less than the lookback delta",
+			config: `
+				adaptive_interval {
+					enabled      = true
+					max_interval = "5m"
+				}
+			`,
+			errorContains: "adaptive_interval max_interval (5m0s) must be less than 5m0s",
+		},
+		{
+			name: "min_interval less than scrape_timeout",
+			config: `
+				adaptive_interval {
+					enabled      = true
+					min_interval = "5s"
+				}
+			`,
+			errorContains: "adaptive_interval min_interval (5s) less than scrape_timeout (10s)",
+		},
+		{
+			name: "invalid change ratio",
+			config: `
+				adaptive_interval {
+					enabled          = true
+					min_change_ratio = 2
+				}
+			`,
+			errorContains: "adaptive_interval min_change_ratio must be between 0 and 1",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var args Arguments
+			err := syntax.Unmarshal([]byte(`
+				targets    = []
+				forward_to = []
+			`+tc.config), &args)
+			if tc.errorContains != "" {
+				require.ErrorContains(t, err, tc.errorContains)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSchedulerAdaptiveInterval(t *testing.T) {
+	args := testScheduleArgs(t, `
+		adaptive_interval {
+			enabled             = true
+			max_interval        = "4m"
+			max_cpu_utilization = 0
+		}
+	`)
+	cfg := getPromScrapeConfigs("test", args)
+	targets := testScheduleTargets(2)
+
+	s := newScheduler()
+	res, replaced := s.apply(targets, cfg, args, true)
+	require.Equal(t, []string{"1m", "1m"}, testIntervals(t, res))
+	require.Empty(t, replaced)
+
+	static, changing := testTargetKey(t, cfg, targets[0]), testTargetKey(t, cfg, targets[1])
+	recordScrapes := func(n int) {
+		for i := range n {
+			s.record(static, []trackedSample{{hash: 1, value: 1}, {hash: 2, value: 2}})
+			s.record(changing, []trackedSample{{hash: 1, value: float64(i)}, {hash: 2, value: 2}})
+		}
+	}
+	recordScrapes(3)
+	require.True(t, s.evaluate(args))
+
+	// The interval is applied to the target, and the target with its previous
+	// interval is returned to replace its scrape loop.
+	res, replaced = s.apply(targets, cfg, args, true)
+	require.Equal(t, []string{"2m", "1m"}, testIntervals(t, res))
+	require.Equal(t, []string{"1m"}, testIntervals(t, replaced))
+	require.Equal(t, getLabel(t, targets[0], model.AddressLabel), getLabel(t, replaced[0], model.AddressLabel))
+
+	// Targets moving to another instance keep the interval of their scrape
+	// loop.
+	moved, _ := s.apply(targets[:1], cfg, args, false)
+	require.Equal(t, []string{"2m"}, testIntervals(t, moved))
+
+	// Nothing changes while the intervals stay the same.
+	recordScrapes(3)
+	s.record(changing, []trackedSample{{hash: 1, value: 10}, {hash: 2, value: 20}})
+	require.True(t, s.evaluate(args))
+	res, _ = s.apply(targets, cfg, args, true)
+	require.Equal(t, []string{"4m", "1m"}, testIntervals(t, res))
+	s.record(changing, []trackedSample{{hash: 1, value: 11}, {hash: 2, value: 21}})
+	require.False(t, s.evaluate(args), "intervals at their bounds shouldn't change")
+
+	// Once samples change, the interval is lowered again.
+	s.record(static, []trackedSample{{hash: 1, value: 5}, {hash: 2, value: 6}})
+	require.True(t, s.evaluate(args))
+	res, _ = s.apply(targets, cfg, args, true)
+	require.Equal(t, []string{"2m", "1m"}, testIntervals(t, res))
+
+	// Series which are no longer scraped are forgotten.
+	s.record(static, []trackedSample{{hash: 1, value: 5}})
+	require.Len(t, s.targets[static].lastValues, 1)
+}
+
+func TestSchedulerDisabled(t *testing.T) {
+	args := testScheduleArgs(t, ``)
+	cfg := getPromScrapeConfigs("test", args)
+	targets := testScheduleTargets(2)
+
+	s := newScheduler()
+	res, replaced := s.apply(targets, cfg, args, true)
+	require.Equal(t, targets, res, "targets shouldn't change without adaptive intervals")
+	require.Empty(t, replaced)
+	require.False(t, s.evaluate(args))
+}
+
+// testIntervals returns the scrape interval labels of targets.
+func testIntervals(t *testing.T, targets []discovery.Target) []string {
+	t.Helper()
+
+	res := make([]string, 0, len(targets))
+	for _, target := range targets {
+		res = append(res, getLabel(t, target, model.ScrapeIntervalLabel))
+	}
+	return res
+}
+
+func testScheduleArgs(t *testing.T, config string) Arguments {
+	var args Arguments
+	require.NoError(t, syntax.Unmarshal([]byte(`
+		targets    = []
+		forward_to = []
+	`+config), &args))
+	return args
+}
+
+func testScheduleTargets(n int) []discovery.Target {
+	targets := make([]discovery.Target, 0, n)
+	for i := range n {
+		targets = append(targets, discovery.NewTargetFromMap(map[string]string{
+			model.AddressLabel: fmt.Sprintf("target-%d:9090", i),
+		}))
+	}
+	return targets
+}
+
+func testTargetKey(t *testing.T, cfg *config.ScrapeConfig, target discovery.Target) uint64 {
+	lset, err := scrape.PopulateLabels(labels.NewBuilder(labels.EmptyLabels()), cfg, target.LabelSet(), nil)
+	require.NoError(t, err)
+	return publicLabelsHash(lset)
+}
+
+func getLabel(t *testing.T, target discovery.Target, name string) string {
+	v, ok := target.Get(name)
+	require.True(t, ok, "missing label %q", name)
+	return v
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/alecthomas/units"
+	"github.com/grafana/ckit/shard"
 	client_prometheus "github.com/prometheus/client_golang/prometheus"
 	config_util "github.com/prometheus/common/config"
 	"github.com/prometheus/common/model"
@@ -139,7 +140,10 @@ type Arguments struct {
 	// buckets will be merged to stay within the limit. Disabled when set zero.
 	NativeHistogramMinBucketFactor float64 `alloy:"native_histogram_min_bucket_factor,attr,optional"`
 
-	Clustering cluster.ComponentBlock `alloy:"clustering,block,optional"`
+	// Settings for adjusting the scrape interval of each target.
+	AdaptiveInterval AdaptiveIntervalArguments `alloy:"adaptive_interval,block,optional"`
+
+	Clustering ClusteringArguments `alloy:"clustering,block,optional"`
 }
 
 // SetToDefault implements syntax.Defaulter.
@@ -162,6 +166,8 @@ func (arg *Arguments) SetToDefault() {
 		EnableCompression:              true,
 		NativeHistogramBucketLimit:     0,
 		NativeHistogramMinBucketFactor: 0,
+		AdaptiveInterval:               DefaultAdaptiveIntervalArguments,
+		Clustering:                     DefaultClusteringArguments,
 	}
 }
 
@@ -240,6 +246,10 @@ func (arg *Arguments) Validate() error {
 		return fmt.Errorf("metric_name_escaping_scheme cannot be set to 'allow-utf-8' while metric_name_validation_scheme is not set to 'utf8'")
 	}
 
+	if err := arg.AdaptiveInterval.validate(arg.ScrapeInterval, arg.ScrapeTimeout); err != nil {
+		return err
+	}
+
 	// We must explicitly Validate because HTTPClientConfig is squashed and it won't run otherwise
 	return arg.HTTPClientConfig.Validate()
 }
@@ -266,7 +276,13 @@ type Component struct {
 	args            Arguments
 	scraper         *scrape.Manager
 	appendable      *prometheus.Fanout
+	scheduler       *scheduler
+	offsets         *offsetChecker
+	costs           *seriesCosts
+	handoff         *discovery.TargetHandoff
+	handoffSalts    map[shard.Key]string // Salts aligning targets handed off by other nodes, by shard key.
 	firstUpdateDone bool
+	targetInContext bool // Whether the scrape manager passes targets to appenders.
 
 	dtMutex            sync.Mutex
 	distributedTargets *discovery.DistributedTargets
@@ -313,6 +329,10 @@ func New(o component.Options, args Arguments) (*Component, error) {
 		},
 		// NOTE: This is not Update()-able.
 		EnableNativeHistogramsIngestion: args.ScrapeNativeHistograms,
+		// Passes the target to the appender, so that samples can be attributed
+		// to their target for adaptive scrape intervals and series costs.
+		// NOTE: This is not Update()-able.
+		PassMetadataInContext: args.needsTargetInContext(),
 	}
 
 	unregisterer := util.WrapWithUnregisterer(o.Registerer)
@@ -339,6 +359,12 @@ func New(o component.Options, args Arguments) (*Component, error) {
 		reloadTargets:       make(chan struct{}, 1),
 		debugDataPublisher:  debugDataPublisher.(livedebugging.DebugDataPublisher),
 		appendable:          alloyAppendable,
+		scheduler:           newScheduler(),
+		offsets:             newOffsetChecker(),
+		costs:               newSeriesCosts(),
+		handoff:             discovery.NewTargetHandoff(clusterData, o.ID, o.Logger),
+		handoffSalts:        make(map[shard.Key]string),
+		targetInContext:     scrapeOptions.PassMetadataInContext,
 		targetsGauge:        targetsGauge,
 		movedTargetsCounter: movedTargetsCounter,
 		unregisterer:        unregisterer,
@@ -350,7 +376,7 @@ func New(o component.Options, args Arguments) (*Component, error) {
 		scrapeOptions,
 		slog.New(logging.NewSlogGoKitHandler(c.opts.Logger)),
 		func(s string) (*promlogging.JSONFileLogger, error) { return promlogging.NewJSONFileLogger(s) },
-		interceptor,
+		&trackingAppendable{next: interceptor, scheduler: c.scheduler, costs: c.costs},
 		unregisterer)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create scrape manager: %w", err)
@@ -384,10 +410,64 @@ func (c *Component) Run(ctx context.Context) error {
 		}
 	}()
 
+	c.mut.RLock()
+	evaluationInterval := c.args.AdaptiveInterval.evaluationInterval()
+	c.mut.RUnlock()
+	evaluateTicker := time.NewTicker(evaluationInterval)
+	defer evaluateTicker.Stop()
+
+	costReportTicker := time.NewTicker(costReportInterval)
+	defer costReportTicker.Stop()
+	defer c.reportCosts(nil)
+
+	handoffTicker := time.NewTicker(handoffCheckInterval)
+	defer handoffTicker.Stop()
+	defer c.handoff.Close()
+	handoffNotify := c.handoff.Notify()
+
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
+		case <-costReportTicker.C:
+			c.reportCosts(c.costs.costs())
+		case <-handoffNotify:
+			select {
+			case c.reloadTargets <- struct{}{}:
+			default:
+			}
+		case <-handoffTicker.C:
+			if c.handoff.Confirming() {
+				scraped := c.scrapedTargets(c.jobName())
+				lastScrapes := make(map[shard.Key]time.Time, len(scraped))
+				for key, h := range scraped {
+					lastScrapes[key] = h.LastRun
+				}
+				c.handoff.Confirm(ctx, lastScrapes)
+			}
+			if c.handoff.Due() {
+				select {
+				case c.reloadTargets <- struct{}{}:
+				default:
+				}
+			}
+		case <-evaluateTicker.C:
+			c.mut.RLock()
+			args := c.args
+			c.mut.RUnlock()
+
+			evaluateTicker.Reset(args.AdaptiveInterval.evaluationInterval())
+			reload := c.scheduler.evaluate(args)
+			if c.offsets.check(c.scraper.TargetsActive()[c.jobName()], time.Now()) {
+				level.Warn(c.opts.Logger).Log("msg", "scrape offsets don't match the offsets predicted for aligning handed off targets, no longer aligning them")
+				reload = true
+			}
+			if reload {
+				select {
+				case c.reloadTargets <- struct{}{}:
+				default:
+				}
+			}
 		case <-c.reloadTargets:
 			c.mut.RLock()
 			var (
@@ -401,7 +481,7 @@ func (c *Component) Run(ctx context.Context) error {
 				jobName = c.args.JobName
 			}
 
-			newTargetGroups, movedTargets := c.distributeTargets(targets, jobName, args)
+			newTargetGroups, movedTargets, offers := c.distributeTargets(targets, jobName, args)
 
 			// Make sure the targets that moved to another instance are NOT marked as stale. This is specific to how
 			// Prometheus handles marking series as stale: it is the client's responsibility to inject the
@@ -409,6 +489,15 @@ func (c *Component) Run(ctx context.Context) error {
 			// over this responsibility to the new owning instance. We must not inject staleness marker here.
 			c.scraper.DisableEndOfRunStalenessMarkers(jobName, movedTargets)
 
+			// Offer the targets which moved to their new owner, which continues
+			// scraping them on the schedule of this instance.
+			if len(offers) > 0 {
+				scraped := c.scrapedTargets(jobName)
+				c.handoff.Offer(ctx, offers, func(key shard.Key) (time.Time, time.Duration) {
+					return scraped[key].LastRun, scraped[key].Interval
+				})
+			}
+
 			select {
 			case targetSetsChan <- newTargetGroups:
 				level.Debug(c.opts.Logger).Log("msg", "passed new targets to scrape manager")
@@ -422,12 +511,21 @@ func (c *Component) distributeTargets(
 	targets []discovery.Target,
 	jobName string,
 	args Arguments,
-) (map[string][]*targetgroup.Group, []*scrape.Target) {
+) (map[string][]*targetgroup.Group, []*scrape.Target, []discovery.MovedTarget) {
 
 	var (
-		newDistTargets        = discovery.NewDistributedTargets(args.Clustering.Enabled, c.cluster, targets)
+		newDistTargets        *discovery.DistributedTargets
 		oldDistributedTargets *discovery.DistributedTargets
+		costAware             = args.Clustering.Enabled && args.Clustering.CostModel == CostModelSeries
 	)
+	switch {
+	case costAware:
+		newDistTargets = discovery.NewCostAwareDistributedTargets(args.Clustering.Enabled, c.cluster, targets, c.opts.ID)
+	case args.Clustering.ReplicationFactor > 1:
+		newDistTargets = discovery.NewReplicatedDistributedTargets(args.Clustering.Enabled, c.cluster, targets, args.Clustering.ReplicationFactor)
+	default:
+		newDistTargets = discovery.NewDistributedTargets(args.Clustering.Enabled, c.cluster, targets)
+	}
 
 	c.dtMutex.Lock()
 	oldDistributedTargets, c.distributedTargets = c.distributedTargets, newDistTargets
@@ -435,16 +533,35 @@ func (c *Component) distributeTargets(
 
 	newLocalTargets := newDistTargets.LocalTargets()
 	c.targetsGauge.Set(float64(len(newLocalTargets)))
+	c.movedTargetsCounter.Add(float64(len(newDistTargets.MovedToRemoteInstance(oldDistributedTargets))))
+
+	scrapeConfig := getPromScrapeConfigs(jobName, args)
+	c.costs.update(costAware, newLocalTargets, newDistTargets.LocalTargetKeys(), scrapeConfig)
+
+	// Targets which moved to another instance are scraped until they're
+	// handed off, if handoffs are enabled.
+	handoff := c.handoff.Update(oldDistributedTargets, newDistTargets, args.Clustering.Enabled)
+
+	// Add the labels which set the scrape interval and offset of each target.
+	// Moved targets keep their previous schedule so that they match the
+	// currently running scrape loops.
+	movedTargets, _ := c.scheduler.apply(handoff.Released, scrapeConfig, args, false)
+	movedTargets = c.applyHandoffSalts(movedTargets)
+	newLocalTargets, rescheduled := c.scheduler.apply(handoff.Targets, scrapeConfig, args, true)
+	newLocalTargets = c.alignHandoffs(newLocalTargets, handoff.Keys, handoff.Aligned, scrapeConfig)
 	promNewTargets := discovery.ComponentTargetsToPromTargetGroups(jobName, newLocalTargets)
 
-	movedTargets := newDistTargets.MovedToRemoteInstance(oldDistributedTargets)
-	c.movedTargetsCounter.Add(float64(len(movedTargets)))
+	// Targets whose adaptive interval changed keep being scraped by a new scrape
+	// loop, so the loop they're currently scraped by must not mark their series
+	// as stale either.
+	movedTargets = append(movedTargets, c.applyHandoffSalts(rescheduled)...)
+
 	// For moved targets, we need to populate prom labels in the same way as the scraper does, so that they match
 	// the currently running scrape loop's targets. This is not needed for new targets, as they will be populated
 	// by the scrape loop itself during the sync.
 	promMovedTargets := c.populatePromLabels(movedTargets, jobName, args)
 
-	return promNewTargets, promMovedTargets
+	return promNewTargets, promMovedTargets, handoff.Moved
 }
 
 // Update implements component.Component.
@@ -465,6 +582,9 @@ func (c *Component) Update(args component.Arguments) error {
 		if c.args.ExtraMetrics != newArgs.ExtraMetrics {
 			return fmt.Errorf("extra_metrics cannot be updated at runtime")
 		}
+		if newArgs.needsTargetInContext() && !c.targetInContext {
+			return fmt.Errorf("adaptive_interval and clustering cost_model %q cannot be enabled at runtime", CostModelSeries)
+		}
 	}
 
 	c.args = newArgs
@@ -507,6 +627,13 @@ func (c *Component) NotifyClusterChange() {
 	}
 }
 
+// reportCosts shares the costs of the local targets with the cluster.
+func (c *Component) reportCosts(costs map[shard.Key]float64) {
+	if costAware, ok := c.cluster.(cluster.CostAwareCluster); ok {
+		costAware.ReportCosts(c.opts.ID, costs)
+	}
+}
+
 // Helper function to bridge the in-house configuration with the Prometheus
 // scrape_config.
 // As explained in the Config struct, the following fields are purposefully
@@ -574,6 +701,7 @@ type TargetStatus struct {
 	LastError          string            `alloy:"last_error,attr,optional"`
 	LastScrape         time.Time         `alloy:"last_scrape,attr"`
 	LastScrapeDuration time.Duration     `alloy:"last_scrape_duration,attr,optional"`
+	ScrapeInterval     time.Duration     `alloy:"scrape_interval,attr,optional"`
 }
 
 // BuildTargetStatuses transforms the targets from a scrape manager into our internal status type for debug info.
@@ -596,6 +724,7 @@ func BuildTargetStatuses(targets map[string][]*scrape.Target) []TargetStatus {
 					LastError:          lastError,
 					LastScrape:         st.LastScrape(),
 					LastScrapeDuration: st.LastScrapeDuration(),
+					ScrapeInterval:     targetInterval(st),
 				})
 			}
 		}
@@ -0,0 +1,331 @@
+package serve
+
+import (
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	prometheus_client "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/metadata"
+	"github.com/prometheus/prometheus/promql/parser"
+	"google.golang.org/protobuf/proto"
+)
+
+// handleMetrics exposes the latest sample of every series, optionally
+// filtered by match[] selectors like the Prometheus /federate endpoint.
+func (c *Component) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var matcherSets [][]*labels.Matcher
+	for _, s := range r.Form["match[]"] {
+		matchers, err := parser.ParseMetricSelector(s)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		matcherSets = append(matcherSets, matchers)
+	}
+
+	gatherer := prometheus_client.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		return c.store.gather(time.Now(), matcherSets), nil
+	})
+	promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{
+		EnableOpenMetrics: true,
+	}).ServeHTTP(w, r)
+}
+
+// gather converts the stored series into metric families. The type and help
+// of a family are taken from the metadata received for it, and float samples
+// without metadata are exposed as untyped metrics. Series of classic
+// histograms and summaries are combined into a single metric.
+//
+// Labels starting with "__" aren't exposed. If several series have the same
+// labels without them, only the latest sample is exposed. Families are sorted
+// by name and metrics by their labels.
+func (s *seriesStore) gather(now time.Time, matcherSets [][]*labels.Matcher) []*dto.MetricFamily {
+	families := map[string]*family{}
+
+	s.mut.RLock()
+	includeTimestamps := s.includeTimestamps
+	s.mut.RUnlock()
+
+	s.collect(now, matcherSets, func(sample *latestSample) {
+		name := sample.lset.Get(labels.MetricName)
+		if name == "" {
+			return
+		}
+		md, _ := s.metadataLocked(name)
+		familyName, typ := familyOf(name, sample, md)
+
+		f, ok := families[familyName]
+		if !ok {
+			f = newFamily(familyName, typ, md.Help)
+			families[familyName] = f
+		} else if f.mf.GetType() != typ {
+			// A family can't mix samples of different types.
+			return
+		}
+		f.add(name, sample)
+	})
+
+	res := make([]*dto.MetricFamily, 0, len(families))
+	for _, f := range families {
+		res = append(res, f.build(includeTimestamps))
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].GetName() < res[j].GetName()
+	})
+	return res
+}
+
+// familyOf returns the name and type of the metric family a sample with the
+// given metric name and metadata belongs to.
+func familyOf(name string, sample *latestSample, md metadata.Metadata) (string, dto.MetricType) {
+	if sample.fh != nil {
+		return name, dto.MetricType_HISTOGRAM
+	}
+
+	switch md.Type {
+	case model.MetricTypeCounter:
+		return name, dto.MetricType_COUNTER
+	case model.MetricTypeGauge:
+		return name, dto.MetricType_GAUGE
+	case model.MetricTypeHistogram:
+		if base := trimClassicSuffix(name); base != name {
+			return base, dto.MetricType_HISTOGRAM
+		}
+	case model.MetricTypeSummary:
+		if sample.lset.Has(model.QuantileLabel) {
+			return name, dto.MetricType_SUMMARY
+		}
+		if base := trimClassicSuffix(name); base != name && !strings.HasSuffix(name, "_bucket") {
+			return base, dto.MetricType_SUMMARY
+		}
+	}
+	return name, dto.MetricType_UNTYPED
+}
+
+// family collects the metrics of a metric family.
+type family struct {
+	mf      *dto.MetricFamily
+	metrics map[string]*metric // Metrics by their exposed labels.
+}
+
+// metric collects the samples of the series which make up a single metric.
+// Classic histograms and summaries are made up of several series, all other
+// metrics of a single series.
+type metric struct {
+	lset labels.Labels
+	t    int64
+
+	value float64
+	fh    *histogram.FloatHistogram
+
+	// Samples of classic histograms and summaries, by their upper bound or
+	// quantile.
+	buckets   map[float64]float64
+	quantiles map[float64]float64
+	sum       float64
+	count     float64
+}
+
+func newFamily(name string, typ dto.MetricType, help string) *family {
+	mf := &dto.MetricFamily{Name: proto.String(name), Type: typ.Enum()}
+	if help != "" {
+		mf.Help = proto.String(help)
+	}
+	return &family{mf: mf, metrics: map[string]*metric{}}
+}
+
+// add adds the sample of a series with the given metric name to the family.
+func (f *family) add(name string, sample *latestSample) {
+	typ := f.mf.GetType()
+
+	var (
+		b     = labels.NewScratchBuilder(sample.lset.Len())
+		bound = math.NaN()
+		err   error
+	)
+	sample.lset.Range(func(l labels.Label) {
+		switch {
+		// Internal labels, including the metric name, aren't exposed.
+		case strings.HasPrefix(l.Name, "__"):
+		case typ == dto.MetricType_HISTOGRAM && sample.fh == nil && l.Name == model.BucketLabel:
+			bound, err = strconv.ParseFloat(l.Value, 64)
+		case typ == dto.MetricType_SUMMARY && l.Name == model.QuantileLabel:
+			bound, err = strconv.ParseFloat(l.Value, 64)
+		default:
+			b.Add(l.Name, l.Value)
+		}
+	})
+	if err != nil {
+		return
+	}
+	lset := b.Labels()
+
+	key := lset.String()
+	m, ok := f.metrics[key]
+	if !ok {
+		m = &metric{lset: lset, t: math.MinInt64}
+		f.metrics[key] = m
+	}
+
+	switch {
+	case typ == dto.MetricType_HISTOGRAM && sample.fh == nil:
+		switch {
+		case strings.HasSuffix(name, "_bucket"):
+			if math.IsNaN(bound) {
+				return
+			}
+			if m.buckets == nil {
+				m.buckets = map[float64]float64{}
+			}
+			m.buckets[bound] = sample.v
+		case strings.HasSuffix(name, "_sum"):
+			m.sum = sample.v
+		case strings.HasSuffix(name, "_count"):
+			m.count = sample.v
+		}
+		m.t = max(m.t, sample.t)
+
+	case typ == dto.MetricType_SUMMARY:
+		switch {
+		case !math.IsNaN(bound):
+			if m.quantiles == nil {
+				m.quantiles = map[float64]float64{}
+			}
+			m.quantiles[bound] = sample.v
+		case strings.HasSuffix(name, "_sum"):
+			m.sum = sample.v
+		case strings.HasSuffix(name, "_count"):
+			m.count = sample.v
+		}
+		m.t = max(m.t, sample.t)
+
+	default:
+		// Series which only differ in internal labels are exposed as the same
+		// metric, so only the latest sample is kept.
+		if sample.t < m.t {
+			return
+		}
+		m.t = sample.t
+		m.value = sample.v
+		m.fh = sample.fh
+	}
+}
+
+// build returns the metric family with its metrics sorted by their labels.
+func (f *family) build(includeTimestamps bool) *dto.MetricFamily {
+	metrics := make([]*metric, 0, len(f.metrics))
+	for _, m := range f.metrics {
+		metrics = append(metrics, m)
+	}
+	sort.Slice(metrics, func(i, j int) bool {
+		return labels.Compare(metrics[i].lset, metrics[j].lset) < 0
+	})
+
+	for _, m := range metrics {
+		dm := &dto.Metric{}
+		m.lset.Range(func(l labels.Label) {
+			dm.Label = append(dm.Label, &dto.LabelPair{Name: proto.String(l.Name), Value: proto.String(l.Value)})
+		})
+		if includeTimestamps {
+			dm.TimestampMs = proto.Int64(m.t)
+		}
+
+		switch f.mf.GetType() {
+		case dto.MetricType_COUNTER:
+			dm.Counter = &dto.Counter{Value: proto.Float64(m.value)}
+		case dto.MetricType_GAUGE:
+			dm.Gauge = &dto.Gauge{Value: proto.Float64(m.value)}
+		case dto.MetricType_HISTOGRAM:
+			if m.fh != nil {
+				dm.Histogram = toDTOHistogram(m.fh)
+			} else {
+				dm.Histogram = toDTOClassicHistogram(m)
+			}
+		case dto.MetricType_SUMMARY:
+			dm.Summary = toDTOSummary(m)
+		default:
+			dm.Untyped = &dto.Untyped{Value: proto.Float64(m.value)}
+		}
+		f.mf.Metric = append(f.mf.Metric, dm)
+	}
+	return f.mf
+}
+
+func toDTOClassicHistogram(m *metric) *dto.Histogram {
+	h := &dto.Histogram{
+		SampleCount: proto.Uint64(uint64(m.count)),
+		SampleSum:   proto.Float64(m.sum),
+	}
+	for _, bound := range slices.Sorted(maps.Keys(m.buckets)) {
+		h.Bucket = append(h.Bucket, &dto.Bucket{
+			UpperBound:      proto.Float64(bound),
+			CumulativeCount: proto.Uint64(uint64(m.buckets[bound])),
+		})
+	}
+	return h
+}
+
+func toDTOSummary(m *metric) *dto.Summary {
+	s := &dto.Summary{
+		SampleCount: proto.Uint64(uint64(m.count)),
+		SampleSum:   proto.Float64(m.sum),
+	}
+	for _, q := range slices.Sorted(maps.Keys(m.quantiles)) {
+		s.Quantile = append(s.Quantile, &dto.Quantile{
+			Quantile: proto.Float64(q),
+			Value:    proto.Float64(m.quantiles[q]),
+		})
+	}
+	return s
+}
+
+// toDTOHistogram converts a native histogram for exposition. The integer
+// sample count is only set for the text formats, which don't support native
+// histograms and only expose the sum and count.
+func toDTOHistogram(fh *histogram.FloatHistogram) *dto.Histogram {
+	h := &dto.Histogram{
+		SampleCount:      proto.Uint64(uint64(fh.Count)),
+		SampleCountFloat: proto.Float64(fh.Count),
+		SampleSum:        proto.Float64(fh.Sum),
+		Schema:           proto.Int32(fh.Schema),
+		ZeroThreshold:    proto.Float64(fh.ZeroThreshold),
+		ZeroCountFloat:   proto.Float64(fh.ZeroCount),
+		NegativeSpan:     toDTOSpans(fh.NegativeSpans),
+		NegativeCount:    fh.NegativeBuckets,
+		PositiveSpan:     toDTOSpans(fh.PositiveSpans),
+		PositiveCount:    fh.PositiveBuckets,
+	}
+	if len(h.PositiveSpan) == 0 && len(h.NegativeSpan) == 0 && fh.ZeroThreshold == 0 {
+		// Add a no-op span so that consumers can tell apart a native histogram
+		// without buckets from a classic histogram.
+		h.PositiveSpan = []*dto.BucketSpan{{Offset: proto.Int32(0), Length: proto.Uint32(0)}}
+	}
+	return h
+}
+
+func toDTOSpans(spans []histogram.Span) []*dto.BucketSpan {
+	res := make([]*dto.BucketSpan, 0, len(spans))
+	for _, s := range spans {
+		res = append(res, &dto.BucketSpan{
+			Offset: proto.Int32(s.Offset),
+			Length: proto.Uint32(s.Length),
+		})
+	}
+	return res
+}
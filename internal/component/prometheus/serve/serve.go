@@ -0,0 +1,129 @@
+package serve
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	prometheus_client "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/storage"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/featuregate"
+)
+
+const name = "prometheus.serve"
+
+func init() {
+	component.Register(component.Registration{
+		Name:      name,
+		Stability: featuregate.StabilityExperimental,
+		Args:      Arguments{},
+		Exports:   Exports{},
+
+		Build: func(opts component.Options, args component.Arguments) (component.Component, error) {
+			return New(opts, args.(Arguments))
+		},
+	})
+}
+
+// Arguments holds values which are used to configure the prometheus.serve
+// component.
+type Arguments struct {
+	// How long a series is exposed after its last sample was received.
+	MaxIdleDuration time.Duration `alloy:"max_idle_duration,attr,optional"`
+
+	// Maximum number of series to keep in memory. Zero means no limit.
+	MaxSeries int `alloy:"max_series,attr,optional"`
+
+	// Whether to expose the timestamp of each sample.
+	IncludeTimestamps bool `alloy:"include_timestamps,attr,optional"`
+}
+
+// SetToDefault implements syntax.Defaulter.
+func (args *Arguments) SetToDefault() {
+	*args = Arguments{
+		MaxIdleDuration:   5 * time.Minute,
+		MaxSeries:         100_000,
+		IncludeTimestamps: true,
+	}
+}
+
+// Validate implements syntax.Validator.
+func (args *Arguments) Validate() error {
+	if args.MaxIdleDuration <= 0 {
+		return fmt.Errorf("max_idle_duration must be greater than 0 and is %s", args.MaxIdleDuration)
+	}
+	if args.MaxSeries < 0 {
+		return fmt.Errorf("max_series must not be negative and is %d", args.MaxSeries)
+	}
+	return nil
+}
+
+// Exports holds values which are exported by the prometheus.serve component.
+type Exports struct {
+	Receiver storage.Appendable `alloy:"receiver,attr"`
+}
+
+// Component implements the prometheus.serve component.
+type Component struct {
+	opts  component.Options
+	store *seriesStore
+}
+
+var (
+	_ component.Component = (*Component)(nil)
+)
+
+// New creates a new prometheus.serve component.
+func New(o component.Options, args Arguments) (*Component, error) {
+	c := &Component{
+		opts:  o,
+		store: newSeriesStore(),
+	}
+
+	for _, metric := range []prometheus_client.Collector{c.store.seriesGauge, c.store.droppedSeries} {
+		if err := o.Registerer.Register(metric); err != nil {
+			return nil, err
+		}
+	}
+
+	// The receiver remains the same for the component lifetime.
+	o.OnStateChange(Exports{Receiver: c.store})
+
+	if err := c.Update(args); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Run implements component.Component.
+func (c *Component) Run(ctx context.Context) error {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			c.store.expire(time.Now())
+		}
+	}
+}
+
+// Update implements component.Component.
+func (c *Component) Update(args component.Arguments) error {
+	newArgs := args.(Arguments)
+	c.store.setLimits(newArgs.MaxIdleDuration, newArgs.MaxSeries, newArgs.IncludeTimestamps)
+	return nil
+}
+
+// Handler implements the HTTP handler of the component. The latest value of
+// every series is exposed under /metrics.
+func (c *Component) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", c.handleMetrics)
+	return mux
+}
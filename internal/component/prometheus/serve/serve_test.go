@@ -0,0 +1,228 @@
+package serve
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	prometheus_client "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/metadata"
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/util"
+	"github.com/grafana/alloy/syntax"
+)
+
+func TestArguments(t *testing.T) {
+	var args Arguments
+	require.NoError(t, syntax.Unmarshal([]byte(``), &args))
+	require.Equal(t, 5*time.Minute, args.MaxIdleDuration)
+	require.Equal(t, 100_000, args.MaxSeries)
+	require.True(t, args.IncludeTimestamps)
+
+	err := syntax.Unmarshal([]byte(`max_idle_duration = "0s"`), &args)
+	require.ErrorContains(t, err, "max_idle_duration must be greater than 0")
+
+	err = syntax.Unmarshal([]byte(`max_series = -1`), &args)
+	require.ErrorContains(t, err, "max_series must not be negative")
+}
+
+func TestServe(t *testing.T) {
+	c := newTestComponent(t, Arguments{
+		MaxIdleDuration:   time.Minute,
+		MaxSeries:         10,
+		IncludeTimestamps: false,
+	})
+
+	app := c.store.Appender(t.Context())
+	_, err := app.Append(0, labels.FromStrings("__name__", "up", "job", "a"), 1000, 1)
+	require.NoError(t, err)
+	_, err = app.Append(0, labels.FromStrings("__name__", "up", "job", "b"), 1000, 0)
+	require.NoError(t, err)
+	_, err = app.Append(0, labels.FromStrings("__name__", "requests_total", "job", "a", "__internal", "x"), 1000, 42)
+	require.NoError(t, err)
+	require.NoError(t, app.Commit())
+
+	require.Equal(t, `# TYPE requests_total untyped
+requests_total{job="a"} 42
+# TYPE up untyped
+up{job="a"} 1
+up{job="b"} 0
+`, scrape(t, c, nil))
+
+	require.Equal(t, `# TYPE up untyped
+up{job="b"} 0
+`, scrape(t, c, url.Values{"match[]": {`up{job="b"}`}}))
+
+	// Out-of-order samples are ignored and stale markers remove the series.
+	app = c.store.Appender(t.Context())
+	_, err = app.Append(0, labels.FromStrings("__name__", "up", "job", "a"), 500, 0)
+	require.NoError(t, err)
+	_, err = app.Append(0, labels.FromStrings("__name__", "requests_total", "job", "a", "__internal", "x"), 2000, math.Float64frombits(value.StaleNaN))
+	require.NoError(t, err)
+	require.NoError(t, app.Commit())
+
+	require.Equal(t, `# TYPE up untyped
+up{job="a"} 1
+up{job="b"} 0
+`, scrape(t, c, nil))
+
+	// Rolled back samples are never exposed.
+	app = c.store.Appender(t.Context())
+	_, err = app.Append(0, labels.FromStrings("__name__", "up", "job", "c"), 1000, 1)
+	require.NoError(t, err)
+	require.NoError(t, app.Rollback())
+	require.NotContains(t, scrape(t, c, nil), `job="c"`)
+}
+
+func TestServe_Metadata(t *testing.T) {
+	c := newTestComponent(t, Arguments{
+		MaxIdleDuration:   time.Minute,
+		IncludeTimestamps: false,
+	})
+
+	app := c.store.Appender(t.Context())
+	appendWithMetadata := func(l labels.Labels, v float64, md metadata.Metadata) {
+		_, err := app.Append(0, l, 1000, v)
+		require.NoError(t, err)
+		_, err = app.UpdateMetadata(0, l, md)
+		require.NoError(t, err)
+	}
+	counter := metadata.Metadata{Type: model.MetricTypeCounter, Help: "Total requests."}
+	appendWithMetadata(labels.FromStrings("__name__", "requests_total", "job", "a"), 5, counter)
+	appendWithMetadata(labels.FromStrings("__name__", "temperature", "job", "a"), 21.5, metadata.Metadata{Type: model.MetricTypeGauge})
+
+	// Metadata of a classic histogram is received for each of its series.
+	hist := metadata.Metadata{Type: model.MetricTypeHistogram, Help: "Request latency."}
+	appendWithMetadata(labels.FromStrings("__name__", "latency_seconds_bucket", "job", "a", "le", "0.5"), 1, hist)
+	appendWithMetadata(labels.FromStrings("__name__", "latency_seconds_bucket", "job", "a", "le", "+Inf"), 3, hist)
+	appendWithMetadata(labels.FromStrings("__name__", "latency_seconds_sum", "job", "a"), 2.5, hist)
+	appendWithMetadata(labels.FromStrings("__name__", "latency_seconds_count", "job", "a"), 3, hist)
+
+	// Metadata of a summary is received for the name of the metric family.
+	for _, l := range []labels.Labels{
+		labels.FromStrings("__name__", "rpc_seconds", "job", "a", "quantile", "0.5"),
+		labels.FromStrings("__name__", "rpc_seconds_sum", "job", "a"),
+		labels.FromStrings("__name__", "rpc_seconds_count", "job", "a"),
+	} {
+		_, err := app.Append(0, l, 1000, 4)
+		require.NoError(t, err)
+	}
+	_, err := app.UpdateMetadata(0, labels.FromStrings("__name__", "rpc_seconds"), metadata.Metadata{Type: model.MetricTypeSummary})
+	require.NoError(t, err)
+	require.NoError(t, app.Commit())
+
+	require.Equal(t, `# HELP latency_seconds Request latency.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{job="a",le="0.5"} 1
+latency_seconds_bucket{job="a",le="+Inf"} 3
+latency_seconds_sum{job="a"} 2.5
+latency_seconds_count{job="a"} 3
+# HELP requests_total Total requests.
+# TYPE requests_total counter
+requests_total{job="a"} 5
+# TYPE rpc_seconds summary
+rpc_seconds{job="a",quantile="0.5"} 4
+rpc_seconds_sum{job="a"} 4
+rpc_seconds_count{job="a"} 4
+# TYPE temperature gauge
+temperature{job="a"} 21.5
+`, scrape(t, c, nil))
+
+	// Metadata is removed together with the last series it applies to.
+	c.store.expire(time.Now().Add(2 * time.Minute))
+	require.Empty(t, c.store.metadata)
+}
+
+func TestServe_InternalLabels(t *testing.T) {
+	c := newTestComponent(t, Arguments{
+		MaxIdleDuration:   time.Minute,
+		IncludeTimestamps: true,
+	})
+
+	// Series which only differ in internal labels are exposed once, with the
+	// latest sample.
+	app := c.store.Appender(t.Context())
+	_, err := app.Append(0, labels.FromStrings("__name__", "up", "__a", "1"), 2000, 1)
+	require.NoError(t, err)
+	_, err = app.Append(0, labels.FromStrings("__name__", "up", "__a", "2"), 1000, 0)
+	require.NoError(t, err)
+	require.NoError(t, app.Commit())
+
+	require.Equal(t, "# TYPE up untyped\nup 1 2000\n", scrape(t, c, nil))
+}
+
+func TestServe_Timestamps(t *testing.T) {
+	c := newTestComponent(t, Arguments{
+		MaxIdleDuration:   time.Minute,
+		IncludeTimestamps: true,
+	})
+
+	app := c.store.Appender(t.Context())
+	_, err := app.Append(0, labels.FromStrings("__name__", "up"), 1000, 1)
+	require.NoError(t, err)
+	require.NoError(t, app.Commit())
+
+	require.Equal(t, "# TYPE up untyped\nup 1 1000\n", scrape(t, c, nil))
+}
+
+func TestServe_Limits(t *testing.T) {
+	c := newTestComponent(t, Arguments{
+		MaxIdleDuration: time.Minute,
+		MaxSeries:       1,
+	})
+
+	app := c.store.Appender(t.Context())
+	_, err := app.Append(0, labels.FromStrings("__name__", "up", "job", "a"), 1000, 1)
+	require.NoError(t, err)
+	_, err = app.Append(0, labels.FromStrings("__name__", "up", "job", "b"), 1000, 1)
+	require.NoError(t, err)
+	require.NoError(t, app.Commit())
+
+	require.Equal(t, "# TYPE up untyped\nup{job=\"a\"} 1\n", scrape(t, c, nil))
+	require.Equal(t, 1, c.store.numSeries)
+
+	// Idle series are removed once they exceed the max idle duration.
+	c.store.expire(time.Now().Add(2 * time.Minute))
+	require.Empty(t, scrape(t, c, nil))
+	require.Equal(t, 0, c.store.numSeries)
+}
+
+func TestServe_BadMatcher(t *testing.T) {
+	c := newTestComponent(t, Arguments{MaxIdleDuration: time.Minute})
+
+	rec := httptest.NewRecorder()
+	c.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics?match[]=up{", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func newTestComponent(t *testing.T, args Arguments) *Component {
+	t.Helper()
+
+	c, err := New(component.Options{
+		ID:            "prometheus.serve.test",
+		Logger:        util.TestAlloyLogger(t),
+		Registerer:    prometheus_client.NewRegistry(),
+		OnStateChange: func(component.Exports) {},
+	}, args)
+	require.NoError(t, err)
+	return c
+}
+
+func scrape(t *testing.T, c *Component, query url.Values) string {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/metrics?"+query.Encode(), nil)
+	req.Header.Set("Accept", "text/plain")
+	c.Handler().ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	return rec.Body.String()
+}
@@ -0,0 +1,299 @@
+package serve
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	prometheus_client "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/exemplar"
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/metadata"
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/prometheus/prometheus/storage"
+)
+
+// seriesStore is a storage.Appendable which keeps the latest sample of every
+// series it receives in memory.
+type seriesStore struct {
+	seriesGauge   prometheus_client.Gauge
+	droppedSeries prometheus_client.Counter
+
+	mut               sync.RWMutex
+	series            map[uint64][]*latestSample
+	metadata          map[string]metadata.Metadata // Metadata by metric name.
+	numSeries         int
+	maxIdleDuration   time.Duration
+	maxSeries         int
+	includeTimestamps bool
+}
+
+var _ storage.Appendable = (*seriesStore)(nil)
+
+// latestSample is the latest sample of a series. Exactly one of v and fh is
+// set.
+type latestSample struct {
+	lset     labels.Labels
+	t        int64
+	v        float64
+	fh       *histogram.FloatHistogram
+	received time.Time
+}
+
+func newSeriesStore() *seriesStore {
+	return &seriesStore{
+		seriesGauge: prometheus_client.NewGauge(prometheus_client.GaugeOpts{
+			Name: "alloy_prometheus_serve_series",
+			Help: "Number of series currently exposed",
+		}),
+		droppedSeries: prometheus_client.NewCounter(prometheus_client.CounterOpts{
+			Name: "alloy_prometheus_serve_series_dropped_total",
+			Help: "Total number of new series dropped because max_series was reached",
+		}),
+		series:   make(map[uint64][]*latestSample),
+		metadata: make(map[string]metadata.Metadata),
+	}
+}
+
+func (s *seriesStore) setLimits(maxIdleDuration time.Duration, maxSeries int, includeTimestamps bool) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.maxIdleDuration = maxIdleDuration
+	s.maxSeries = maxSeries
+	s.includeTimestamps = includeTimestamps
+}
+
+// Appender implements storage.Appendable.
+func (s *seriesStore) Appender(context.Context) storage.Appender {
+	return &appender{store: s}
+}
+
+// commit stores the given samples as the latest samples of their series and
+// the given metadata by metric name. Stale markers remove their series.
+func (s *seriesStore) commit(samples []latestSample, md map[string]metadata.Metadata, now time.Time) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	for name, m := range md {
+		if _, ok := s.metadata[name]; !ok && s.maxSeries > 0 && len(s.metadata) >= s.maxSeries {
+			continue
+		}
+		s.metadata[name] = m
+	}
+
+	for _, sample := range samples {
+		hash := sample.lset.Hash()
+		bucket := s.series[hash]
+		idx := slices.IndexFunc(bucket, func(other *latestSample) bool {
+			return labels.Equal(other.lset, sample.lset)
+		})
+
+		if isStale(sample) {
+			if idx >= 0 {
+				s.deleteLocked(hash, idx)
+			}
+			continue
+		}
+
+		sample.received = now
+		switch {
+		case idx >= 0:
+			// Ignore out-of-order samples so that the exposed value doesn't go
+			// back in time.
+			if bucket[idx].t <= sample.t {
+				*bucket[idx] = sample
+			}
+		case s.maxSeries > 0 && s.numSeries >= s.maxSeries:
+			s.droppedSeries.Inc()
+		default:
+			s.series[hash] = append(bucket, &sample)
+			s.numSeries++
+		}
+	}
+	s.seriesGauge.Set(float64(s.numSeries))
+}
+
+// expire removes all series which haven't received a sample for longer than
+// the max idle duration.
+func (s *seriesStore) expire(now time.Time) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	for hash, bucket := range s.series {
+		for i := len(bucket) - 1; i >= 0; i-- {
+			if now.Sub(bucket[i].received) > s.maxIdleDuration {
+				s.deleteLocked(hash, i)
+			}
+		}
+	}
+	s.seriesGauge.Set(float64(s.numSeries))
+
+	// Metadata is only sent when it changes, so it's kept for as long as there
+	// are series it applies to.
+	names := make(map[string]struct{}, len(s.metadata))
+	for _, bucket := range s.series {
+		for _, sample := range bucket {
+			name := sample.lset.Get(labels.MetricName)
+			names[name] = struct{}{}
+			names[trimClassicSuffix(name)] = struct{}{}
+		}
+	}
+	for name := range s.metadata {
+		if _, ok := names[name]; !ok {
+			delete(s.metadata, name)
+		}
+	}
+}
+
+func (s *seriesStore) deleteLocked(hash uint64, idx int) {
+	bucket := slices.Delete(s.series[hash], idx, idx+1)
+	if len(bucket) == 0 {
+		delete(s.series, hash)
+	} else {
+		s.series[hash] = bucket
+	}
+	s.numSeries--
+}
+
+// metadataLocked returns the metadata of a series with the given metric name.
+// Metadata of classic histograms and summaries may be stored either for every
+// series or for the name of the metric family.
+func (s *seriesStore) metadataLocked(name string) (metadata.Metadata, bool) {
+	if m, ok := s.metadata[name]; ok {
+		return m, true
+	}
+	m, ok := s.metadata[trimClassicSuffix(name)]
+	if !ok || (m.Type != model.MetricTypeHistogram && m.Type != model.MetricTypeSummary) {
+		return metadata.Metadata{}, false
+	}
+	return m, true
+}
+
+// trimClassicSuffix removes the suffix of the series of classic histograms
+// and summaries from name.
+func trimClassicSuffix(name string) string {
+	for _, suffix := range []string{"_bucket", "_sum", "_count"} {
+		if base, ok := strings.CutSuffix(name, suffix); ok {
+			return base
+		}
+	}
+	return name
+}
+
+// collect calls fn for every series which hasn't been idle for longer than the
+// max idle duration and matches any of the matcher sets. All series match if
+// no matcher sets are given. fn is called with the read lock held.
+func (s *seriesStore) collect(now time.Time, matcherSets [][]*labels.Matcher, fn func(sample *latestSample)) {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	for _, bucket := range s.series {
+		for _, sample := range bucket {
+			if now.Sub(sample.received) > s.maxIdleDuration {
+				continue
+			}
+			if len(matcherSets) > 0 && !slices.ContainsFunc(matcherSets, func(matchers []*labels.Matcher) bool {
+				return matchesAll(sample.lset, matchers)
+			}) {
+				continue
+			}
+			fn(sample)
+		}
+	}
+}
+
+func matchesAll(lset labels.Labels, matchers []*labels.Matcher) bool {
+	for _, m := range matchers {
+		if !m.Matches(lset.Get(m.Name)) {
+			return false
+		}
+	}
+	return true
+}
+
+func isStale(s latestSample) bool {
+	if s.fh != nil {
+		return value.IsStaleNaN(s.fh.Sum)
+	}
+	return value.IsStaleNaN(s.v)
+}
+
+// appender buffers samples and metadata until they're committed to the store.
+type appender struct {
+	store    *seriesStore
+	pending  []latestSample
+	metadata map[string]metadata.Metadata
+}
+
+var _ storage.Appender = (*appender)(nil)
+
+// Append implements storage.Appender.
+func (a *appender) Append(ref storage.SeriesRef, l labels.Labels, t int64, v float64) (storage.SeriesRef, error) {
+	a.pending = append(a.pending, latestSample{lset: l, t: t, v: v})
+	return ref, nil
+}
+
+// AppendHistogram implements storage.Appender.
+func (a *appender) AppendHistogram(ref storage.SeriesRef, l labels.Labels, t int64, h *histogram.Histogram, fh *histogram.FloatHistogram) (storage.SeriesRef, error) {
+	if h != nil {
+		fh = h.ToFloat(nil)
+	} else if fh != nil {
+		fh = fh.Copy()
+	} else {
+		return ref, nil
+	}
+	a.pending = append(a.pending, latestSample{lset: l, t: t, fh: fh})
+	return ref, nil
+}
+
+// Commit implements storage.Appender.
+func (a *appender) Commit() error {
+	a.store.commit(a.pending, a.metadata, time.Now())
+	a.pending = nil
+	a.metadata = nil
+	return nil
+}
+
+// Rollback implements storage.Appender.
+func (a *appender) Rollback() error {
+	a.pending = nil
+	a.metadata = nil
+	return nil
+}
+
+// AppendExemplar implements storage.Appender. Exemplars aren't exposed.
+func (a *appender) AppendExemplar(ref storage.SeriesRef, _ labels.Labels, _ exemplar.Exemplar) (storage.SeriesRef, error) {
+	return ref, nil
+}
+
+// UpdateMetadata implements storage.Appender. Metadata is stored by the metric
+// name of l.
+func (a *appender) UpdateMetadata(ref storage.SeriesRef, l labels.Labels, m metadata.Metadata) (storage.SeriesRef, error) {
+	name := l.Get(labels.MetricName)
+	if name == "" {
+		return ref, nil
+	}
+	if a.metadata == nil {
+		a.metadata = make(map[string]metadata.Metadata)
+	}
+	a.metadata[name] = m
+	return ref, nil
+}
+
+// AppendCTZeroSample implements storage.Appender.
+func (a *appender) AppendCTZeroSample(ref storage.SeriesRef, _ labels.Labels, _, _ int64) (storage.SeriesRef, error) {
+	return ref, nil
+}
+
+// AppendHistogramCTZeroSample implements storage.Appender.
+func (a *appender) AppendHistogramCTZeroSample(ref storage.SeriesRef, _ labels.Labels, _, _ int64, _ *histogram.Histogram, _ *histogram.FloatHistogram) (storage.SeriesRef, error) {
+	return ref, nil
+}
+
+// SetOptions implements storage.Appender.
+func (a *appender) SetOptions(*storage.AppendOptions) {}
@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/grafana/ckit/shard"
 	config_util "github.com/prometheus/common/config"
 	"github.com/prometheus/prometheus/discovery/targetgroup"
 
@@ -36,6 +37,10 @@ const (
 	defaultProfilingDuration time.Duration = defaultScrapeInterval - 1*time.Second
 )
 
+// handoffCheckInterval is how often handoffs of targets are checked for
+// progress.
+const handoffCheckInterval = time.Second
+
 func init() {
 	component.Register(component.Registration{
 		Name:      "pyroscope.scrape",
@@ -245,11 +250,16 @@ type Component struct {
 	cluster cluster.Cluster
 
 	reloadTargets chan struct{}
+	handoff       *discovery.TargetHandoff
 
 	mut        sync.RWMutex
 	args       Arguments
 	scraper    *Manager
 	appendable *pyroscope.Fanout
+
+	// distributedTargets are the targets distributed in the last reload.
+	// Only accessed from Run.
+	distributedTargets *discovery.DistributedTargets
 }
 
 var _ component.Component = (*Component)(nil)
@@ -282,6 +292,7 @@ func New(o component.Options, args Arguments) (*Component, error) {
 		opts:          o,
 		cluster:       clusterData,
 		reloadTargets: make(chan struct{}, 1),
+		handoff:       discovery.NewTargetHandoff(clusterData, o.ID, o.Logger),
 		scraper:       scraper,
 		appendable:    alloyAppendable,
 	}
@@ -305,10 +316,24 @@ func (c *Component) Run(ctx context.Context) error {
 		level.Info(c.opts.Logger).Log("msg", "scrape manager stopped")
 	}()
 
+	handoffTicker := time.NewTicker(handoffCheckInterval)
+	defer handoffTicker.Stop()
+	defer c.handoff.Close()
+	handoffNotify := c.handoff.Notify()
+
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
+		case <-handoffNotify:
+			c.scheduleReload()
+		case <-handoffTicker.C:
+			if c.handoff.Confirming() {
+				c.handoff.Confirm(ctx, c.lastScrapes())
+			}
+			if c.handoff.Due() {
+				c.scheduleReload()
+			}
 		case <-c.reloadTargets:
 			c.mut.RLock()
 			var (
@@ -322,7 +347,13 @@ func (c *Component) Run(ctx context.Context) error {
 			c.mut.RUnlock()
 
 			ct := discovery.NewDistributedTargets(clusteringEnabled, c.cluster, tgs)
-			promTargets := discovery.ComponentTargetsToPromTargetGroupsForSingleJob(jobName, ct.LocalTargets())
+			// Targets which moved to another instance are scraped until
+			// they're handed off, if handoffs are enabled. Targets are scraped
+			// at the same offset on every instance, so they don't need to be
+			// aligned with their previous owner.
+			handoff := c.handoff.Update(c.distributedTargets, ct, clusteringEnabled)
+			c.distributedTargets = ct
+			promTargets := discovery.ComponentTargetsToPromTargetGroupsForSingleJob(jobName, handoff.Targets)
 
 			select {
 			case targetSetsChan <- promTargets:
@@ -330,6 +361,13 @@ func (c *Component) Run(ctx context.Context) error {
 			case <-ctx.Done():
 				return nil
 			}
+
+			if len(handoff.Moved) > 0 {
+				lastScrapes := c.lastScrapes()
+				c.handoff.Offer(ctx, handoff.Moved, func(key shard.Key) (time.Time, time.Duration) {
+					return lastScrapes[key], c.scrapeInterval()
+				})
+			}
 		}
 	}
 }
@@ -368,12 +406,39 @@ func (c *Component) NotifyClusterChange() {
 	}
 
 	// Schedule a reload so targets get redistributed.
+	c.scheduleReload()
+}
+
+func (c *Component) scheduleReload() {
 	select {
 	case c.reloadTargets <- struct{}{}:
 	default:
 	}
 }
 
+func (c *Component) scrapeInterval() time.Duration {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+	return c.args.ScrapeInterval
+}
+
+// lastScrapes returns the time of the last scrape of the targets which are
+// handed off, by shard key. Targets are scraped once per profile type, so the
+// earliest scrape of all profile types is used.
+func (c *Component) lastScrapes() map[shard.Key]time.Time {
+	res := make(map[shard.Key]time.Time)
+	for _, t := range c.scraper.TargetsActive() {
+		key, ok := discovery.HandoffKey(t.allLabels.Get)
+		if !ok {
+			continue
+		}
+		if last, ok := res[key]; !ok || t.LastScrape().Before(last) {
+			res[key] = t.LastScrape()
+		}
+	}
+	return res
+}
+
 // DebugInfo implements component.DebugComponent.
 func (c *Component) DebugInfo() interface{} {
 	var res []scrape.TargetStatus
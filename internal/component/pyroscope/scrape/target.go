@@ -141,8 +141,10 @@ func (t *Target) Hash() uint64 {
 func (t *Target) offset(interval time.Duration) time.Duration {
 	now := time.Now().UnixNano()
 
+	// Base is pinned to absolute time, so that a target is scraped at the same
+	// time regardless of which instance scrapes it.
 	var (
-		base   = now % int64(interval)
+		base   = int64(interval) - now%int64(interval)
 		offset = t.hash % uint64(interval)
 		next   = base + int64(offset)
 	)
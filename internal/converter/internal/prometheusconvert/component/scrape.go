@@ -16,7 +16,6 @@ import (
 	"github.com/grafana/alloy/internal/converter/diag"
 	"github.com/grafana/alloy/internal/converter/internal/common"
 	"github.com/grafana/alloy/internal/converter/internal/prometheusconvert/build"
-	"github.com/grafana/alloy/internal/service/cluster"
 )
 
 func AppendPrometheusScrape(pb *build.PrometheusBlocks, scrapeConfig *prom_config.ScrapeConfig, forwardTo []storage.Appendable, targets []discovery.Target, label string) {
@@ -89,7 +88,8 @@ func toScrapeArguments(scrapeConfig *prom_config.ScrapeConfig, forwardTo []stora
 		MetricNameValidationScheme:     scrapeConfig.MetricNameValidationScheme,
 		MetricNameEscapingScheme:       scrapeConfig.MetricNameEscapingScheme,
 		ScrapeFallbackProtocol:         fallbackProtocol,
-		Clustering:                     cluster.ComponentBlock{Enabled: false},
+		AdaptiveInterval:               scrape.DefaultAdaptiveIntervalArguments,
+		Clustering:                     scrape.DefaultClusteringArguments,
 	}
 	return alloyArgs
 }
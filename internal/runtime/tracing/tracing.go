@@ -192,3 +192,16 @@ func (t *Tracer) Run(ctx context.Context) error {
 func (t *Tracer) Tracer(name string, options ...trace.TracerOption) trace.Tracer {
 	return t.tp.Tracer(name, options...)
 }
+
+// RegisterSpanProcessor adds a span processor which is called for every
+// sampled span, in addition to the one forwarding spans to the write_to
+// consumers.
+func (t *Tracer) RegisterSpanProcessor(sp tracesdk.SpanProcessor) {
+	t.tp.RegisterSpanProcessor(sp)
+}
+
+// UnregisterSpanProcessor removes a span processor added with
+// RegisterSpanProcessor.
+func (t *Tracer) UnregisterSpanProcessor(sp tracesdk.SpanProcessor) {
+	t.tp.UnregisterSpanProcessor(sp)
+}
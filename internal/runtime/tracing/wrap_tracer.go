@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"go.opentelemetry.io/otel/attribute"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -57,6 +58,29 @@ func (wp *wrappedProvider) Tracer(name string, options ...trace.TracerOption) tr
 	}
 }
 
+// spanProcessorRegisterer is implemented by trace providers which accept
+// additional span processors, such as [Tracer].
+type spanProcessorRegisterer interface {
+	RegisterSpanProcessor(tracesdk.SpanProcessor)
+	UnregisterSpanProcessor(tracesdk.SpanProcessor)
+}
+
+// RegisterSpanProcessor forwards the span processor to the inner provider if
+// it accepts span processors.
+func (wp *wrappedProvider) RegisterSpanProcessor(sp tracesdk.SpanProcessor) {
+	if r, ok := wp.TracerProvider.(spanProcessorRegisterer); ok {
+		r.RegisterSpanProcessor(sp)
+	}
+}
+
+// UnregisterSpanProcessor forwards the span processor to the inner provider
+// if it accepts span processors.
+func (wp *wrappedProvider) UnregisterSpanProcessor(sp tracesdk.SpanProcessor) {
+	if r, ok := wp.TracerProvider.(spanProcessorRegisterer); ok {
+		r.UnregisterSpanProcessor(sp)
+	}
+}
+
 type wrappedTracer struct {
 	trace.Tracer
 	id       string
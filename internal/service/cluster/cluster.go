@@ -32,8 +32,6 @@ import (
 	"github.com/grafana/alloy/internal/runtime/logging/level"
 	"github.com/grafana/alloy/internal/service"
 	"github.com/grafana/alloy/internal/service/cluster/discovery"
-	httpservice "github.com/grafana/alloy/internal/service/http"
-	"github.com/grafana/alloy/internal/service/remotecfg"
 	"github.com/grafana/alloy/internal/util"
 )
 
@@ -62,6 +60,14 @@ const (
 	// stateUpdateMinInterval is the minimum time interval between propagating peer changes to Alloy components.
 	// This allows to rate limit the number of updates when the cluster is frequently changing (e.g. during rollout).
 	stateUpdateMinInterval = time.Second
+
+	// httpServiceName is the name of the HTTP service, which serves the
+	// handler of the cluster service.
+	httpServiceName = "http"
+
+	// clusterAPIBase is the base route of the HTTP endpoints which peers use to
+	// exchange node info, handoffs and key-value state.
+	clusterAPIBase = "/api/v1/cluster/"
 )
 
 // Options are used to configure the cluster service. Options are constant for
@@ -88,10 +94,21 @@ type Options struct {
 	ClusterName            string        // Name to prevent nodes without this identifier from joining the cluster.
 	MinimumClusterSize     int           // Minimum cluster size before admitting traffic to components that use clustering.
 	MinimumSizeWaitTimeout time.Duration // Maximum duration to wait for minimum cluster size before proceeding; 0 means no timeout.
+	NodeWeight             float64       // Capacity weight of this node. Nodes receive work proportionally to their weight; 0 means 1.
+	HandoffTimeout         time.Duration // Maximum duration to hand off work to the new owner of a key; 0 disables handoffs.
+	SharedSecret           []byte        // Secret shared by all peers to authenticate their requests to each other; handoffs are disabled without it.
+	Zone                   string        // Failure zone of this node. Replicas of a key are placed in different zones.
 
 	// Function to discover peers to join. If this function is nil or returns an
 	// empty slice, no peers will be joined.
 	DiscoverPeers discovery.DiscoverFn
+	// WatchPeers watches for changes to the peers discovered by DiscoverPeers
+	// while the service runs. May be nil.
+	WatchPeers discovery.WatchFn
+	// PeersChanged receives a value when WatchPeers notices that discovered
+	// peers changed. Peers are rejoined immediately instead of on the next
+	// RejoinInterval. May be nil.
+	PeersChanged <-chan struct{}
 }
 
 // Service is the cluster service.
@@ -100,9 +117,22 @@ type Service struct {
 	tracer trace.TracerProvider
 	opts   Options
 
-	sharder shard.Sharder
-	node    *ckit.Node
-	randGen *rand.Rand
+	sharder    shard.Sharder
+	node       *ckit.Node
+	randGen    *rand.Rand
+	httpClient *http.Client
+
+	// nodeInfos holds the information shared between peers, such as their
+	// capacity weights.
+	nodeInfos *nodeInfos
+	// nodeInfoSync is used to signal that the node information of peers must be fetched again.
+	nodeInfoSync chan struct{}
+	// handoffs holds the handoffs of work between the local node and its peers.
+	handoffs *handoffs
+	// localStatus builds the status of the local node shared with peers.
+	localStatus *localStatus
+	// kv holds the key-value state shared with peers.
+	kv *kvStore
 
 	// alloyCluster is given to components via calls to Data() and implements Cluster.
 	alloyCluster *alloyCluster
@@ -129,10 +159,7 @@ type ComponentBlock struct {
 	Enabled bool `alloy:"enabled,attr"`
 }
 
-var (
-	_ service.Service            = (*Service)(nil)
-	_ httpservice.ServiceHandler = (*Service)(nil)
-)
+var _ service.Service = (*Service)(nil)
 
 // New returns a new, unstarted instance of the cluster service.
 func New(opts Options) (*Service, error) {
@@ -146,12 +173,18 @@ func New(opts Options) (*Service, error) {
 	if t == nil {
 		t = noop.NewTracerProvider()
 	}
+	if opts.NodeWeight <= 0 {
+		opts.NodeWeight = 1
+	}
+
+	// Weights and zones are set once peers agree on them, see syncNodeInfo.
+	sharder := newWeightedSharder(shard.Ring(tokensPerNode))
 
 	ckitConfig := ckit.Config{
 		Name:          opts.NodeName,
 		AdvertiseAddr: opts.AdvertiseAddress,
 		Log:           l,
-		Sharder:       shard.Ring(tokensPerNode),
+		Sharder:       sharder,
 		Label:         opts.ClusterName,
 		EnableTLS:     opts.EnableTLS,
 	}
@@ -192,19 +225,48 @@ func New(opts Options) (*Service, error) {
 		if err := opts.Metrics.Register(node.Metrics()); err != nil {
 			return nil, fmt.Errorf("failed to register metrics: %w", err)
 		}
+
+		nodeWeightGauge := prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "cluster_node_weight",
+			Help: "The capacity weight of this node, which the cluster distributes work proportionally to.",
+			ConstLabels: prometheus.Labels{
+				"cluster_name": opts.ClusterName,
+			},
+		})
+		nodeWeightGauge.Set(opts.NodeWeight)
+		if err := opts.Metrics.Register(nodeWeightGauge); err != nil {
+			return nil, fmt.Errorf("failed to register metrics: %w", err)
+		}
+	}
+
+	scheme := "http"
+	if opts.EnableTLS {
+		scheme = "https"
 	}
 
+	peers := newPeerClient(opts.NodeName, scheme, httpClient, opts.SharedSecret, node.Peers)
+
 	s := &Service{
 		log:    l,
 		tracer: t,
 		opts:   opts,
 
-		sharder:             ckitConfig.Sharder,
+		sharder:             sharder,
 		node:                node,
 		randGen:             rand.New(rand.NewSource(time.Now().UnixNano())),
+		httpClient:          httpClient,
+		nodeInfos:           newNodeInfos(opts.NodeWeight, opts.Zone),
+		nodeInfoSync:        make(chan struct{}, 1),
+		handoffs:            newHandoffs(peers),
+		localStatus:         &localStatus{},
+		kv:                  newKVStore(peers),
 		notifyClusterChange: make(chan struct{}, 1),
 	}
-	s.alloyCluster = newAlloyCluster(ckitConfig.Sharder, s.triggerClusterChangeNotification, opts, l)
+	s.alloyCluster = newAlloyCluster(sharder, s.triggerClusterChangeNotification, opts, l)
+	s.alloyCluster.nodeInfos = s.nodeInfos
+	s.alloyCluster.handoffs = s.handoffs
+	s.alloyCluster.localStatus = s.localStatus
+	s.alloyCluster.kv = s.kv
 
 	return s, nil
 }
@@ -257,8 +319,10 @@ func (s *Service) Definition() service.Definition {
 		Name:       ServiceName,
 		ConfigType: nil, // cluster does not accept configuration.
 		DependsOn: []string{
-			// Cluster depends on the HTTP service to work properly.
-			httpservice.ServiceName,
+			// Cluster depends on the HTTP service to work properly. The HTTP
+			// service isn't imported, since it depends on remotecfg, which runs
+			// components that use clustering.
+			httpServiceName,
 		},
 		Stability: featuregate.StabilityGenerallyAvailable,
 	}
@@ -270,14 +334,33 @@ func (s *Service) ServiceHandler(_ service.Host) (base string, handler http.Hand
 	base, handler = s.node.Handler()
 
 	if !s.opts.EnableClustering {
-		handler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
-			http.Error(w, "clustering is disabled", http.StatusNotFound)
-		})
+		handler = clusteringDisabledHandler
 	}
 
 	return base, handler
 }
 
+// ExtraServiceHandlers returns the handlers of the endpoints peers use to
+// exchange node info, handoffs and key-value state. The resulting handler
+// always returns 404 when clustering is disabled.
+func (s *Service) ExtraServiceHandlers(_ service.Host) map[string]http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(nodeInfoPath, s.handleNodeInfo)
+	mux.HandleFunc(handoffPath, s.handleHandoff)
+	mux.HandleFunc(kvPath, s.handleKV)
+
+	var handler http.Handler = mux
+	if !s.opts.EnableClustering {
+		handler = clusteringDisabledHandler
+	}
+
+	return map[string]http.Handler{clusterAPIBase: handler}
+}
+
+var clusteringDisabledHandler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+	http.Error(w, "clustering is disabled", http.StatusNotFound)
+})
+
 // ChangeState changes the state of the service. If clustering is enabled,
 // ChangeState will block until the state change has been propagated to another
 // node; cancel the current context to stop waiting. ChangeState fails if the
@@ -294,6 +377,8 @@ func (s *Service) Run(ctx context.Context, host service.Host) error {
 	// Stop the node on shutdown.
 	defer s.stop()
 
+	s.localStatus.setHost(host)
+
 	var wg sync.WaitGroup
 	defer wg.Wait()
 
@@ -306,9 +391,18 @@ func (s *Service) Run(ctx context.Context, host service.Host) error {
 			return false
 		}
 		s.triggerClusterChangeNotification()
+		s.triggerNodeInfoSync()
 		return true
 	}))
 
+	if s.opts.EnableClustering && s.opts.WatchPeers != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.opts.WatchPeers(ctx)
+		}()
+	}
+
 	peers, err := s.getRandomPeers()
 	if err != nil {
 		// Warn when failed to get peers on startup as it can result in a split brain. We do not fail hard here
@@ -353,32 +447,69 @@ func (s *Service) Run(ctx context.Context, host service.Host) error {
 		}
 	}()
 
-	if s.opts.EnableClustering && s.opts.RejoinInterval > 0 {
+	if s.opts.EnableClustering {
 		wg.Add(1)
-
 		go func() {
 			defer wg.Done()
 
-			t := time.NewTicker(s.opts.RejoinInterval)
+			t := time.NewTicker(nodeInfoSyncInterval)
 			defer t.Stop()
 
 			for {
 				select {
 				case <-ctx.Done():
 					return
-
 				case <-t.C:
-					peers, err := s.getRandomPeers()
-					if err != nil {
-						level.Warn(s.log).Log("msg", "failed to refresh list of peers", "err", err)
-						continue
-					}
-					s.logPeers("rejoining peers", peers)
-
-					if err := s.node.Start(peers); err != nil {
-						level.Error(s.log).Log("msg", "failed to rejoin list of peers", "err", err)
-						continue
-					}
+				case <-s.nodeInfoSync:
+				}
+				s.syncNodeInfo(ctx)
+			}
+		}()
+	}
+
+	if s.opts.EnableClustering && s.kv.peers.enabled() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.runKVGossip(ctx)
+		}()
+	} else if s.opts.EnableClustering {
+		level.Info(s.log).Log("msg", "key-value state isn't shared with peers because no cluster shared secret is configured")
+	}
+
+	if s.opts.EnableClustering && (s.opts.RejoinInterval > 0 || s.opts.PeersChanged != nil) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			// A nil channel never fires, so rejoining periodically is disabled
+			// when RejoinInterval is 0.
+			var tick <-chan time.Time
+			if s.opts.RejoinInterval > 0 {
+				t := time.NewTicker(s.opts.RejoinInterval)
+				defer t.Stop()
+				tick = t.C
+			}
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+
+				case <-tick:
+				case <-s.opts.PeersChanged:
+				}
+
+				peers, err := s.getRandomPeers()
+				if err != nil {
+					level.Warn(s.log).Log("msg", "failed to refresh list of peers", "err", err)
+					continue
+				}
+				s.logPeers("rejoining peers", peers)
+
+				if err := s.node.Start(peers); err != nil {
+					level.Error(s.log).Log("msg", "failed to rejoin list of peers", "err", err)
 				}
 			}
 		}()
@@ -418,8 +549,8 @@ func (s *Service) notifyComponentsOfClusterChanges(ctx context.Context, limiter
 	// Notify all components about the clustering change.
 	components := component.GetAllComponents(host, component.InfoOptions{})
 
-	if remoteCfgHost, err := remotecfg.GetHost(host); err == nil {
-		components = append(components, component.GetAllComponents(remoteCfgHost, component.InfoOptions{})...)
+	if remoteHost := s.localStatus.remoteHost(); remoteHost != nil {
+		components = append(components, component.GetAllComponents(remoteHost, component.InfoOptions{})...)
 	}
 
 	for _, comp := range components {
@@ -451,6 +582,13 @@ func (s *Service) triggerClusterChangeNotification() {
 	}
 }
 
+func (s *Service) triggerNodeInfoSync() {
+	select {
+	case s.nodeInfoSync <- struct{}{}:
+	default:
+	}
+}
+
 func (s *Service) getRandomPeers() ([]string, error) {
 	if !s.opts.EnableClustering || s.opts.DiscoverPeers == nil {
 		return nil, nil
@@ -487,12 +625,12 @@ func (s *Service) stop() {
 	defer cancel()
 
 	// The node is going away. We move to the Terminating state to signal
-	// that we should not be owners for write hashing operations anymore.
-	//
-	// TODO(rfratto): should we enter terminating state earlier to allow for
-	// some kind of hand-off between components?
-	if err := s.node.ChangeState(ctx, peer.StateTerminating); err != nil {
-		level.Error(s.log).Log("msg", "failed to change state to Terminating", "err", err)
+	// that we should not be owners for write hashing operations anymore. The
+	// node is already terminating if it was drained before shutting down.
+	if s.node.CurrentState() != peer.StateTerminating {
+		if err := s.node.ChangeState(ctx, peer.StateTerminating); err != nil {
+			level.Error(s.log).Log("msg", "failed to change state to Terminating", "err", err)
+		}
 	}
 
 	if err := s.node.Stop(); err != nil {
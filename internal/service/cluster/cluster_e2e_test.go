@@ -36,6 +36,15 @@ import (
 	remotecfgservice "github.com/grafana/alloy/internal/service/remotecfg"
 )
 
+// The cluster service doesn't import the HTTP service, so this is checked here.
+var _ httpservice.ExtraServiceHandler = (*cluster.Service)(nil)
+
+func TestDependsOnHTTPService(t *testing.T) {
+	svc, err := cluster.New(cluster.Options{NodeName: "node", AdvertiseAddress: "127.0.0.1:12345"})
+	require.NoError(t, err)
+	require.Contains(t, svc.Definition().DependsOn, httpservice.ServiceName)
+}
+
 type testCase struct {
 	name                   string
 	nodeCountInitial       int
@@ -475,12 +484,12 @@ type testPeer struct {
 	logsWriter        *logsWriter
 }
 
-func (p *testPeer) discoveryFn(_ discovery.Options) (discovery.DiscoverFn, error) {
+func (p *testPeer) discoveryFn(_ discovery.Options) (discovery.DiscoverFn, discovery.WatchFn, error) {
 	return func() ([]string, error) {
 		p.mutex.Lock()
 		defer p.mutex.Unlock()
 		return p.discoverablePeers, nil
-	}, nil
+	}, nil, nil
 }
 
 func (p *testPeer) setDiscoverablePeers(addresses []string) {
@@ -581,6 +590,7 @@ func startNewNode(t *testing.T, state *testState, nodeName string) {
 		Metrics:     reg,
 	})
 	require.NoError(t, err)
+	clusterService.SetRemoteConfig(remoteCfgService)
 
 	f := runtime.New(runtime.Options{
 		Logger:               logger,
@@ -1,6 +1,8 @@
 package cluster
 
 import (
+	"context"
+	"fmt"
 	"sync"
 	"time"
 
@@ -54,12 +56,48 @@ type Cluster interface {
 	Ready() bool
 }
 
+// CostAwareCluster is a Cluster which can distribute keys by their cost.
+type CostAwareCluster interface {
+	Cluster
+
+	// ReportCosts shares the cost of the keys of scope owned by the local node
+	// with the cluster. Components typically use their ID as the scope. Passing
+	// no costs stops sharing costs for scope.
+	ReportCosts(scope string, costs map[shard.Key]float64)
+
+	// LookupByCost returns the owner of each key of scope, so that the cost of
+	// the keys owned by every peer is proportional to its capacity weight. The
+	// cost of a key is the last cost reported for it by any peer.
+	//
+	// LookupByCost only considers peers in the Participant state.
+	LookupByCost(scope string, keys []shard.Key) ([]peer.Peer, error)
+}
+
+// ReplicatedCluster is a Cluster which can assign several owners to a key,
+// spread across the failure zones of peers.
+type ReplicatedCluster interface {
+	Cluster
+
+	// LookupReplicas returns up to replicas owners of key. Owners are in
+	// different zones as long as there are enough zones, and the key is owned
+	// by all peers if there are fewer than replicas. The owners of a key only
+	// change when peers join or leave, or when their zone or weight changes.
+	//
+	// LookupReplicas only considers peers in the Participant state.
+	LookupReplicas(key shard.Key, replicas int) ([]peer.Peer, error)
+}
+
 // alloyCluster implements the Cluster interface and manages the admission control logic.
 type alloyCluster struct {
 	log     log.Logger
 	sharder shard.Sharder
 	opts    Options
 
+	nodeInfos   *nodeInfos
+	handoffs    *handoffs
+	localStatus *localStatus
+	kv          *kvStore
+
 	clusterChangeCallback func()
 	clusterReadyGauge     prometheus.Gauge
 
@@ -68,7 +106,13 @@ type alloyCluster struct {
 	clusterState  clusterState
 }
 
-var _ Cluster = (*alloyCluster)(nil)
+var (
+	_ CostAwareCluster  = (*alloyCluster)(nil)
+	_ HandoffCluster    = (*alloyCluster)(nil)
+	_ ReplicatedCluster = (*alloyCluster)(nil)
+	_ FleetCluster      = (*alloyCluster)(nil)
+	_ KVCluster         = (*alloyCluster)(nil)
+)
 
 func newAlloyCluster(sharder shard.Sharder, clusterChangeCallback func(), opts Options, log log.Logger) *alloyCluster {
 	c := &alloyCluster{
@@ -133,6 +177,95 @@ func (c *alloyCluster) Peers() []peer.Peer {
 	return c.sharder.Peers()
 }
 
+func (c *alloyCluster) ReportCosts(scope string, costs map[shard.Key]float64) {
+	if c.nodeInfos != nil {
+		c.nodeInfos.SetLocalCosts(scope, costs)
+	}
+}
+
+func (c *alloyCluster) LookupByCost(scope string, keys []shard.Key) ([]peer.Peer, error) {
+	ws, ok := c.sharder.(*weightedSharder)
+	if !ok || c.nodeInfos == nil {
+		owners := make([]peer.Peer, len(keys))
+		for i, key := range keys {
+			peers, err := c.sharder.Lookup(key, 1, shard.OpReadWrite)
+			if err != nil {
+				return nil, err
+			}
+			owners[i] = peers[0]
+		}
+		return owners, nil
+	}
+	return ws.LookupByCost(keys, c.nodeInfos.Costs(scope, keys))
+}
+
+func (c *alloyCluster) LookupReplicas(key shard.Key, replicas int) ([]peer.Peer, error) {
+	if ws, ok := c.sharder.(*weightedSharder); ok {
+		return ws.LookupReplicas(key, replicas)
+	}
+
+	var participants int
+	for _, p := range c.sharder.Peers() {
+		if p.State == peer.StateParticipant {
+			participants++
+		}
+	}
+	return c.sharder.Lookup(key, min(max(replicas, 1), max(participants, 1)), shard.OpReadWrite)
+}
+
+func (c *alloyCluster) KV(scope string) KV {
+	if c.opts.EnableClustering && !c.kv.peers.enabled() {
+		return nil
+	}
+	return scopedKV{store: c.kv, scope: scope}
+}
+
+func (c *alloyCluster) HandoffTimeout() time.Duration {
+	if !c.opts.EnableClustering || c.handoffs == nil || !c.handoffs.peers.enabled() {
+		return 0
+	}
+	return c.opts.HandoffTimeout
+}
+
+func (c *alloyCluster) OfferHandoffs(ctx context.Context, scope string, to peer.Peer, handoffs []Handoff) error {
+	if c.handoffs == nil || len(handoffs) == 0 {
+		return nil
+	}
+	return c.handoffs.send(ctx, to.Addr, handoffMessage{Scope: scope, Offers: handoffs})
+}
+
+func (c *alloyCluster) ConfirmHandoffs(ctx context.Context, scope string, to string, keys []shard.Key) error {
+	if c.handoffs == nil || len(keys) == 0 {
+		return nil
+	}
+	for _, p := range c.sharder.Peers() {
+		if p.Name == to {
+			return c.handoffs.send(ctx, p.Addr, handoffMessage{Scope: scope, Confirmed: keys})
+		}
+	}
+	return fmt.Errorf("peer %q not found", to)
+}
+
+func (c *alloyCluster) TakeHandoffs(scope string) ([]ReceivedHandoff, []shard.Key) {
+	if c.handoffs == nil {
+		return nil, nil
+	}
+	return c.handoffs.take(scope)
+}
+
+func (c *alloyCluster) HandoffNotify(scope string) <-chan struct{} {
+	if c.handoffs == nil {
+		return nil
+	}
+	return c.handoffs.subscribe(scope)
+}
+
+func (c *alloyCluster) ReportReleasing(scope string, count int) {
+	if c.handoffs != nil {
+		c.handoffs.setReleasing(scope, count)
+	}
+}
+
 func (c *alloyCluster) Ready() bool {
 	// Lock-free path: if clustering is disabled or no minimum size is set, the cluster is always ready.
 	if !c.opts.EnableClustering || c.opts.MinimumClusterSize == 0 {
@@ -0,0 +1,208 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	discoverylisters "k8s.io/client-go/listers/discovery/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+)
+
+const (
+	// namespaceFile holds the namespace of the pod when running in Kubernetes.
+	namespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+	// kubernetesSyncTimeout is how long discovering peers waits for the initial
+	// list of EndpointSlices.
+	kubernetesSyncTimeout = 30 * time.Second
+)
+
+// newWithKubernetes creates a DiscoverFn that returns the addresses of the
+// ready endpoints of a Kubernetes Service. The returned WatchFn watches the
+// EndpointSlices of the Service, and notifies opts.PeersChanged whenever the
+// ready endpoints change.
+func newWithKubernetes(opts Options) (DiscoverFn, WatchFn, error) {
+	namespace, name, err := parseKubernetesService(opts.KubernetesService)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client := opts.kubeClient
+	if client == nil {
+		config, err := rest.InClusterConfig()
+		if err != nil {
+			return nil, nil, fmt.Errorf("building Kubernetes client configuration: %w", err)
+		}
+		client, err = kubernetes.NewForConfig(config)
+		if err != nil {
+			return nil, nil, fmt.Errorf("building Kubernetes client: %w", err)
+		}
+	}
+
+	factory := informers.NewSharedInformerFactoryWithOptions(
+		client,
+		0,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(o *metav1.ListOptions) {
+			o.LabelSelector = discoveryv1.LabelServiceName + "=" + name
+		}),
+	)
+	informer := factory.Discovery().V1().EndpointSlices()
+
+	d := &kubernetesDiscovery{
+		opts:      opts,
+		lister:    informer.Lister().EndpointSlices(namespace),
+		hasSynced: informer.Informer().HasSynced,
+	}
+	d.mut.Lock()
+	d.registration, err = informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(any) { d.changed() },
+		UpdateFunc: func(any, any) { d.changed() },
+		DeleteFunc: func(any) { d.changed() },
+	})
+	d.mut.Unlock()
+	if err != nil {
+		return nil, nil, fmt.Errorf("watching EndpointSlices: %w", err)
+	}
+
+	watch := func(ctx context.Context) {
+		level.Info(opts.Logger).Log("msg", "watching Kubernetes EndpointSlices to discover peers", "namespace", namespace, "service", name)
+		factory.Start(ctx.Done())
+		<-ctx.Done()
+		factory.Shutdown()
+	}
+	return d.discover, watch, nil
+}
+
+type kubernetesDiscovery struct {
+	opts      Options
+	lister    discoverylisters.EndpointSliceNamespaceLister
+	hasSynced cache.InformerSynced
+
+	mut          sync.Mutex
+	registration cache.ResourceEventHandlerRegistration
+	// last holds the ready addresses at the previous change, so that changes
+	// to EndpointSlices which don't affect them are ignored.
+	last []string
+}
+
+func (d *kubernetesDiscovery) discover() ([]string, error) {
+	_, span := d.opts.Tracer.Tracer("").Start(
+		context.Background(),
+		"DiscoverKubernetesClusterPeers",
+		trace.WithSpanKind(trace.SpanKindInternal),
+	)
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(context.Background(), kubernetesSyncTimeout)
+	defer cancel()
+	if !cache.WaitForCacheSync(ctx.Done(), d.hasSynced) {
+		err := fmt.Errorf("timed out waiting for the list of EndpointSlices")
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	addrs, err := d.readyAddresses()
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return nil, fmt.Errorf("discovering peers: %w", err)
+	}
+
+	span.SetAttributes(attribute.Int("discovered_addresses_count", len(addrs)))
+	span.SetStatus(codes.Ok, "discovered peers")
+	return addrs, nil
+}
+
+// readyAddresses returns the sorted addresses of the ready endpoints of the
+// Service.
+func (d *kubernetesDiscovery) readyAddresses() ([]string, error) {
+	endpointSlices, err := d.lister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	port := strconv.Itoa(d.opts.DefaultPort)
+	var addrs []string
+	for _, slice := range endpointSlices {
+		for _, ep := range slice.Endpoints {
+			// A nil Ready condition must be interpreted as ready.
+			if ep.Conditions.Ready != nil && !*ep.Conditions.Ready {
+				continue
+			}
+			// Every address of an endpoint belongs to the same pod.
+			if len(ep.Addresses) > 0 {
+				addrs = append(addrs, net.JoinHostPort(ep.Addresses[0], port))
+			}
+		}
+	}
+	return sortedUniq(addrs), nil
+}
+
+// changed notifies opts.PeersChanged if the ready endpoints changed.
+func (d *kubernetesDiscovery) changed() {
+	d.mut.Lock()
+	defer d.mut.Unlock()
+
+	addrs, err := d.readyAddresses()
+	if err != nil || slices.Equal(addrs, d.last) {
+		return
+	}
+	d.last = addrs
+
+	// Events for the initial list of EndpointSlices aren't notified, since
+	// peers are discovered when the cluster starts.
+	if d.registration == nil || !d.registration.HasSynced() {
+		return
+	}
+
+	level.Debug(d.opts.Logger).Log("msg", "ready Kubernetes endpoints changed", "peers", strings.Join(addrs, ","))
+	if d.opts.PeersChanged == nil {
+		return
+	}
+	select {
+	case d.opts.PeersChanged <- struct{}{}:
+	default:
+		// A notification is already pending.
+	}
+}
+
+// parseKubernetesService parses a Service in the form [namespace/]name. The
+// namespace defaults to the namespace of the pod.
+func parseKubernetesService(s string) (namespace, name string, err error) {
+	namespace, name, found := strings.Cut(s, "/")
+	if !found {
+		name = namespace
+		b, err := os.ReadFile(namespaceFile)
+		if err != nil {
+			return "", "", fmt.Errorf("reading the namespace of the pod, set the namespace of the Kubernetes service explicitly: %w", err)
+		}
+		namespace = strings.TrimSpace(string(b))
+	}
+	if namespace == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("invalid Kubernetes service %q: must be in the form [namespace/]name", s)
+	}
+	return namespace, name, nil
+}
+
+func sortedUniq(s []string) []string {
+	slices.Sort(s)
+	return slices.Compact(s)
+}
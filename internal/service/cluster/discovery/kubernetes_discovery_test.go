@@ -0,0 +1,120 @@
+package discovery
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace/noop"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+)
+
+func TestKubernetesDiscovery(t *testing.T) {
+	client := fake.NewClientset(
+		testEndpointSlice("alloy-cluster-abc", "alloy-cluster",
+			testEndpoint("10.0.0.1", nil),
+			testEndpoint("10.0.0.2", ptr.To(true)),
+			testEndpoint("10.0.0.3", ptr.To(false)),
+		),
+		testEndpointSlice("alloy-cluster-def", "alloy-cluster",
+			testEndpoint("10.0.0.4", ptr.To(true)),
+		),
+		testEndpointSlice("other-abc", "other",
+			testEndpoint("10.0.1.1", ptr.To(true)),
+		),
+	)
+
+	changes := make(chan struct{}, 1)
+	fn, watch, err := newWithKubernetes(Options{
+		KubernetesService: "alloy/alloy-cluster",
+		DefaultPort:       12345,
+		PeersChanged:      changes,
+		Logger:            log.NewNopLogger(),
+		Tracer:            noop.NewTracerProvider(),
+		kubeClient:        client,
+	})
+	require.NoError(t, err)
+	go watch(t.Context())
+
+	// Only ready endpoints of the Service are peers.
+	peers, err := fn()
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.0.0.1:12345", "10.0.0.2:12345", "10.0.0.4:12345"}, peers)
+
+	// A pod becoming ready is picked up without polling.
+	_, err = client.DiscoveryV1().EndpointSlices("alloy").Update(t.Context(), testEndpointSlice("alloy-cluster-abc", "alloy-cluster",
+		testEndpoint("10.0.0.1", nil),
+		testEndpoint("10.0.0.2", ptr.To(true)),
+		testEndpoint("10.0.0.3", ptr.To(true)),
+	), metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected peers to change")
+	}
+	peers, err = fn()
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.0.0.1:12345", "10.0.0.2:12345", "10.0.0.3:12345", "10.0.0.4:12345"}, peers)
+
+	// Changes which don't affect ready endpoints aren't notified.
+	err = client.DiscoveryV1().EndpointSlices("alloy").Delete(t.Context(), "other-abc", metav1.DeleteOptions{})
+	require.NoError(t, err)
+	_, err = client.DiscoveryV1().EndpointSlices("alloy").Update(t.Context(), testEndpointSlice("alloy-cluster-def", "alloy-cluster",
+		testEndpoint("10.0.0.4", ptr.To(true)),
+		testEndpoint("10.0.0.5", ptr.To(false)),
+	), metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// Deleting a slice removes its endpoints.
+	err = client.DiscoveryV1().EndpointSlices("alloy").Delete(t.Context(), "alloy-cluster-def", metav1.DeleteOptions{})
+	require.NoError(t, err)
+
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected peers to change")
+	}
+	require.Eventually(t, func() bool {
+		peers, err = fn()
+		return err == nil && len(peers) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"10.0.0.1:12345", "10.0.0.2:12345", "10.0.0.3:12345"}, peers)
+}
+
+func TestParseKubernetesService(t *testing.T) {
+	namespace, name, err := parseKubernetesService("alloy/alloy-cluster")
+	require.NoError(t, err)
+	require.Equal(t, "alloy", namespace)
+	require.Equal(t, "alloy-cluster", name)
+
+	_, _, err = parseKubernetesService("alloy/")
+	require.ErrorContains(t, err, "must be in the form [namespace/]name")
+
+	_, _, err = parseKubernetesService("a/b/c")
+	require.ErrorContains(t, err, "must be in the form [namespace/]name")
+}
+
+func testEndpointSlice(name, service string, endpoints ...discoveryv1.Endpoint) *discoveryv1.EndpointSlice {
+	return &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "alloy",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: service},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints:   endpoints,
+	}
+}
+
+func testEndpoint(addr string, ready *bool) discoveryv1.Endpoint {
+	return discoveryv1.Endpoint{
+		Addresses:  []string{addr},
+		Conditions: discoveryv1.EndpointConditions{Ready: ready},
+	}
+}
@@ -1,6 +1,7 @@
 package discovery
 
 import (
+	"context"
 	"fmt"
 	"net"
 	"strings"
@@ -8,16 +9,28 @@ import (
 	"github.com/go-kit/log"
 	godiscover "github.com/hashicorp/go-discover"
 	"go.opentelemetry.io/otel/trace"
+	"k8s.io/client-go/kubernetes"
 
 	"github.com/grafana/alloy/internal/runtime/logging/level"
 )
 
 type DiscoverFn func() ([]string, error)
 
+// WatchFn watches for changes to the discovered peers until ctx is canceled,
+// and notifies Options.PeersChanged when they change. The DiscoverFn created
+// together with a WatchFn only discovers peers while the WatchFn runs.
+type WatchFn func(ctx context.Context)
+
 type Options struct {
 	JoinPeers     []string
 	DiscoverPeers string
-	DefaultPort   int
+	// KubernetesService is the Service, in the form [namespace/]name, whose
+	// ready endpoints are the peers.
+	KubernetesService string
+	DefaultPort       int
+	// PeersChanged, if non-nil, is notified when discovered peers change.
+	// Only discovery mechanisms which watch for changes notify it.
+	PeersChanged chan<- struct{}
 	// Logger to surface extra information to the user. Required.
 	Logger log.Logger
 	// Tracer to emit spans. Required.
@@ -30,6 +43,8 @@ type Options struct {
 	// goDiscoverFactory is a function that can be used to create a new discover.Discover instance.
 	// If nil, godiscover.New is used. Used for testing.
 	goDiscoverFactory goDiscoverFactory
+	// kubeClient is the client used to watch EndpointSlices. If nil, an in-cluster client is used. Used for testing.
+	kubeClient kubernetes.Interface
 }
 
 // lookupSRVFn is a function that can be used to lookup SRV records. Matches net.LookupSRV signature.
@@ -42,31 +57,39 @@ type lookupIPFn func(host string) ([]net.IP, error)
 // Matches discover.New signature.
 type goDiscoverFactory func(opts ...godiscover.Option) (*godiscover.Discover, error)
 
-func NewPeerDiscoveryFn(opts Options) (DiscoverFn, error) {
+// NewPeerDiscoveryFn creates the DiscoverFn configured by opts. The returned
+// WatchFn is nil unless the discovery mechanism watches for changes.
+func NewPeerDiscoveryFn(opts Options) (DiscoverFn, WatchFn, error) {
 	if opts.Logger == nil {
-		return nil, fmt.Errorf("logger is required, got nil")
+		return nil, nil, fmt.Errorf("logger is required, got nil")
 	}
 	if opts.Tracer == nil {
-		return nil, fmt.Errorf("tracer is required, got nil")
+		return nil, nil, fmt.Errorf("tracer is required, got nil")
 	}
 	if len(opts.JoinPeers) > 0 && opts.DiscoverPeers != "" {
-		return nil, fmt.Errorf("at most one of join peers and discover peers may be set, "+
+		return nil, nil, fmt.Errorf("at most one of join peers and discover peers may be set, "+
 			"got join peers %q and discover peers %q", opts.JoinPeers, opts.DiscoverPeers)
 	}
+	if opts.KubernetesService != "" && (len(opts.JoinPeers) > 0 || opts.DiscoverPeers != "") {
+		return nil, nil, fmt.Errorf("kubernetes service can't be set together with join peers or discover peers")
+	}
 
 	switch {
 	case len(opts.JoinPeers) > 0:
 		level.Info(opts.Logger).Log("msg", "using provided peers for discovery", "join_peers", strings.Join(opts.JoinPeers, ", "))
-		return newWithJoinPeers(opts), nil
+		return newWithJoinPeers(opts), nil, nil
+	case opts.KubernetesService != "":
+		return newWithKubernetes(opts)
 	case opts.DiscoverPeers != "":
 		// opts.DiscoverPeers is not logged to avoid leaking sensitive information.
 		level.Info(opts.Logger).Log("msg", "using go-discovery to discover peers")
-		return newWithGoDiscovery(opts)
+		fn, err := newWithGoDiscovery(opts)
+		return fn, nil, err
 	default:
 		// Here, both JoinPeers and DiscoverPeers are empty. This is desirable when
 		// starting a seed node that other nodes connect to, so we don't require
 		// one of the fields to be set.
 		level.Info(opts.Logger).Log("msg", "no peer discovery configured: both join and discover peers are empty")
-		return nil, nil
+		return nil, nil, nil
 	}
 }
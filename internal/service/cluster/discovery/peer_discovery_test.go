@@ -52,6 +52,16 @@ func TestPeerDiscovery(t *testing.T) {
 			},
 			expectedCreateErrContain: "at most one of join peers and discover peers may be set",
 		},
+		{
+			name: "both join peers and kubernetes service given",
+			args: Options{
+				JoinPeers:         []string{"host:1234"},
+				KubernetesService: "alloy/alloy-cluster",
+				Logger:            logger,
+				Tracer:            tracer,
+			},
+			expectedCreateErrContain: "kubernetes service can't be set together with join peers or discover peers",
+		},
 		{
 			name: "static host:port resolves to IP addresses with the specified port",
 			args: Options{
@@ -503,7 +513,7 @@ func TestPeerDiscovery(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			fn, err := NewPeerDiscoveryFn(tt.args)
+			fn, _, err := NewPeerDiscoveryFn(tt.args)
 			if tt.expectedCreateErrContain != "" {
 				require.ErrorContains(t, err, tt.expectedCreateErrContain)
 				return
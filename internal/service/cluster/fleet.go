@@ -0,0 +1,225 @@
+package cluster
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/grafana/ckit/peer"
+
+	"github.com/grafana/alloy/internal/build"
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/service"
+)
+
+// maxUnhealthyIDs is the maximum number of unhealthy components listed in the
+// status of a node.
+const maxUnhealthyIDs = 20
+
+// NodeStatus summarizes the state of a node, so that peers running different
+// configurations or unhealthy components can be spotted.
+type NodeStatus struct {
+	Version          string          `json:"version"`
+	ConfigHash       string          `json:"configHash,omitempty"`       // Hash of the loaded configuration files.
+	RemoteConfigHash string          `json:"remoteConfigHash,omitempty"` // Hash of the configuration loaded by remotecfg.
+	Components       ComponentHealth `json:"components"`
+	UpdateTime       time.Time       `json:"updateTime"` // When the node reported the status.
+}
+
+// ComponentHealth counts the components of a node by health.
+type ComponentHealth struct {
+	Healthy   int `json:"healthy"`
+	Unhealthy int `json:"unhealthy"`
+	Exited    int `json:"exited"`
+	Unknown   int `json:"unknown"`
+
+	// UnhealthyIDs holds the IDs of the unhealthy and exited components, up to
+	// maxUnhealthyIDs.
+	UnhealthyIDs []string `json:"unhealthyIDs,omitempty"`
+}
+
+// PeerStatus is the status of a peer of the cluster.
+type PeerStatus struct {
+	Peer   peer.Peer   `json:"peer"`
+	Status *NodeStatus `json:"status"` // nil if the status of the peer isn't known.
+
+	// ConfigDiverged and VersionDiverged are true if the peer runs another
+	// configuration or version than most peers, or if there's no majority.
+	ConfigDiverged  bool `json:"configDiverged"`
+	VersionDiverged bool `json:"versionDiverged"`
+}
+
+// FleetCluster is a Cluster which knows the status of its peers.
+type FleetCluster interface {
+	Cluster
+
+	// Fleet returns the status of every peer, ordered by name. The status of
+	// peers is refreshed periodically, and is only known for peers in the
+	// Participant or Terminating state.
+	Fleet() []PeerStatus
+}
+
+// RemoteConfig is the configuration loaded by the remotecfg service. It's set
+// with SetRemoteConfig rather than looked up from the host, since remotecfg
+// runs components which depend on the cluster service.
+type RemoteConfig interface {
+	// ConfigHash returns the hash of the loaded configuration.
+	ConfigHash() string
+
+	// Host returns the host running the components of the loaded
+	// configuration, or nil if it isn't running yet.
+	Host() service.Host
+}
+
+// localStatus builds the NodeStatus of the local node.
+type localStatus struct {
+	mut          sync.RWMutex
+	host         service.Host
+	configHash   string
+	remoteConfig RemoteConfig
+}
+
+func (ls *localStatus) setHost(host service.Host) {
+	ls.mut.Lock()
+	defer ls.mut.Unlock()
+	ls.host = host
+}
+
+func (ls *localStatus) setConfigHash(hash string) {
+	ls.mut.Lock()
+	defer ls.mut.Unlock()
+	ls.configHash = hash
+}
+
+func (ls *localStatus) setRemoteConfig(rc RemoteConfig) {
+	ls.mut.Lock()
+	defer ls.mut.Unlock()
+	ls.remoteConfig = rc
+}
+
+// remoteHost returns the host running the components of the remote
+// configuration, or nil if there's none.
+func (ls *localStatus) remoteHost() service.Host {
+	ls.mut.RLock()
+	rc := ls.remoteConfig
+	ls.mut.RUnlock()
+
+	if rc == nil {
+		return nil
+	}
+	return rc.Host()
+}
+
+// Status returns the current NodeStatus of the local node.
+func (ls *localStatus) Status() *NodeStatus {
+	ls.mut.RLock()
+	host, configHash, remoteConfig := ls.host, ls.configHash, ls.remoteConfig
+	ls.mut.RUnlock()
+
+	status := &NodeStatus{
+		Version:    build.Version,
+		ConfigHash: configHash,
+		UpdateTime: time.Now(),
+	}
+	if host == nil {
+		return status
+	}
+
+	components := component.GetAllComponents(host, component.InfoOptions{GetHealth: true})
+	if remoteConfig != nil {
+		status.RemoteConfigHash = remoteConfig.ConfigHash()
+		if remoteHost := remoteConfig.Host(); remoteHost != nil {
+			components = append(components, component.GetAllComponents(remoteHost, component.InfoOptions{GetHealth: true})...)
+		}
+	}
+
+	for _, info := range components {
+		switch info.Health.Health {
+		case component.HealthTypeHealthy:
+			status.Components.Healthy++
+			continue
+		case component.HealthTypeUnknown:
+			status.Components.Unknown++
+			continue
+		case component.HealthTypeUnhealthy:
+			status.Components.Unhealthy++
+		case component.HealthTypeExited:
+			status.Components.Exited++
+		}
+		if len(status.Components.UnhealthyIDs) < maxUnhealthyIDs {
+			status.Components.UnhealthyIDs = append(status.Components.UnhealthyIDs, info.ID.String())
+		}
+	}
+	return status
+}
+
+// SetConfigHash sets the hash of the configuration files loaded by the local
+// node, which is shared with peers.
+func (s *Service) SetConfigHash(hash string) {
+	s.localStatus.setConfigHash(hash)
+}
+
+// SetRemoteConfig sets the configuration loaded by the remotecfg service.
+// Its components are notified of cluster changes, and its hash is shared with
+// peers.
+func (s *Service) SetRemoteConfig(rc RemoteConfig) {
+	s.localStatus.setRemoteConfig(rc)
+}
+
+func (c *alloyCluster) Fleet() []PeerStatus {
+	var statuses map[string]*NodeStatus
+	if c.nodeInfos != nil {
+		statuses = c.nodeInfos.Statuses()
+	}
+
+	fleet := make([]PeerStatus, 0, len(c.sharder.Peers()))
+	for _, p := range c.sharder.Peers() {
+		ps := PeerStatus{Peer: p, Status: statuses[p.Name]}
+		if p.Self && c.localStatus != nil {
+			ps.Status = c.localStatus.Status()
+		}
+		fleet = append(fleet, ps)
+	}
+	slices.SortFunc(fleet, func(a, b PeerStatus) int { return cmp.Compare(a.Peer.Name, b.Peer.Name) })
+
+	markDiverged(fleet, func(s *NodeStatus) string { return s.ConfigHash + "/" + s.RemoteConfigHash }, func(ps *PeerStatus) {
+		ps.ConfigDiverged = true
+	})
+	markDiverged(fleet, func(s *NodeStatus) string { return s.Version }, func(ps *PeerStatus) {
+		ps.VersionDiverged = true
+	})
+	return fleet
+}
+
+// markDiverged calls mark for every peer with a known status whose value
+// differs from the most common value. If several values are the most common,
+// all peers with a known status are marked.
+func markDiverged(fleet []PeerStatus, value func(*NodeStatus) string, mark func(*PeerStatus)) {
+	counts := make(map[string]int)
+	for _, ps := range fleet {
+		if ps.Status != nil {
+			counts[value(ps.Status)]++
+		}
+	}
+
+	var (
+		majority string
+		best     int
+		tie      bool
+	)
+	for v, n := range counts {
+		switch {
+		case n > best:
+			majority, best, tie = v, n, false
+		case n == best:
+			tie = true
+		}
+	}
+
+	for i := range fleet {
+		if fleet[i].Status != nil && (tie || value(fleet[i].Status) != majority) {
+			mark(&fleet[i])
+		}
+	}
+}
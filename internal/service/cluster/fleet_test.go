@@ -0,0 +1,57 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/grafana/ckit/shard"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/build"
+)
+
+func TestFleet(t *testing.T) {
+	sharder := newWeightedSharder(shard.Ring(tokensPerNode))
+	sharder.SetPeers(testPeers())
+
+	ls := &localStatus{}
+	ls.setConfigHash("abc")
+
+	ni := newNodeInfos(1, "")
+	ni.Update("a", []string{"b", "c"}, map[string]nodeInfo{
+		"b": {Weight: 1, Status: &NodeStatus{Version: build.Version, ConfigHash: "abc"}},
+		"c": {Weight: 1, Status: &NodeStatus{Version: "v0.0.1", ConfigHash: "def", Components: ComponentHealth{Unhealthy: 1, UnhealthyIDs: []string{"prometheus.scrape.default"}}}},
+	})
+
+	c := &alloyCluster{sharder: sharder, nodeInfos: ni, localStatus: ls}
+	fleet := c.Fleet()
+	require.Len(t, fleet, 3)
+
+	require.Equal(t, "a", fleet[0].Peer.Name)
+	require.Equal(t, "abc", fleet[0].Status.ConfigHash)
+	require.Equal(t, build.Version, fleet[0].Status.Version)
+	require.False(t, fleet[0].ConfigDiverged)
+	require.False(t, fleet[0].VersionDiverged)
+
+	require.Equal(t, "b", fleet[1].Peer.Name)
+	require.False(t, fleet[1].ConfigDiverged)
+	require.False(t, fleet[1].VersionDiverged)
+
+	require.Equal(t, "c", fleet[2].Peer.Name)
+	require.True(t, fleet[2].ConfigDiverged)
+	require.True(t, fleet[2].VersionDiverged)
+	require.Equal(t, []string{"prometheus.scrape.default"}, fleet[2].Status.Components.UnhealthyIDs)
+}
+
+func TestFleet_NoMajority(t *testing.T) {
+	fleet := []PeerStatus{
+		{Status: &NodeStatus{ConfigHash: "abc"}},
+		{Status: &NodeStatus{ConfigHash: "def"}},
+		{Status: nil},
+	}
+	markDiverged(fleet, func(s *NodeStatus) string { return s.ConfigHash }, func(ps *PeerStatus) {
+		ps.ConfigDiverged = true
+	})
+	require.True(t, fleet[0].ConfigDiverged)
+	require.True(t, fleet[1].ConfigDiverged)
+	require.False(t, fleet[2].ConfigDiverged, "peers with an unknown status aren't marked")
+}
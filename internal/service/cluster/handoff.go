@@ -0,0 +1,257 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/ckit/peer"
+	"github.com/grafana/ckit/shard"
+
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+)
+
+const (
+	// handoffPath is the HTTP path where nodes receive handoff offers and
+	// confirmations from peers.
+	handoffPath = clusterAPIBase + "handoff"
+
+	// handoffRequestTimeout is the timeout for sending handoff messages to a
+	// peer.
+	handoffRequestTimeout = 5 * time.Second
+
+	// handoffSettleTime is how long Drain waits for components to start
+	// handing off their work after the node moved to the Terminating state.
+	handoffSettleTime = 2 * stateUpdateMinInterval
+
+	// handoffPollInterval is how often Drain checks whether components are
+	// done handing off their work.
+	handoffPollInterval = 250 * time.Millisecond
+)
+
+// Handoff describes a key whose work moves from its previous owner to a new
+// owner.
+type Handoff struct {
+	Key shard.Key `json:"key"`
+
+	// LastRun is when the previous owner last did the work for Key, and
+	// Interval how often it does the work. The new owner can use them to
+	// continue on the schedule of the previous owner. LastRun is zero if the
+	// previous owner didn't do the work yet.
+	LastRun  time.Time     `json:"last_run"`
+	Interval time.Duration `json:"interval"`
+}
+
+// ReceivedHandoff is a Handoff offered to the local node.
+type ReceivedHandoff struct {
+	Handoff
+
+	// From is the name of the previous owner of the key.
+	From string
+}
+
+// HandoffCluster is a Cluster which can hand off work between peers when keys
+// move, so that the work isn't interrupted.
+//
+// The previous owner of a key offers the key to its new owner with
+// OfferHandoffs, and keeps doing the work until the new owner confirms that
+// it took over with ConfirmHandoffs, or until HandoffTimeout passes.
+type HandoffCluster interface {
+	Cluster
+
+	// HandoffTimeout returns how long the previous owner of a key keeps doing
+	// the work for the key after it moved. Handoffs are disabled if
+	// HandoffTimeout returns zero.
+	HandoffTimeout() time.Duration
+
+	// OfferHandoffs offers the keys of scope which moved to the peer to. Components
+	// typically use their ID as the scope.
+	OfferHandoffs(ctx context.Context, scope string, to peer.Peer, handoffs []Handoff) error
+
+	// ConfirmHandoffs tells the peer named to, which offered keys of scope,
+	// that the local node took over the work for keys.
+	ConfirmHandoffs(ctx context.Context, scope string, to string, keys []shard.Key) error
+
+	// TakeHandoffs returns the offers and confirmations received for scope
+	// since the last call.
+	TakeHandoffs(scope string) (offers []ReceivedHandoff, confirmed []shard.Key)
+
+	// HandoffNotify returns a channel which receives a value whenever offers
+	// or confirmations are received for scope.
+	HandoffNotify(scope string) <-chan struct{}
+
+	// ReportReleasing sets how many keys of scope the local node still works
+	// on while waiting for their new owner to confirm the handoff. Nodes
+	// which shut down wait for all keys to be handed off.
+	ReportReleasing(scope string, count int)
+}
+
+// handoffMessage is the body of requests sent to handoffPath.
+type handoffMessage struct {
+	Scope     string      `json:"scope"`
+	From      string      `json:"-"` // Set from the authenticated peer on receipt.
+	Offers    []Handoff   `json:"offers,omitempty"`
+	Confirmed []shard.Key `json:"confirmed,omitempty"`
+}
+
+// handoffs tracks the handoffs of the local node.
+type handoffs struct {
+	peers *peerClient
+
+	mut       sync.Mutex
+	offers    map[string][]ReceivedHandoff
+	confirmed map[string][]shard.Key
+	notify    map[string]chan struct{}
+	releasing map[string]int
+}
+
+func newHandoffs(peers *peerClient) *handoffs {
+	return &handoffs{
+		peers:     peers,
+		offers:    make(map[string][]ReceivedHandoff),
+		confirmed: make(map[string][]shard.Key),
+		notify:    make(map[string]chan struct{}),
+		releasing: make(map[string]int),
+	}
+}
+
+// receive stores a message received from a peer.
+func (h *handoffs) receive(msg handoffMessage) {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+
+	for _, offer := range msg.Offers {
+		h.offers[msg.Scope] = append(h.offers[msg.Scope], ReceivedHandoff{Handoff: offer, From: msg.From})
+	}
+	h.confirmed[msg.Scope] = append(h.confirmed[msg.Scope], msg.Confirmed...)
+
+	select {
+	case h.notifyChan(msg.Scope) <- struct{}{}:
+	default:
+	}
+}
+
+// take returns and forgets the messages received for scope.
+func (h *handoffs) take(scope string) ([]ReceivedHandoff, []shard.Key) {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+
+	offers, confirmed := h.offers[scope], h.confirmed[scope]
+	delete(h.offers, scope)
+	delete(h.confirmed, scope)
+	return offers, confirmed
+}
+
+func (h *handoffs) notifyChan(scope string) chan struct{} {
+	ch, ok := h.notify[scope]
+	if !ok {
+		ch = make(chan struct{}, 1)
+		h.notify[scope] = ch
+	}
+	return ch
+}
+
+func (h *handoffs) subscribe(scope string) <-chan struct{} {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+	return h.notifyChan(scope)
+}
+
+func (h *handoffs) setReleasing(scope string, count int) {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+
+	if count <= 0 {
+		delete(h.releasing, scope)
+		return
+	}
+	h.releasing[scope] = count
+}
+
+// pending returns the scopes with keys which weren't handed off yet.
+func (h *handoffs) pending() []string {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+	return slices.Sorted(maps.Keys(h.releasing))
+}
+
+// send sends msg to the peer at addr.
+func (h *handoffs) send(ctx context.Context, addr string, msg handoffMessage) error {
+	ctx, cancel := context.WithTimeout(ctx, handoffRequestTimeout)
+	defer cancel()
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return h.peers.post(ctx, addr, handoffPath, body)
+}
+
+// handleHandoff receives handoff offers and confirmations from peers. Only
+// authenticated requests from current peers are accepted.
+func (s *Service) handleHandoff(w http.ResponseWriter, r *http.Request) {
+	from, body, ok := s.handoffs.peers.receive(w, r)
+	if !ok {
+		return
+	}
+
+	var msg handoffMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		http.Error(w, fmt.Sprintf("invalid handoff message: %s", err), http.StatusBadRequest)
+		return
+	}
+	msg.From = from
+	s.handoffs.receive(msg)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Drain moves the node to the Terminating state, so that its work moves to
+// other peers, and waits until components handed off their work, the handoff
+// timeout passes, or ctx is canceled. Drain returns immediately if clustering
+// or handoffs are disabled, or if the node doesn't participate in the cluster.
+// Handoffs are disabled without a shared secret.
+func (s *Service) Drain(ctx context.Context) error {
+	if !s.opts.EnableClustering || s.opts.HandoffTimeout <= 0 || !s.handoffs.peers.enabled() || s.node.CurrentState() != peer.StateParticipant {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.opts.HandoffTimeout)
+	defer cancel()
+
+	level.Info(s.log).Log("msg", "handing off work to other peers before shutting down", "timeout", s.opts.HandoffTimeout)
+	if err := s.node.ChangeState(ctx, peer.StateTerminating); err != nil {
+		return fmt.Errorf("failed to change state to Terminating: %w", err)
+	}
+
+	// Give components time to notice the state change and start handing off
+	// their work.
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-time.After(handoffSettleTime):
+	}
+
+	t := time.NewTicker(handoffPollInterval)
+	defer t.Stop()
+
+	for {
+		scopes := s.handoffs.pending()
+		if len(scopes) == 0 {
+			level.Info(s.log).Log("msg", "handed off all work to other peers")
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			level.Warn(s.log).Log("msg", "timed out handing off work to other peers", "pending", strings.Join(scopes, ","))
+			return nil
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package cluster
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/ckit/peer"
+	"github.com/grafana/ckit/shard"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandoffs(t *testing.T) {
+	secret := []byte("secret")
+
+	// Receiving node.
+	recv := &Service{handoffs: newHandoffs(newPeerClient("b", "http", http.DefaultClient, secret, func() []peer.Peer {
+		return []peer.Peer{{Name: "a", Addr: "127.0.0.1:12345"}}
+	}))}
+	srv := httptest.NewServer(http.HandlerFunc(recv.handleHandoff))
+	defer srv.Close()
+	notify := recv.handoffs.subscribe("scrape")
+
+	// Sending node.
+	send := newHandoffs(newPeerClient("a", "http", srv.Client(), secret, nil))
+	to := peer.Peer{Name: "b", Addr: strings.TrimPrefix(srv.URL, "http://")}
+	c := &alloyCluster{handoffs: send}
+
+	offer := Handoff{Key: 1, LastRun: time.Unix(100, 0).UTC(), Interval: time.Minute}
+	require.NoError(t, c.OfferHandoffs(t.Context(), "scrape", to, []Handoff{offer}))
+	require.NoError(t, send.send(t.Context(), to.Addr, handoffMessage{Scope: "scrape", Confirmed: []shard.Key{2}}))
+
+	select {
+	case <-notify:
+	default:
+		t.Fatal("expected notification")
+	}
+
+	offers, confirmed := recv.handoffs.take("scrape")
+	require.Equal(t, []ReceivedHandoff{{Handoff: offer, From: "a"}}, offers)
+	require.Equal(t, []shard.Key{2}, confirmed)
+
+	// Messages are only returned once, and scopes are independent.
+	offers, confirmed = recv.handoffs.take("scrape")
+	require.Empty(t, offers)
+	require.Empty(t, confirmed)
+	offers, _ = recv.handoffs.take("other")
+	require.Empty(t, offers)
+
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	// Nodes which aren't peers can't hand off keys.
+	other := newHandoffs(newPeerClient("c", "http", srv.Client(), secret, nil))
+	require.ErrorContains(t, other.send(t.Context(), to.Addr, handoffMessage{Scope: "scrape", Confirmed: []shard.Key{3}}), "403")
+	_, confirmed = recv.handoffs.take("scrape")
+	require.Empty(t, confirmed)
+}
+
+func TestHandoffs_NoSharedSecret(t *testing.T) {
+	h := newHandoffs(newPeerClient("a", "http", http.DefaultClient, nil, nil))
+	c := &alloyCluster{opts: Options{EnableClustering: true, HandoffTimeout: time.Minute}, handoffs: h}
+	require.Zero(t, c.HandoffTimeout())
+	require.ErrorIs(t, h.send(t.Context(), "127.0.0.1:1", handoffMessage{Scope: "scrape"}), errNoSharedSecret)
+}
+
+func TestHandoffs_Pending(t *testing.T) {
+	h := newHandoffs(newPeerClient("a", "http", http.DefaultClient, nil, nil))
+	h.setReleasing("b", 2)
+	h.setReleasing("a", 1)
+	require.Equal(t, []string{"a", "b"}, h.pending())
+
+	h.setReleasing("a", 0)
+	h.setReleasing("b", 0)
+	require.Empty(t, h.pending())
+}
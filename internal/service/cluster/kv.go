@@ -0,0 +1,612 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/ckit/peer"
+)
+
+const (
+	// kvPath is the HTTP path where nodes receive key-value state from peers.
+	kvPath = clusterAPIBase + "kv"
+
+	// kvGossipInterval is how often nodes send changed key-value state to
+	// peers.
+	kvGossipInterval = time.Second
+
+	// kvGossipFanout is the number of random peers nodes send changed state to
+	// every kvGossipInterval. Peers forward state they didn't know, so state
+	// reaches every peer after a few intervals.
+	kvGossipFanout = 3
+
+	// kvRequestTimeout is the timeout for sending state to a peer.
+	kvRequestTimeout = 5 * time.Second
+
+	// kvMaxKeys is the maximum number of keys in a scope, counting registers
+	// and counters.
+	kvMaxKeys = 10_000
+
+	// kvMaxValueSize is the maximum size of the value of a register.
+	kvMaxValueSize = 64 << 10
+
+	// kvMaxTTL is the maximum TTL of keys, which bounds how long deleted keys
+	// are remembered.
+	kvMaxTTL = 24 * time.Hour
+)
+
+var (
+	// ErrKVFull is returned when a scope of the key-value state has too many
+	// keys.
+	ErrKVFull = errors.New("too many keys in the key-value state")
+
+	// ErrKVValueTooLarge is returned when a value is larger than the maximum
+	// value size.
+	ErrKVValueTooLarge = errors.New("value too large for the key-value state")
+)
+
+// KVCluster is a Cluster whose peers share key-value state.
+type KVCluster interface {
+	Cluster
+
+	// KV returns the key-value state of scope. Components typically use their
+	// ID as the scope. KV returns nil if clustering is enabled but the state
+	// can't be shared with peers, because no cluster shared secret is
+	// configured.
+	KV(scope string) KV
+}
+
+// KV is key-value state shared between the peers of a cluster. Changes are
+// gossiped to peers in the background, so the state is eventually
+// consistent: peers may briefly see different values, but converge once
+// changes stop.
+//
+// KV holds two kinds of keys, which live in separate namespaces:
+//
+//   - Registers hold a value. Concurrent writes to a register by different
+//     peers resolve to the most recent write.
+//   - Counters hold a number per peer. Each peer only adds to its own number,
+//     and the value of a counter is the sum of the numbers of all peers, so
+//     concurrent additions never conflict.
+//
+// Every key expires after its TTL, which is capped to 24 hours. A scope holds
+// at most 10000 keys, and values are at most 64KiB.
+//
+// Peers authenticate the state they exchange with the cluster shared secret.
+type KV interface {
+	// Get returns the value of the register key. It returns false if the
+	// register doesn't exist, was deleted or expired.
+	Get(key string) ([]byte, bool)
+
+	// Set sets the value of the register key, which expires after ttl.
+	Set(key string, value []byte, ttl time.Duration) error
+
+	// Delete deletes the register key.
+	Delete(key string)
+
+	// Keys returns the sorted names of the registers starting with prefix.
+	Keys(prefix string) []string
+
+	// Add adds delta to the number of the local node for the counter key, and
+	// returns the new value of the counter. The number of the local node
+	// expires after ttl, which is refreshed by every call to Add.
+	Add(key string, delta float64, ttl time.Duration) (float64, error)
+
+	// Count returns the value of the counter key, which is 0 if the counter
+	// doesn't exist.
+	Count(key string) float64
+}
+
+// kvVersion orders the writes to a register. Versions are compared by time,
+// then by the name of the node, so that every node resolves concurrent
+// writes the same way.
+type kvVersion struct {
+	Time int64  `json:"time"` // Unix nanoseconds.
+	Node string `json:"node"`
+}
+
+func (v kvVersion) after(o kvVersion) bool {
+	if v.Time != o.Time {
+		return v.Time > o.Time
+	}
+	return v.Node > o.Node
+}
+
+// kvRegister is the state of a register. Deleted registers are kept until
+// they expire, so that deletes win over older writes received later.
+type kvRegister struct {
+	Value   []byte    `json:"value,omitempty"`
+	Deleted bool      `json:"deleted,omitempty"`
+	Version kvVersion `json:"version"`
+	Expires int64     `json:"expires"` // Unix nanoseconds.
+
+	seq uint64 // Local sequence number of the last change.
+}
+
+// kvCount is the number of a node for a counter. Only the node owning the
+// number changes it, so the number with the highest version is the latest.
+// Versions are based on time, so that they keep increasing when the node
+// restarts.
+type kvCount struct {
+	Value   float64 `json:"value"`
+	Version int64   `json:"version"`
+	Expires int64   `json:"expires"` // Unix nanoseconds.
+
+	seq uint64 // Local sequence number of the last change.
+}
+
+// kvMessage is the body of requests sent to kvPath.
+type kvMessage struct {
+	From        string              `json:"-"` // Set from the authenticated peer.
+	Incarnation string              `json:"incarnation"`
+	Registers   []kvRegisterMessage `json:"registers,omitempty"`
+	Counters    []kvCountMessage    `json:"counters,omitempty"`
+}
+
+type kvRegisterMessage struct {
+	Scope string `json:"scope"`
+	Key   string `json:"key"`
+	kvRegister
+}
+
+type kvCountMessage struct {
+	Scope string `json:"scope"`
+	Key   string `json:"key"`
+	Node  string `json:"node"`
+	kvCount
+}
+
+// kvScope holds the state of a scope.
+type kvScope struct {
+	registers map[string]*kvRegister
+	counters  map[string]map[string]*kvCount // Numbers of nodes by counter.
+}
+
+func (s *kvScope) size() int { return len(s.registers) + len(s.counters) }
+
+// kvStore holds the key-value state of the local node and gossips it to
+// peers.
+type kvStore struct {
+	self  string
+	peers *peerClient
+	now   func() time.Time
+
+	// incarnation identifies this run of the local node. Peers send their
+	// whole state again to a node whose incarnation changed, since it lost
+	// its state when it restarted.
+	incarnation string
+
+	mut          sync.Mutex
+	scopes       map[string]*kvScope
+	seq          uint64            // Sequence number of the last change.
+	sent         map[string]uint64 // Sequence number of the last change sent to each peer, by name.
+	incarnations map[string]string // Last incarnation received from each peer, by name.
+}
+
+func newKVStore(peers *peerClient) *kvStore {
+	return &kvStore{
+		self:         peers.self,
+		peers:        peers,
+		now:          time.Now,
+		incarnation:  fmt.Sprintf("%016x", rand.Uint64()),
+		scopes:       make(map[string]*kvScope),
+		sent:         make(map[string]uint64),
+		incarnations: make(map[string]string),
+	}
+}
+
+// scope returns the state of name, creating it if needed. kv.mut must be
+// held by the caller.
+func (kv *kvStore) scope(name string) *kvScope {
+	s, ok := kv.scopes[name]
+	if !ok {
+		s = &kvScope{
+			registers: make(map[string]*kvRegister),
+			counters:  make(map[string]map[string]*kvCount),
+		}
+		kv.scopes[name] = s
+	}
+	return s
+}
+
+func (kv *kvStore) expiry(now time.Time, ttl time.Duration) int64 {
+	return now.Add(min(ttl, kvMaxTTL)).UnixNano()
+}
+
+func (kv *kvStore) get(scope, key string) ([]byte, bool) {
+	kv.mut.Lock()
+	defer kv.mut.Unlock()
+
+	s, ok := kv.scopes[scope]
+	if !ok {
+		return nil, false
+	}
+	r, ok := s.registers[key]
+	if !ok || r.Deleted || r.Expires <= kv.now().UnixNano() {
+		return nil, false
+	}
+	return bytes.Clone(r.Value), true
+}
+
+func (kv *kvStore) set(scope, key string, value []byte, deleted bool, ttl time.Duration) error {
+	if len(value) > kvMaxValueSize {
+		return ErrKVValueTooLarge
+	}
+
+	kv.mut.Lock()
+	defer kv.mut.Unlock()
+
+	s := kv.scope(scope)
+	prev, exists := s.registers[key]
+	if !exists && deleted {
+		return nil
+	}
+	if !exists && s.size() >= kvMaxKeys {
+		return ErrKVFull
+	}
+
+	now := kv.now()
+	version := kvVersion{Time: now.UnixNano(), Node: kv.self}
+	if exists && !version.after(prev.Version) {
+		// Keep versions increasing if the clock went backwards.
+		version.Time = prev.Version.Time + 1
+	}
+	expires := kv.expiry(now, ttl)
+	if deleted {
+		// Remember the delete as long as the deleted value could be known.
+		expires = max(prev.Expires, now.UnixNano()+1)
+	}
+
+	kv.seq++
+	s.registers[key] = &kvRegister{
+		Value:   bytes.Clone(value),
+		Deleted: deleted,
+		Version: version,
+		Expires: expires,
+		seq:     kv.seq,
+	}
+	return nil
+}
+
+func (kv *kvStore) keys(scope, prefix string) []string {
+	kv.mut.Lock()
+	defer kv.mut.Unlock()
+
+	s, ok := kv.scopes[scope]
+	if !ok {
+		return nil
+	}
+	now := kv.now().UnixNano()
+	var keys []string
+	for key, r := range s.registers {
+		if strings.HasPrefix(key, prefix) && !r.Deleted && r.Expires > now {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func (kv *kvStore) add(scope, key string, delta float64, ttl time.Duration) (float64, error) {
+	kv.mut.Lock()
+	defer kv.mut.Unlock()
+
+	s := kv.scope(scope)
+	counts, exists := s.counters[key]
+	if !exists {
+		if s.size() >= kvMaxKeys {
+			return 0, ErrKVFull
+		}
+		counts = make(map[string]*kvCount)
+		s.counters[key] = counts
+	}
+
+	now := kv.now()
+	c, ok := counts[kv.self]
+	if !ok || c.Expires <= now.UnixNano() {
+		// Start from zero once the number expired.
+		var version int64
+		if ok {
+			version = c.Version
+		}
+		c = &kvCount{Version: version}
+		counts[kv.self] = c
+	}
+
+	kv.seq++
+	c.Value += delta
+	c.Version = max(c.Version+1, now.UnixNano())
+	c.Expires = kv.expiry(now, ttl)
+	c.seq = kv.seq
+	return kv.sum(counts, now.UnixNano()), nil
+}
+
+func (kv *kvStore) count(scope, key string) float64 {
+	kv.mut.Lock()
+	defer kv.mut.Unlock()
+
+	s, ok := kv.scopes[scope]
+	if !ok {
+		return 0
+	}
+	return kv.sum(s.counters[key], kv.now().UnixNano())
+}
+
+// sum returns the sum of the numbers of counts which didn't expire.
+func (kv *kvStore) sum(counts map[string]*kvCount, now int64) float64 {
+	var sum float64
+	for _, c := range counts {
+		if c.Expires > now {
+			sum += c.Value
+		}
+	}
+	return sum
+}
+
+// merge merges state received from a peer. State which is older than the
+// local state, expired, or exceeds the limits is ignored.
+func (kv *kvStore) merge(msg kvMessage) {
+	kv.mut.Lock()
+	defer kv.mut.Unlock()
+
+	if kv.incarnations[msg.From] != msg.Incarnation {
+		// The peer restarted, or it's the first message from it: send it the
+		// whole state.
+		kv.incarnations[msg.From] = msg.Incarnation
+		delete(kv.sent, msg.From)
+	}
+
+	now := kv.now().UnixNano()
+	for _, m := range msg.Registers {
+		if m.Expires <= now || len(m.Value) > kvMaxValueSize {
+			continue
+		}
+		s := kv.scope(m.Scope)
+		prev, exists := s.registers[m.Key]
+		if exists && !m.Version.after(prev.Version) {
+			continue
+		}
+		if !exists && s.size() >= kvMaxKeys {
+			continue
+		}
+		kv.seq++
+		r := m.kvRegister
+		r.Expires = min(r.Expires, now+int64(kvMaxTTL))
+		r.seq = kv.seq
+		s.registers[m.Key] = &r
+	}
+
+	for _, m := range msg.Counters {
+		if m.Expires <= now || m.Node == kv.self {
+			// The local node is the only writer of its own numbers.
+			continue
+		}
+		s := kv.scope(m.Scope)
+		counts, exists := s.counters[m.Key]
+		if !exists {
+			if s.size() >= kvMaxKeys {
+				continue
+			}
+			counts = make(map[string]*kvCount)
+			s.counters[m.Key] = counts
+		}
+		if prev, ok := counts[m.Node]; ok && m.Version <= prev.Version {
+			continue
+		}
+		kv.seq++
+		c := m.kvCount
+		c.Expires = min(c.Expires, now+int64(kvMaxTTL))
+		c.seq = kv.seq
+		counts[m.Node] = &c
+	}
+}
+
+// expire removes the expired state.
+func (kv *kvStore) expire() {
+	kv.mut.Lock()
+	defer kv.mut.Unlock()
+
+	now := kv.now().UnixNano()
+	for name, s := range kv.scopes {
+		for key, r := range s.registers {
+			if r.Expires <= now {
+				delete(s.registers, key)
+			}
+		}
+		for key, counts := range s.counters {
+			for node, c := range counts {
+				if c.Expires <= now {
+					delete(counts, node)
+				}
+			}
+			if len(counts) == 0 {
+				delete(s.counters, key)
+			}
+		}
+		if s.size() == 0 {
+			delete(kv.scopes, name)
+		}
+	}
+}
+
+// changes returns the state which changed after the sequence number since,
+// and the current sequence number.
+func (kv *kvStore) changes(since uint64) (kvMessage, uint64) {
+	kv.mut.Lock()
+	defer kv.mut.Unlock()
+
+	msg := kvMessage{From: kv.self, Incarnation: kv.incarnation}
+	for name, s := range kv.scopes {
+		for key, r := range s.registers {
+			if r.seq > since {
+				msg.Registers = append(msg.Registers, kvRegisterMessage{Scope: name, Key: key, kvRegister: *r})
+			}
+		}
+		for key, counts := range s.counters {
+			for node, c := range counts {
+				if c.seq > since {
+					msg.Counters = append(msg.Counters, kvCountMessage{Scope: name, Key: key, Node: node, kvCount: *c})
+				}
+			}
+		}
+	}
+	return msg, kv.seq
+}
+
+// gossip sends the state which changed since the last successful send to up
+// to kvGossipFanout random peers. Peers which left are forgotten.
+//
+// The first message to a peer is sent even if it's empty, so that the peer
+// learns the incarnation of the local node and sends back its whole state
+// after the local node restarted.
+func (kv *kvStore) gossip(ctx context.Context, peers []peer.Peer, rnd *rand.Rand) {
+	var targets []peer.Peer
+	names := make(map[string]struct{}, len(peers))
+	for _, p := range peers {
+		if p.Self || (p.State != peer.StateParticipant && p.State != peer.StateTerminating) {
+			continue
+		}
+		targets = append(targets, p)
+		names[p.Name] = struct{}{}
+	}
+
+	kv.mut.Lock()
+	for name := range kv.sent {
+		if _, ok := names[name]; !ok {
+			delete(kv.sent, name)
+		}
+	}
+	for name := range kv.incarnations {
+		if _, ok := names[name]; !ok {
+			delete(kv.incarnations, name)
+		}
+	}
+	kv.mut.Unlock()
+
+	rnd.Shuffle(len(targets), func(i, j int) { targets[i], targets[j] = targets[j], targets[i] })
+	targets = targets[:min(len(targets), kvGossipFanout)]
+
+	var wg sync.WaitGroup
+	for _, p := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = kv.sendChanges(ctx, p)
+		}()
+	}
+	wg.Wait()
+}
+
+// sendChanges sends the state which changed since the last successful send
+// to p.
+func (kv *kvStore) sendChanges(ctx context.Context, p peer.Peer) error {
+	kv.mut.Lock()
+	since, known := kv.sent[p.Name]
+	incarnation := kv.incarnations[p.Name]
+	kv.mut.Unlock()
+
+	msg, seq := kv.changes(since)
+	if !known || len(msg.Registers) > 0 || len(msg.Counters) > 0 {
+		if err := kv.send(ctx, p.Addr, msg); err != nil {
+			return err
+		}
+	}
+
+	kv.mut.Lock()
+	defer kv.mut.Unlock()
+	if kv.incarnations[p.Name] != incarnation {
+		// The peer restarted while the changes were sent, so it needs the
+		// whole state again.
+		return nil
+	}
+	kv.sent[p.Name] = max(kv.sent[p.Name], seq)
+	return nil
+}
+
+// send sends msg to the peer at addr.
+func (kv *kvStore) send(ctx context.Context, addr string, msg kvMessage) error {
+	ctx, cancel := context.WithTimeout(ctx, kvRequestTimeout)
+	defer cancel()
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return kv.peers.post(ctx, addr, kvPath, body)
+}
+
+// handleKV receives key-value state from peers.
+func (s *Service) handleKV(w http.ResponseWriter, r *http.Request) {
+	from, body, ok := s.kv.peers.receive(w, r)
+	if !ok {
+		return
+	}
+
+	var msg kvMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		http.Error(w, fmt.Sprintf("invalid key-value message: %s", err), http.StatusBadRequest)
+		return
+	}
+	msg.From = from
+	s.kv.merge(msg)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// runKVGossip gossips key-value state to peers until ctx is canceled.
+func (s *Service) runKVGossip(ctx context.Context) {
+	t := time.NewTicker(kvGossipInterval)
+	defer t.Stop()
+
+	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		s.kv.expire()
+		s.kv.gossip(ctx, s.node.Peers(), rnd)
+	}
+}
+
+// scopedKV is the KV of a scope.
+type scopedKV struct {
+	store *kvStore
+	scope string
+}
+
+var _ KV = scopedKV{}
+
+func (kv scopedKV) Get(key string) ([]byte, bool) { return kv.store.get(kv.scope, key) }
+
+func (kv scopedKV) Set(key string, value []byte, ttl time.Duration) error {
+	if ttl <= 0 {
+		return fmt.Errorf("ttl must be positive, got %s", ttl)
+	}
+	return kv.store.set(kv.scope, key, value, false, ttl)
+}
+
+func (kv scopedKV) Delete(key string) {
+	// Deletes never exceed the limits.
+	_ = kv.store.set(kv.scope, key, nil, true, 0)
+}
+
+func (kv scopedKV) Keys(prefix string) []string { return kv.store.keys(kv.scope, prefix) }
+
+func (kv scopedKV) Add(key string, delta float64, ttl time.Duration) (float64, error) {
+	if ttl <= 0 {
+		return 0, fmt.Errorf("ttl must be positive, got %s", ttl)
+	}
+	return kv.store.add(kv.scope, key, delta, ttl)
+}
+
+func (kv scopedKV) Count(key string) float64 { return kv.store.count(kv.scope, key) }
@@ -0,0 +1,231 @@
+package cluster
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/ckit/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestKVStore(self string, now *time.Time) *kvStore {
+	kv := newKVStore(newPeerClient(self, "http", http.DefaultClient, nil, nil))
+	kv.now = func() time.Time { return *now }
+	return kv
+}
+
+func TestKV_Registers(t *testing.T) {
+	now := time.Unix(1000, 0)
+	kv := scopedKV{store: newTestKVStore("a", &now), scope: "test"}
+
+	require.NoError(t, kv.Set("silence/1", []byte("one"), time.Minute))
+	require.NoError(t, kv.Set("silence/2", []byte("two"), 2*time.Minute))
+	require.NoError(t, kv.Set("other", []byte("other"), time.Minute))
+
+	v, ok := kv.Get("silence/1")
+	require.True(t, ok)
+	require.Equal(t, []byte("one"), v)
+	require.Equal(t, []string{"silence/1", "silence/2"}, kv.Keys("silence/"))
+
+	// Scopes are independent.
+	_, ok = scopedKV{store: kv.store, scope: "other"}.Get("silence/1")
+	require.False(t, ok)
+
+	kv.Delete("silence/2")
+	_, ok = kv.Get("silence/2")
+	require.False(t, ok)
+
+	now = now.Add(time.Minute)
+	_, ok = kv.Get("silence/1")
+	require.False(t, ok, "expired register")
+	require.Empty(t, kv.Keys("silence/"))
+
+	require.Error(t, kv.Set("key", []byte("value"), 0))
+	require.ErrorIs(t, kv.Set("key", make([]byte, kvMaxValueSize+1), time.Minute), ErrKVValueTooLarge)
+}
+
+func TestKV_MaxKeys(t *testing.T) {
+	now := time.Unix(1000, 0)
+	kv := scopedKV{store: newTestKVStore("a", &now), scope: "test"}
+
+	for i := range kvMaxKeys {
+		require.NoError(t, kv.Set(fmt.Sprintf("k%d", i), nil, time.Minute))
+	}
+	require.ErrorIs(t, kv.Set("new", nil, time.Minute), ErrKVFull)
+	_, err := kv.Add("new", 1, time.Minute)
+	require.ErrorIs(t, err, ErrKVFull)
+
+	// Existing keys can still be written, and space is freed once keys expire.
+	require.NoError(t, kv.Set("k0", []byte("v"), time.Minute))
+	now = now.Add(time.Minute)
+	kv.store.expire()
+	require.NoError(t, kv.Set("new", nil, time.Minute))
+}
+
+func TestKV_Merge(t *testing.T) {
+	now := time.Unix(1000, 0)
+	a, b := newTestKVStore("a", &now), newTestKVStore("b", &now)
+	kvA, kvB := scopedKV{store: a, scope: "test"}, scopedKV{store: b, scope: "test"}
+
+	// Concurrent writes resolve to the latest one on every node.
+	require.NoError(t, kvA.Set("key", []byte("a"), time.Minute))
+	now = now.Add(time.Second)
+	require.NoError(t, kvB.Set("key", []byte("b"), time.Minute))
+	exchange(a, b)
+
+	for _, kv := range []scopedKV{kvA, kvB} {
+		v, ok := kv.Get("key")
+		require.True(t, ok)
+		require.Equal(t, []byte("b"), v)
+	}
+
+	// Deletes win over older writes received later.
+	now = now.Add(time.Second)
+	kvA.Delete("key")
+	stale, _ := b.changes(0)
+	exchange(a, b)
+	b.merge(stale)
+	a.merge(stale)
+	for _, kv := range []scopedKV{kvA, kvB} {
+		_, ok := kv.Get("key")
+		require.False(t, ok)
+	}
+
+	// Counters sum the numbers of all nodes.
+	total, err := kvA.Add("requests", 2, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, 2.0, total)
+	_, err = kvB.Add("requests", 3, time.Minute)
+	require.NoError(t, err)
+	exchange(a, b)
+	require.Equal(t, 5.0, kvA.Count("requests"))
+	require.Equal(t, 5.0, kvB.Count("requests"))
+
+	// Merging is idempotent.
+	exchange(a, b)
+	require.Equal(t, 5.0, kvA.Count("requests"))
+
+	// Numbers of nodes expire independently.
+	now = now.Add(30 * time.Second)
+	_, err = kvA.Add("requests", 1, time.Minute)
+	require.NoError(t, err)
+	now = now.Add(40 * time.Second)
+	require.Equal(t, 3.0, kvA.Count("requests"))
+	exchange(a, b)
+	require.Equal(t, 3.0, kvB.Count("requests"))
+}
+
+func TestKV_Gossip(t *testing.T) {
+	var (
+		peers  []peer.Peer
+		stores []*kvStore
+	)
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		store := newKVStore(newPeerClient(name, "http", http.DefaultClient, []byte("secret"), func() []peer.Peer { return peers }))
+		svc := &Service{kv: store}
+		srv := httptest.NewServer(http.HandlerFunc(svc.handleKV))
+		t.Cleanup(srv.Close)
+
+		stores = append(stores, store)
+		peers = append(peers, peer.Peer{Name: name, Addr: strings.TrimPrefix(srv.URL, "http://"), State: peer.StateParticipant})
+	}
+
+	for i, store := range stores {
+		_, err := scopedKV{store: store, scope: "test"}.Add("requests", float64(i+1), time.Minute)
+		require.NoError(t, err)
+	}
+	require.NoError(t, scopedKV{store: stores[0], scope: "test"}.Set("leader", []byte("a"), time.Minute))
+
+	rnd := rand.New(rand.NewSource(0))
+	require.Eventually(t, func() bool {
+		for i, store := range stores {
+			self := make([]peer.Peer, len(peers))
+			copy(self, peers)
+			self[i].Self = true
+			store.gossip(t.Context(), self, rnd)
+		}
+		for _, store := range stores {
+			kv := scopedKV{store: store, scope: "test"}
+			if v, ok := kv.Get("leader"); !ok || string(v) != "a" || kv.Count("requests") != 15 {
+				return false
+			}
+		}
+		return true
+	}, 10*time.Second, 10*time.Millisecond)
+
+	// Nodes which aren't peers can't change the state.
+	other := newKVStore(newPeerClient("x", "http", http.DefaultClient, []byte("secret"), nil))
+	require.NoError(t, scopedKV{store: other, scope: "test"}.Set("leader", []byte("x"), time.Minute))
+	require.ErrorContains(t, other.sendChanges(t.Context(), peers[1]), "403")
+	v, _ := scopedKV{store: stores[1], scope: "test"}.Get("leader")
+	require.Equal(t, "a", string(v))
+}
+
+// TestKV_GossipRestart ensures that a node which restarted gets the state
+// which its peers already sent before it restarted.
+func TestKV_GossipRestart(t *testing.T) {
+	var (
+		peers  []peer.Peer
+		stores []*kvStore
+	)
+	start := func(name string) (*kvStore, string) {
+		store := newKVStore(newPeerClient(name, "http", http.DefaultClient, []byte("secret"), func() []peer.Peer { return peers }))
+		svc := &Service{kv: store}
+		srv := httptest.NewServer(http.HandlerFunc(svc.handleKV))
+		t.Cleanup(srv.Close)
+		return store, strings.TrimPrefix(srv.URL, "http://")
+	}
+	for _, name := range []string{"a", "b", "c"} {
+		store, addr := start(name)
+		stores = append(stores, store)
+		peers = append(peers, peer.Peer{Name: name, Addr: addr, State: peer.StateParticipant})
+	}
+	require.NoError(t, scopedKV{store: stores[0], scope: "test"}.Set("leader", []byte("a"), time.Minute))
+
+	rnd := rand.New(rand.NewSource(0))
+	converged := func() bool {
+		for i, store := range stores {
+			self := make([]peer.Peer, len(peers))
+			copy(self, peers)
+			self[i].Self = true
+			store.gossip(t.Context(), self, rnd)
+		}
+		for _, store := range stores {
+			if v, ok := (scopedKV{store: store, scope: "test"}).Get("leader"); !ok || string(v) != "a" {
+				return false
+			}
+		}
+		return true
+	}
+	require.Eventually(t, converged, 10*time.Second, 10*time.Millisecond)
+
+	// Restart b with an empty state. The state doesn't change anymore, so b
+	// only gets it if its peers send it again.
+	stores[1], peers[1].Addr = start("b")
+	require.Eventually(t, converged, 10*time.Second, 10*time.Millisecond)
+}
+
+// exchange sends the full state of a to b and back.
+func exchange(a, b *kvStore) {
+	msgA, _ := a.changes(0)
+	msgB, _ := b.changes(0)
+	b.merge(msgA)
+	a.merge(msgB)
+}
+
+func TestKV_NoSharedSecret(t *testing.T) {
+	c := &alloyCluster{
+		opts: Options{EnableClustering: true},
+		kv:   newKVStore(newPeerClient("a", "http", http.DefaultClient, nil, nil)),
+	}
+	require.Nil(t, c.KV("test"))
+
+	// The state of a node without clustering is never shared.
+	c.opts.EnableClustering = false
+	require.NotNil(t, c.KV("test"))
+}
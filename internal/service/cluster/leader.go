@@ -0,0 +1,108 @@
+package cluster
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/ckit/peer"
+	"github.com/grafana/ckit/shard"
+
+	"github.com/grafana/alloy/internal/component"
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+)
+
+// LeaderBlock holds common arguments for components which can be configured
+// to run on a single instance of the cluster. LeaderBlock is intended to be
+// exposed as a block called "clustering".
+type LeaderBlock struct {
+	LeaderOnly bool `alloy:"leader_only,attr"`
+}
+
+// LeaderComponent is a component which can be configured to only do its work
+// on the leader of the cluster.
+type LeaderComponent interface {
+	Component
+
+	// Leadership returns the leadership of the component, or nil if the
+	// component isn't configured to only run on the leader.
+	Leadership() *Leadership
+}
+
+// Leadership tracks which peer of the cluster leads a key. The leader of a
+// key is the single owner of the key in the hash ring, so the leaders of
+// different keys are spread across the cluster.
+//
+// Peers stop being eligible as soon as they move to the Terminating state,
+// so leadership is handed over when the leader leaves the cluster, before it
+// stops.
+//
+// Leadership must be updated by calling Update when the cluster changes,
+// typically from [Component.NotifyClusterChange].
+type Leadership struct {
+	key     string
+	log     log.Logger
+	cluster Cluster
+
+	mut    sync.RWMutex
+	leader peer.Peer
+}
+
+// NewLeadership returns a new Leadership for key. Call Update to determine
+// the leader.
+func NewLeadership(key string, cluster Cluster, logger log.Logger) *Leadership {
+	return &Leadership{
+		key:     key,
+		log:     logger,
+		cluster: cluster,
+	}
+}
+
+// NewComponentLeadership returns a new Leadership for the component
+// identified by opts, using the cluster service of the component.
+func NewComponentLeadership(opts component.Options) (*Leadership, error) {
+	data, err := opts.GetServiceData(ServiceName)
+	if err != nil {
+		return nil, fmt.Errorf("getting cluster service failed: %w", err)
+	}
+	return NewLeadership(opts.ID, data.(Cluster), opts.Logger), nil
+}
+
+// Update determines the current leader, and returns true if the local node
+// gained or lost leadership since the last call.
+func (l *Leadership) Update() (bool, error) {
+	// NOTE: since this is leader election, it is okay to NOT check if cluster is ready.
+	peers, err := l.cluster.Lookup(shard.StringKey(l.key), 1, shard.OpReadWrite)
+	if err != nil {
+		return false, fmt.Errorf("unable to determine leader for %s: %w", l.key, err)
+	}
+	if len(peers) != 1 {
+		return false, fmt.Errorf("unexpected peers from leadership check: %+v", peers)
+	}
+
+	l.mut.Lock()
+	prev := l.leader
+	l.leader = peers[0]
+	l.mut.Unlock()
+
+	changed := prev.Self != peers[0].Self
+	if prev.Name != peers[0].Name {
+		level.Info(l.log).Log("msg", "leader changed", "key", l.key, "leader", peers[0].Name, "is_leader", peers[0].Self)
+	}
+	return changed, nil
+}
+
+// IsLeader returns true if the local node is the leader.
+func (l *Leadership) IsLeader() bool {
+	l.mut.RLock()
+	defer l.mut.RUnlock()
+	return l.leader.Self
+}
+
+// Leader returns the current leader. It returns false if the leader hasn't
+// been determined yet.
+func (l *Leadership) Leader() (peer.Peer, bool) {
+	l.mut.RLock()
+	defer l.mut.RUnlock()
+	return l.leader, l.leader.Name != ""
+}
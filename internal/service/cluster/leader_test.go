@@ -0,0 +1,58 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/ckit/peer"
+	"github.com/grafana/ckit/shard"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeadership(t *testing.T) {
+	sharder := shard.Ring(tokensPerNode)
+	sharder.SetPeers([]peer.Peer{
+		{Name: "a", Addr: "a:12345", Self: true, State: peer.StateParticipant},
+		{Name: "b", Addr: "b:12345", State: peer.StateParticipant},
+	})
+	l := NewLeadership("loki.source.kubernetes_events.default", &alloyCluster{sharder: sharder}, log.NewNopLogger())
+
+	_, ok := l.Leader()
+	require.False(t, ok, "leader must not be known before Update")
+
+	_, err := l.Update()
+	require.NoError(t, err)
+	leader, ok := l.Leader()
+	require.True(t, ok)
+	require.Equal(t, leader.Self, l.IsLeader())
+
+	changed, err := l.Update()
+	require.NoError(t, err)
+	require.False(t, changed, "leadership must not change with the same peers")
+
+	// The leader terminating hands over leadership to the other peer.
+	var peers []peer.Peer
+	for _, p := range sharder.Peers() {
+		if p.Name == leader.Name {
+			p.State = peer.StateTerminating
+		}
+		peers = append(peers, p)
+	}
+	sharder.SetPeers(peers)
+
+	changed, err = l.Update()
+	require.NoError(t, err)
+	require.True(t, changed)
+	newLeader, ok := l.Leader()
+	require.True(t, ok)
+	require.NotEqual(t, leader.Name, newLeader.Name)
+	require.Equal(t, !leader.Self, l.IsLeader())
+}
+
+func TestLeadership_NoPeers(t *testing.T) {
+	l := NewLeadership("key", &alloyCluster{sharder: shard.Ring(tokensPerNode)}, log.NewNopLogger())
+
+	_, err := l.Update()
+	require.Error(t, err)
+	require.False(t, l.IsLeader())
+}
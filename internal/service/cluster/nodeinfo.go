@@ -0,0 +1,392 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/grafana/ckit/peer"
+	"github.com/grafana/ckit/shard"
+
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+)
+
+const (
+	// nodeInfoPath is the HTTP path where nodes serve their nodeInfo to peers.
+	nodeInfoPath = clusterAPIBase + "node"
+
+	// nodeInfoSyncInterval is how often nodes fetch the nodeInfo of their
+	// peers. Nodes also fetch it whenever peers change.
+	nodeInfoSyncInterval = 15 * time.Second
+
+	// nodeInfoTimeout is the timeout for fetching the nodeInfo of a peer.
+	nodeInfoTimeout = 5 * time.Second
+
+	// costTTL is how long the cost of a key is remembered after no node reports
+	// it anymore. This keeps the cost of keys known while they move between
+	// peers.
+	costTTL = 10 * time.Minute
+
+	// costChangeThreshold is the relative change of the total cost of a scope
+	// from which components are notified to redistribute their keys.
+	costChangeThreshold = 0.1
+)
+
+// nodeInfo is the information a node shares with the other peers of the
+// cluster.
+type nodeInfo struct {
+	// Weight is the capacity weight of the node.
+	Weight float64 `json:"weight"`
+	// Zone is the failure zone of the node.
+	Zone string `json:"zone,omitempty"`
+	// Status summarizes the state of the node.
+	Status *NodeStatus `json:"status,omitempty"`
+	// Costs holds the cost of the keys owned by the node, by scope.
+	Costs map[string]map[shard.Key]float64 `json:"costs,omitempty"`
+	// View is a digest of the peers, weights and zones known by the node.
+	View uint64 `json:"view,omitempty"`
+}
+
+// nodeInfos tracks the nodeInfo of the local node and its peers.
+type nodeInfos struct {
+	mut    sync.RWMutex
+	local  nodeInfo
+	peers  map[string]nodeInfo                // Last known nodeInfo of peers by name.
+	costs  map[string]map[shard.Key]knownCost // Costs reported by any node, by scope.
+	totals map[string]float64                 // Total cost of every scope when it was last considered changed.
+	agreed bool                               // True if every peer reported the same view as the local node.
+}
+
+type knownCost struct {
+	cost     float64
+	lastSeen time.Time
+}
+
+func newNodeInfos(weight float64, zone string) *nodeInfos {
+	return &nodeInfos{
+		local:  nodeInfo{Weight: weight, Zone: zone, Costs: make(map[string]map[shard.Key]float64)},
+		peers:  make(map[string]nodeInfo),
+		costs:  make(map[string]map[shard.Key]knownCost),
+		totals: make(map[string]float64),
+	}
+}
+
+// Local returns the nodeInfo of the local node.
+func (ni *nodeInfos) Local() nodeInfo {
+	ni.mut.RLock()
+	defer ni.mut.RUnlock()
+	return nodeInfo{Weight: ni.local.Weight, Zone: ni.local.Zone, Costs: maps.Clone(ni.local.Costs), View: ni.local.View}
+}
+
+// Agreed returns true if every peer reported the same view of the peers,
+// weights and zones as the local node on the last call to Update.
+//
+// ckit doesn't propagate node metadata, so weights and zones are exchanged
+// over HTTP and peers learn about changes at different times. Peers must only
+// use them for lookups once they agree, otherwise peers which already know a
+// weight and peers which don't would pick different owners for the same keys.
+func (ni *nodeInfos) Agreed() bool {
+	ni.mut.RLock()
+	defer ni.mut.RUnlock()
+	return ni.agreed
+}
+
+// Statuses returns the last known status of every peer by name.
+func (ni *nodeInfos) Statuses() map[string]*NodeStatus {
+	ni.mut.RLock()
+	defer ni.mut.RUnlock()
+
+	statuses := make(map[string]*NodeStatus, len(ni.peers))
+	for name, info := range ni.peers {
+		if info.Status != nil {
+			statuses[name] = info.Status
+		}
+	}
+	return statuses
+}
+
+// Zones returns the zone of the local node, named self, and of every known
+// peer. Nodes without a zone are omitted.
+func (ni *nodeInfos) Zones(self string) map[string]string {
+	ni.mut.RLock()
+	defer ni.mut.RUnlock()
+
+	zones := make(map[string]string, len(ni.peers)+1)
+	for name, info := range ni.peers {
+		if info.Zone != "" {
+			zones[name] = info.Zone
+		}
+	}
+	if ni.local.Zone != "" {
+		zones[self] = ni.local.Zone
+	}
+	return zones
+}
+
+// SetLocalCosts sets the costs of the keys of scope owned by the local node.
+// Passing no costs removes the scope.
+func (ni *nodeInfos) SetLocalCosts(scope string, costs map[shard.Key]float64) {
+	ni.mut.Lock()
+	defer ni.mut.Unlock()
+
+	if len(costs) == 0 {
+		delete(ni.local.Costs, scope)
+		return
+	}
+	ni.local.Costs[scope] = costs
+	ni.mergeCosts(scope, costs, time.Now())
+}
+
+// Update stores the nodeInfo fetched from peers. Peers which aren't in names
+// are forgotten, while peers in names which are missing from infos keep their
+// previous nodeInfo. Update returns the weights of all peers, and whether the
+// cost of any scope changed significantly since the last call which returned
+// true.
+func (ni *nodeInfos) Update(self string, names []string, infos map[string]nodeInfo) (weights map[string]float64, costsChanged bool) {
+	ni.mut.Lock()
+	defer ni.mut.Unlock()
+
+	now := time.Now()
+	peers := make(map[string]nodeInfo, len(names))
+	for _, name := range names {
+		if info, ok := infos[name]; ok {
+			peers[name] = info
+		} else if info, ok := ni.peers[name]; ok {
+			peers[name] = info
+		}
+	}
+	ni.peers = peers
+
+	weights = map[string]float64{self: ni.local.Weight}
+	for name, info := range infos {
+		if info.Weight > 0 {
+			weights[name] = info.Weight
+		}
+		for scope, costs := range info.Costs {
+			ni.mergeCosts(scope, costs, now)
+		}
+	}
+	for name, info := range ni.peers {
+		if _, ok := weights[name]; !ok && info.Weight > 0 {
+			weights[name] = info.Weight
+		}
+	}
+	for scope, costs := range ni.local.Costs {
+		ni.mergeCosts(scope, costs, now)
+	}
+
+	// The view compared with peers is the one from this call, while peers
+	// report the one from their last sync, so peers agree from the sync after
+	// they all learned about a change.
+	ni.local.View = ni.viewDigest(self, weights)
+	ni.agreed = true
+	for _, name := range names {
+		if info, ok := ni.peers[name]; !ok || info.View != ni.local.View {
+			ni.agreed = false
+			break
+		}
+	}
+
+	// Expire costs and find scopes with a significant change.
+	for scope, costs := range ni.costs {
+		var total float64
+		for key, c := range costs {
+			if now.Sub(c.lastSeen) > costTTL {
+				delete(costs, key)
+				continue
+			}
+			total += c.cost
+		}
+		if len(costs) == 0 {
+			delete(ni.costs, scope)
+		}
+
+		prev, ok := ni.totals[scope]
+		if !ok || math.Abs(total-prev) > costChangeThreshold*prev {
+			ni.totals[scope] = total
+			costsChanged = true
+		}
+	}
+	for scope := range ni.totals {
+		if _, ok := ni.costs[scope]; !ok {
+			delete(ni.totals, scope)
+			costsChanged = true
+		}
+	}
+	return weights, costsChanged
+}
+
+// viewDigest returns a digest of the local node, named self, and of the known
+// peers with their weights and zones. ni.mut must be held by the caller.
+func (ni *nodeInfos) viewDigest(self string, weights map[string]float64) uint64 {
+	names := slices.Sorted(maps.Keys(ni.peers))
+	if !slices.Contains(names, self) {
+		names = append(names, self)
+		slices.Sort(names)
+	}
+
+	h := xxhash.New()
+	for _, name := range names {
+		weight, ok := weights[name]
+		if !ok {
+			weight = 1
+		}
+		zone := ni.peers[name].Zone
+		if name == self {
+			zone = ni.local.Zone
+		}
+		_, _ = h.WriteString(name)
+		_, _ = h.WriteString("\x00" + strconv.FormatFloat(weight, 'g', -1, 64))
+		_, _ = h.WriteString("\x00" + zone + "\x00")
+	}
+	return h.Sum64()
+}
+
+// mergeCosts records costs reported for scope. ni.mut must be held by the
+// caller.
+func (ni *nodeInfos) mergeCosts(scope string, costs map[shard.Key]float64, now time.Time) {
+	known, ok := ni.costs[scope]
+	if !ok {
+		known = make(map[shard.Key]knownCost, len(costs))
+		ni.costs[scope] = known
+	}
+	for key, c := range costs {
+		known[key] = knownCost{cost: c, lastSeen: now}
+	}
+}
+
+// Costs returns the cost of every key of scope. Keys with an unknown cost are
+// given the average cost of the known keys, or 1 if no cost is known.
+func (ni *nodeInfos) Costs(scope string, keys []shard.Key) []float64 {
+	ni.mut.RLock()
+	defer ni.mut.RUnlock()
+
+	var (
+		res   = make([]float64, len(keys))
+		known = ni.costs[scope]
+		total float64
+		count int
+	)
+	for i, key := range keys {
+		res[i] = math.NaN()
+		if c, ok := known[key]; ok {
+			res[i] = c.cost
+			total += c.cost
+			count++
+		}
+	}
+
+	fallback := 1.0
+	if count > 0 && total > 0 {
+		fallback = total / float64(count)
+	}
+	for i := range res {
+		if math.IsNaN(res[i]) {
+			res[i] = fallback
+		}
+	}
+	return res
+}
+
+// handleNodeInfo serves the nodeInfo of the local node.
+func (s *Service) handleNodeInfo(w http.ResponseWriter, _ *http.Request) {
+	info := s.nodeInfos.Local()
+	if s.localStatus != nil {
+		info.Status = s.localStatus.Status()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(info)
+}
+
+// syncNodeInfo fetches the nodeInfo of all peers, and notifies components if
+// peer weights, zones or costs changed. Weights and zones are only given to
+// the sharder once all peers agree on them; until then, lookups use the hash
+// ring.
+func (s *Service) syncNodeInfo(ctx context.Context) {
+	var (
+		mut   sync.Mutex
+		wg    sync.WaitGroup
+		names []string
+		infos = make(map[string]nodeInfo)
+	)
+	for _, p := range s.node.Peers() {
+		if p.Self || (p.State != peer.StateParticipant && p.State != peer.StateTerminating) {
+			continue
+		}
+		names = append(names, p.Name)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			info, err := s.fetchNodeInfo(ctx, p)
+			if err != nil {
+				level.Debug(s.log).Log("msg", "failed to fetch node info of peer", "peer", p.Name, "err", err)
+				return
+			}
+			mut.Lock()
+			infos[p.Name] = info
+			mut.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	weights, costsChanged := s.nodeInfos.Update(s.opts.NodeName, names, infos)
+	zones := s.nodeInfos.Zones(s.opts.NodeName)
+	if !s.nodeInfos.Agreed() {
+		level.Debug(s.log).Log("msg", "peers don't agree on weights and zones yet, using the hash ring")
+		weights, zones = nil, nil
+	}
+
+	var weightsChanged, zonesChanged bool
+	if ws, ok := s.sharder.(*weightedSharder); ok {
+		if ws.SetWeights(weights) {
+			weightsChanged = true
+			level.Info(s.log).Log("msg", "peer weights changed", "weighted", ws.Weighted())
+		}
+		if ws.SetZones(zones) {
+			zonesChanged = true
+			level.Info(s.log).Log("msg", "peer zones changed")
+		}
+	}
+	if weightsChanged || zonesChanged || costsChanged {
+		s.triggerClusterChangeNotification()
+	}
+}
+
+func (s *Service) fetchNodeInfo(ctx context.Context, p peer.Peer) (nodeInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, nodeInfoTimeout)
+	defer cancel()
+
+	scheme := "http"
+	if s.opts.EnableTLS {
+		scheme = "https"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, scheme+"://"+p.Addr+nodeInfoPath, nil)
+	if err != nil {
+		return nodeInfo{}, err
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nodeInfo{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nodeInfo{}, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	var info nodeInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nodeInfo{}, fmt.Errorf("decoding node info: %w", err)
+	}
+	return info, nil
+}
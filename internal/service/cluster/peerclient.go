@@ -0,0 +1,186 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grafana/ckit/peer"
+)
+
+const (
+	// Headers of the requests sent between peers.
+	peerNameHeader      = "X-Alloy-Cluster-Peer"
+	peerTimestampHeader = "X-Alloy-Cluster-Timestamp"
+	peerSignatureHeader = "X-Alloy-Cluster-Signature"
+
+	// peerRequestMaxAge is how far the timestamp of a request from a peer may
+	// be from the local time, which bounds how long a captured request can be
+	// replayed.
+	peerRequestMaxAge = time.Minute
+
+	// peerRequestMaxBodySize is the maximum size of the body of a request
+	// from a peer.
+	peerRequestMaxBodySize = 32 << 20
+)
+
+// errNoSharedSecret is returned when a request must be exchanged with a peer
+// but no shared secret is configured.
+var errNoSharedSecret = errors.New("no cluster shared secret is configured")
+
+// peerClient sends requests to the cluster API of peers, and authenticates
+// the requests received from them.
+//
+// Requests are signed with an HMAC of a secret shared by all peers, and are
+// only accepted from current peers of the cluster, at the address they
+// advertise.
+type peerClient struct {
+	self   string
+	scheme string
+	client *http.Client
+	secret []byte
+	peers  func() []peer.Peer
+	now    func() time.Time
+}
+
+func newPeerClient(self, scheme string, client *http.Client, secret []byte, peers func() []peer.Peer) *peerClient {
+	return &peerClient{
+		self:   self,
+		scheme: scheme,
+		client: client,
+		secret: secret,
+		peers:  peers,
+		now:    time.Now,
+	}
+}
+
+// enabled returns true if requests can be exchanged with peers.
+func (pc *peerClient) enabled() bool {
+	return len(pc.secret) > 0
+}
+
+// post sends body to path of the peer at addr, and expects an empty
+// response.
+func (pc *peerClient) post(ctx context.Context, addr, path string, body []byte) error {
+	if !pc.enabled() {
+		return errNoSharedSecret
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, pc.scheme+"://"+addr+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(pc.now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(peerNameHeader, pc.self)
+	req.Header.Set(peerTimestampHeader, timestamp)
+	req.Header.Set(peerSignatureHeader, hex.EncodeToString(pc.signature(http.MethodPost, path, pc.self, timestamp, body)))
+
+	resp, err := pc.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// receive authenticates a request sent with post, and returns the name of
+// the peer which sent it and its body. If the request isn't accepted,
+// receive writes an error response and returns false.
+func (pc *peerClient) receive(w http.ResponseWriter, r *http.Request) (from string, body []byte, ok bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return "", nil, false
+	}
+	if !pc.enabled() {
+		http.Error(w, errNoSharedSecret.Error(), http.StatusForbidden)
+		return "", nil, false
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, peerRequestMaxBodySize))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("reading request: %s", err), http.StatusBadRequest)
+		return "", nil, false
+	}
+
+	from = r.Header.Get(peerNameHeader)
+	if err := pc.verify(r, from, body); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return "", nil, false
+	}
+	if err := pc.checkPeer(r, from); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return "", nil, false
+	}
+	return from, body, true
+}
+
+// verify checks the timestamp and the signature of a request from the peer
+// named from.
+func (pc *peerClient) verify(r *http.Request, from string, body []byte) error {
+	timestamp := r.Header.Get(peerTimestampHeader)
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %s header", peerTimestampHeader)
+	}
+	if age := pc.now().Sub(time.Unix(unix, 0)); age > peerRequestMaxAge || age < -peerRequestMaxAge {
+		return fmt.Errorf("request timestamp is too far from the local time")
+	}
+
+	signature, err := hex.DecodeString(r.Header.Get(peerSignatureHeader))
+	if err != nil || !hmac.Equal(signature, pc.signature(r.Method, r.URL.Path, from, timestamp, body)) {
+		return fmt.Errorf("invalid request signature")
+	}
+	return nil
+}
+
+// checkPeer checks that name is a current peer of the cluster, and that r
+// comes from the address it advertises.
+func (pc *peerClient) checkPeer(r *http.Request, name string) error {
+	for _, p := range pc.peers() {
+		if p.Self || p.Name != name {
+			continue
+		}
+
+		// Peers are usually advertised by IP. Other addresses can't be
+		// compared with the address of the request.
+		host, _, err := net.SplitHostPort(p.Addr)
+		if err != nil {
+			return fmt.Errorf("invalid address of peer %q", name)
+		}
+		advertised := net.ParseIP(host)
+		if advertised == nil {
+			return nil
+		}
+		remoteHost, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil || !advertised.Equal(net.ParseIP(remoteHost)) {
+			return fmt.Errorf("request doesn't come from the address of peer %q", name)
+		}
+		return nil
+	}
+	return fmt.Errorf("%q isn't a peer of the cluster", name)
+}
+
+func (pc *peerClient) signature(method, path, from, timestamp string, body []byte) []byte {
+	bodyHash := sha256.Sum256(body)
+
+	mac := hmac.New(sha256.New, pc.secret)
+	for _, s := range []string{method, path, from, timestamp, hex.EncodeToString(bodyHash[:])} {
+		_, _ = io.WriteString(mac, s)
+		_, _ = mac.Write([]byte{0})
+	}
+	return mac.Sum(nil)
+}
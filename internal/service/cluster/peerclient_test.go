@@ -0,0 +1,91 @@
+package cluster
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/ckit/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerClient(t *testing.T) {
+	secret := []byte("secret")
+	peers := []peer.Peer{
+		{Name: "a", Addr: "127.0.0.1:12345"},
+		{Name: "b", Addr: "127.0.0.1:12345", Self: true},
+		{Name: "remote", Addr: "10.0.0.1:12345"},
+		{Name: "dns", Addr: "alloy-0.alloy:12345"},
+	}
+
+	var received []string
+	recv := newPeerClient("b", "http", http.DefaultClient, secret, func() []peer.Peer { return peers })
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		from, body, ok := recv.receive(w, r)
+		if !ok {
+			return
+		}
+		received = append(received, from+":"+string(body))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+	addr := strings.TrimPrefix(srv.URL, "http://")
+
+	tests := []struct {
+		name   string
+		sender *peerClient
+		err    string
+	}{
+		{
+			name:   "peer",
+			sender: newPeerClient("a", "http", srv.Client(), secret, nil),
+		},
+		{
+			name:   "peer advertised by name",
+			sender: newPeerClient("dns", "http", srv.Client(), secret, nil),
+		},
+		{
+			name:   "wrong secret",
+			sender: newPeerClient("a", "http", srv.Client(), []byte("other"), nil),
+			err:    "unexpected status code 401",
+		},
+		{
+			name:   "unknown peer",
+			sender: newPeerClient("c", "http", srv.Client(), secret, nil),
+			err:    "unexpected status code 403",
+		},
+		{
+			name:   "other address",
+			sender: newPeerClient("remote", "http", srv.Client(), secret, nil),
+			err:    "unexpected status code 403",
+		},
+		{
+			name:   "self",
+			sender: newPeerClient("b", "http", srv.Client(), secret, nil),
+			err:    "unexpected status code 403",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			received = nil
+			err := tc.sender.post(t.Context(), addr, "/api/v1/cluster/test", []byte("hello"))
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				require.Empty(t, received)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, []string{tc.sender.self + ":hello"}, received)
+		})
+	}
+
+	t.Run("stale request", func(t *testing.T) {
+		received = nil
+		sender := newPeerClient("a", "http", srv.Client(), secret, nil)
+		sender.now = func() time.Time { return time.Now().Add(-2 * peerRequestMaxAge) }
+		require.EqualError(t, sender.post(t.Context(), addr, "/api/v1/cluster/test", []byte("hello")), "unexpected status code 401")
+		require.Empty(t, received)
+	})
+}
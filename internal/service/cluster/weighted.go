@@ -0,0 +1,320 @@
+package cluster
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"sync"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/grafana/ckit/peer"
+	"github.com/grafana/ckit/shard"
+)
+
+// costBalanceSlack is how much more cost than its share a peer may be
+// assigned by LookupByCost before keys spill over to other peers. Some slack
+// keeps ownership stable when costs change slightly.
+const costBalanceSlack = 0.1
+
+// weightedSharder is a shard.Sharder which distributes keys proportionally to
+// the capacity weight of peers.
+//
+// While all peers have the same weight, lookups are delegated to the hash
+// ring, so ownership is the same as in clusters which don't use weights.
+// Otherwise, owners are picked with weighted rendezvous hashing. The service
+// only sets weights once all peers agree on them (see nodeInfos.Agreed), so
+// peers never pick owners with different algorithms.
+type weightedSharder struct {
+	ring shard.Sharder
+
+	mut      sync.RWMutex
+	peers    []peer.Peer
+	weights  map[string]float64 // Weights of peers by name. Missing peers have a weight of 1.
+	weighted bool               // True if any peer has a weight other than 1.
+	zones    map[string]string  // Zones of peers by name. Peers without a zone are each in their own zone.
+}
+
+var _ shard.Sharder = (*weightedSharder)(nil)
+
+func newWeightedSharder(ring shard.Sharder) *weightedSharder {
+	return &weightedSharder{ring: ring}
+}
+
+// Peers implements shard.Sharder.
+func (s *weightedSharder) Peers() []peer.Peer {
+	return s.ring.Peers()
+}
+
+// SetPeers implements shard.Sharder.
+func (s *weightedSharder) SetPeers(ps []peer.Peer) {
+	s.ring.SetPeers(ps)
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.peers = s.ring.Peers()
+}
+
+// SetWeights sets the weights of peers by name, and returns true if they
+// changed. Peers without a weight have a weight of 1.
+func (s *weightedSharder) SetWeights(weights map[string]float64) bool {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	changed := len(weights) != len(s.weights)
+	weighted := false
+	for name, w := range weights {
+		if prev, ok := s.weights[name]; !ok || prev != w {
+			changed = true
+		}
+		if w != 1 {
+			weighted = true
+		}
+	}
+	s.weights = weights
+	s.weighted = weighted
+	return changed
+}
+
+// SetZones sets the failure zones of peers by name, and returns true if they
+// changed.
+func (s *weightedSharder) SetZones(zones map[string]string) bool {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	changed := !maps.Equal(zones, s.zones)
+	s.zones = zones
+	return changed
+}
+
+// Weighted returns true if any peer has a weight other than 1.
+func (s *weightedSharder) Weighted() bool {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+	return s.weighted
+}
+
+// Weight returns the weight of the peer with the given name.
+func (s *weightedSharder) Weight(name string) float64 {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+	return s.weight(name)
+}
+
+func (s *weightedSharder) weight(name string) float64 {
+	if w, ok := s.weights[name]; ok {
+		return w
+	}
+	return 1
+}
+
+// Lookup implements shard.Sharder.
+func (s *weightedSharder) Lookup(key shard.Key, numOwners int, op shard.Op) ([]peer.Peer, error) {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	if !s.weighted {
+		return s.ring.Lookup(key, numOwners, op)
+	}
+
+	eligible, err := s.eligiblePeers(op)
+	if err != nil {
+		return nil, err
+	}
+	if len(eligible) < numOwners {
+		return nil, fmt.Errorf("not enough peers: need %d, have %d", numOwners, len(eligible))
+	}
+	return s.rank(key, eligible)[:numOwners], nil
+}
+
+// LookupByCost returns the owner of each key, so that every peer owns a total
+// cost of keys proportional to its weight. costs holds the cost of each key.
+//
+// Keys are assigned in order of decreasing cost to the first peer in their
+// rendezvous ranking which has capacity left, so most keys keep their owner
+// when keys or peers change. The result only depends on the keys, the costs,
+// the peers and their weights. Peers compute the same owners only when they
+// agree on all of them; costs are exchanged with the node info of peers, so
+// after a cost change peers may disagree until the next sync, and a key may be
+// owned by more than one peer or by none in the meantime.
+func (s *weightedSharder) LookupByCost(keys []shard.Key, costs []float64) ([]peer.Peer, error) {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	eligible, err := s.eligiblePeers(shard.OpReadWrite)
+	if err != nil {
+		return nil, err
+	}
+	if len(eligible) == 0 {
+		return nil, fmt.Errorf("not enough peers: need 1, have 0")
+	}
+
+	var totalCost, totalWeight float64
+	for _, c := range costs {
+		totalCost += c
+	}
+	for _, p := range eligible {
+		totalWeight += s.weight(p.Name)
+	}
+
+	capacity := make(map[string]float64, len(eligible))
+	load := make(map[string]float64, len(eligible))
+	for _, p := range eligible {
+		capacity[p.Name] = (1 + costBalanceSlack) * totalCost * s.weight(p.Name) / totalWeight
+	}
+
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortFunc(order, func(a, b int) int {
+		if c := cmp.Compare(costs[b], costs[a]); c != 0 {
+			return c
+		}
+		return cmp.Compare(keys[a], keys[b])
+	})
+
+	owners := make([]peer.Peer, len(keys))
+	for _, i := range order {
+		ranked := s.rank(keys[i], eligible)
+
+		// Fall back to the peer with the lowest relative load if no peer has
+		// capacity left, which happens for keys costing more than a share.
+		owner := ranked[0]
+		for _, p := range ranked[1:] {
+			if (load[p.Name]+costs[i])/s.weight(p.Name) < (load[owner.Name]+costs[i])/s.weight(owner.Name) {
+				owner = p
+			}
+		}
+		for _, p := range ranked {
+			if load[p.Name]+costs[i] <= capacity[p.Name] {
+				owner = p
+				break
+			}
+		}
+
+		load[owner.Name] += costs[i]
+		owners[i] = owner
+	}
+	return owners, nil
+}
+
+// LookupReplicas returns up to replicas owners of key, in order of preference.
+// Owners are picked in order of their rendezvous ranking, skipping peers in
+// the zone of an owner picked before. If there are fewer zones than replicas,
+// the remaining owners are the next peers of the ranking. If there are fewer
+// peers than replicas, all peers own the key.
+//
+// LookupReplicas only considers peers in the Participant state.
+func (s *weightedSharder) LookupReplicas(key shard.Key, replicas int) ([]peer.Peer, error) {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+
+	eligible, err := s.eligiblePeers(shard.OpReadWrite)
+	if err != nil {
+		return nil, err
+	}
+	if len(eligible) == 0 {
+		return nil, fmt.Errorf("not enough peers: need 1, have 0")
+	}
+	replicas = min(max(replicas, 1), len(eligible))
+
+	var (
+		ranked = s.rank(key, eligible)
+		owners = make([]peer.Peer, 0, replicas)
+		picked = make([]bool, len(ranked))
+		zones  = make(map[string]struct{}, replicas)
+	)
+	for i, p := range ranked {
+		if len(owners) == replicas {
+			break
+		}
+		zone := s.zone(p.Name)
+		if _, ok := zones[zone]; ok {
+			continue
+		}
+		zones[zone] = struct{}{}
+		owners = append(owners, p)
+		picked[i] = true
+	}
+	for i, p := range ranked {
+		if len(owners) == replicas {
+			break
+		}
+		if !picked[i] {
+			owners = append(owners, p)
+		}
+	}
+	return owners, nil
+}
+
+// zone returns the zone of the peer with the given name. Peers without a zone
+// are in a zone of their own. s.mut must be held by the caller.
+func (s *weightedSharder) zone(name string) string {
+	if z, ok := s.zones[name]; ok && z != "" {
+		return "zone/" + z
+	}
+	return "peer/" + name
+}
+
+// eligiblePeers returns the peers which may own keys for op. s.mut must be
+// held by the caller.
+func (s *weightedSharder) eligiblePeers(op shard.Op) ([]peer.Peer, error) {
+	if op != shard.OpRead && op != shard.OpReadWrite {
+		return nil, fmt.Errorf("unknown op %s", op)
+	}
+
+	eligible := make([]peer.Peer, 0, len(s.peers))
+	for _, p := range s.peers {
+		if p.State == peer.StateParticipant || (p.State == peer.StateTerminating && op == shard.OpRead) {
+			eligible = append(eligible, p)
+		}
+	}
+	return eligible, nil
+}
+
+// rank returns peers ordered by their weighted rendezvous score for key. s.mut
+// must be held by the caller.
+func (s *weightedSharder) rank(key shard.Key, peers []peer.Peer) []peer.Peer {
+	type scoredPeer struct {
+		peer  peer.Peer
+		score float64
+	}
+	scored := make([]scoredPeer, len(peers))
+	for i, p := range peers {
+		scored[i] = scoredPeer{peer: p, score: rendezvousScore(key, p.Name, s.weight(p.Name))}
+	}
+	slices.SortFunc(scored, func(a, b scoredPeer) int {
+		if c := cmp.Compare(b.score, a.score); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.peer.Name, b.peer.Name)
+	})
+
+	res := make([]peer.Peer, len(scored))
+	for i, sp := range scored {
+		res[i] = sp.peer
+	}
+	return res
+}
+
+// rendezvousScore returns the score of a peer for key in weighted rendezvous
+// hashing. The peer with the highest score owns the key, and the chance of a
+// peer having the highest score is proportional to its weight.
+func rendezvousScore(key shard.Key, name string, weight float64) float64 {
+	h := mix64(uint64(key) ^ xxhash.Sum64String(name))
+	// Map the hash to a uniformly distributed number in (0, 1).
+	u := (float64(h>>11) + 0.5) / (1 << 53)
+	return -weight / math.Log(u)
+}
+
+// mix64 is the finalizer of SplitMix64, which spreads the bits of x.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,251 @@
+package cluster
+
+import (
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grafana/ckit/peer"
+	"github.com/grafana/ckit/shard"
+	"github.com/stretchr/testify/require"
+)
+
+func testPeers() []peer.Peer {
+	return []peer.Peer{
+		{Name: "a", Addr: "a:12345", Self: true, State: peer.StateParticipant},
+		{Name: "b", Addr: "b:12345", State: peer.StateParticipant},
+		{Name: "c", Addr: "c:12345", State: peer.StateParticipant},
+	}
+}
+
+func TestWeightedSharder_Uniform(t *testing.T) {
+	ring := shard.Ring(tokensPerNode)
+	ring.SetPeers(testPeers())
+
+	s := newWeightedSharder(shard.Ring(tokensPerNode))
+	s.SetPeers(testPeers())
+	require.True(t, s.SetWeights(map[string]float64{"a": 1, "b": 1}))
+	require.False(t, s.Weighted())
+
+	// Lookups must match the ring while all weights are equal.
+	for i := range 1000 {
+		key := shard.Key(rand.Uint64())
+		expect, err := ring.Lookup(key, 1, shard.OpReadWrite)
+		require.NoError(t, err)
+		actual, err := s.Lookup(key, 1, shard.OpReadWrite)
+		require.NoError(t, err)
+		require.Equal(t, expect, actual, "key %d", i)
+	}
+}
+
+func TestWeightedSharder_Weights(t *testing.T) {
+	s := newWeightedSharder(shard.Ring(tokensPerNode))
+	s.SetPeers(testPeers())
+	require.True(t, s.SetWeights(map[string]float64{"a": 1, "b": 1, "c": 2}))
+	require.False(t, s.SetWeights(map[string]float64{"a": 1, "b": 1, "c": 2}))
+	require.True(t, s.Weighted())
+
+	const numKeys = 100_000
+	owned := make(map[string]int)
+	for range numKeys {
+		peers, err := s.Lookup(shard.Key(rand.Uint64()), 1, shard.OpReadWrite)
+		require.NoError(t, err)
+		owned[peers[0].Name]++
+	}
+	require.InEpsilon(t, 0.25, float64(owned["a"])/numKeys, 0.05)
+	require.InEpsilon(t, 0.25, float64(owned["b"])/numKeys, 0.05)
+	require.InEpsilon(t, 0.5, float64(owned["c"])/numKeys, 0.05)
+
+	// Terminating peers only own keys for reads.
+	peers := testPeers()
+	peers[2].State = peer.StateTerminating
+	s.SetPeers(peers)
+	for range 1000 {
+		key := shard.Key(rand.Uint64())
+		owners, err := s.Lookup(key, 2, shard.OpReadWrite)
+		require.NoError(t, err)
+		require.NotContains(t, []string{owners[0].Name, owners[1].Name}, "c")
+
+		owners, err = s.Lookup(key, 3, shard.OpRead)
+		require.NoError(t, err)
+		require.Len(t, owners, 3)
+	}
+	_, err := s.Lookup(1, 3, shard.OpReadWrite)
+	require.Error(t, err)
+}
+
+func TestWeightedSharder_LookupByCost(t *testing.T) {
+	s := newWeightedSharder(shard.Ring(tokensPerNode))
+	s.SetPeers(testPeers())
+	s.SetWeights(map[string]float64{"a": 1, "b": 1, "c": 2})
+
+	// One key is a hundred times heavier than the others.
+	keys := make([]shard.Key, 1000)
+	costs := make([]float64, len(keys))
+	var total float64
+	for i := range keys {
+		keys[i] = shard.Key(rand.Uint64())
+		costs[i] = 1
+		if i == 0 {
+			costs[i] = 100
+		}
+		total += costs[i]
+	}
+
+	owners, err := s.LookupByCost(keys, costs)
+	require.NoError(t, err)
+
+	load := make(map[string]float64)
+	for i, owner := range owners {
+		load[owner.Name] += costs[i]
+	}
+	require.LessOrEqual(t, load["a"], (1+costBalanceSlack)*total/4)
+	require.LessOrEqual(t, load["b"], (1+costBalanceSlack)*total/4)
+	require.LessOrEqual(t, load["c"], (1+costBalanceSlack)*total/2)
+
+	// Owners only depend on the keys, costs and peers.
+	again, err := s.LookupByCost(keys, costs)
+	require.NoError(t, err)
+	require.Equal(t, owners, again)
+
+	// A small change to costs moves few keys.
+	costs[1] = 2
+	changed, err := s.LookupByCost(keys, costs)
+	require.NoError(t, err)
+	var moved int
+	for i := range owners {
+		if owners[i].Name != changed[i].Name {
+			moved++
+		}
+	}
+	require.Less(t, moved, len(keys)/10)
+}
+
+func TestWeightedSharder_LookupReplicas(t *testing.T) {
+	peers := []peer.Peer{
+		{Name: "a", Addr: "a:12345", Self: true, State: peer.StateParticipant},
+		{Name: "b", Addr: "b:12345", State: peer.StateParticipant},
+		{Name: "c", Addr: "c:12345", State: peer.StateParticipant},
+		{Name: "d", Addr: "d:12345", State: peer.StateParticipant},
+		{Name: "e", Addr: "e:12345", State: peer.StateTerminating},
+	}
+	s := newWeightedSharder(shard.Ring(tokensPerNode))
+	s.SetPeers(peers)
+	require.True(t, s.SetZones(map[string]string{"a": "z1", "b": "z1", "c": "z2", "d": "z2", "e": "z3"}))
+	require.False(t, s.SetZones(map[string]string{"a": "z1", "b": "z1", "c": "z2", "d": "z2", "e": "z3"}))
+
+	zone := map[string]string{"a": "z1", "b": "z1", "c": "z2", "d": "z2"}
+	for range 1000 {
+		key := shard.Key(rand.Uint64())
+
+		// Replicas are in different zones.
+		owners, err := s.LookupReplicas(key, 2)
+		require.NoError(t, err)
+		require.Len(t, owners, 2)
+		require.NotEqual(t, zone[owners[0].Name], zone[owners[1].Name])
+
+		// Zones are reused once all of them have a replica, and keys are
+		// owned by all participants if there aren't enough.
+		owners, err = s.LookupReplicas(key, 3)
+		require.NoError(t, err)
+		require.Len(t, owners, 3)
+		owners, err = s.LookupReplicas(key, 10)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"a", "b", "c", "d"}, peerNames(owners))
+
+		// Lower replication factors pick a prefix of the owners.
+		first, err := s.LookupReplicas(key, 1)
+		require.NoError(t, err)
+		require.Equal(t, owners[0], first[0])
+	}
+
+	// Peers without a zone are each in their own zone.
+	s.SetZones(nil)
+	owners, err := s.LookupReplicas(1, 4)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"a", "b", "c", "d"}, peerNames(owners))
+}
+
+func peerNames(peers []peer.Peer) []string {
+	names := make([]string, len(peers))
+	for i, p := range peers {
+		names[i] = p.Name
+	}
+	return names
+}
+
+func TestNodeInfos(t *testing.T) {
+	ni := newNodeInfos(2, "")
+	ni.SetLocalCosts("scrape", map[shard.Key]float64{1: 10})
+
+	weights, changed := ni.Update("a", []string{"b", "c"}, map[string]nodeInfo{
+		"b": {Weight: 4, Costs: map[string]map[shard.Key]float64{"scrape": {2: 30}}},
+		"c": {Weight: 1},
+	})
+	require.True(t, changed)
+	require.Equal(t, map[string]float64{"a": 2, "b": 4, "c": 1}, weights)
+	require.Equal(t, []float64{10, 30, 20}, ni.Costs("scrape", []shard.Key{1, 2, 3}))
+	require.Equal(t, []float64{1}, ni.Costs("other", []shard.Key{1}))
+
+	// Peers which couldn't be fetched keep their weight, and the costs they
+	// reported stay known.
+	weights, changed = ni.Update("a", []string{"b"}, nil)
+	require.False(t, changed)
+	require.Equal(t, map[string]float64{"a": 2, "b": 4}, weights)
+	require.Equal(t, []float64{10, 30}, ni.Costs("scrape", []shard.Key{1, 2}))
+
+	ni.SetLocalCosts("scrape", map[shard.Key]float64{1: 100})
+	_, changed = ni.Update("a", []string{"b"}, nil)
+	require.True(t, changed)
+}
+
+func TestNodeInfos_Zones(t *testing.T) {
+	ni := newNodeInfos(1, "z1")
+	ni.Update("a", []string{"b", "c"}, map[string]nodeInfo{
+		"b": {Weight: 1, Zone: "z2"},
+		"c": {Weight: 1},
+	})
+	require.Equal(t, map[string]string{"a": "z1", "b": "z2"}, ni.Zones("a"))
+	require.Equal(t, "z1", ni.Local().Zone)
+
+	ni.Update("a", nil, nil)
+	require.Equal(t, map[string]string{"a": "z1"}, ni.Zones("a"))
+}
+
+func TestNodeInfos_Agreed(t *testing.T) {
+	a, b := newNodeInfos(2, "z1"), newNodeInfos(1, "z2")
+	exchange := func() {
+		a.Update("a", []string{"b"}, map[string]nodeInfo{"b": b.Local()})
+		b.Update("b", []string{"a"}, map[string]nodeInfo{"a": a.Local()})
+	}
+
+	// Peers only agree once they both reported the view they computed from
+	// each other's weights.
+	exchange()
+	require.False(t, a.Agreed())
+	exchange()
+	require.True(t, a.Agreed())
+	require.True(t, b.Agreed())
+
+	// A peer which doesn't report a view, such as an older version, never
+	// agrees.
+	a.Update("a", []string{"b", "c"}, map[string]nodeInfo{"b": b.Local(), "c": {Weight: 1}})
+	require.False(t, a.Agreed())
+}
+
+func TestService_NodeInfo(t *testing.T) {
+	s := &Service{nodeInfos: newNodeInfos(3, ""), httpClient: http.DefaultClient}
+	s.nodeInfos.SetLocalCosts("scrape", map[shard.Key]float64{42: 7})
+
+	srv := httptest.NewServer(http.HandlerFunc(s.handleNodeInfo))
+	defer srv.Close()
+
+	info, err := s.fetchNodeInfo(t.Context(), peer.Peer{Name: "a", Addr: strings.TrimPrefix(srv.URL, "http://")})
+	require.NoError(t, err)
+	require.Equal(t, nodeInfo{
+		Weight: 3,
+		Costs:  map[string]map[shard.Key]float64{"scrape": {42: 7}},
+	}, info)
+}
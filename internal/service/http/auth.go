@@ -4,10 +4,13 @@ import (
 	"crypto/sha256"
 	"crypto/subtle"
 	"errors"
+	"fmt"
 	"net/http"
 	"slices"
 	"strings"
 
+	"github.com/go-kit/log"
+
 	"github.com/grafana/alloy/syntax"
 	"github.com/grafana/alloy/syntax/alloytypes"
 )
@@ -15,6 +18,47 @@ import (
 type AuthArguments struct {
 	Basic  *BasicAuthArguments `alloy:"basic,block,optional"`
 	Filter FilterAuthArguments `alloy:"filter,block,optional"`
+
+	// Identities enable role-based access control. They can't be combined
+	// with Basic.
+	Identities          []IdentityArguments `alloy:"identity,block,optional"`
+	UnauthenticatedRole string              `alloy:"unauthenticated_role,attr,optional"`
+}
+
+var _ syntax.Validator = (*AuthArguments)(nil)
+
+// Validate implements syntax.Validator.
+func (a *AuthArguments) Validate() error {
+	if len(a.Identities) == 0 {
+		if a.UnauthenticatedRole != "" {
+			return errors.New("unauthenticated_role can only be set with identity blocks")
+		}
+		return nil
+	}
+	if a.Basic != nil {
+		return errors.New("basic and identity blocks can't be set at the same time")
+	}
+	if _, err := parseRole(a.UnauthenticatedRole); err != nil {
+		return fmt.Errorf("unauthenticated_role: %w", err)
+	}
+
+	names := make(map[string]struct{}, len(a.Identities))
+	for _, id := range a.Identities {
+		if _, ok := names[id.Name]; ok {
+			return fmt.Errorf("identity %q is defined more than once", id.Name)
+		}
+		names[id.Name] = struct{}{}
+	}
+	return nil
+}
+
+// rbac returns the role-based access control of the arguments, or nil if no
+// identities are configured.
+func (a *AuthArguments) rbac(l log.Logger) *rbac {
+	if len(a.Identities) == 0 {
+		return nil
+	}
+	return newRBAC(a, l)
 }
 
 type BasicAuthArguments struct {
@@ -87,6 +87,8 @@ type Service struct {
 	authenticatorMut sync.RWMutex
 	// authenticator is applied to every request made to http server
 	authenticator authenticator
+	// rbac replaces authenticator when identities are configured.
+	rbac *rbac
 
 	// publicLis and tcpLis are used to lazily enable TLS, since TLS is
 	// optionally configurable at runtime.
@@ -199,9 +201,18 @@ func (s *Service) Run(ctx context.Context, host service.Host) error {
 	r.Use(func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			s.authenticatorMut.RLock()
-			err := s.authenticator(w, r)
+			authenticator, rbac := s.authenticator, s.rbac
 			s.authenticatorMut.RUnlock()
-			if err != nil {
+
+			if rbac != nil {
+				r, ok := rbac.authorize(w, r)
+				if ok {
+					h.ServeHTTP(w, r)
+				}
+				return
+			}
+
+			if err := authenticator(w, r); err != nil {
 				level.Info(s.log).Log("msg", "failed to authenticate request", "path", r.URL.Path, "err", err)
 				w.WriteHeader(http.StatusUnauthorized)
 				return
@@ -412,6 +423,15 @@ func (s *Service) getServiceRoutes(host service.Host) []serviceRoute {
 			Base:    base,
 			Handler: handler,
 		})
+
+		if esh, ok := sh.(ExtraServiceHandler); ok {
+			for base, handler := range esh.ExtraServiceHandlers(host) {
+				routes = append(routes, serviceRoute{
+					Base:    base,
+					Handler: handler,
+				})
+			}
+		}
 	}
 
 	sort.Sort(routes)
@@ -448,6 +468,13 @@ func (s *Service) componentHandler(getHost func() (service.Host, error), pathPre
 			w.WriteHeader(http.StatusNotFound)
 			return
 		}
+		s.authenticatorMut.RLock()
+		rbac := s.rbac
+		s.authenticatorMut.RUnlock()
+		if rbac != nil && !rbac.authorizeComponent(w, r, componentID.String(), componentRole(component, r.Method, componentPath)) {
+			return
+		}
+
 		handler := component.Handler()
 		if handler == nil {
 			w.WriteHeader(http.StatusNotFound)
@@ -498,8 +525,10 @@ func (s *Service) Update(newConfig any) error {
 	s.authenticatorMut.Lock()
 	if newArgs.Auth != nil {
 		s.authenticator = newArgs.Auth.authenticator()
+		s.rbac = newArgs.Auth.rbac(s.log)
 	} else {
 		s.authenticator = allowAuthenticator
+		s.rbac = nil
 	}
 	s.authenticatorMut.Unlock()
 
@@ -582,6 +611,17 @@ type ServiceHandler interface {
 	ServiceHandler(host service.Host) (base string, handler http.Handler)
 }
 
+// ExtraServiceHandler is a ServiceHandler which exposes HTTP handlers under
+// more than one base route.
+type ExtraServiceHandler interface {
+	ServiceHandler
+
+	// ExtraServiceHandlers returns additional HTTP handlers to register for
+	// the provided service by base route. Base routes are prioritized the same
+	// way as the base route returned by ServiceHandler.
+	ExtraServiceHandlers(host service.Host) map[string]http.Handler
+}
+
 // lazyListener is a [net.Listener] which lazily initializes the underlying
 // listener.
 type lazyListener struct {
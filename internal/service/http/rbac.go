@@ -0,0 +1,381 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/go-kit/log"
+
+	"github.com/grafana/alloy/internal/runtime/logging/level"
+	"github.com/grafana/alloy/syntax"
+	"github.com/grafana/alloy/syntax/alloytypes"
+)
+
+// Role is the role of a caller of the HTTP server. Roles are ordered: every
+// role is granted access to the routes of the roles before it.
+type Role int
+
+const (
+	// RoleNone is the role of unauthenticated callers, which can't access any
+	// route requiring authentication.
+	RoleNone Role = iota
+	// RoleViewer can access the UI, the metrics and health endpoints, and the
+	// handlers of components.
+	RoleViewer
+	// RoleDebugger can also access endpoints exposing raw telemetry and
+	// internals, such as live debugging, profiling and the support bundle.
+	RoleDebugger
+	// RoleAdmin can also access endpoints changing the state of Alloy, such as
+	// reloading the configuration.
+	RoleAdmin
+)
+
+// String returns the name of the role.
+func (r Role) String() string {
+	switch r {
+	case RoleViewer:
+		return "viewer"
+	case RoleDebugger:
+		return "debugger"
+	case RoleAdmin:
+		return "admin"
+	default:
+		return "none"
+	}
+}
+
+func parseRole(s string) (Role, error) {
+	switch s {
+	case "", "none":
+		return RoleNone, nil
+	case "viewer":
+		return RoleViewer, nil
+	case "debugger":
+		return RoleDebugger, nil
+	case "admin":
+		return RoleAdmin, nil
+	default:
+		return RoleNone, fmt.Errorf("invalid role %q: must be one of none, viewer, debugger or admin", s)
+	}
+}
+
+// routeRoles holds the role required to access routes by path prefix. Routes
+// which aren't listed require RoleViewer.
+var routeRoles = []struct {
+	prefix string
+	role   Role
+}{
+	{"/-/reload", RoleAdmin},
+	{"/-/support", RoleDebugger},
+	{"/debug/pprof", RoleDebugger},
+	{"/api/v0/web/debug/", RoleDebugger},
+}
+
+// peerRoutes holds the path prefixes of the routes which peers of a cluster
+// call on each other. Peers don't have credentials of the auth block, so these
+// routes are exempt from access control: the gossip transport is protected by
+// the cluster name and TLS settings, and the cluster API authenticates peers
+// with the cluster shared secret.
+var peerRoutes = []string{
+	"/api/v1/ckit/transport/",
+	"/api/v1/cluster/",
+}
+
+// requiredRole returns the role required to access path.
+func requiredRole(path string) Role {
+	for _, rr := range routeRoles {
+		if strings.HasPrefix(path, rr.prefix) {
+			return rr.role
+		}
+	}
+	return RoleViewer
+}
+
+// RoleComponent is a Component whose HTTP handler chooses the roles required
+// to access its routes.
+//
+// The handlers of other components require RoleViewer for GET, HEAD and
+// OPTIONS requests, and RoleAdmin for requests with other methods, which may
+// change the state of the component.
+type RoleComponent interface {
+	Component
+
+	// RequiredRole returns the role required to access path of the handler of
+	// the component, for any method. path is relative to the root of the
+	// handler.
+	RequiredRole(path string) Role
+}
+
+// componentRole returns the role required to send a request with method to
+// path of the handler of c.
+func componentRole(c Component, method, path string) Role {
+	if rc, ok := c.(RoleComponent); ok {
+		return rc.RequiredRole(path)
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return RoleViewer
+	default:
+		return RoleAdmin
+	}
+}
+
+// IdentityArguments maps the credentials of a caller to a role. Exactly one
+// of Basic, BearerToken and ClientCertificateSubject must be set.
+type IdentityArguments struct {
+	Name string `alloy:"name,attr"`
+	Role string `alloy:"role,attr"`
+
+	Basic                    *BasicAuthArguments `alloy:"basic,block,optional"`
+	BearerToken              alloytypes.Secret   `alloy:"bearer_token,attr,optional"`
+	ClientCertificateSubject string              `alloy:"client_certificate_subject,attr,optional"`
+}
+
+var _ syntax.Validator = (*IdentityArguments)(nil)
+
+// Validate implements syntax.Validator.
+func (args *IdentityArguments) Validate() error {
+	if args.Name == "" {
+		return fmt.Errorf("identity name must not be empty")
+	}
+	role, err := parseRole(args.Role)
+	if err != nil {
+		return fmt.Errorf("identity %q: %w", args.Name, err)
+	}
+	if role == RoleNone {
+		return fmt.Errorf("identity %q: role must not be none", args.Name)
+	}
+
+	var credentials int
+	if args.Basic != nil {
+		credentials++
+	}
+	if args.BearerToken != "" {
+		credentials++
+	}
+	if args.ClientCertificateSubject != "" {
+		credentials++
+	}
+	if credentials != 1 {
+		return fmt.Errorf("identity %q: exactly one of basic, bearer_token or client_certificate_subject must be set", args.Name)
+	}
+	return nil
+}
+
+// identity is an authenticated caller.
+type identity struct {
+	name string
+	role Role
+}
+
+type roleContextKey struct{}
+
+// rbac authenticates callers with the identities of the auth block, and
+// authorizes them by role.
+type rbac struct {
+	log                 log.Logger
+	filter              FilterAuthArguments
+	unauthenticatedRole Role
+
+	basic   []basicIdentity
+	tokens  []tokenIdentity
+	clients map[string]identity // Identities by client certificate subject.
+}
+
+type basicIdentity struct {
+	identity
+	username, password [sha256.Size]byte
+}
+
+type tokenIdentity struct {
+	identity
+	token [sha256.Size]byte
+}
+
+func newRBAC(args *AuthArguments, l log.Logger) *rbac {
+	// Roles were validated when the arguments were decoded.
+	unauthenticatedRole, _ := parseRole(args.UnauthenticatedRole)
+	r := &rbac{
+		log:                 l,
+		filter:              args.Filter,
+		unauthenticatedRole: unauthenticatedRole,
+		clients:             make(map[string]identity),
+	}
+	for _, idArgs := range args.Identities {
+		role, _ := parseRole(idArgs.Role)
+		id := identity{name: idArgs.Name, role: role}
+
+		switch {
+		case idArgs.Basic != nil:
+			r.basic = append(r.basic, basicIdentity{
+				identity: id,
+				username: sha256.Sum256([]byte(idArgs.Basic.Username)),
+				password: sha256.Sum256([]byte(idArgs.Basic.Password)),
+			})
+		case idArgs.BearerToken != "":
+			r.tokens = append(r.tokens, tokenIdentity{identity: id, token: sha256.Sum256([]byte(idArgs.BearerToken))})
+		default:
+			r.clients[idArgs.ClientCertificateSubject] = id
+		}
+	}
+	return r
+}
+
+// authorize authenticates the caller of r and checks that it's allowed to
+// access the route of r. authorize writes an error response and returns false
+// if it isn't. Otherwise, it returns the request to serve, which carries the
+// role of the caller.
+func (rb *rbac) authorize(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	// In-memory traffic comes from components of this process.
+	if addr, ok := r.Context().Value(http.LocalAddrContextKey).(net.Addr); ok && addr.Network() == "memory" {
+		return r, true
+	}
+
+	compare := func(s string) bool { return strings.HasPrefix(r.URL.Path, s) }
+	if slices.ContainsFunc(peerRoutes, compare) {
+		return r, true
+	}
+	if slices.ContainsFunc(rb.filter.Paths, compare) != rb.filter.AuthMatchingPaths {
+		return r, true
+	}
+
+	id, err := rb.authenticate(r)
+	if err != nil {
+		rb.deny(w, r, nil, requiredRole(r.URL.Path), err.Error())
+		return nil, false
+	}
+	if !rb.check(w, r, id, requiredRole(r.URL.Path)) {
+		return nil, false
+	}
+
+	role := rb.unauthenticatedRole
+	if id != nil {
+		role = id.role
+	}
+	return r.WithContext(context.WithValue(r.Context(), roleContextKey{}, callerRole{id: id, role: role})), true
+}
+
+// callerRole is the role of the caller of a request, stored in its context.
+type callerRole struct {
+	id   *identity // nil for unauthenticated callers.
+	role Role
+}
+
+// authorizeComponent checks that the caller of r may access path of the
+// handler of the component with the given ID.
+func (rb *rbac) authorizeComponent(w http.ResponseWriter, r *http.Request, componentID string, required Role) bool {
+	caller, ok := r.Context().Value(roleContextKey{}).(callerRole)
+	if !ok {
+		// The request wasn't subject to access control.
+		return true
+	}
+	if caller.role >= required {
+		return true
+	}
+	rb.deny(w, r, caller.id, required, fmt.Sprintf("insufficient role for component %s", componentID))
+	return false
+}
+
+// authenticate returns the identity of the caller of r, or nil if r has no
+// credentials. An error is returned if r has invalid credentials.
+func (rb *rbac) authenticate(r *http.Request) (*identity, error) {
+	if username, password, ok := r.BasicAuth(); ok {
+		var (
+			usernameHash = sha256.Sum256([]byte(username))
+			passwordHash = sha256.Sum256([]byte(password))
+			match        *identity
+		)
+		// Compare with every identity to not leak which one matched.
+		for i, bi := range rb.basic {
+			usernameMatch := subtle.ConstantTimeCompare(usernameHash[:], bi.username[:]) == 1
+			passwordMatch := subtle.ConstantTimeCompare(passwordHash[:], bi.password[:]) == 1
+			if usernameMatch && passwordMatch && match == nil {
+				match = &rb.basic[i].identity
+			}
+		}
+		if match == nil {
+			return nil, fmt.Errorf("invalid basic auth credentials")
+		}
+		return match, nil
+	}
+
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		scheme, token, _ := strings.Cut(auth, " ")
+		if !strings.EqualFold(scheme, "Bearer") {
+			return nil, fmt.Errorf("unsupported authorization scheme %q", scheme)
+		}
+		var (
+			tokenHash = sha256.Sum256([]byte(token))
+			match     *identity
+		)
+		for i, ti := range rb.tokens {
+			if subtle.ConstantTimeCompare(tokenHash[:], ti.token[:]) == 1 && match == nil {
+				match = &rb.tokens[i].identity
+			}
+		}
+		if match == nil {
+			return nil, fmt.Errorf("invalid bearer token")
+		}
+		return match, nil
+	}
+
+	// Only trust client certificates which were verified by the TLS
+	// configuration of the server.
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+		subject := r.TLS.VerifiedChains[0][0].Subject.String()
+		if id, ok := rb.clients[subject]; ok {
+			return &id, nil
+		}
+		return nil, fmt.Errorf("unknown client certificate subject %q", subject)
+	}
+	return nil, nil
+}
+
+// check checks that id, or an unauthenticated caller if id is nil, has the
+// required role.
+func (rb *rbac) check(w http.ResponseWriter, r *http.Request, id *identity, required Role) bool {
+	role := rb.unauthenticatedRole
+	if id != nil {
+		role = id.role
+	}
+	if role >= required {
+		return true
+	}
+	rb.deny(w, r, id, required, "insufficient role")
+	return false
+}
+
+// deny logs a denied request for auditing and writes the error response.
+// Unauthenticated callers are asked for credentials.
+func (rb *rbac) deny(w http.ResponseWriter, r *http.Request, id *identity, required Role, reason string) {
+	var (
+		name   = "anonymous"
+		role   = rb.unauthenticatedRole
+		status = http.StatusUnauthorized
+	)
+	if id != nil {
+		name, role, status = id.name, id.role, http.StatusForbidden
+	}
+
+	level.Warn(rb.log).Log(
+		"msg", "denied HTTP request",
+		"identity", name,
+		"role", role,
+		"required_role", required,
+		"method", r.Method,
+		"path", r.URL.Path,
+		"remote_addr", r.RemoteAddr,
+		"reason", reason,
+	)
+
+	if status == http.StatusUnauthorized && len(rb.basic) > 0 {
+		w.Header().Set("WWW-Authenticate", `Basic realm="Restricted"`)
+	}
+	http.Error(w, http.StatusText(status), status)
+}
@@ -0,0 +1,257 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/alloy/internal/component"
+)
+
+func testRBACArguments() AuthArguments {
+	return AuthArguments{
+		Identities: []IdentityArguments{
+			{Name: "alice", Role: "admin", Basic: &BasicAuthArguments{Username: "alice", Password: "password"}},
+			{Name: "ci", Role: "debugger", BearerToken: "token"},
+			{Name: "dashboards", Role: "viewer", ClientCertificateSubject: "CN=dashboards"},
+		},
+		Filter: FilterAuthArguments{
+			Paths:             []string{"/public"},
+			AuthMatchingPaths: false,
+		},
+	}
+}
+
+func Test_rbacAuthorize(t *testing.T) {
+	args := testRBACArguments()
+	require.NoError(t, args.Validate())
+	rb := args.rbac(log.NewNopLogger())
+
+	tests := []struct {
+		name          string
+		path          string
+		basic         []string
+		bearer        string
+		clientSubject string
+		expectStatus  int // 0 if the request is allowed.
+		expectRole    Role
+	}{
+		{
+			name:         "anonymous request to protected path",
+			path:         "/",
+			expectStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "anonymous request to excluded path",
+			path: "/public",
+		},
+		{
+			name:       "admin may reload",
+			path:       "/-/reload",
+			basic:      []string{"alice", "password"},
+			expectRole: RoleAdmin,
+		},
+		{
+			name:         "invalid password",
+			path:         "/",
+			basic:        []string{"alice", "invalid"},
+			expectStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "debugger may take support bundles",
+			path:       "/-/support",
+			bearer:     "token",
+			expectRole: RoleDebugger,
+		},
+		{
+			name:         "debugger may not reload",
+			path:         "/-/reload",
+			bearer:       "token",
+			expectStatus: http.StatusForbidden,
+		},
+		{
+			name:         "invalid bearer token",
+			path:         "/",
+			bearer:       "invalid",
+			expectStatus: http.StatusUnauthorized,
+		},
+		{
+			name:          "viewer may view the UI",
+			path:          "/graph",
+			clientSubject: "CN=dashboards",
+			expectRole:    RoleViewer,
+		},
+		{
+			name:          "viewer may not stream live debugging data",
+			path:          "/api/v0/web/debug/prometheus.scrape.default",
+			clientSubject: "CN=dashboards",
+			expectStatus:  http.StatusForbidden,
+		},
+		{
+			name: "anonymous request from a cluster peer",
+			path: "/api/v1/ckit/transport/message",
+		},
+		{
+			name: "anonymous request to the cluster API",
+			path: "/api/v1/cluster/kv",
+		},
+		{
+			name:          "unknown client certificate",
+			path:          "/",
+			clientSubject: "CN=unknown",
+			expectStatus:  http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodGet, "http://localhost"+tt.path, nil)
+			require.NoError(t, err)
+
+			switch {
+			case tt.basic != nil:
+				req.SetBasicAuth(tt.basic[0], tt.basic[1])
+			case tt.bearer != "":
+				req.Header.Set("Authorization", "Bearer "+tt.bearer)
+			case tt.clientSubject != "":
+				cert := &x509.Certificate{Subject: pkix.Name{CommonName: tt.clientSubject[len("CN="):]}}
+				req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+			}
+
+			authorized, ok := rb.authorize(w, req)
+			if tt.expectStatus != 0 {
+				assert.False(t, ok)
+				assert.Equal(t, tt.expectStatus, w.Code)
+				return
+			}
+			require.True(t, ok)
+			caller, hasRole := authorized.Context().Value(roleContextKey{}).(callerRole)
+			if tt.expectRole == RoleNone {
+				assert.False(t, hasRole, "excluded paths aren't subject to access control")
+				return
+			}
+			assert.Equal(t, tt.expectRole, caller.role)
+		})
+	}
+}
+
+func Test_rbacUnauthenticatedRole(t *testing.T) {
+	args := testRBACArguments()
+	args.UnauthenticatedRole = "viewer"
+	require.NoError(t, args.Validate())
+	rb := args.rbac(log.NewNopLogger())
+
+	req, err := http.NewRequest(http.MethodGet, "http://localhost/", nil)
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	authorized, ok := rb.authorize(w, req)
+	require.True(t, ok)
+
+	// Anonymous callers are asked for credentials when their role isn't
+	// sufficient for a component.
+	w = httptest.NewRecorder()
+	assert.False(t, rb.authorizeComponent(w, authorized, "prometheus.remote_write.default", RoleDebugger))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, `Basic realm="Restricted"`, w.Header().Get("WWW-Authenticate"))
+
+	w = httptest.NewRecorder()
+	assert.True(t, rb.authorizeComponent(w, authorized, "prometheus.scrape.default", RoleViewer))
+}
+
+type fakeComponent struct{}
+
+func (fakeComponent) Run(context.Context) error        { return nil }
+func (fakeComponent) Update(component.Arguments) error { return nil }
+func (fakeComponent) Handler() http.Handler            { return http.NotFoundHandler() }
+
+type fakeRoleComponent struct{ fakeComponent }
+
+func (fakeRoleComponent) RequiredRole(string) Role { return RoleDebugger }
+
+func Test_componentRole(t *testing.T) {
+	assert.Equal(t, RoleViewer, componentRole(fakeComponent{}, http.MethodGet, "/metrics"))
+	assert.Equal(t, RoleViewer, componentRole(fakeComponent{}, http.MethodHead, "/metrics"))
+	assert.Equal(t, RoleAdmin, componentRole(fakeComponent{}, http.MethodPost, "/metrics"))
+	assert.Equal(t, RoleAdmin, componentRole(fakeComponent{}, http.MethodDelete, "/metrics"))
+
+	assert.Equal(t, RoleDebugger, componentRole(fakeRoleComponent{}, http.MethodGet, "/api/v1/query"))
+	assert.Equal(t, RoleDebugger, componentRole(fakeRoleComponent{}, http.MethodPost, "/api/v1/query"))
+}
+
+func TestAuthArgumentsValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        AuthArguments
+		expectError string
+	}{
+		{
+			name: "valid",
+			args: testRBACArguments(),
+		},
+		{
+			name: "basic and identities",
+			args: AuthArguments{
+				Basic:      &BasicAuthArguments{Username: "a", Password: "b"},
+				Identities: []IdentityArguments{{Name: "ci", Role: "viewer", BearerToken: "token"}},
+			},
+			expectError: "basic and identity blocks can't be set at the same time",
+		},
+		{
+			name: "duplicate identity",
+			args: AuthArguments{
+				Identities: []IdentityArguments{
+					{Name: "ci", Role: "viewer", BearerToken: "a"},
+					{Name: "ci", Role: "viewer", BearerToken: "b"},
+				},
+			},
+			expectError: `identity "ci" is defined more than once`,
+		},
+		{
+			name:        "unauthenticated role without identities",
+			args:        AuthArguments{UnauthenticatedRole: "viewer"},
+			expectError: "unauthenticated_role can only be set with identity blocks",
+		},
+		{
+			name: "invalid unauthenticated role",
+			args: AuthArguments{
+				Identities:          []IdentityArguments{{Name: "ci", Role: "viewer", BearerToken: "token"}},
+				UnauthenticatedRole: "owner",
+			},
+			expectError: `unauthenticated_role: invalid role "owner"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.args.Validate()
+			if tt.expectError == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.expectError)
+		})
+	}
+}
+
+func TestIdentityArgumentsValidate(t *testing.T) {
+	valid := IdentityArguments{Name: "ci", Role: "viewer", BearerToken: "token"}
+	require.NoError(t, valid.Validate())
+
+	noCredentials := IdentityArguments{Name: "ci", Role: "viewer"}
+	require.ErrorContains(t, noCredentials.Validate(), "exactly one of")
+
+	twoCredentials := IdentityArguments{Name: "ci", Role: "viewer", BearerToken: "token", ClientCertificateSubject: "CN=ci"}
+	require.ErrorContains(t, twoCredentials.Validate(), "exactly one of")
+
+	noneRole := IdentityArguments{Name: "ci", Role: "none", BearerToken: "token"}
+	require.ErrorContains(t, noneRole.Validate(), "role must not be none")
+}
@@ -1,14 +1,18 @@
 // Package otel implements the otel service.
 // This service registers feature gates will be used by the otelcol components
-// based on upstream Collector components.
+// based on upstream Collector components. It also exposes the running
+// components to the otelcol extensions which report on their health.
 package otel
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"sync"
 
 	"github.com/go-kit/log"
+	"github.com/grafana/alloy/internal/component"
 	"github.com/grafana/alloy/internal/featuregate"
 	"github.com/grafana/alloy/internal/service"
 	"github.com/grafana/alloy/internal/util"
@@ -17,9 +21,19 @@ import (
 // ServiceName defines the name used for the otel service.
 const ServiceName = "otel"
 
-type Service struct{}
+// errNotRunning is returned by the component.Provider of the service until
+// the service is running.
+var errNotRunning = errors.New("the otel service is not running yet")
 
-var _ service.Service = (*Service)(nil)
+type Service struct {
+	mut  sync.RWMutex
+	host service.Host
+}
+
+var (
+	_ service.Service    = (*Service)(nil)
+	_ component.Provider = (*Service)(nil)
+)
 
 func New(logger log.Logger) *Service {
 	if logger == nil {
@@ -49,10 +63,30 @@ func New(logger log.Logger) *Service {
 	return &Service{}
 }
 
-// Data implements service.Service. It returns nil, as the otel service does
-// not have any runtime data.
-func (*Service) Data() any {
-	return nil
+// Data implements service.Service. It returns a [component.Provider] which
+// lists the components of the running Alloy instance.
+func (s *Service) Data() any {
+	return s
+}
+
+// GetComponent implements [component.Provider].
+func (s *Service) GetComponent(id component.ID, opts component.InfoOptions) (*component.Info, error) {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+	if s.host == nil {
+		return nil, errNotRunning
+	}
+	return s.host.GetComponent(id, opts)
+}
+
+// ListComponents implements [component.Provider].
+func (s *Service) ListComponents(moduleID string, opts component.InfoOptions) ([]*component.Info, error) {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+	if s.host == nil {
+		return nil, errNotRunning
+	}
+	return s.host.ListComponents(moduleID, opts)
 }
 
 // Definition implements service.Service.
@@ -67,7 +101,15 @@ func (*Service) Definition() service.Definition {
 
 // Run implements service.Service.
 func (s *Service) Run(ctx context.Context, host service.Host) error {
+	s.mut.Lock()
+	s.host = host
+	s.mut.Unlock()
+
 	<-ctx.Done()
+
+	s.mut.Lock()
+	s.host = nil
+	s.mut.Unlock()
 	return nil
 }
 
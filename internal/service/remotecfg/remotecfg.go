@@ -266,6 +266,12 @@ func (s *Service) Data() any {
 	return Data{Host: host}
 }
 
+// Host returns the host of the controller running the remote configuration,
+// or nil if the service didn't start yet.
+func (s *Service) Host() service.Host {
+	return s.Data().(Data).Host
+}
+
 // Data includes information associated with the HTTP service.
 type Data struct {
 	// Host exposes the Host of the isolated controller that is created by the
@@ -429,7 +435,6 @@ func (s *Service) fetchRemote() error {
 	s.metrics.totalAttempts.Add(1)
 
 	if err == nil {
-		s.metrics.lastLoadSuccess.Set(1)
 		s.metrics.lastFetchSuccessTime.SetToCurrentTime()
 	} else if err != errNotModified {
 		s.metrics.totalFailures.Add(1)
@@ -446,16 +451,23 @@ func (s *Service) fetchRemote() error {
 	}
 
 	// API returned the same configuration as the last one we loaded, no need to reload.
+	// Only successfully loaded configurations are recorded, so a configuration
+	// which failed to load is loaded again.
 	newConfigHash := getHash(b)
 	if s.getLastLoadedCfgHash() == newConfigHash {
 		level.Debug(s.opts.Logger).Log("msg", "skipping over API response since it matched the last loaded one")
+		s.metrics.lastLoadSuccess.Set(1)
 		return nil
 	}
 
 	err = s.parseAndLoad(b)
 	if err != nil {
+		// The configuration was fetched, but couldn't be applied.
+		s.metrics.totalFailures.Add(1)
+		s.metrics.lastLoadSuccess.Set(0)
 		return err
 	}
+	s.metrics.lastLoadSuccess.Set(1)
 
 	// If successful, flush to disk and keep a copy.
 	s.setCachedConfig(b)
@@ -529,16 +541,22 @@ func (s *Service) parseAndLoad(b []byte) error {
 	if len(b) == 0 {
 		return nil
 	}
-	s.setLastLoadedCfgHash(getHash(b))
 	file, err := ctrl.LoadSource(b, nil, s.opts.ConfigPath)
 	if err != nil {
 		return err
 	}
 
+	s.setLastLoadedCfgHash(getHash(b))
 	s.setAstFile(file)
 	return nil
 }
 
+// ConfigHash returns the hash of the last configuration loaded by the
+// service, or an empty string if no configuration was loaded.
+func (s *Service) ConfigHash() string {
+	return s.getLastLoadedCfgHash()
+}
+
 func (s *Service) getLastLoadedCfgHash() string {
 	s.mut.RLock()
 	defer s.mut.RUnlock()
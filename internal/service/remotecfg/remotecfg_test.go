@@ -28,6 +28,7 @@ import (
 	"github.com/grafana/alloy/syntax"
 	"github.com/grafana/alloy/syntax/ast"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -119,19 +120,20 @@ func TestGoodBadGood(t *testing.T) {
 	client.mut.Lock()
 	client.getConfigFunc = buildGetConfigHandler(cfgBad, "", false)
 	client.mut.Unlock()
+	calls := client.getConfigCalls.Load()
 
-	// Verify that the service has still the same "good" configuration has
-	// loaded and flushed on disk, but also recorded the "bad" hash saved for
-	// comparison.
+	// Verify that the service has still the same "good" configuration loaded
+	// and flushed on disk, and that the "bad" configuration is reported as
+	// failing to load every time it's polled.
 	require.EventuallyWithT(t, func(c *assert.CollectT) {
-		b, err := env.svc.getCachedConfig()
-		assert.NoError(c, err)
-		assert.Equal(c, cfgGood, string(b))
+		assert.GreaterOrEqual(c, client.getConfigCalls.Load(), calls+2)
 	}, 1*time.Second, 10*time.Millisecond)
 
-	require.EventuallyWithT(t, func(c *assert.CollectT) {
-		assert.Equal(c, getHash([]byte(cfgBad)), env.svc.getLastLoadedCfgHash())
-	}, 1*time.Second, 10*time.Millisecond)
+	b, err := env.svc.getCachedConfig()
+	require.NoError(t, err)
+	require.Equal(t, cfgGood, string(b))
+	require.Equal(t, getHash([]byte(cfgGood)), env.svc.getLastLoadedCfgHash())
+	require.Equal(t, 0.0, testutil.ToFloat64(env.svc.metrics.lastLoadSuccess))
 
 	// Update the response returned by the API to the previous "good"
 	// configuration.
@@ -142,6 +144,7 @@ func TestGoodBadGood(t *testing.T) {
 	// Verify that the service has updated the hash.
 	require.EventuallyWithT(t, func(c *assert.CollectT) {
 		assert.Equal(c, getHash([]byte(cfgGood)), env.svc.getLastLoadedCfgHash())
+		assert.Equal(c, 1.0, testutil.ToFloat64(env.svc.metrics.lastLoadSuccess))
 	}, 1*time.Second, 10*time.Millisecond)
 
 	cancel()
@@ -0,0 +1,328 @@
+package wal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"sort"
+
+	"github.com/prometheus/prometheus/model/histogram"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/prometheus/prometheus/tsdb/chunks"
+	"github.com/prometheus/prometheus/tsdb/record"
+	"github.com/prometheus/prometheus/tsdb/wlog"
+	"github.com/prometheus/prometheus/util/annotations"
+)
+
+// querierMaxSamples is the maximum number of samples a single Select call of a
+// querier loads from the WAL.
+var querierMaxSamples = 1_000_000
+
+// errTooManySamples is returned when a Select call would load more than
+// querierMaxSamples samples.
+var errTooManySamples = errors.New("query would load too many samples from the WAL, narrow down the time range or the series selected")
+
+// Querier returns a storage.Querier over the series of the head, i.e. the
+// series which are still being tracked in memory.
+//
+// Label names, label values and Select calls with the "series" hint only use
+// the series in memory. Samples are read from the WAL segments following the
+// last checkpoint, so other Select calls are expensive and are only intended for
+// debugging what is being sent to remote storage.
+func (w *Storage) Querier(mint, maxt int64) (storage.Querier, error) {
+	w.walMtx.RLock()
+	defer w.walMtx.RUnlock()
+
+	if w.walClosed {
+		return nil, ErrWALClosed
+	}
+	return &walQuerier{w: w, mint: mint, maxt: maxt}, nil
+}
+
+type walSeries struct {
+	lset    labels.Labels
+	samples []chunks.Sample
+}
+
+// headSeries returns the series in memory which match all matchers. Series
+// which haven't received a sample since mint are skipped.
+func (w *Storage) headSeries(mint int64, matchers []*labels.Matcher) map[chunks.HeadSeriesRef]*walSeries {
+	res := make(map[chunks.HeadSeriesRef]*walSeries)
+	for i := 0; i < w.series.size; i++ {
+		w.series.locks[i].RLock()
+		for ref, s := range w.series.series[i] {
+			s.Lock()
+			lastTs := s.lastTs
+			s.Unlock()
+
+			if lastTs < mint || !matchesAll(s.lset, matchers) {
+				continue
+			}
+			res[ref] = &walSeries{lset: s.lset}
+		}
+		w.series.locks[i].RUnlock()
+	}
+	return res
+}
+
+// readSamples adds the samples between mint and maxt of series from the WAL
+// segments following the last checkpoint.
+//
+// walMtx is only held to look up the WAL directory; segments removed by a
+// concurrent truncation are skipped.
+func (w *Storage) readSamples(series map[chunks.HeadSeriesRef]*walSeries, mint, maxt int64) error {
+	w.walMtx.RLock()
+	if w.walClosed {
+		w.walMtx.RUnlock()
+		return ErrWALClosed
+	}
+	dir := w.wal.Dir()
+	w.walMtx.RUnlock()
+
+	first, last, err := wlog.Segments(dir)
+	if err != nil {
+		return fmt.Errorf("finding WAL segments: %w", err)
+	}
+	if first < 0 {
+		return nil
+	}
+
+	_, lastCheckpoint, err := wlog.LastCheckpoint(dir)
+	switch {
+	case err == nil:
+		first = max(first, lastCheckpoint+1)
+	case !errors.Is(err, record.ErrNotFound):
+		return fmt.Errorf("find last checkpoint: %w", err)
+	}
+
+	r := &walSampleReader{mint: mint, maxt: maxt, series: series}
+	for i := first; i <= last; i++ {
+		s, err := wlog.OpenReadSegment(wlog.SegmentName(dir, i))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("open WAL segment %d: %w", i, err)
+		}
+
+		sr := wlog.NewSegmentBufReader(s)
+		err = r.read(wlog.NewReader(sr))
+		_ = sr.Close()
+
+		switch {
+		case errors.Is(err, errTooManySamples):
+			return err
+		case err != nil && i != last:
+			// The last segment is still being written to, so its final record
+			// may be incomplete.
+			return fmt.Errorf("read WAL segment %d: %w", i, err)
+		}
+	}
+
+	for _, s := range series {
+		sort.SliceStable(s.samples, func(i, j int) bool {
+			return s.samples[i].T() < s.samples[j].T()
+		})
+		s.samples = slices.CompactFunc(s.samples, func(a, b chunks.Sample) bool {
+			return a.T() == b.T()
+		})
+	}
+	return nil
+}
+
+// walSampleReader collects the samples of a set of series from WAL records.
+type walSampleReader struct {
+	mint, maxt int64
+	series     map[chunks.HeadSeriesRef]*walSeries
+	loaded     int
+
+	dec record.Decoder
+}
+
+func (r *walSampleReader) read(reader *wlog.Reader) error {
+	for reader.Next() {
+		rec := reader.Record()
+		switch r.dec.Type(rec) {
+		case record.Samples:
+			samples, err := r.dec.Samples(rec, nil)
+			if err != nil {
+				return err
+			}
+			for _, s := range samples {
+				if err := r.add(s.Ref, walSample{t: s.T, f: s.V}); err != nil {
+					return err
+				}
+			}
+		case record.HistogramSamples:
+			histograms, err := r.dec.HistogramSamples(rec, nil)
+			if err != nil {
+				return err
+			}
+			for _, s := range histograms {
+				if err := r.add(s.Ref, walSample{t: s.T, h: s.H}); err != nil {
+					return err
+				}
+			}
+		case record.FloatHistogramSamples:
+			histograms, err := r.dec.FloatHistogramSamples(rec, nil)
+			if err != nil {
+				return err
+			}
+			for _, s := range histograms {
+				if err := r.add(s.Ref, walSample{t: s.T, fh: s.FH}); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return reader.Err()
+}
+
+func (r *walSampleReader) add(ref chunks.HeadSeriesRef, s walSample) error {
+	if s.t < r.mint || s.t > r.maxt {
+		return nil
+	}
+	series, ok := r.series[ref]
+	if !ok {
+		return nil
+	}
+	if r.loaded >= querierMaxSamples {
+		return errTooManySamples
+	}
+	r.loaded++
+	series.samples = append(series.samples, s)
+	return nil
+}
+
+// walQuerier implements storage.Querier over the series of the head.
+type walQuerier struct {
+	w          *Storage
+	mint, maxt int64
+}
+
+var _ storage.Querier = (*walQuerier)(nil)
+
+// Select implements storage.Querier. Series are always returned sorted. If the
+// "series" hint is set, series are returned without reading their samples.
+func (q *walQuerier) Select(_ context.Context, _ bool, hints *storage.SelectHints, matchers ...*labels.Matcher) storage.SeriesSet {
+	series := q.w.headSeries(q.mint, matchers)
+
+	metadataOnly := hints != nil && hints.Func == "series"
+	if !metadataOnly {
+		if err := q.w.readSamples(series, q.mint, q.maxt); err != nil {
+			return storage.ErrSeriesSet(err)
+		}
+	}
+
+	res := make([]storage.Series, 0, len(series))
+	for _, s := range series {
+		if !metadataOnly && len(s.samples) == 0 {
+			continue
+		}
+		res = append(res, storage.NewListSeries(s.lset, s.samples))
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return labels.Compare(res[i].Labels(), res[j].Labels()) < 0
+	})
+	return &listSeriesSet{series: res, idx: -1}
+}
+
+// LabelValues implements storage.LabelQuerier.
+func (q *walQuerier) LabelValues(_ context.Context, name string, hints *storage.LabelHints, matchers ...*labels.Matcher) ([]string, annotations.Annotations, error) {
+	values := map[string]struct{}{}
+	for _, s := range q.w.headSeries(q.mint, matchers) {
+		if v := s.lset.Get(name); v != "" {
+			values[v] = struct{}{}
+		}
+	}
+	return sortedKeys(values, hints), nil, nil
+}
+
+// LabelNames implements storage.LabelQuerier.
+func (q *walQuerier) LabelNames(_ context.Context, hints *storage.LabelHints, matchers ...*labels.Matcher) ([]string, annotations.Annotations, error) {
+	names := map[string]struct{}{}
+	for _, s := range q.w.headSeries(q.mint, matchers) {
+		s.lset.Range(func(l labels.Label) {
+			names[l.Name] = struct{}{}
+		})
+	}
+	return sortedKeys(names, hints), nil, nil
+}
+
+// Close implements storage.LabelQuerier.
+func (q *walQuerier) Close() error { return nil }
+
+func matchesAll(lset labels.Labels, matchers []*labels.Matcher) bool {
+	for _, m := range matchers {
+		if !m.Matches(lset.Get(m.Name)) {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys(set map[string]struct{}, hints *storage.LabelHints) []string {
+	res := make([]string, 0, len(set))
+	for k := range set {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	if hints != nil && hints.Limit > 0 && len(res) > hints.Limit {
+		res = res[:hints.Limit]
+	}
+	return res
+}
+
+// listSeriesSet implements storage.SeriesSet over a sorted list of series.
+type listSeriesSet struct {
+	series []storage.Series
+	idx    int
+}
+
+func (s *listSeriesSet) Next() bool {
+	s.idx++
+	return s.idx < len(s.series)
+}
+
+func (s *listSeriesSet) At() storage.Series                { return s.series[s.idx] }
+func (s *listSeriesSet) Err() error                        { return nil }
+func (s *listSeriesSet) Warnings() annotations.Annotations { return nil }
+
+// walSample implements chunks.Sample for float and histogram samples read from
+// the WAL.
+type walSample struct {
+	t  int64
+	f  float64
+	h  *histogram.Histogram
+	fh *histogram.FloatHistogram
+}
+
+func (s walSample) T() int64                      { return s.t }
+func (s walSample) F() float64                    { return s.f }
+func (s walSample) H() *histogram.Histogram       { return s.h }
+func (s walSample) FH() *histogram.FloatHistogram { return s.fh }
+
+func (s walSample) Type() chunkenc.ValueType {
+	switch {
+	case s.h != nil:
+		return chunkenc.ValHistogram
+	case s.fh != nil:
+		return chunkenc.ValFloatHistogram
+	default:
+		return chunkenc.ValFloat
+	}
+}
+
+func (s walSample) Copy() chunks.Sample {
+	c := walSample{t: s.t, f: s.f}
+	if s.h != nil {
+		c.h = s.h.Copy()
+	}
+	if s.fh != nil {
+		c.fh = s.fh.Copy()
+	}
+	return c
+}
@@ -0,0 +1,154 @@
+package wal
+
+import (
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/storage"
+	"github.com/prometheus/prometheus/tsdb/chunkenc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorage_Querier(t *testing.T) {
+	walDir := t.TempDir()
+
+	s, err := NewStorage(log.NewNopLogger(), nil, walDir)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, s.Close())
+	}()
+
+	app := s.Appender(t.Context())
+	payload := buildSeries([]string{"foo", "bar", "baz"})
+	for _, metric := range payload {
+		metric.Write(t, app)
+	}
+	require.NoError(t, app.Commit())
+
+	q, err := s.Querier(0, 2)
+	require.NoError(t, err)
+	defer q.Close()
+
+	// Only foo (ts 1) and bar (ts 2) have samples in the time range.
+	ss := q.Select(t.Context(), true, nil, labels.MustNewMatcher(labels.MatchRegexp, "__name__", "foo|bar|baz"))
+
+	var names []string
+	for ss.Next() {
+		series := ss.At()
+		names = append(names, series.Labels().Get("__name__"))
+
+		it := series.Iterator(nil)
+		require.Equal(t, chunkenc.ValFloat, it.Next())
+		ts, _ := it.At()
+		require.LessOrEqual(t, ts, int64(2))
+		require.Equal(t, chunkenc.ValNone, it.Next())
+	}
+	require.NoError(t, ss.Err())
+	require.Equal(t, []string{"bar", "foo"}, names)
+
+	// Label values only use the series in memory, which all received a sample
+	// after the start of the time range.
+	values, _, err := q.LabelValues(t.Context(), "__name__", nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"bar", "baz", "foo"}, values)
+
+	names, _, err = q.LabelNames(t.Context(), nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"__name__"}, names)
+}
+
+func TestStorage_QuerierAfterTruncate(t *testing.T) {
+	walDir := t.TempDir()
+
+	s, err := NewStorage(log.NewNopLogger(), nil, walDir)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, s.Close())
+	}()
+
+	app := s.Appender(t.Context())
+	payload := buildSeries([]string{"foo", "bar", "baz"})
+	for _, metric := range payload {
+		metric.Write(t, app)
+	}
+	require.NoError(t, app.Commit())
+
+	// Series are still in memory after truncation.
+	require.NoError(t, s.Truncate(1))
+
+	q, err := s.Querier(0, 100)
+	require.NoError(t, err)
+	defer q.Close()
+
+	values, _, err := q.LabelValues(t.Context(), "__name__", nil)
+	require.NoError(t, err)
+	require.Equal(t, []string{"bar", "baz", "foo"}, values)
+}
+
+func TestStorage_QuerierSeriesHint(t *testing.T) {
+	walDir := t.TempDir()
+
+	s, err := NewStorage(log.NewNopLogger(), nil, walDir)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, s.Close())
+	}()
+
+	app := s.Appender(t.Context())
+	payload := buildSeries([]string{"foo", "bar"})
+	for _, metric := range payload {
+		metric.Write(t, app)
+	}
+	require.NoError(t, app.Commit())
+
+	q, err := s.Querier(0, 100)
+	require.NoError(t, err)
+	defer q.Close()
+
+	ss := q.Select(t.Context(), true, &storage.SelectHints{Func: "series"})
+	var names []string
+	for ss.Next() {
+		series := ss.At()
+		names = append(names, series.Labels().Get("__name__"))
+		require.Equal(t, chunkenc.ValNone, series.Iterator(nil).Next())
+	}
+	require.NoError(t, ss.Err())
+	require.Equal(t, []string{"bar", "foo"}, names)
+}
+
+func TestStorage_QuerierMaxSamples(t *testing.T) {
+	prev := querierMaxSamples
+	querierMaxSamples = 1
+	defer func() { querierMaxSamples = prev }()
+
+	s, err := NewStorage(log.NewNopLogger(), nil, t.TempDir())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, s.Close())
+	}()
+
+	app := s.Appender(t.Context())
+	payload := buildSeries([]string{"foo", "bar"})
+	for _, metric := range payload {
+		metric.Write(t, app)
+	}
+	require.NoError(t, app.Commit())
+
+	q, err := s.Querier(0, 100)
+	require.NoError(t, err)
+	defer q.Close()
+
+	ss := q.Select(t.Context(), true, nil)
+	require.False(t, ss.Next())
+	require.ErrorIs(t, ss.Err(), errTooManySamples)
+}
+
+func TestStorage_QuerierAfterClose(t *testing.T) {
+	s, err := NewStorage(log.NewNopLogger(), nil, t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, s.Close())
+
+	_, err = s.Querier(0, 100)
+	require.ErrorIs(t, err, ErrWALClosed)
+}
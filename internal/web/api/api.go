@@ -22,6 +22,7 @@ import (
 	"github.com/grafana/alloy/internal/service/cluster"
 	"github.com/grafana/alloy/internal/service/livedebugging"
 	"github.com/grafana/alloy/internal/service/remotecfg"
+	"github.com/grafana/ckit/peer"
 	"github.com/prometheus/prometheus/util/httputil"
 )
 
@@ -53,6 +54,8 @@ func (a *AlloyAPI) RegisterRoutes(urlPrefix string, r *mux.Router) {
 	r.Handle(path.Join(urlPrefix, "/remotecfg/components/{id:.+}"), httputil.CompressionHandler{Handler: getComponentHandlerRemoteCfg(a.alloy)})
 
 	r.Handle(path.Join(urlPrefix, "/peers"), httputil.CompressionHandler{Handler: getClusteringPeersHandler(a.alloy)})
+	r.Handle(path.Join(urlPrefix, "/leaders"), httputil.CompressionHandler{Handler: getClusteringLeadersHandler(a.alloy)})
+	r.Handle(path.Join(urlPrefix, "/fleet"), httputil.CompressionHandler{Handler: getClusteringFleetHandler(a.alloy)})
 	r.Handle(path.Join(urlPrefix, "/debug/{id:.+}"), liveDebugging(a.alloy, a.CallbackManager, a.logger))
 
 	r.Handle(path.Join(urlPrefix, "/graph"), graph(a.alloy, a.CallbackManager, a.logger))
@@ -159,6 +162,68 @@ func getClusteringPeersHandler(host service.Host) http.HandlerFunc {
 	}
 }
 
+func getClusteringFleetHandler(host service.Host) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		svc, found := host.GetService(cluster.ServiceName)
+		if !found {
+			http.Error(w, "cluster service not running", http.StatusInternalServerError)
+			return
+		}
+		fc, ok := svc.Data().(cluster.FleetCluster)
+		if !ok {
+			http.Error(w, "cluster doesn't report the status of peers", http.StatusNotImplemented)
+			return
+		}
+		bb, err := json.Marshal(fc.Fleet())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write(bb)
+	}
+}
+
+// leaderInfo describes the leader of a component which only runs on the
+// leader of the cluster.
+type leaderInfo struct {
+	ComponentID string     `json:"componentID"`
+	Leader      *peer.Peer `json:"leader"` // nil if the leader isn't known yet.
+}
+
+func getClusteringLeadersHandler(host service.Host) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		components := component.GetAllComponents(host, component.InfoOptions{})
+		if remoteCfgHost, err := remotecfg.GetHost(host); err == nil {
+			components = append(components, component.GetAllComponents(remoteCfgHost, component.InfoOptions{})...)
+		}
+
+		leaders := []leaderInfo{}
+		for _, info := range components {
+			lc, ok := info.Component.(cluster.LeaderComponent)
+			if !ok {
+				continue
+			}
+			leadership := lc.Leadership()
+			if leadership == nil {
+				continue
+			}
+
+			li := leaderInfo{ComponentID: info.ID.String()}
+			if leader, ok := leadership.Leader(); ok {
+				li.Leader = &leader
+			}
+			leaders = append(leaders, li)
+		}
+
+		bb, err := json.Marshal(leaders)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write(bb)
+	}
+}
+
 type dataKey struct {
 	ComponentID livedebugging.ComponentID
 	Type        livedebugging.DataType
@@ -3,6 +3,7 @@ import { BrowserRouter, Route, Routes } from 'react-router-dom';
 import Navbar from './features/layout/Navbar';
 import PageClusteringPeers from './pages/Clustering';
 import ComponentDetailPage from './pages/ComponentDetailPage';
+import PageFleet from './pages/Fleet';
 import Graph from './pages/Graph';
 import PageLiveDebugging from './pages/LiveDebugging';
 import PageComponentList from './pages/PageComponentList';
@@ -27,6 +28,7 @@ const Router = ({ basePath }: Props) => {
 
           <Route path="/graph/*" element={<Graph />} />
           <Route path="/clustering" element={<PageClusteringPeers />} />
+          <Route path="/fleet" element={<PageFleet />} />
           <Route path="/debug/*" element={<PageLiveDebugging />} />
         </Routes>
       </main>
@@ -0,0 +1,71 @@
+import { FleetPeer, NodeStatus } from '../clustering/types';
+
+import Table from './Table';
+
+import styles from './PeerList.module.css';
+
+interface FleetListProps {
+  fleet: FleetPeer[];
+}
+
+const TABLEHEADERS = ['Node Name', 'Current State', 'Version', 'Config Hash', 'Components', 'Local Node'];
+
+/**
+ * shortHash abbreviates the config hashes of a node for display.
+ */
+const shortHash = (status: NodeStatus): string => {
+  const hashes = [status.configHash, status.remoteConfigHash].filter((h) => h).map((h) => h?.substring(0, 12));
+  return hashes.length > 0 ? hashes.join(' / ') : 'none';
+};
+
+const FleetList = ({ fleet }: FleetListProps) => {
+  const tableStyles = { width: '130px' };
+
+  /**
+   * Custom renderer for table data
+   */
+  const renderTableData = () => {
+    return fleet.map(({ peer, status, configDiverged, versionDiverged }) => {
+      const components = status?.components;
+      const failing = (components?.unhealthy ?? 0) + (components?.exited ?? 0);
+
+      return (
+        <tr key={peer.name} style={{ lineHeight: '2.5' }}>
+          <td>
+            <span className={styles.idName}>{peer.name}</span>
+          </td>
+          <td>
+            <span className={styles.idName}>{peer.state}</span>
+          </td>
+          <td>
+            <span className={styles.idName} title={versionDiverged ? 'Differs from most peers' : undefined}>
+              {status ? status.version : 'unknown'} {versionDiverged ? '⚠️' : ''}
+            </span>
+          </td>
+          <td>
+            <span className={styles.idName} title={configDiverged ? 'Differs from most peers' : undefined}>
+              {status ? shortHash(status) : 'unknown'} {configDiverged ? '⚠️' : ''}
+            </span>
+          </td>
+          <td>
+            <span className={styles.idName} title={components?.unhealthyIDs?.join('\n')}>
+              {components ? `${components.healthy} healthy, ${failing} unhealthy` : 'unknown'}{' '}
+              {failing > 0 ? '⚠️' : ''}
+            </span>
+          </td>
+          <td>
+            <span> {peer.isSelf ? '✅' : ' '}</span>
+          </td>
+        </tr>
+      );
+    });
+  };
+
+  return (
+    <div className={styles.list}>
+      <Table tableHeaders={TABLEHEADERS} renderTableData={renderTableData} style={tableStyles} />
+    </div>
+  );
+};
+
+export default FleetList;
@@ -0,0 +1,45 @@
+import { LeaderInfo } from '../clustering/types';
+
+import Table from './Table';
+
+import styles from './PeerList.module.css';
+
+interface LeaderListProps {
+  leaders: LeaderInfo[];
+}
+
+const TABLEHEADERS = ['Component', 'Leader Node', 'Advertised Address', 'Local Node'];
+
+const LeaderList = ({ leaders }: LeaderListProps) => {
+  const tableStyles = { width: '130px' };
+
+  /**
+   * Custom renderer for table data
+   */
+  const renderTableData = () => {
+    return leaders.map(({ componentID, leader }) => (
+      <tr key={componentID} style={{ lineHeight: '2.5' }}>
+        <td>
+          <span className={styles.idName}>{componentID}</span>
+        </td>
+        <td>
+          <span className={styles.idName}>{leader?.name ?? 'unknown'}</span>
+        </td>
+        <td>
+          <span className={styles.idName}>{leader?.addr ?? ''}</span>
+        </td>
+        <td>
+          <span> {leader?.isSelf ? '✅' : ' '}</span>
+        </td>
+      </tr>
+    ));
+  };
+
+  return (
+    <div className={styles.list}>
+      <Table tableHeaders={TABLEHEADERS} renderTableData={renderTableData} style={tableStyles} />
+    </div>
+  );
+};
+
+export default LeaderList;
@@ -7,3 +7,50 @@ export interface PeerInfo {
 
   isSelf: boolean;
 }
+
+export interface LeaderInfo {
+  componentID: string;
+
+  // Leader is null if the leader of the component isn't known yet.
+  leader: PeerInfo | null;
+}
+
+export interface ComponentHealthSummary {
+  healthy: number;
+
+  unhealthy: number;
+
+  exited: number;
+
+  unknown: number;
+
+  // IDs of the unhealthy and exited components, possibly truncated.
+  unhealthyIDs?: string[];
+}
+
+export interface NodeStatus {
+  version: string;
+
+  // Hash of the configuration files loaded by the node.
+  configHash?: string;
+
+  // Hash of the configuration loaded from remote configuration.
+  remoteConfigHash?: string;
+
+  components: ComponentHealthSummary;
+
+  updateTime: string;
+}
+
+export interface FleetPeer {
+  peer: PeerInfo;
+
+  // Status is null if the status of the peer isn't known yet.
+  status: NodeStatus | null;
+
+  // True if the peer runs another configuration than most peers.
+  configDiverged: boolean;
+
+  // True if the peer runs another version than most peers.
+  versionDiverged: boolean;
+}
@@ -23,6 +23,11 @@ function Navbar() {
             Clustering
           </NavLink>
         </li>
+        <li>
+          <NavLink to="/fleet" className="nav-link">
+            Fleet
+          </NavLink>
+        </li>
         <li>
           <NavLink to="/remotecfg" className="nav-link">
             Remote Configuration
@@ -0,0 +1,27 @@
+import { useEffect, useState } from 'react';
+
+import { FleetPeer } from '../features/clustering/types';
+
+/**
+ * useFleetInfo retrieves the status of every clustering peer from the API.
+ */
+export const useFleetInfo = (): FleetPeer[] => {
+  const [fleet, setFleet] = useState<FleetPeer[]>([]);
+
+  useEffect(function () {
+    const worker = async () => {
+      const infoPath = './api/v0/web/fleet';
+
+      // Request is relative to the <base> tag inside of <head>.
+      const resp = await fetch(infoPath, {
+        cache: 'no-cache',
+        credentials: 'same-origin',
+      });
+      setFleet(await resp.json());
+    };
+
+    worker().catch(console.error);
+  }, []);
+
+  return fleet;
+};
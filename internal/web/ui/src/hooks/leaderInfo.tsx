@@ -0,0 +1,28 @@
+import { useEffect, useState } from 'react';
+
+import { LeaderInfo } from '../features/clustering/types';
+
+/**
+ * useLeaderInfo retrieves the leaders of the components which only run on the
+ * leader of the cluster from the API.
+ */
+export const useLeaderInfo = (): LeaderInfo[] => {
+  const [leaders, setLeaders] = useState<LeaderInfo[]>([]);
+
+  useEffect(function () {
+    const worker = async () => {
+      const infoPath = './api/v0/web/leaders';
+
+      // Request is relative to the <base> tag inside of <head>.
+      const resp = await fetch(infoPath, {
+        cache: 'no-cache',
+        credentials: 'same-origin',
+      });
+      setLeaders(await resp.json());
+    };
+
+    worker().catch(console.error);
+  }, []);
+
+  return leaders;
+};
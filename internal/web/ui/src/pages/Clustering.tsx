@@ -1,15 +1,25 @@
 import { faNetworkWired } from '@fortawesome/free-solid-svg-icons';
 
+import LeaderList from '../features/clustering/LeaderList';
 import PeerList from '../features/clustering/PeerList';
 import Page from '../features/layout/Page';
+import { useLeaderInfo } from '../hooks/leaderInfo';
 import { usePeerInfo } from '../hooks/peerInfo';
 
 function PageClusteringPeers() {
   const peers = usePeerInfo();
+  const leaders = useLeaderInfo();
 
   return (
     <Page name="Clustering" desc="List of clustering peers" icon={faNetworkWired}>
       <PeerList peers={peers} />
+      {leaders.length > 0 && (
+        <>
+          <h2>Leaders</h2>
+          <p>Components which only run on the leader of the cluster.</p>
+          <LeaderList leaders={leaders} />
+        </>
+      )}
     </Page>
   );
 }
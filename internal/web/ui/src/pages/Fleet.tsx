@@ -0,0 +1,21 @@
+import { faServer } from '@fortawesome/free-solid-svg-icons';
+
+import FleetList from '../features/clustering/FleetList';
+import Page from '../features/layout/Page';
+import { useFleetInfo } from '../hooks/fleetInfo';
+
+function PageFleet() {
+  const fleet = useFleetInfo();
+  const diverged = fleet.filter((p) => p.configDiverged || p.versionDiverged).length;
+  const unhealthy = fleet.filter((p) => (p.status?.components.unhealthy ?? 0) + (p.status?.components.exited ?? 0) > 0);
+
+  return (
+    <Page name="Fleet" desc="Configuration, version and component health of clustering peers" icon={faServer}>
+      {diverged > 0 && <p>⚠️ {diverged} peer(s) run a different configuration or version than most peers.</p>}
+      {unhealthy.length > 0 && <p>⚠️ {unhealthy.length} peer(s) have unhealthy components.</p>}
+      <FleetList fleet={fleet} />
+    </Page>
+  );
+}
+
+export default PageFleet;